@@ -0,0 +1,33 @@
+// schemagen записывает JSON Schema актуальных версий событий в каталог -out.
+// Схемы прежних версий не трогает: они нужны, пока в Kafka есть такие сообщения
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"contracts/events"
+)
+
+func main() {
+	out := flag.String("out", "schemas", "directory to write schemas to")
+	flag.Parse()
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatalf("Failed to create %s: %v", *out, err)
+	}
+
+	for _, contract := range events.Contracts() {
+		data, err := events.MarshalSchema(events.GenerateSchema(contract))
+		if err != nil {
+			log.Fatalf("Failed to marshal schema for %s: %v", contract.Type, err)
+		}
+
+		path := filepath.Join(*out, events.SchemaFileName(contract.Type, contract.Version))
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			log.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Package events — общие контракты событий Kafka между сервисами: типы payload,
+// их версии, JSON Schema и апкастеры старых версий.
+//
+// Чтобы изменить payload несовместимо: поднять версию события в contracts,
+// добавить апкастер с прежней версии в upcasters и перегенерировать схемы
+// (go generate ./...). Схемы прежних версий остаются в schemas/ как есть.
+package events
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+//go:generate go run ../cmd/schemagen -out schemas
+
+var (
+	ErrUnknownEvent       = errors.New("unknown event type")
+	ErrUnsupportedVersion = errors.New("unsupported schema version")
+	ErrInvalidPayload     = errors.New("payload does not match schema")
+)
+
+// Contract — актуальная версия события и Go-тип его payload
+type Contract struct {
+	Type    string
+	Version int
+	Payload any
+}
+
+var contracts = []Contract{
+	{Type: OrderCreated, Version: 1, Payload: OrderCreatedEvent{}},
+	{Type: OrderUpdated, Version: 1, Payload: OrderUpdatedEvent{}},
+	{Type: OrderCancelled, Version: 1, Payload: OrderCancelledEvent{}},
+	{Type: OrderCompleted, Version: 1, Payload: OrderCompletedEvent{}},
+	{Type: PaymentCompleted, Version: 1, Payload: PaymentCompletedEvent{}},
+	{Type: PaymentFailed, Version: 1, Payload: PaymentFailedEvent{}},
+	{Type: PaymentRefunded, Version: 1, Payload: PaymentRefundedEvent{}},
+	{Type: PaymentAuthorized, Version: 1, Payload: PaymentAuthorizedEvent{}},
+	{Type: PaymentCaptured, Version: 1, Payload: PaymentCapturedEvent{}},
+	{Type: PaymentReleased, Version: 1, Payload: PaymentReleasedEvent{}},
+	{Type: AccountToppedUp, Version: 1, Payload: AccountToppedUpEvent{}},
+}
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+var (
+	schemasOnce sync.Once
+	schemas     map[string]*Schema
+	schemasErr  error
+)
+
+// Contracts возвращает актуальные версии всех событий
+func Contracts() []Contract {
+	return append([]Contract(nil), contracts...)
+}
+
+func Lookup(eventType string) (Contract, bool) {
+	for _, contract := range contracts {
+		if contract.Type == eventType {
+			return contract, true
+		}
+	}
+	return Contract{}, false
+}
+
+// LatestVersion возвращает актуальную версию события, 0 — если контракта нет
+func LatestVersion(eventType string) int {
+	contract, _ := Lookup(eventType)
+	return contract.Version
+}
+
+func SchemaFileName(eventType string, version int) string {
+	return fmt.Sprintf("%s.v%d.json", eventType, version)
+}
+
+// LoadSchema возвращает сохранённую схему конкретной версии события
+func LoadSchema(eventType string, version int) (*Schema, error) {
+	schemasOnce.Do(loadSchemas)
+	if schemasErr != nil {
+		return nil, schemasErr
+	}
+
+	schema, ok := schemas[SchemaFileName(eventType, version)]
+	if !ok {
+		if _, known := Lookup(eventType); !known {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownEvent, eventType)
+		}
+		return nil, fmt.Errorf("%w: %s v%d", ErrUnsupportedVersion, eventType, version)
+	}
+	return schema, nil
+}
+
+// Validate проверяет payload по схеме указанной версии события
+func Validate(eventType string, version int, payload []byte) error {
+	schema, err := LoadSchema(eventType, version)
+	if err != nil {
+		return err
+	}
+
+	if err := schema.Validate(payload); err != nil {
+		return fmt.Errorf("%w: %s v%d: %v", ErrInvalidPayload, eventType, version, err)
+	}
+	return nil
+}
+
+// IsContractViolation сообщает, что событие не соответствует контракту:
+// повторная отправка или обработка того же payload не поможет
+func IsContractViolation(err error) bool {
+	return errors.Is(err, ErrUnknownEvent) || errors.Is(err, ErrUnsupportedVersion) || errors.Is(err, ErrInvalidPayload)
+}
+
+func loadSchemas() {
+	entries, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		schemasErr = fmt.Errorf("failed to read schemas: %w", err)
+		return
+	}
+
+	schemas = make(map[string]*Schema, len(entries))
+	for _, entry := range entries {
+		data, err := schemaFiles.ReadFile("schemas/" + entry.Name())
+		if err != nil {
+			schemasErr = fmt.Errorf("failed to read schema %s: %w", entry.Name(), err)
+			return
+		}
+
+		var schema Schema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			schemasErr = fmt.Errorf("failed to parse schema %s: %w", entry.Name(), err)
+			return
+		}
+		schemas[entry.Name()] = &schema
+	}
+}
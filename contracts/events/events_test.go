@@ -0,0 +1,101 @@
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"contracts/money"
+)
+
+func TestSchemasUpToDate(t *testing.T) {
+	for _, contract := range Contracts() {
+		generated, err := MarshalSchema(GenerateSchema(contract))
+		assert.NoError(t, err)
+
+		stored, err := schemaFiles.ReadFile("schemas/" + SchemaFileName(contract.Type, contract.Version))
+		assert.NoError(t, err, "run go generate ./... in contracts")
+		assert.Equal(t, string(generated), string(stored), "schema of %s is stale, run go generate ./... in contracts", contract.Type)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	payload, _ := json.Marshal(PaymentAuthorizedEvent{
+		PaymentID:     "payment-1",
+		OrderID:       "order-1",
+		UserID:        "user-1",
+		AmountMoney:   money.New(1500, money.USD),
+		Currency:      money.USD,
+		HeldMoney:     money.New(1500, money.USD),
+		HoldExpiresAt: time.Now(),
+		Amount:        15,
+	})
+	assert.NoError(t, Validate(PaymentAuthorized, 1, payload))
+
+	err := Validate(PaymentAuthorized, 1, []byte(`{"payment_id": "payment-1"}`))
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+	assert.True(t, IsContractViolation(err))
+
+	err = Validate(OrderCreated, 1, []byte(`{"order_id": "order-1", "user_id": "user-1", "amount_money": {"minor_units": 1.5, "currency": "USD"}, "currency": "USD", "items": null, "amount": 0}`))
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+	assert.Contains(t, err.Error(), "$.amount_money.minor_units")
+
+	assert.ErrorIs(t, Validate("order.shipped", 1, payload), ErrUnknownEvent)
+	assert.ErrorIs(t, Validate(PaymentAuthorized, 2, payload), ErrUnsupportedVersion)
+}
+
+func TestValidate_AllowsUnknownProperties(t *testing.T) {
+	payload := []byte(`{"order_id": "order-1", "status": "paid", "tracking_number": "TRK-1"}`)
+
+	assert.NoError(t, Validate(OrderUpdated, 1, payload))
+}
+
+func TestUpcast_LegacyMoney(t *testing.T) {
+	legacy := []byte(`{"order_id": "order-1", "user_id": "user-1", "currency": "EUR", "amount": 25.5,
+		"items": [{"product_id": "p-1", "product_name": "Book", "quantity": 2, "unit_price": 12.75, "amount": 25.5}]}`)
+
+	payload, version, err := Upcast(OrderCreated, 0, legacy)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, version)
+	assert.NoError(t, Validate(OrderCreated, version, payload))
+
+	var event OrderCreatedEvent
+	assert.NoError(t, json.Unmarshal(payload, &event))
+	assert.Equal(t, money.New(2550, money.EUR), event.AmountMoney)
+	assert.Equal(t, money.New(1275, money.EUR), event.Items[0].UnitPriceMoney)
+	assert.Equal(t, money.New(2550, money.EUR), event.Items[0].AmountMoney)
+}
+
+func TestUpcast_KeepsMoneyAndLatestVersion(t *testing.T) {
+	current := []byte(`{"order_id": "order-1", "amount_money": {"minor_units": 100, "currency": "USD"}, "amount": 7}`)
+
+	payload, version, err := Upcast(OrderCancelled, 0, current)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, version)
+	assert.JSONEq(t, string(current), string(payload))
+
+	payload, version, err = Upcast(OrderCancelled, 1, current)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, version)
+	assert.Equal(t, current, payload)
+
+	_, _, err = Upcast(OrderCancelled, 2, current)
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+}
+
+// События из testdata/order_flow читают тесты сквозного сценария в orders и payments,
+// поэтому они должны соответствовать актуальным схемам
+func TestOrderFlowTestdataMatchesSchemas(t *testing.T) {
+	for file, eventType := range map[string]string{
+		"order_created.json":      OrderCreated,
+		"payment_authorized.json": PaymentAuthorized,
+	} {
+		payload, err := os.ReadFile(filepath.Join("testdata", "order_flow", file))
+		assert.NoError(t, err)
+		assert.NoError(t, Validate(eventType, LatestVersion(eventType), payload), file)
+	}
+}
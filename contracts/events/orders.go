@@ -0,0 +1,65 @@
+package events
+
+import "contracts/money"
+
+const (
+	OrderCreated   = "order.created"
+	OrderUpdated   = "order.updated"
+	OrderCancelled = "order.cancelled"
+	OrderCompleted = "order.completed"
+)
+
+type OrderCreatedEvent struct {
+	OrderID     string           `json:"order_id"`
+	UserID      string           `json:"user_id"`
+	AmountMoney money.Money      `json:"amount_money"`
+	Currency    string           `json:"currency"`
+	Items       []OrderItemEvent `json:"items"`
+
+	// Deprecated: use AmountMoney.
+	Amount float64 `json:"amount"`
+}
+
+type OrderItemEvent struct {
+	ProductID      string      `json:"product_id"`
+	ProductName    string      `json:"product_name"`
+	Quantity       int         `json:"quantity"`
+	UnitPriceMoney money.Money `json:"unit_price_money"`
+	AmountMoney    money.Money `json:"amount_money"`
+
+	// Deprecated: use UnitPriceMoney.
+	UnitPrice float64 `json:"unit_price"`
+
+	// Deprecated: use AmountMoney.
+	Amount float64 `json:"amount"`
+}
+
+type OrderUpdatedEvent struct {
+	OrderID   string `json:"order_id"`
+	Status    string `json:"status"`
+	PaymentID string `json:"payment_id,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type OrderCompletedEvent struct {
+	OrderID     string      `json:"order_id"`
+	UserID      string      `json:"user_id"`
+	AmountMoney money.Money `json:"amount_money"`
+	Currency    string      `json:"currency"`
+	PaymentID   string      `json:"payment_id"`
+
+	// Deprecated: use AmountMoney.
+	Amount float64 `json:"amount"`
+}
+
+type OrderCancelledEvent struct {
+	OrderID     string      `json:"order_id"`
+	UserID      string      `json:"user_id"`
+	AmountMoney money.Money `json:"amount_money"`
+	Currency    string      `json:"currency"`
+	PaymentID   string      `json:"payment_id,omitempty"`
+	Reason      string      `json:"reason,omitempty"`
+
+	// Deprecated: use AmountMoney.
+	Amount float64 `json:"amount"`
+}
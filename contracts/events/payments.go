@@ -0,0 +1,102 @@
+package events
+
+import (
+	"time"
+
+	"contracts/money"
+)
+
+const (
+	PaymentCompleted  = "payment.completed"
+	PaymentFailed     = "payment.failed"
+	PaymentRefunded   = "payment.refunded"
+	PaymentAuthorized = "payment.authorized"
+	PaymentCaptured   = "payment.captured"
+	PaymentReleased   = "payment.released"
+	AccountToppedUp   = "account.topped_up"
+)
+
+type PaymentCompletedEvent struct {
+	PaymentID     string      `json:"payment_id"`
+	OrderID       string      `json:"order_id"`
+	UserID        string      `json:"user_id"`
+	AmountMoney   money.Money `json:"amount_money"`
+	Currency      string      `json:"currency"`
+	TransactionID string      `json:"transaction_id"`
+
+	// Deprecated: use AmountMoney.
+	Amount float64 `json:"amount"`
+}
+
+type PaymentFailedEvent struct {
+	PaymentID    string      `json:"payment_id"`
+	OrderID      string      `json:"order_id"`
+	UserID       string      `json:"user_id"`
+	AmountMoney  money.Money `json:"amount_money"`
+	Currency     string      `json:"currency"`
+	ErrorMessage string      `json:"error_message"`
+
+	// Deprecated: use AmountMoney.
+	Amount float64 `json:"amount"`
+}
+
+type PaymentRefundedEvent struct {
+	PaymentID     string      `json:"payment_id"`
+	OrderID       string      `json:"order_id"`
+	UserID        string      `json:"user_id"`
+	AmountMoney   money.Money `json:"amount_money"`
+	Currency      string      `json:"currency"`
+	TransactionID string      `json:"transaction_id"`
+	Reason        string      `json:"reason,omitempty"`
+
+	// Deprecated: use AmountMoney.
+	Amount float64 `json:"amount"`
+}
+
+type PaymentAuthorizedEvent struct {
+	PaymentID     string      `json:"payment_id"`
+	OrderID       string      `json:"order_id"`
+	UserID        string      `json:"user_id"`
+	AmountMoney   money.Money `json:"amount_money"`
+	Currency      string      `json:"currency"`
+	HeldMoney     money.Money `json:"held_money"`
+	HoldExpiresAt time.Time   `json:"hold_expires_at"`
+
+	// Deprecated: use AmountMoney.
+	Amount float64 `json:"amount"`
+}
+
+type PaymentCapturedEvent struct {
+	PaymentID     string      `json:"payment_id"`
+	OrderID       string      `json:"order_id"`
+	UserID        string      `json:"user_id"`
+	AmountMoney   money.Money `json:"amount_money"`
+	Currency      string      `json:"currency"`
+	ChargedMoney  money.Money `json:"charged_money"`
+	TransactionID string      `json:"transaction_id"`
+
+	// Deprecated: use AmountMoney.
+	Amount float64 `json:"amount"`
+}
+
+type PaymentReleasedEvent struct {
+	PaymentID   string      `json:"payment_id"`
+	OrderID     string      `json:"order_id"`
+	UserID      string      `json:"user_id"`
+	AmountMoney money.Money `json:"amount_money"`
+	Currency    string      `json:"currency"`
+	Reason      string      `json:"reason,omitempty"`
+
+	// Deprecated: use AmountMoney.
+	Amount float64 `json:"amount"`
+}
+
+type AccountToppedUpEvent struct {
+	AccountID      string      `json:"account_id"`
+	UserID         string      `json:"user_id"`
+	AmountMoney    money.Money `json:"amount_money"`
+	BalanceMoney   money.Money `json:"balance_money"`
+	AvailableMoney money.Money `json:"available_money"`
+	Currency       string      `json:"currency"`
+	TransactionID  string      `json:"transaction_id"`
+}
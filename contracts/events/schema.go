@@ -0,0 +1,238 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"contracts/money"
+)
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema — подмножество JSON Schema (draft 2020-12), которого достаточно для
+// описания событий: типы, обязательные поля, вложенные объекты и массивы.
+// Type — строка или список строк, как в самой спецификации
+type Schema struct {
+	Dialect    string             `json:"$schema,omitempty"`
+	Title      string             `json:"title,omitempty"`
+	Type       any                `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+}
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	moneyType = reflect.TypeOf(money.Money{})
+)
+
+// GenerateSchema строит схему по Go-типу события: поля без omitempty обязательны
+func GenerateSchema(contract Contract) *Schema {
+	schema := schemaFor(reflect.TypeOf(contract.Payload))
+	schema.Dialect = schemaDialect
+	schema.Title = fmt.Sprintf("%s v%d", contract.Type, contract.Version)
+	return schema
+}
+
+// MarshalSchema сериализует схему в том виде, в каком она лежит в schemas/
+func MarshalSchema(schema *Schema) ([]byte, error) {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func schemaFor(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case moneyType:
+		// Money сериализуется сам (MarshalJSON), поэтому его поля описаны явно
+		return &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"minor_units": {Type: "integer"},
+				"currency":    {Type: "string"},
+			},
+			Required: []string{"minor_units", "currency"},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := schemaFor(t.Elem())
+		schema.Type = []string{schema.Type.(string), "null"}
+		return schema
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		// nil-срез сериализуется в null
+		return &Schema{Type: []string{"array", "null"}, Items: schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return &Schema{}
+	}
+}
+
+func structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = schemaFor(field.Type)
+		if !slices.Contains(strings.Split(options, ","), "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+// Validate проверяет JSON-документ по схеме и возвращает первое найденное нарушение
+func (s *Schema) Validate(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	return s.validate(value, "$")
+}
+
+func (s *Schema) validate(value any, path string) error {
+	if types := s.types(); len(types) > 0 && !slices.ContainsFunc(types, func(t string) bool { return matchesType(t, value) }) {
+		return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(types, " or "), jsonType(value))
+	}
+
+	switch value := value.(type) {
+	case string:
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+				return fmt.Errorf("%s: expected date-time, got %q", path, value)
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := value[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		// Свойства вне схемы допустимы: новые поля не ломают старых консьюмеров
+		for _, name := range sortedKeys(s.Properties) {
+			property, ok := value[name]
+			if !ok {
+				continue
+			}
+			if err := s.Properties[name].validate(property, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if s.Items == nil {
+			return nil
+		}
+		for i, item := range value {
+			if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) types() []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	default:
+		return nil
+	}
+}
+
+func matchesType(schemaType string, value any) bool {
+	switch schemaType {
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := number.Int64()
+		return err == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	default:
+		return schemaType == jsonType(value)
+	}
+}
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func sortedKeys(properties map[string]*Schema) []string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,108 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"contracts/money"
+)
+
+// Upcaster переводит payload события с версии N на N+1 на месте
+type Upcaster func(payload map[string]any) error
+
+type upcasterKey struct {
+	eventType string
+	from      int
+}
+
+var upcasters = map[upcasterKey]Upcaster{}
+
+func init() {
+	// Версия 0 — сообщения без заголовка schema_version, отправленные до версионирования
+	for _, contract := range contracts {
+		upcasters[upcasterKey{contract.Type, 0}] = upcastLegacyMoney
+	}
+}
+
+// Upcast поднимает payload до актуальной версии события и возвращает её номер.
+// Payload актуальной версии возвращается без изменений
+func Upcast(eventType string, version int, payload []byte) ([]byte, int, error) {
+	contract, ok := Lookup(eventType)
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: %s", ErrUnknownEvent, eventType)
+	}
+	if version == contract.Version {
+		return payload, version, nil
+	}
+	if version > contract.Version || version < 0 {
+		return nil, 0, fmt.Errorf("%w: %s v%d, latest is v%d", ErrUnsupportedVersion, eventType, version, contract.Version)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	var data map[string]any
+	if err := decoder.Decode(&data); err != nil {
+		return nil, 0, fmt.Errorf("%w: %s v%d: %v", ErrInvalidPayload, eventType, version, err)
+	}
+
+	for ; version < contract.Version; version++ {
+		upcaster, ok := upcasters[upcasterKey{eventType, version}]
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: no upcaster for %s v%d", ErrUnsupportedVersion, eventType, version)
+		}
+		if err := upcaster(data); err != nil {
+			return nil, 0, fmt.Errorf("failed to upcast %s v%d: %w", eventType, version, err)
+		}
+	}
+
+	upcasted, err := json.Marshal(data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal upcasted payload: %w", err)
+	}
+	return upcasted, contract.Version, nil
+}
+
+// upcastLegacyMoney заполняет *_money из устаревших сумм float64 для событий,
+// отправленных до перехода на минорные единицы
+func upcastLegacyMoney(payload map[string]any) error {
+	currency, _ := payload["currency"].(string)
+	if err := fillMoney(payload, "amount", "amount_money", currency); err != nil {
+		return err
+	}
+
+	items, _ := payload["items"].([]any)
+	for _, item := range items {
+		item, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if err := fillMoney(item, "unit_price", "unit_price_money", currency); err != nil {
+			return err
+		}
+		if err := fillMoney(item, "amount", "amount_money", currency); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func fillMoney(payload map[string]any, legacyKey, key, currency string) error {
+	if _, ok := payload[key]; ok {
+		return nil
+	}
+
+	legacy, ok := payload[legacyKey].(json.Number)
+	if !ok {
+		return nil
+	}
+
+	amount, err := legacy.Float64()
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", legacyKey, err)
+	}
+	payload[key] = money.FromFloat(amount, currency)
+	return nil
+}
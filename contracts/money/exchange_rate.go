@@ -0,0 +1,94 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// rateScale — число знаков после запятой, с которым курс пишется в базу.
+const rateScale = 10
+
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// ExchangeRate — курс пересчёта: 1 единица from стоит rate единиц to.
+// Курс хранится как big.Rat, чтобы кросс-курсы не теряли точность до округления суммы.
+type ExchangeRate struct {
+	from string
+	to   string
+	rate *big.Rat
+}
+
+func NewExchangeRate(from, to string, rate *big.Rat) (ExchangeRate, error) {
+	if rate == nil || rate.Sign() <= 0 {
+		return ExchangeRate{}, fmt.Errorf("%w: %s/%s must be positive", ErrInvalidRate, from, to)
+	}
+	return ExchangeRate{
+		from: strings.ToUpper(from),
+		to:   strings.ToUpper(to),
+		rate: new(big.Rat).Set(rate),
+	}, nil
+}
+
+// ParseExchangeRate разбирает курс из десятичной строки вида "0.92".
+func ParseExchangeRate(from, to, rate string) (ExchangeRate, error) {
+	value, ok := new(big.Rat).SetString(strings.TrimSpace(rate))
+	if !ok {
+		return ExchangeRate{}, fmt.Errorf("%w: %q", ErrInvalidRate, rate)
+	}
+	return NewExchangeRate(from, to, value)
+}
+
+func (r ExchangeRate) From() string {
+	return r.from
+}
+
+func (r ExchangeRate) To() string {
+	return r.to
+}
+
+// Rat возвращает копию курса, чтобы вызывающий код не мог изменить его.
+func (r ExchangeRate) Rat() *big.Rat {
+	if r.rate == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Set(r.rate)
+}
+
+// Decimal возвращает курс в виде "0.9200000000" — в таком виде он пишется в DECIMAL колонки.
+func (r ExchangeRate) Decimal() string {
+	return r.Rat().FloatString(rateScale)
+}
+
+func (r ExchangeRate) String() string {
+	return fmt.Sprintf("%s/%s %s", r.from, r.to, r.Decimal())
+}
+
+// Convert пересчитывает сумму по курсу с округлением до минорной единицы (половина — от нуля).
+func (m Money) Convert(rate ExchangeRate) (Money, error) {
+	if rate.rate == nil {
+		return Money{}, fmt.Errorf("%w: empty rate", ErrInvalidRate)
+	}
+	if m.currency != rate.from {
+		return Money{}, fmt.Errorf("%w: %s amount with %s/%s rate", ErrCurrencyMismatch, m.currency, rate.from, rate.to)
+	}
+
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(m.minor), rate.rate)
+	return New(roundHalfAwayFromZero(value), rate.to), nil
+}
+
+func roundHalfAwayFromZero(value *big.Rat) int64 {
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+
+	doubled := new(big.Int).Lsh(new(big.Int).Abs(remainder), 1)
+	if doubled.Cmp(value.Denom()) >= 0 {
+		if value.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	return quotient.Int64()
+}
@@ -0,0 +1,50 @@
+package money
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoney_Convert(t *testing.T) {
+	rate, err := ParseExchangeRate(EUR, USD, "1.0869565217")
+	assert.NoError(t, err)
+
+	converted, err := New(1999, EUR).Convert(rate)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2173), converted.MinorUnits())
+	assert.Equal(t, USD, converted.Currency())
+	assert.Equal(t, "1.0869565217", rate.Decimal())
+
+	_, err = New(100, GBP).Convert(rate)
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestMoney_Convert_RoundsHalfAwayFromZero(t *testing.T) {
+	rate, err := NewExchangeRate(USD, RUB, big.NewRat(1, 2))
+	assert.NoError(t, err)
+
+	converted, _ := New(5, USD).Convert(rate)
+	assert.Equal(t, int64(3), converted.MinorUnits())
+
+	converted, _ = New(-5, USD).Convert(rate)
+	assert.Equal(t, int64(-3), converted.MinorUnits())
+}
+
+func TestExchangeRate_Invalid(t *testing.T) {
+	_, err := ParseExchangeRate(USD, EUR, "abc")
+	assert.ErrorIs(t, err, ErrInvalidRate)
+
+	_, err = ParseExchangeRate(USD, EUR, "0")
+	assert.ErrorIs(t, err, ErrInvalidRate)
+
+	_, err = New(100, USD).Convert(ExchangeRate{})
+	assert.ErrorIs(t, err, ErrInvalidRate)
+}
+
+func TestValidateCurrency(t *testing.T) {
+	assert.NoError(t, ValidateCurrency("eur"))
+	assert.ErrorIs(t, ValidateCurrency("JPY"), ErrUnsupportedCurrency)
+	assert.True(t, IsSupportedCurrency(RUB))
+}
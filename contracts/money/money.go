@@ -0,0 +1,222 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	USD = "USD"
+	EUR = "EUR"
+	GBP = "GBP"
+	RUB = "RUB"
+)
+
+var supportedCurrencies = []string{USD, EUR, GBP, RUB}
+
+// minorUnitsPerMajor — все поддерживаемые валюты хранятся с двумя знаками после запятой,
+// как и колонки DECIMAL(15,2) в базе.
+const minorUnitsPerMajor = 100
+
+var (
+	ErrCurrencyMismatch    = errors.New("currency mismatch")
+	ErrInvalidAmount       = errors.New("invalid money amount")
+	ErrInvalidCurrency     = errors.New("invalid currency")
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+)
+
+// SupportedCurrencies возвращает валюты, в которых можно выставлять заказы и держать кошельки.
+func SupportedCurrencies() []string {
+	return append([]string(nil), supportedCurrencies...)
+}
+
+func IsSupportedCurrency(currency string) bool {
+	return slices.Contains(supportedCurrencies, strings.ToUpper(currency))
+}
+
+// ValidateCurrency возвращает ErrUnsupportedCurrency для валют вне списка поддерживаемых.
+func ValidateCurrency(currency string) error {
+	if !IsSupportedCurrency(currency) {
+		return fmt.Errorf("%w: %q", ErrUnsupportedCurrency, currency)
+	}
+	return nil
+}
+
+// Money — денежная сумма в минорных единицах (центах) с ISO 4217 кодом валюты.
+type Money struct {
+	minor    int64
+	currency string
+}
+
+func New(minor int64, currency string) Money {
+	return Money{minor: minor, currency: strings.ToUpper(currency)}
+}
+
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// FromFloat переводит устаревшие float-суммы в Money с округлением до цента.
+func FromFloat(amount float64, currency string) Money {
+	return New(int64(math.Round(amount*minorUnitsPerMajor)), currency)
+}
+
+// Parse разбирает десятичную строку вида "123.45" без потери точности.
+func Parse(amount string, currency string) (Money, error) {
+	value := strings.TrimSpace(amount)
+	if value == "" {
+		return Money{}, fmt.Errorf("%w: empty amount", ErrInvalidAmount)
+	}
+
+	negative := false
+	switch value[0] {
+	case '-':
+		negative = true
+		value = value[1:]
+	case '+':
+		value = value[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" {
+		whole = "0"
+	}
+	if len(fraction) > 2 {
+		if strings.TrimRight(fraction[2:], "0") != "" {
+			return Money{}, fmt.Errorf("%w: %q has more than 2 decimal places", ErrInvalidAmount, amount)
+		}
+		fraction = fraction[:2]
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	major, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	minor, err := strconv.ParseInt(fraction, 10, 64)
+	if err != nil || minor < 0 {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+
+	total := major*minorUnitsPerMajor + minor
+	if negative {
+		total = -total
+	}
+
+	return New(total, currency), nil
+}
+
+// OrLegacy нужен консьюмерам событий: сообщения старого формата несут только
+// float-поле amount, и тогда сумма восстанавливается из него.
+func (m Money) OrLegacy(amount float64, currency string) Money {
+	if m.currency != "" {
+		return m
+	}
+	if currency == "" {
+		currency = USD
+	}
+	return FromFloat(amount, currency)
+}
+
+func (m Money) MinorUnits() int64 {
+	return m.minor
+}
+
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// Float64 нужен только для обратной совместимости со старыми float-полями.
+func (m Money) Float64() float64 {
+	return float64(m.minor) / minorUnitsPerMajor
+}
+
+// Decimal возвращает сумму в виде "123.45" — в таком виде она пишется в DECIMAL колонки.
+func (m Money) Decimal() string {
+	sign := ""
+	minor := m.minor
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/minorUnitsPerMajor, minor%minorUnitsPerMajor)
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.currency
+}
+
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.minor > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.minor < 0
+}
+
+func (m Money) SameCurrency(other Money) bool {
+	return m.currency == other.currency
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if !m.SameCurrency(other) {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	return New(m.minor+other.minor, m.currency), nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if !m.SameCurrency(other) {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	return New(m.minor-other.minor, m.currency), nil
+}
+
+func (m Money) Multiply(quantity int64) Money {
+	return New(m.minor*quantity, m.currency)
+}
+
+// Cmp возвращает -1, 0 или 1, как strings.Compare.
+func (m Money) Cmp(other Money) (int, error) {
+	if !m.SameCurrency(other) {
+		return 0, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	switch {
+	case m.minor < other.minor:
+		return -1, nil
+	case m.minor > other.minor:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+type moneyJSON struct {
+	MinorUnits int64  `json:"minor_units"`
+	Currency   string `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{MinorUnits: m.minor, Currency: m.currency})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw moneyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Currency != "" && len(raw.Currency) != 3 {
+		return fmt.Errorf("%w: %q", ErrInvalidCurrency, raw.Currency)
+	}
+	*m = New(raw.MinorUnits, raw.Currency)
+	return nil
+}
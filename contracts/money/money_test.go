@@ -0,0 +1,77 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected int64
+	}{
+		{"123.45", 12345},
+		{"0.1", 10},
+		{"10", 1000},
+		{"-5.05", -505},
+		{"7.500", 750},
+		{".99", 99},
+	}
+
+	for _, tc := range testCases {
+		m, err := Parse(tc.input, "usd")
+		assert.NoError(t, err, tc.input)
+		assert.Equal(t, tc.expected, m.MinorUnits(), tc.input)
+		assert.Equal(t, USD, m.Currency())
+	}
+
+	_, err := Parse("1.234", USD)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = Parse("abc", USD)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	balance := Zero(USD)
+	for i := 0; i < 10; i++ {
+		balance, _ = balance.Add(FromFloat(0.1, USD))
+	}
+	assert.Equal(t, int64(100), balance.MinorUnits())
+	assert.Equal(t, "1.00", balance.Decimal())
+
+	rest, err := balance.Sub(New(150, USD))
+	assert.NoError(t, err)
+	assert.True(t, rest.IsNegative())
+	assert.Equal(t, "-0.50 USD", rest.String())
+
+	assert.Equal(t, int64(2997), New(999, USD).Multiply(3).MinorUnits())
+
+	_, err = balance.Add(New(100, "EUR"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	cmp, err := New(100, USD).Cmp(New(99, USD))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cmp)
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(New(12345, USD))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"minor_units":12345,"currency":"USD"}`, string(data))
+
+	var m Money
+	assert.NoError(t, json.Unmarshal(data, &m))
+	assert.Equal(t, New(12345, USD), m)
+	assert.Equal(t, 123.45, m.Float64())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"minor_units":1,"currency":"DOLLAR"}`), &m))
+}
+
+func TestMoney_OrLegacy(t *testing.T) {
+	assert.Equal(t, New(500, USD), New(500, USD).OrLegacy(1.23, "EUR"))
+	assert.Equal(t, New(123, "EUR"), Money{}.OrLegacy(1.23, "EUR"))
+	assert.Equal(t, New(1999, USD), Money{}.OrLegacy(19.99, ""))
+}
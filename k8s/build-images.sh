@@ -12,10 +12,10 @@ echo "Building orders-client for Kubernetes..."
 docker build -t orders-client:k8s -f ./orders-client/Dockerfile.k8s ./orders-client
 
 echo "Building orders-service..."
-docker build -t orders-service:latest ./orders-service
+docker build -t orders-service:latest -f ./orders-service/Dockerfile .
 
 echo "Building payments-service..."
-docker build -t payments-service:latest ./payments-service
+docker build -t payments-service:latest -f ./payments-service/Dockerfile .
 
 echo "Docker images built successfully inside minikube."
 
@@ -32,6 +32,9 @@ echo "Waiting for migration jobs to complete..."
 kubectl wait --for=condition=complete job/orders-service-migration -n microservices --timeout=1200s
 kubectl wait --for=condition=complete job/payments-service-migration -n microservices --timeout=1200s
 
+# Services run as StatefulSets so that pod names (and Kafka transactional.id) survive restarts
+kubectl delete deployment orders-service payments-service -n microservices --ignore-not-found
+
 echo "Applying application services and ingress..."
 kubectl apply -R -f k8s/manifests/
 
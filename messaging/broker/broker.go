@@ -0,0 +1,62 @@
+// Package broker — транспорт событий между сервисами. Outbox publisher и inbox processor
+// работают только с Publisher и Subscriber, а брокер (Kafka, RabbitMQ или шина в памяти)
+// выбирается в конфиге
+package broker
+
+import (
+	"context"
+
+	"messaging/correlation"
+)
+
+// Заголовки сообщения с correlation.Metadata, одинаковые во всех транспортах
+const (
+	HeaderCorrelationID = "correlation_id"
+	HeaderCausationID   = "causation_id"
+	HeaderTraceparent   = "traceparent"
+	HeaderSource        = "source"
+	HeaderSchemaVersion = "schema_version"
+)
+
+// Event.PartitionKey — ключ упорядочивания (обычно id агрегата). События с одним ключом
+// обрабатываются подписчиком строго по порядку. Без ключа используется EventID.
+// Metadata передаётся заголовками сообщения, а не в теле
+type Event struct {
+	EventType    string                 `json:"event_type"`
+	EventID      string                 `json:"event_id"`
+	PartitionKey string                 `json:"partition_key,omitempty"`
+	Data         map[string]interface{} `json:"data"`
+	Timestamp    int64                  `json:"timestamp"`
+	Metadata     correlation.Metadata   `json:"-"`
+}
+
+func (e Event) Key() string {
+	if e.PartitionKey != "" {
+		return e.PartitionKey
+	}
+	return e.EventID
+}
+
+// TopicEvent — событие вместе с топиком назначения для пакетной отправки
+type TopicEvent struct {
+	Topic string
+	Event Event
+}
+
+type EventHandler func(ctx context.Context, event Event) error
+
+type Publisher interface {
+	PublishEvent(ctx context.Context, topic string, event Event) error
+	// PublishBatch отправляет пачку событий. Атомарно — только если IsTransactional
+	PublishBatch(ctx context.Context, events []TopicEvent) error
+	IsTransactional() bool
+	Close() error
+}
+
+// Subscriber передаёт события топиков обработчикам по типу события; события без
+// обработчика пропускаются. Ошибка обработчика не теряет событие: оно будет доставлено снова.
+// Start блокируется, пока не завершится ctx
+type Subscriber interface {
+	RegisterHandler(eventType string, handler EventHandler)
+	Start(ctx context.Context) error
+}
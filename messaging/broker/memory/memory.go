@@ -0,0 +1,192 @@
+// Package memory — шина событий в памяти процесса для локальной разработки и тестов.
+// Издатель и подписчики должны работать в одном процессе и на одной Bus
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"messaging/broker"
+	"messaging/correlation"
+)
+
+const defaultRetryDelay = 100 * time.Millisecond
+
+// Bus раздаёт опубликованные события подписчикам топика. Событие получают подписчики,
+// созданные до публикации, даже если Start ещё не вызван
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[string][]*Subscriber
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[string][]*Subscriber)}
+}
+
+func (b *Bus) Publisher() *Publisher {
+	return &Publisher{bus: b}
+}
+
+func (b *Bus) Subscriber(topics ...string) *Subscriber {
+	subscriber := &Subscriber{
+		handlers:   make(map[string]broker.EventHandler),
+		retryDelay: defaultRetryDelay,
+		notify:     make(chan struct{}, 1),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, topic := range topics {
+		b.subscribers[topic] = append(b.subscribers[topic], subscriber)
+	}
+	return subscriber
+}
+
+func (b *Bus) publish(events []broker.TopicEvent) error {
+	// Как и настоящий брокер, шина передаёт копию в JSON: обработчик получает
+	// те же типы (числа — float64), что и из Kafka, и не делит map с издателем
+	encoded := make([][]byte, len(events))
+	for i, event := range events {
+		data, err := json.Marshal(event.Event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event %s: %w", event.Event.EventID, err)
+		}
+		encoded[i] = data
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, event := range events {
+		for _, subscriber := range b.subscribers[event.Topic] {
+			subscriber.enqueue(message{data: encoded[i], metadata: event.Event.Metadata})
+		}
+	}
+	return nil
+}
+
+type Publisher struct {
+	bus *Bus
+}
+
+var _ broker.Publisher = (*Publisher)(nil)
+
+func (p *Publisher) PublishEvent(ctx context.Context, topic string, event broker.Event) error {
+	return p.bus.publish([]broker.TopicEvent{{Topic: topic, Event: event}})
+}
+
+func (p *Publisher) PublishBatch(ctx context.Context, events []broker.TopicEvent) error {
+	return p.bus.publish(events)
+}
+
+// IsTransactional — false: пачка и так публикуется целиком, но outbox не нужно
+// переходить на пакетную отправку ради шины в памяти
+func (p *Publisher) IsTransactional() bool {
+	return false
+}
+
+func (p *Publisher) Close() error {
+	return nil
+}
+
+type message struct {
+	data     []byte
+	metadata correlation.Metadata
+}
+
+// Subscriber обрабатывает события по одному в порядке публикации. Сбой обработчика
+// повторяется через retryDelay, пока событие не будет обработано или не завершится ctx
+type Subscriber struct {
+	handlers   map[string]broker.EventHandler
+	retryDelay time.Duration
+
+	mu     sync.Mutex
+	queue  []message
+	notify chan struct{}
+}
+
+var _ broker.Subscriber = (*Subscriber)(nil)
+
+func (s *Subscriber) RegisterHandler(eventType string, handler broker.EventHandler) {
+	s.handlers[eventType] = handler
+}
+
+func (s *Subscriber) SetRetryDelay(delay time.Duration) {
+	s.retryDelay = delay
+}
+
+func (s *Subscriber) Start(ctx context.Context) error {
+	for {
+		message, ok := s.next()
+		if !ok {
+			select {
+			case <-s.notify:
+				continue
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		if err := s.process(ctx, message); err != nil {
+			return nil
+		}
+	}
+}
+
+func (s *Subscriber) enqueue(message message) {
+	s.mu.Lock()
+	s.queue = append(s.queue, message)
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Subscriber) next() (message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) == 0 {
+		return message{}, false
+	}
+	head := s.queue[0]
+	s.queue = s.queue[1:]
+	return head, true
+}
+
+// process возвращает ошибку, только если ctx завершился раньше, чем событие обработано
+func (s *Subscriber) process(ctx context.Context, message message) error {
+	var event broker.Event
+	if err := json.Unmarshal(message.data, &event); err != nil {
+		// Событие закодировала сама шина, повтор его не исправит
+		log.Printf("Failed to decode in-memory event: %v", err)
+		return nil
+	}
+	event.Metadata = message.metadata
+	event.Metadata.EventID = event.EventID
+
+	handler, exists := s.handlers[event.EventType]
+	if !exists {
+		log.Printf("No handler registered for event type: %s", event.EventType)
+		return nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := handler(correlation.WithMetadata(ctx, event.Metadata), event)
+		if err == nil {
+			return nil
+		}
+
+		log.Printf("Retrying event %s in %s (attempt %d): %v", event.EventID, s.retryDelay, attempt, err)
+		select {
+		case <-time.After(s.retryDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"messaging/broker"
+	"messaging/correlation"
+)
+
+func TestBus_DeliversInOrderAndRetries(t *testing.T) {
+	bus := NewBus()
+	subscriber := bus.Subscriber("orders-events")
+	subscriber.SetRetryDelay(time.Millisecond)
+
+	var handled []string
+	failures := 2
+	done := make(chan struct{})
+	subscriber.RegisterHandler("order.updated", func(ctx context.Context, event broker.Event) error {
+		if event.EventID == "event-1" && failures > 0 {
+			failures--
+			return errors.New("database is down")
+		}
+		handled = append(handled, event.EventID)
+		assert.Equal(t, "corr-1", correlation.FromContext(ctx).CorrelationID)
+		assert.Equal(t, event.EventID, correlation.FromContext(ctx).EventID)
+		if len(handled) == 2 {
+			close(done)
+		}
+		return nil
+	})
+
+	// Событие опубликовано до Start и всё равно дойдёт до подписчика
+	publisher := bus.Publisher()
+	metadata := correlation.Metadata{CorrelationID: "corr-1"}
+	assert.NoError(t, publisher.PublishBatch(context.Background(), []broker.TopicEvent{
+		{Topic: "orders-events", Event: broker.Event{EventType: "order.updated", EventID: "event-1", Metadata: metadata}},
+		{Topic: "orders-events", Event: broker.Event{EventType: "order.unknown", EventID: "event-2", Metadata: metadata}},
+		{Topic: "payments-events", Event: broker.Event{EventType: "order.updated", EventID: "event-3", Metadata: metadata}},
+	}))
+	assert.NoError(t, publisher.PublishEvent(context.Background(), "orders-events", broker.Event{
+		EventType: "order.updated",
+		EventID:   "event-4",
+		Data:      map[string]any{"amount": 15},
+		Metadata:  metadata,
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go subscriber.Start(ctx)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("events were not handled")
+	}
+	assert.Equal(t, []string{"event-1", "event-4"}, handled)
+}
+
+func TestBus_DecodesLikeBroker(t *testing.T) {
+	bus := NewBus()
+	subscriber := bus.Subscriber("orders-events")
+
+	received := make(chan broker.Event, 1)
+	subscriber.RegisterHandler("order.created", func(ctx context.Context, event broker.Event) error {
+		received <- event
+		return nil
+	})
+
+	data := map[string]any{"amount": 15}
+	assert.NoError(t, bus.Publisher().PublishEvent(context.Background(), "orders-events", broker.Event{
+		EventType: "order.created",
+		EventID:   "event-1",
+		Data:      data,
+	}))
+	data["amount"] = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go subscriber.Start(ctx)
+
+	event := <-received
+	assert.Equal(t, float64(15), event.Data["amount"])
+}
@@ -0,0 +1,405 @@
+// Package rabbitmq — транспорт событий через RabbitMQ. Топик соответствует durable
+// exchange типа topic с тем же именем, ключ маршрутизации — тип события. Группа подписчиков
+// читает топик из durable-очереди "<group>.<topic>", поэтому реплики одного сервиса делят
+// события между собой, а разные сервисы получают каждый свою копию
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"messaging/broker"
+	"messaging/correlation"
+)
+
+const (
+	exchangeKind   = "topic"
+	retryDelay     = time.Second
+	reconnectDelay = 5 * time.Second
+)
+
+func declareExchange(channel *amqp.Channel, topic string) error {
+	if err := channel.ExchangeDeclare(topic, exchangeKind, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare exchange %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Publisher публикует события с подтверждениями брокера: PublishEvent возвращается,
+// когда RabbitMQ принял сообщение. После обрыва соединение открывается заново
+// при следующей публикации
+type Publisher struct {
+	url string
+
+	mu         sync.Mutex
+	connection *amqp.Connection
+	channel    *amqp.Channel
+	exchanges  map[string]bool
+}
+
+var _ broker.Publisher = (*Publisher)(nil)
+
+func NewPublisher(url string) (*Publisher, error) {
+	publisher := &Publisher{url: url}
+	if err := publisher.connect(); err != nil {
+		return nil, err
+	}
+	return publisher, nil
+}
+
+func (p *Publisher) connect() error {
+	if p.channel != nil && !p.channel.IsClosed() {
+		return nil
+	}
+	if p.connection != nil {
+		p.connection.Close()
+	}
+
+	connection, err := amqp.Dial(p.url)
+	if err != nil {
+		return fmt.Errorf("failed to connect to rabbitmq: %w", err)
+	}
+
+	channel, err := connection.Channel()
+	if err != nil {
+		connection.Close()
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	if err := channel.Confirm(false); err != nil {
+		connection.Close()
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	p.connection = connection
+	p.channel = channel
+	p.exchanges = make(map[string]bool)
+	return nil
+}
+
+func (p *Publisher) PublishEvent(ctx context.Context, topic string, event broker.Event) error {
+	return p.PublishBatch(ctx, []broker.TopicEvent{{Topic: topic, Event: event}})
+}
+
+// PublishBatch отправляет события по порядку и ждёт подтверждения всех. Пачка не атомарна:
+// при ошибке часть событий может быть уже доставлена, и outbox отправит их повторно
+func (p *Publisher) PublishBatch(ctx context.Context, events []broker.TopicEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.connect(); err != nil {
+		return err
+	}
+
+	confirmations := make([]*amqp.DeferredConfirmation, 0, len(events))
+	for _, event := range events {
+		if !p.exchanges[event.Topic] {
+			if err := declareExchange(p.channel, event.Topic); err != nil {
+				return err
+			}
+			p.exchanges[event.Topic] = true
+		}
+
+		publishing, err := newPublishing(event.Event)
+		if err != nil {
+			return err
+		}
+
+		confirmation, err := p.channel.PublishWithDeferredConfirmWithContext(ctx, event.Topic, event.Event.EventType, false, false, publishing)
+		if err != nil {
+			return fmt.Errorf("failed to publish event %s: %w", event.Event.EventID, err)
+		}
+		confirmations = append(confirmations, confirmation)
+	}
+
+	for i, confirmation := range confirmations {
+		acked, err := confirmation.WaitContext(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to confirm event %s: %w", events[i].Event.EventID, err)
+		}
+		if !acked {
+			return fmt.Errorf("rabbitmq rejected event %s", events[i].Event.EventID)
+		}
+	}
+	return nil
+}
+
+func (p *Publisher) IsTransactional() bool {
+	return false
+}
+
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.connection == nil {
+		return nil
+	}
+	return p.connection.Close()
+}
+
+func newPublishing(event broker.Event) (amqp.Publishing, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return amqp.Publishing{}, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	return amqp.Publishing{
+		ContentType:   "application/json",
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     event.EventID,
+		Type:          event.EventType,
+		Timestamp:     time.Unix(event.Timestamp, 0),
+		CorrelationId: event.Metadata.CorrelationID,
+		Headers:       metadataHeaders(event.Metadata),
+		Body:          body,
+	}, nil
+}
+
+// PoisonMessage — сообщение, которое подписчик не смог разобрать, в исходном виде
+type PoisonMessage struct {
+	Exchange   string
+	RoutingKey string
+	MessageID  string
+	Body       []byte
+	Headers    map[string]string
+	Error      string
+	Timestamp  time.Time
+}
+
+// PoisonStore сохраняет poison-сообщения. Пока Quarantine возвращает ошибку,
+// сообщение возвращается в очередь, как при сбое обработчика
+type PoisonStore interface {
+	Quarantine(ctx context.Context, message PoisonMessage) error
+}
+
+// Subscriber читает очереди группы по одному сообщению за раз, поэтому события
+// одного топика обрабатываются в порядке публикации. Сбой обработчика возвращает
+// сообщение в очередь через retryDelay, сообщение, которое не разбирается, уходит в poison store
+type Subscriber struct {
+	url         string
+	groupID     string
+	topics      []string
+	handlers    map[string]broker.EventHandler
+	poisonStore PoisonStore
+}
+
+var _ broker.Subscriber = (*Subscriber)(nil)
+
+func NewSubscriber(url, groupID string, topics []string) *Subscriber {
+	return &Subscriber{
+		url:      url,
+		groupID:  groupID,
+		topics:   topics,
+		handlers: make(map[string]broker.EventHandler),
+	}
+}
+
+func (s *Subscriber) RegisterHandler(eventType string, handler broker.EventHandler) {
+	s.handlers[eventType] = handler
+}
+
+// SetPoisonStore задаёт хранилище для сообщений, которые не удалось разобрать.
+// Без него такие сообщения отбрасываются
+func (s *Subscriber) SetPoisonStore(store PoisonStore) {
+	s.poisonStore = store
+}
+
+// Start переподключается после обрыва соединения, пока не завершится ctx
+func (s *Subscriber) Start(ctx context.Context) error {
+	for {
+		if err := s.consume(ctx); err != nil {
+			log.Printf("Error from rabbitmq subscriber: %v", err)
+		}
+
+		select {
+		case <-time.After(reconnectDelay):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (s *Subscriber) consume(ctx context.Context) error {
+	connection, err := amqp.Dial(s.url)
+	if err != nil {
+		return fmt.Errorf("failed to connect to rabbitmq: %w", err)
+	}
+	defer connection.Close()
+
+	channel, err := connection.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	if err := channel.Qos(1, 0, false); err != nil {
+		return fmt.Errorf("failed to set prefetch: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(s.topics))
+	for _, topic := range s.topics {
+		deliveries, err := s.subscribe(ctx, channel, topic)
+		if err != nil {
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.drain(ctx, deliveries)
+		}()
+	}
+
+	// Закрытие соединения закрывает все каналы доставки, поэтому ждём первую
+	// остановку, а остальные чтения отменяем
+	err = <-errs
+	cancel()
+	connection.Close()
+	wg.Wait()
+	return err
+}
+
+func (s *Subscriber) subscribe(ctx context.Context, channel *amqp.Channel, topic string) (<-chan amqp.Delivery, error) {
+	if err := declareExchange(channel, topic); err != nil {
+		return nil, err
+	}
+
+	queue := s.groupID + "." + topic
+	if _, err := channel.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		return nil, fmt.Errorf("failed to declare queue %s: %w", queue, err)
+	}
+	if err := channel.QueueBind(queue, "#", topic, false, nil); err != nil {
+		return nil, fmt.Errorf("failed to bind queue %s: %w", queue, err)
+	}
+
+	deliveries, err := channel.ConsumeWithContext(ctx, queue, "", false, false, false, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume queue %s: %w", queue, err)
+	}
+	return deliveries, nil
+}
+
+func (s *Subscriber) drain(ctx context.Context, deliveries <-chan amqp.Delivery) error {
+	for {
+		select {
+		case delivery, ok := <-deliveries:
+			if !ok {
+				return errors.New("delivery channel closed")
+			}
+			if err := s.handle(ctx, delivery); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (s *Subscriber) handle(ctx context.Context, delivery amqp.Delivery) error {
+	var event broker.Event
+	if err := json.Unmarshal(delivery.Body, &event); err != nil {
+		return s.quarantine(ctx, delivery, err)
+	}
+	event.Metadata = metadataFromHeaders(delivery.Headers)
+	event.Metadata.EventID = event.EventID
+
+	handler, exists := s.handlers[event.EventType]
+	if !exists {
+		log.Printf("No handler registered for event type: %s", event.EventType)
+		return delivery.Ack(false)
+	}
+
+	if err := handler(correlation.WithMetadata(ctx, event.Metadata), event); err != nil {
+		log.Printf("Failed to handle event %s, requeueing in %s: %v", event.EventID, retryDelay, err)
+		select {
+		case <-time.After(retryDelay):
+		case <-ctx.Done():
+		}
+		return delivery.Nack(false, true)
+	}
+
+	log.Printf("Successfully processed event %s with ID %s", event.EventType, event.EventID)
+	return delivery.Ack(false)
+}
+
+// quarantine откладывает сообщение, которое не разбирается: повторная доставка его не исправит.
+// Если сохранить его не удалось, сообщение возвращается в очередь, чтобы не потеряться
+func (s *Subscriber) quarantine(ctx context.Context, delivery amqp.Delivery, reason error) error {
+	log.Printf("Failed to decode message %s from %s: %v", delivery.MessageId, delivery.Exchange, reason)
+	if s.poisonStore == nil {
+		return delivery.Reject(false)
+	}
+
+	if err := s.poisonStore.Quarantine(ctx, newPoisonMessage(delivery, reason)); err != nil {
+		log.Printf("Failed to quarantine message %s, requeueing in %s: %v", delivery.MessageId, retryDelay, err)
+		select {
+		case <-time.After(retryDelay):
+		case <-ctx.Done():
+		}
+		return delivery.Nack(false, true)
+	}
+
+	log.Printf("Quarantined message %s from %s", delivery.MessageId, delivery.Exchange)
+	return delivery.Ack(false)
+}
+
+func newPoisonMessage(delivery amqp.Delivery, reason error) PoisonMessage {
+	headers := make(map[string]string, len(delivery.Headers))
+	for key, value := range delivery.Headers {
+		headers[key] = fmt.Sprint(value)
+	}
+
+	return PoisonMessage{
+		Exchange:   delivery.Exchange,
+		RoutingKey: delivery.RoutingKey,
+		MessageID:  delivery.MessageId,
+		Body:       delivery.Body,
+		Headers:    headers,
+		Error:      reason.Error(),
+		Timestamp:  delivery.Timestamp,
+	}
+}
+
+func metadataHeaders(metadata correlation.Metadata) amqp.Table {
+	headers := amqp.Table{}
+	for key, value := range map[string]string{
+		broker.HeaderCorrelationID: metadata.CorrelationID,
+		broker.HeaderCausationID:   metadata.CausationID,
+		broker.HeaderTraceparent:   metadata.Traceparent,
+		broker.HeaderSource:        metadata.Source,
+	} {
+		if value != "" {
+			headers[key] = value
+		}
+	}
+	if metadata.SchemaVersion > 0 {
+		headers[broker.HeaderSchemaVersion] = strconv.Itoa(metadata.SchemaVersion)
+	}
+	return headers
+}
+
+func metadataFromHeaders(headers amqp.Table) correlation.Metadata {
+	value := func(key string) string {
+		s, _ := headers[key].(string)
+		return s
+	}
+
+	metadata := correlation.Metadata{
+		CorrelationID: value(broker.HeaderCorrelationID),
+		CausationID:   value(broker.HeaderCausationID),
+		Traceparent:   value(broker.HeaderTraceparent),
+		Source:        value(broker.HeaderSource),
+	}
+	metadata.SchemaVersion, _ = strconv.Atoi(value(broker.HeaderSchemaVersion))
+	return metadata
+}
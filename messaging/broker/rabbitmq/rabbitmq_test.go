@@ -0,0 +1,135 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"messaging/broker"
+	"messaging/correlation"
+)
+
+func TestMetadataHeaders_RoundTrip(t *testing.T) {
+	metadata := correlation.Metadata{
+		CorrelationID: "corr-1",
+		CausationID:   "event-0",
+		Traceparent:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		Source:        "orders-service",
+		SchemaVersion: 1,
+	}
+
+	headers := metadataHeaders(metadata)
+	assert.Len(t, headers, 5)
+	assert.NoError(t, headers.Validate())
+	assert.Equal(t, metadata, metadataFromHeaders(headers))
+
+	assert.Empty(t, metadataHeaders(correlation.Metadata{}))
+	assert.Equal(t, correlation.Metadata{}, metadataFromHeaders(nil))
+}
+
+func TestNewPublishing(t *testing.T) {
+	publishing, err := newPublishing(broker.Event{
+		EventType: "order.created",
+		EventID:   "event-1",
+		Data:      map[string]any{"order_id": "order-1"},
+		Timestamp: 1700000000,
+		Metadata:  correlation.Metadata{CorrelationID: "corr-1"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "event-1", publishing.MessageId)
+	assert.Equal(t, "order.created", publishing.Type)
+	assert.Equal(t, "corr-1", publishing.CorrelationId)
+	assert.Equal(t, uint8(amqp.Persistent), publishing.DeliveryMode)
+	assert.JSONEq(t, `{"event_type": "order.created", "event_id": "event-1", "data": {"order_id": "order-1"}, "timestamp": 1700000000}`, string(publishing.Body))
+}
+
+// acknowledger запоминает, как подписчик завершил доставку
+type acknowledger struct {
+	acked    bool
+	requeued bool
+	rejected bool
+}
+
+func (a *acknowledger) Ack(tag uint64, multiple bool) error {
+	a.acked = true
+	return nil
+}
+
+func (a *acknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.requeued = requeue
+	return nil
+}
+
+func (a *acknowledger) Reject(tag uint64, requeue bool) error {
+	a.rejected = !requeue
+	return nil
+}
+
+type poisonStore struct {
+	err      error
+	messages []PoisonMessage
+}
+
+func (s *poisonStore) Quarantine(ctx context.Context, message PoisonMessage) error {
+	s.messages = append(s.messages, message)
+	return s.err
+}
+
+func newPoisonDelivery(ack *acknowledger) amqp.Delivery {
+	return amqp.Delivery{
+		Acknowledger: ack,
+		Exchange:     "orders-events",
+		RoutingKey:   "order.created",
+		MessageId:    "event-1",
+		Headers:      amqp.Table{broker.HeaderCorrelationID: "corr-1"},
+		Body:         []byte(`{"event_type":`),
+	}
+}
+
+// Сообщение, которое не разбирается, уходит в poison store, как у консьюмера Kafka
+func TestSubscriber_QuarantinesUndecodableMessage(t *testing.T) {
+	t.Run("stored", func(t *testing.T) {
+		store := &poisonStore{}
+		subscriber := NewSubscriber("", "payments-service", []string{"orders-events"})
+		subscriber.SetPoisonStore(store)
+
+		ack := &acknowledger{}
+		require.NoError(t, subscriber.handle(context.Background(), newPoisonDelivery(ack)))
+
+		assert.True(t, ack.acked)
+		require.Len(t, store.messages, 1)
+		assert.Equal(t, "orders-events", store.messages[0].Exchange)
+		assert.Equal(t, "order.created", store.messages[0].RoutingKey)
+		assert.Equal(t, "event-1", store.messages[0].MessageID)
+		assert.Equal(t, []byte(`{"event_type":`), store.messages[0].Body)
+		assert.Equal(t, map[string]string{broker.HeaderCorrelationID: "corr-1"}, store.messages[0].Headers)
+		assert.NotEmpty(t, store.messages[0].Error)
+	})
+
+	t.Run("store failed", func(t *testing.T) {
+		subscriber := NewSubscriber("", "payments-service", []string{"orders-events"})
+		subscriber.SetPoisonStore(&poisonStore{err: errors.New("database is down")})
+
+		// Отменённый контекст не ждёт retryDelay
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		ack := &acknowledger{}
+		require.NoError(t, subscriber.handle(ctx, newPoisonDelivery(ack)))
+		assert.True(t, ack.requeued)
+		assert.False(t, ack.acked)
+	})
+
+	t.Run("no store", func(t *testing.T) {
+		subscriber := NewSubscriber("", "payments-service", []string{"orders-events"})
+
+		ack := &acknowledger{}
+		require.NoError(t, subscriber.handle(context.Background(), newPoisonDelivery(ack)))
+		assert.True(t, ack.rejected)
+	})
+}
@@ -0,0 +1,29 @@
+package correlation
+
+import "context"
+
+// Metadata — идентификаторы цепочки событий, которые переносятся через context:
+// HTTP-запрос → outbox → Kafka → inbox → обработчик → следующий outbox
+type Metadata struct {
+	// CorrelationID общий для всех событий одной бизнес-операции (например, заказа)
+	CorrelationID string
+	// CausationID — id события, которое вызвало текущее
+	CausationID string
+	// EventID — id обрабатываемого события; для порождённых им событий он станет CausationID
+	EventID string
+	// Traceparent — заголовок W3C Trace Context
+	Traceparent   string
+	Source        string
+	SchemaVersion int
+}
+
+type contextKey struct{}
+
+func WithMetadata(ctx context.Context, metadata Metadata) context.Context {
+	return context.WithValue(ctx, contextKey{}, metadata)
+}
+
+func FromContext(ctx context.Context) Metadata {
+	metadata, _ := ctx.Value(contextKey{}).(Metadata)
+	return metadata
+}
@@ -0,0 +1,60 @@
+package deadletter
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	ErrInvalidSource      = errors.New("invalid dead letter source")
+	ErrInvalidPayload     = errors.New("payload must be a JSON object")
+)
+
+// Source — таблица, из которой пришло сообщение
+type Source string
+
+const (
+	SourceOutbox Source = "outbox"
+	SourceInbox  Source = "inbox"
+)
+
+func ParseSource(value string) (Source, error) {
+	switch Source(value) {
+	case SourceOutbox, SourceInbox:
+		return Source(value), nil
+	default:
+		return "", ErrInvalidSource
+	}
+}
+
+// Attempt — одна неудачная попытка доставки или обработки сообщения
+type Attempt struct {
+	At    time.Time `json:"at"`
+	Error string    `json:"error"`
+}
+
+// DeadLetter — сообщение outbox или inbox, которое больше не повторяется автоматически
+type DeadLetter struct {
+	ID             string          `json:"id"`
+	Source         Source          `json:"source"`
+	EventID        string          `json:"event_id,omitempty"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	LastError      string          `json:"last_error"`
+	Attempts       []Attempt       `json:"attempts"`
+	RetryCount     int             `json:"retry_count"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	DeadLetteredAt time.Time       `json:"dead_lettered_at"`
+}
+
+// ValidatePayload проверяет исправленный payload: публикация и обработчики ждут JSON-объект
+func ValidatePayload(payload json.RawMessage) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &object); err != nil || object == nil {
+		return ErrInvalidPayload
+	}
+	return nil
+}
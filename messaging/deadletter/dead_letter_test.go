@@ -0,0 +1,28 @@
+package deadletter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSource(t *testing.T) {
+	source, err := ParseSource("outbox")
+	assert.NoError(t, err)
+	assert.Equal(t, SourceOutbox, source)
+
+	source, err = ParseSource("inbox")
+	assert.NoError(t, err)
+	assert.Equal(t, SourceInbox, source)
+
+	_, err = ParseSource("orders")
+	assert.ErrorIs(t, err, ErrInvalidSource)
+}
+
+func TestValidatePayload(t *testing.T) {
+	assert.NoError(t, ValidatePayload(json.RawMessage(`{"order_id":"order-1"}`)))
+	assert.ErrorIs(t, ValidatePayload(json.RawMessage(`[1,2]`)), ErrInvalidPayload)
+	assert.ErrorIs(t, ValidatePayload(json.RawMessage(`null`)), ErrInvalidPayload)
+	assert.ErrorIs(t, ValidatePayload(json.RawMessage(`{"broken"`)), ErrInvalidPayload)
+}
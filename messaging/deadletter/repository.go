@@ -0,0 +1,14 @@
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Repository работает с сообщениями outbox/inbox в статусе dead_letter
+type Repository interface {
+	List(ctx context.Context, source Source, limit int) ([]*DeadLetter, error)
+	Get(ctx context.Context, source Source, id string) (*DeadLetter, error)
+	UpdatePayload(ctx context.Context, source Source, id string, payload json.RawMessage) error
+	Requeue(ctx context.Context, source Source, id string) error
+}
@@ -0,0 +1,77 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const MaxKeyLength = 255
+
+var (
+	ErrRecordNotFound = errors.New("idempotency record not found")
+	ErrInvalidKey     = errors.New("invalid idempotency key")
+)
+
+// Record хранит ответ на первый запрос с данным ключом. Пока StatusCode равен нулю,
+// запрос ещё выполняется и ключ считается занятым.
+type Record struct {
+	Scope        string
+	Key          string
+	RequestHash  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	CompletedAt  *time.Time
+}
+
+func NewRecord(scope, key, requestHash string) (*Record, error) {
+	if key == "" || len(key) > MaxKeyLength {
+		return nil, ErrInvalidKey
+	}
+
+	return &Record{
+		Scope:       scope,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+func (r *Record) IsCompleted() bool {
+	return r.StatusCode != 0
+}
+
+func (r *Record) Matches(requestHash string) bool {
+	return r.RequestHash == requestHash
+}
+
+func (r *Record) Complete(statusCode int, contentType string, body []byte) {
+	now := time.Now()
+	r.StatusCode = statusCode
+	r.ContentType = contentType
+	r.ResponseBody = body
+	r.CompletedAt = &now
+}
+
+// HashRequest считает SHA-256 тела запроса. JSON приводится к каноническому виду,
+// поэтому порядок полей и пробелы не влияют на хеш.
+func HashRequest(body []byte) string {
+	canonical := body
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err == nil {
+		if encoded, err := json.Marshal(value); err == nil {
+			canonical = encoded
+		}
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,36 @@
+package idempotency
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashRequest_CanonicalJSON(t *testing.T) {
+	assert.Equal(t,
+		HashRequest([]byte(`{"user_id":"user-1","amount":100.10}`)),
+		HashRequest([]byte(`{ "amount": 100.10, "user_id": "user-1" }`)),
+	)
+	assert.NotEqual(t,
+		HashRequest([]byte(`{"amount":100.10}`)),
+		HashRequest([]byte(`{"amount":100.1}`)),
+	)
+	assert.NotEqual(t, HashRequest([]byte(`not json`)), HashRequest([]byte(`not  json`)))
+}
+
+func TestRecord_Complete(t *testing.T) {
+	record, err := NewRecord("POST /orders", "key-1", "hash")
+	assert.NoError(t, err)
+	assert.False(t, record.IsCompleted())
+	assert.True(t, record.Matches("hash"))
+
+	record.Complete(201, "application/json", []byte(`{}`))
+	assert.True(t, record.IsCompleted())
+	assert.NotNil(t, record.CompletedAt)
+
+	_, err = NewRecord("POST /orders", "", "hash")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = NewRecord("POST /orders", strings.Repeat("k", MaxKeyLength+1), "hash")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
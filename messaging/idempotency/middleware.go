@@ -0,0 +1,182 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	KeyHeader      = "Idempotency-Key"
+	ReplayedHeader = "Idempotent-Replayed"
+)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Caller возвращает пользователя, от имени которого выполняется запрос, или пустую строку.
+// Он входит в scope ключа, поэтому одинаковые ключи разных пользователей не пересекаются
+type Caller func(r *http.Request, body []byte) string
+
+// BodyField берёт пользователя из строкового поля field JSON-тела запроса
+func BodyField(field string) Caller {
+	return func(r *http.Request, body []byte) string {
+		var fields map[string]any
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return ""
+		}
+		value, _ := fields[field].(string)
+		return value
+	}
+}
+
+// Middleware сохраняет ответ на запрос с заголовком Idempotency-Key и отдаёт его
+// при повторе. Запросы без заголовка проходят как есть.
+type Middleware struct {
+	repo Repository
+	ttl  time.Duration
+}
+
+func NewMiddleware(repo Repository, ttl time.Duration) *Middleware {
+	return &Middleware{
+		repo: repo,
+		ttl:  ttl,
+	}
+}
+
+func (m *Middleware) Wrap(next http.HandlerFunc, caller Caller) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(KeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, err := NewRecord(scope(r, caller(r, body)), key, HashRequest(body))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		ctx := r.Context()
+		reserved, err := m.repo.Reserve(ctx, record, time.Now().Add(-m.ttl))
+		if err != nil {
+			log.Printf("Failed to reserve idempotency key %s: %v", key, err)
+			writeError(w, http.StatusInternalServerError, "Failed to process idempotency key")
+			return
+		}
+
+		if !reserved {
+			m.replay(ctx, w, record)
+			return
+		}
+
+		// Если обработчик упал или вернул 5xx, ключ освобождается, чтобы запрос можно было повторить
+		completed := false
+		defer func() {
+			if !completed {
+				m.release(ctx, record)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		next(recorder, r)
+
+		if recorder.StatusCode() >= http.StatusInternalServerError {
+			return
+		}
+
+		record.Complete(recorder.StatusCode(), recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		if err := m.repo.Complete(context.WithoutCancel(ctx), record); err != nil {
+			// Ключ остаётся занятым до истечения ttl: повторное выполнение опаснее ответа 409
+			log.Printf("Failed to store response for idempotency key %s: %v", key, err)
+		}
+		completed = true
+	}
+}
+
+func (m *Middleware) replay(ctx context.Context, w http.ResponseWriter, record *Record) {
+	existing, err := m.repo.Get(ctx, record.Scope, record.Key)
+	if err != nil && !errors.Is(err, ErrRecordNotFound) {
+		log.Printf("Failed to get idempotency record %s: %v", record.Key, err)
+		writeError(w, http.StatusInternalServerError, "Failed to process idempotency key")
+		return
+	}
+
+	switch {
+	case existing == nil:
+		writeError(w, http.StatusConflict, "Request with this Idempotency-Key was interrupted, retry it")
+	case !existing.Matches(record.RequestHash):
+		writeError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request body")
+	case !existing.IsCompleted():
+		writeError(w, http.StatusConflict, "Request with this Idempotency-Key is still in progress")
+	default:
+		if existing.ContentType != "" {
+			w.Header().Set("Content-Type", existing.ContentType)
+		}
+		w.Header().Set(ReplayedHeader, "true")
+		w.WriteHeader(existing.StatusCode)
+		w.Write(existing.ResponseBody)
+	}
+}
+
+func (m *Middleware) release(ctx context.Context, record *Record) {
+	if err := m.repo.Delete(context.WithoutCancel(ctx), record.Scope, record.Key); err != nil {
+		log.Printf("Failed to release idempotency key %s: %v", record.Key, err)
+	}
+}
+
+func scope(r *http.Request, caller string) string {
+	if caller == "" {
+		return r.Method + " " + r.URL.Path
+	}
+	return r.Method + " " + r.URL.Path + " user:" + caller
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: message})
+}
+
+// responseRecorder пропускает ответ клиенту и параллельно запоминает его
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) StatusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
@@ -0,0 +1,172 @@
+package idempotency
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryIdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+func newMemoryIdempotencyRepository() *memoryIdempotencyRepository {
+	return &memoryIdempotencyRepository{records: make(map[string]Record)}
+}
+
+func (m *memoryIdempotencyRepository) Reserve(ctx context.Context, record *Record, expiredBefore time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.records[record.Scope+record.Key]
+	if ok && !existing.CreatedAt.Before(expiredBefore) {
+		return false, nil
+	}
+	m.records[record.Scope+record.Key] = *record
+	return true, nil
+}
+
+func (m *memoryIdempotencyRepository) Get(ctx context.Context, scope, key string) (*Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, ok := m.records[scope+key]
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return &record, nil
+}
+
+func (m *memoryIdempotencyRepository) Complete(ctx context.Context, record *Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.records[record.Scope+record.Key] = *record
+	return nil
+}
+
+func (m *memoryIdempotencyRepository) Delete(ctx context.Context, scope, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.records, scope+key)
+	return nil
+}
+
+func countingHandler(calls *int, status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"call":` + strconv.Itoa(*calls) + `,"echo":` + string(body) + `}`))
+	}
+}
+
+func doRequest(handler http.HandlerFunc, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(KeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	calls := 0
+	handler := NewMiddleware(newMemoryIdempotencyRepository(), time.Hour).Wrap(countingHandler(&calls, http.StatusOK), BodyField("user_id"))
+
+	first := doRequest(handler, "key-1", `{"user_id":"user-1","items":[]}`)
+	// Тот же JSON с другим порядком полей и пробелами считается тем же запросом
+	second := doRequest(handler, "key-1", `{ "items": [], "user_id": "user-1" }`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "application/json", second.Header().Get("Content-Type"))
+	assert.Equal(t, "true", second.Header().Get(ReplayedHeader))
+	assert.Empty(t, first.Header().Get(ReplayedHeader))
+}
+
+func TestIdempotency_RejectsDifferentBody(t *testing.T) {
+	calls := 0
+	handler := NewMiddleware(newMemoryIdempotencyRepository(), time.Hour).Wrap(countingHandler(&calls, http.StatusOK), BodyField("user_id"))
+
+	doRequest(handler, "key-1", `{"user_id":"user-1","amount":100}`)
+	rec := doRequest(handler, "key-1", `{"user_id":"user-1","amount":200}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestIdempotency_KeysOfDifferentCallersDoNotCollide(t *testing.T) {
+	calls := 0
+	handler := NewMiddleware(newMemoryIdempotencyRepository(), time.Hour).Wrap(countingHandler(&calls, http.StatusOK), BodyField("user_id"))
+
+	first := doRequest(handler, "key-1", `{"user_id":"user-1"}`)
+	second := doRequest(handler, "key-1", `{"user_id":"user-2"}`)
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.NotEqual(t, first.Body.String(), second.Body.String())
+	assert.Empty(t, second.Header().Get(ReplayedHeader))
+}
+
+func TestIdempotency_WithoutKeyPassesThrough(t *testing.T) {
+	calls := 0
+	handler := NewMiddleware(newMemoryIdempotencyRepository(), time.Hour).Wrap(countingHandler(&calls, http.StatusOK), BodyField("user_id"))
+
+	doRequest(handler, "", `{}`)
+	doRequest(handler, "", `{}`)
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotency_ServerErrorReleasesKey(t *testing.T) {
+	calls := 0
+	repo := newMemoryIdempotencyRepository()
+	failing := NewMiddleware(repo, time.Hour).Wrap(countingHandler(&calls, http.StatusInternalServerError), BodyField("user_id"))
+
+	rec := doRequest(failing, "key-1", `{}`)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	_, err := repo.Get(context.Background(), "POST /orders", "key-1")
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+
+	succeeding := NewMiddleware(repo, time.Hour).Wrap(countingHandler(&calls, http.StatusOK), BodyField("user_id"))
+	rec = doRequest(succeeding, "key-1", `{}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotency_InProgressConflict(t *testing.T) {
+	repo := newMemoryIdempotencyRepository()
+	record, _ := NewRecord("POST /orders", "key-1", HashRequest([]byte(`{}`)))
+	_, _ = repo.Reserve(context.Background(), record, time.Now().Add(-time.Hour))
+
+	calls := 0
+	rec := doRequest(NewMiddleware(repo, time.Hour).Wrap(countingHandler(&calls, http.StatusOK), BodyField("user_id")), "key-1", `{}`)
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, 0, calls)
+}
+
+func TestIdempotency_InvalidKey(t *testing.T) {
+	calls := 0
+	handler := NewMiddleware(newMemoryIdempotencyRepository(), time.Hour).Wrap(countingHandler(&calls, http.StatusOK), BodyField("user_id"))
+
+	rec := doRequest(handler, strings.Repeat("k", MaxKeyLength+1), `{}`)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, 0, calls)
+}
@@ -0,0 +1,16 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// Repository хранит ответы по ключам идемпотентности в таблице idempotency_keys,
+// которую создают миграции сервиса
+type Repository interface {
+	// Reserve занимает ключ; false означает, что ключ уже занят записью новее expiredBefore.
+	Reserve(ctx context.Context, record *Record, expiredBefore time.Time) (bool, error)
+	Get(ctx context.Context, scope, key string) (*Record, error)
+	Complete(ctx context.Context, record *Record) error
+	Delete(ctx context.Context, scope, key string) error
+}
@@ -0,0 +1,43 @@
+package inbox
+
+import (
+	"context"
+	"sync"
+)
+
+type afterCommitKey struct{}
+
+type afterCommitHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+// WithAfterCommit готовит контекст обработчика к отложенным действиям.
+// Возвращённая функция выполняет их; её вызывают только после успешной фиксации
+func WithAfterCommit(ctx context.Context) (context.Context, func()) {
+	hooks := &afterCommitHooks{}
+	return context.WithValue(ctx, afterCommitKey{}, hooks), func() {
+		hooks.mu.Lock()
+		fns := hooks.fns
+		hooks.fns = nil
+		hooks.mu.Unlock()
+
+		for _, fn := range fns {
+			fn()
+		}
+	}
+}
+
+// AfterCommit откладывает fn до фиксации транзакции обработчика inbox, например
+// уведомление клиентов: при откате fn не выполнится. Вне обработчика fn выполняется сразу
+func AfterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(afterCommitKey{}).(*afterCommitHooks)
+	if !ok {
+		fn()
+		return
+	}
+
+	hooks.mu.Lock()
+	hooks.fns = append(hooks.fns, fn)
+	hooks.mu.Unlock()
+}
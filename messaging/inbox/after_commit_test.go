@@ -0,0 +1,28 @@
+package inbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAfterCommit(t *testing.T) {
+	// Вне обработчика inbox откладывать некуда
+	ran := false
+	AfterCommit(context.Background(), func() { ran = true })
+	assert.True(t, ran)
+
+	ctx, runAfterCommit := WithAfterCommit(context.Background())
+	var order []int
+	AfterCommit(ctx, func() { order = append(order, 1) })
+	AfterCommit(ctx, func() { order = append(order, 2) })
+	assert.Empty(t, order)
+
+	runAfterCommit()
+	assert.Equal(t, []int{1, 2}, order)
+
+	// Повторный вызов не выполняет действия второй раз
+	runAfterCommit()
+	assert.Equal(t, []int{1, 2}, order)
+}
@@ -0,0 +1,114 @@
+package inbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"messaging/correlation"
+)
+
+type InboxMessageStatus string
+
+const (
+	InboxMessageStatusPending    InboxMessageStatus = "pending"
+	InboxMessageStatusProcessing InboxMessageStatus = "processing"
+	InboxMessageStatusProcessed  InboxMessageStatus = "processed"
+	InboxMessageStatusFailed     InboxMessageStatus = "failed"
+	InboxMessageStatusDeadLetter InboxMessageStatus = "dead_letter"
+)
+
+type InboxMessage struct {
+	ID        string
+	EventID   string
+	EventType string
+	// PartitionKey — ключ упорядочивания события: сообщения с одним ключом обрабатываются по очереди
+	PartitionKey string
+	Payload      json.RawMessage
+	Status       InboxMessageStatus
+	ProcessedAt  *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	RetryCount   int
+	MaxRetries   int
+	// Заголовки полученного сообщения Kafka
+	CorrelationID string
+	CausationID   string
+	Traceparent   string
+	Source        string
+	SchemaVersion int
+}
+
+func NewInboxMessage(eventID, eventType string, payload json.RawMessage) (*InboxMessage, error) {
+	v7, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	return &InboxMessage{
+		ID:         v7.String(),
+		EventID:    eventID,
+		EventType:  eventType,
+		Payload:    payload,
+		Status:     InboxMessageStatusPending,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+		RetryCount: 0,
+		MaxRetries: 3,
+	}, nil
+}
+
+// ApplyMetadata сохраняет заголовки сообщения Kafka, из которого получено событие
+func (m *InboxMessage) ApplyMetadata(metadata correlation.Metadata) {
+	m.CorrelationID = metadata.CorrelationID
+	m.CausationID = metadata.CausationID
+	m.Traceparent = metadata.Traceparent
+	m.Source = metadata.Source
+	m.SchemaVersion = metadata.SchemaVersion
+}
+
+// Metadata восстанавливает контекст события для его обработчика
+func (m *InboxMessage) Metadata() correlation.Metadata {
+	return correlation.Metadata{
+		CorrelationID: m.CorrelationID,
+		CausationID:   m.CausationID,
+		EventID:       m.EventID,
+		Traceparent:   m.Traceparent,
+		Source:        m.Source,
+		SchemaVersion: m.SchemaVersion,
+	}
+}
+
+func (m *InboxMessage) MarkAsProcessed() {
+	now := time.Now()
+	m.Status = InboxMessageStatusProcessed
+	m.ProcessedAt = &now
+	m.UpdatedAt = now
+}
+
+func (m *InboxMessage) MarkAsFailed() {
+	m.Status = InboxMessageStatusFailed
+	m.RetryCount++
+	m.UpdatedAt = time.Now()
+}
+
+func (m *InboxMessage) CanRetry() bool {
+	return m.RetryCount < m.MaxRetries
+}
+
+func (m *InboxMessage) IsProcessed() bool {
+	return m.Status == InboxMessageStatusProcessed
+}
+
+func (m *InboxMessage) IsFailed() bool {
+	return m.Status == InboxMessageStatusFailed
+}
+
+func (m *InboxMessage) IsDeadLetter() bool {
+	return m.Status == InboxMessageStatusDeadLetter
+}
+
+func (m *InboxMessage) IsPending() bool {
+	return m.Status == InboxMessageStatusPending
+}
@@ -48,7 +48,6 @@ func TestInboxMessage_CanRetry(t *testing.T) {
 
 	assert.True(t, msg.CanRetry())
 
-	// Simulate time passing
-	msg.CreatedAt = time.Now().Add(-20 * time.Minute)
+	msg.RetryCount = msg.MaxRetries
 	assert.False(t, msg.CanRetry())
 }
@@ -0,0 +1,149 @@
+// Package inboxtest — inbox в памяти для тестов сервисов: события проходят через
+// relay.InboxProcessor так же, как с PostgreSQL, но без базы.
+package inboxtest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"messaging/inbox"
+)
+
+// Repository хранит сообщения в памяти. Отметки не зависят от фиксации транзакции:
+// сообщение считается обработанным, как только процессор отметил его в tx
+type Repository struct {
+	mu       sync.Mutex
+	messages []*inbox.InboxMessage
+	errors   map[string]string
+}
+
+func NewRepository() *Repository {
+	return &Repository{errors: make(map[string]string)}
+}
+
+func (r *Repository) Store(ctx context.Context, message *inbox.InboxMessage) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.messages {
+		if stored.EventID == message.EventID {
+			return false, nil
+		}
+	}
+
+	stored := *message
+	r.messages = append(r.messages, &stored)
+	return true, nil
+}
+
+func (r *Repository) StoreWithTx(ctx context.Context, tx *sql.Tx, message *inbox.InboxMessage) (bool, error) {
+	return r.Store(ctx, message)
+}
+
+func (r *Repository) GetByEventID(ctx context.Context, eventID string) (*inbox.InboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.messages {
+		if stored.EventID == eventID {
+			message := *stored
+			return &message, nil
+		}
+	}
+	return nil, fmt.Errorf("inbox message not found for event: %s", eventID)
+}
+
+func (r *Repository) ClaimPendingMessages(ctx context.Context, limit int, lease time.Duration) ([]*inbox.InboxMessage, error) {
+	return r.claim(limit, func(message *inbox.InboxMessage) bool {
+		return message.IsPending()
+	})
+}
+
+// ClaimFailedMessages отдаёт упавшие сообщения сразу, не дожидаясь следующей попытки
+func (r *Repository) ClaimFailedMessages(ctx context.Context, limit int, lease time.Duration) ([]*inbox.InboxMessage, error) {
+	return r.claim(limit, func(message *inbox.InboxMessage) bool {
+		return message.IsFailed()
+	})
+}
+
+func (r *Repository) claim(limit int, match func(message *inbox.InboxMessage) bool) ([]*inbox.InboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var claimed []*inbox.InboxMessage
+	for _, stored := range r.messages {
+		if len(claimed) == limit {
+			break
+		}
+		if match(stored) {
+			stored.Status = inbox.InboxMessageStatusProcessing
+			message := *stored
+			claimed = append(claimed, &message)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *Repository) LockWithTx(ctx context.Context, tx *sql.Tx, id string) error {
+	return r.update(id, func(message *inbox.InboxMessage) error {
+		if message.Status != inbox.InboxMessageStatusProcessing {
+			return fmt.Errorf("%w: %s", inbox.ErrLeaseLost, id)
+		}
+		return nil
+	})
+}
+
+func (r *Repository) MarkAsProcessed(ctx context.Context, id string) error {
+	return r.update(id, func(message *inbox.InboxMessage) error {
+		message.MarkAsProcessed()
+		return nil
+	})
+}
+
+func (r *Repository) MarkAsProcessedWithTx(ctx context.Context, tx *sql.Tx, id string) error {
+	return r.MarkAsProcessed(ctx, id)
+}
+
+func (r *Repository) MarkAsFailed(ctx context.Context, id string, reason string, nextAttemptAt time.Time) error {
+	return r.update(id, func(message *inbox.InboxMessage) error {
+		message.MarkAsFailed()
+		r.errors[message.EventID] = reason
+		return nil
+	})
+}
+
+func (r *Repository) MarkAsDeadLetter(ctx context.Context, id string, reason string) error {
+	return r.update(id, func(message *inbox.InboxMessage) error {
+		message.Status = inbox.InboxMessageStatusDeadLetter
+		r.errors[message.EventID] = reason
+		return nil
+	})
+}
+
+func (r *Repository) update(id string, fn func(message *inbox.InboxMessage) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.messages {
+		if stored.ID == id {
+			return fn(stored)
+		}
+	}
+	return fmt.Errorf("inbox message not found: %s", id)
+}
+
+// Status возвращает статус сообщения события и последнюю ошибку его обработки
+func (r *Repository) Status(eventID string) (inbox.InboxMessageStatus, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.messages {
+		if stored.EventID == eventID {
+			return stored.Status, r.errors[eventID]
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,27 @@
+package inbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrLeaseLost — аренда сообщения истекла, и его могла захватить другая реплика
+var ErrLeaseLost = errors.New("inbox message lease lost")
+
+type Repository interface {
+	// Store сохраняет сообщение, если события с таким event_id ещё нет, и сообщает, было ли оно сохранено
+	Store(ctx context.Context, message *InboxMessage) (bool, error)
+	StoreWithTx(ctx context.Context, tx *sql.Tx, message *InboxMessage) (bool, error)
+	GetByEventID(ctx context.Context, eventID string) (*InboxMessage, error)
+	ClaimPendingMessages(ctx context.Context, limit int, lease time.Duration) ([]*InboxMessage, error)
+	ClaimFailedMessages(ctx context.Context, limit int, lease time.Duration) ([]*InboxMessage, error)
+	// LockWithTx блокирует захваченное сообщение до конца транзакции обработчика;
+	// если аренда уже истекла, возвращает ErrLeaseLost
+	LockWithTx(ctx context.Context, tx *sql.Tx, id string) error
+	MarkAsProcessed(ctx context.Context, id string) error
+	MarkAsProcessedWithTx(ctx context.Context, tx *sql.Tx, id string) error
+	MarkAsFailed(ctx context.Context, id string, reason string, nextAttemptAt time.Time) error
+	MarkAsDeadLetter(ctx context.Context, id string, reason string) error
+}
@@ -0,0 +1,196 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// Format — формат сообщения в топике
+type Format string
+
+const (
+	// FormatLegacy — собственный конверт: event_type, event_id, data, timestamp
+	FormatLegacy Format = "legacy"
+	// FormatCloudEventsStructured — CloudEvents 1.0, structured content mode:
+	// атрибуты и data в одном JSON (application/cloudevents+json)
+	FormatCloudEventsStructured Format = "cloudevents-structured"
+	// FormatCloudEventsBinary — CloudEvents 1.0, binary content mode:
+	// атрибуты в заголовках ce_*, в теле только data
+	FormatCloudEventsBinary Format = "cloudevents-binary"
+)
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsContentType = "application/cloudevents+json; charset=UTF-8"
+	dataContentType        = "application/json"
+
+	headerContentType     = "content-type"
+	headerCESpecVersion   = "ce_specversion"
+	headerCEID            = "ce_id"
+	headerCEType          = "ce_type"
+	headerCESource        = "ce_source"
+	headerCETime          = "ce_time"
+	headerCESubject       = "ce_subject"
+	cloudEventsJSONPrefix = "application/cloudevents+json"
+)
+
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(value); format {
+	case FormatLegacy, FormatCloudEventsStructured, FormatCloudEventsBinary:
+		return format, nil
+	case "":
+		return FormatLegacy, nil
+	default:
+		return "", fmt.Errorf("unknown event format %q", value)
+	}
+}
+
+// cloudEvent — structured-представление CloudEvents 1.0
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Source          string          `json:"source"`
+	Time            string          `json:"time,omitempty"`
+	Subject         string          `json:"subject,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+// encodeEvent собирает тело и заголовки сообщения в формате топика.
+// Заголовки корреляции (correlation_id и др.) добавляются во всех форматах
+func encodeEvent(topic string, event Event, format Format) ([]byte, []sarama.RecordHeader, error) {
+	headers := metadataHeaders(event.Metadata)
+
+	if format == FormatLegacy {
+		value, err := json.Marshal(event)
+		return value, headers, err
+	}
+
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ce := cloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              event.EventID,
+		Type:            event.EventType,
+		Source:          cloudEventSource(topic, event),
+		Time:            time.Unix(event.Timestamp, 0).UTC().Format(time.RFC3339),
+		Subject:         event.PartitionKey,
+		DataContentType: dataContentType,
+		Data:            data,
+	}
+
+	if format == FormatCloudEventsStructured {
+		value, err := json.Marshal(ce)
+		headers = append(headers, sarama.RecordHeader{Key: []byte(headerContentType), Value: []byte(cloudEventsContentType)})
+		return value, headers, err
+	}
+
+	for _, attribute := range [][2]string{
+		{headerCESpecVersion, ce.SpecVersion},
+		{headerCEID, ce.ID},
+		{headerCEType, ce.Type},
+		{headerCESource, ce.Source},
+		{headerCETime, ce.Time},
+		{headerCESubject, ce.Subject},
+		{headerContentType, ce.DataContentType},
+	} {
+		if attribute[1] != "" {
+			headers = append(headers, sarama.RecordHeader{Key: []byte(attribute[0]), Value: []byte(attribute[1])})
+		}
+	}
+	return data, headers, nil
+}
+
+// decodeEvent разбирает сообщение любого из форматов: binary CloudEvents узнаётся
+// по заголовку ce_specversion, structured — по content-type или полю specversion,
+// остальное читается как legacy-конверт
+func decodeEvent(message *sarama.ConsumerMessage) (Event, error) {
+	headers := make(map[string]string, len(message.Headers))
+	for _, header := range message.Headers {
+		if header != nil {
+			headers[strings.ToLower(string(header.Key))] = string(header.Value)
+		}
+	}
+
+	if _, ok := headers[headerCESpecVersion]; ok {
+		return fromCloudEvent(cloudEvent{
+			SpecVersion:     headers[headerCESpecVersion],
+			ID:              headers[headerCEID],
+			Type:            headers[headerCEType],
+			Source:          headers[headerCESource],
+			Time:            headers[headerCETime],
+			Subject:         headers[headerCESubject],
+			DataContentType: headers[headerContentType],
+			Data:            message.Value,
+		})
+	}
+
+	var probe struct {
+		SpecVersion string `json:"specversion"`
+	}
+	if strings.HasPrefix(headers[headerContentType], cloudEventsJSONPrefix) || json.Unmarshal(message.Value, &probe) == nil && probe.SpecVersion != "" {
+		var ce cloudEvent
+		if err := json.Unmarshal(message.Value, &ce); err != nil {
+			return Event{}, fmt.Errorf("failed to unmarshal cloudevent: %w", err)
+		}
+		return fromCloudEvent(ce)
+	}
+
+	var event Event
+	if err := json.Unmarshal(message.Value, &event); err != nil {
+		return Event{}, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	return event, nil
+}
+
+func fromCloudEvent(ce cloudEvent) (Event, error) {
+	if ce.SpecVersion != cloudEventsSpecVersion {
+		return Event{}, fmt.Errorf("unsupported cloudevents specversion %q", ce.SpecVersion)
+	}
+	if ce.ID == "" || ce.Type == "" || ce.Source == "" {
+		return Event{}, fmt.Errorf("cloudevent requires id, type and source")
+	}
+	if ce.DataContentType != "" && !strings.HasPrefix(ce.DataContentType, dataContentType) {
+		return Event{}, fmt.Errorf("unsupported datacontenttype %q", ce.DataContentType)
+	}
+
+	event := Event{
+		EventType:    ce.Type,
+		EventID:      ce.ID,
+		PartitionKey: ce.Subject,
+	}
+
+	if ce.Time != "" {
+		t, err := time.Parse(time.RFC3339Nano, ce.Time)
+		if err != nil {
+			return Event{}, fmt.Errorf("invalid cloudevent time %q: %w", ce.Time, err)
+		}
+		event.Timestamp = t.Unix()
+	}
+
+	if len(ce.Data) > 0 {
+		if err := json.Unmarshal(ce.Data, &event.Data); err != nil {
+			return Event{}, fmt.Errorf("failed to unmarshal cloudevent data: %w", err)
+		}
+	}
+
+	event.Metadata.Source = ce.Source
+	return event, nil
+}
+
+// cloudEventSource — атрибут source обязателен; без имени сервиса в Metadata
+// источником считается топик
+func cloudEventSource(topic string, event Event) string {
+	if event.Metadata.Source != "" {
+		return event.Metadata.Source
+	}
+	return topic
+}
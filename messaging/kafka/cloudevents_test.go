@@ -0,0 +1,98 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+
+	"messaging/correlation"
+)
+
+func newTestEvent() Event {
+	return Event{
+		EventType:    "order.created",
+		EventID:      "event-1",
+		PartitionKey: "order-1",
+		Data:         map[string]interface{}{"order_id": "order-1"},
+		Timestamp:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC).Unix(),
+		Metadata: correlation.Metadata{
+			CorrelationID: "corr-1",
+			Source:        "orders-service",
+			SchemaVersion: 1,
+		},
+	}
+}
+
+func consumed(value []byte, headers []sarama.RecordHeader) *sarama.ConsumerMessage {
+	message := &sarama.ConsumerMessage{Value: value}
+	for i := range headers {
+		message.Headers = append(message.Headers, &headers[i])
+	}
+	return message
+}
+
+func TestCloudEvents_RoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatLegacy, FormatCloudEventsStructured, FormatCloudEventsBinary} {
+		t.Run(string(format), func(t *testing.T) {
+			event := newTestEvent()
+
+			value, headers, err := encodeEvent("orders-events", event, format)
+			assert.NoError(t, err)
+
+			decoded, err := decodeEvent(consumed(value, headers))
+			assert.NoError(t, err)
+			assert.Equal(t, event.EventType, decoded.EventType)
+			assert.Equal(t, event.EventID, decoded.EventID)
+			assert.Equal(t, event.PartitionKey, decoded.PartitionKey)
+			assert.Equal(t, event.Data, decoded.Data)
+			assert.Equal(t, event.Timestamp, decoded.Timestamp)
+			assert.Equal(t, event.Metadata, metadataFromHeaders(consumed(value, headers).Headers))
+		})
+	}
+}
+
+func TestCloudEvents_StructuredAttributes(t *testing.T) {
+	value, headers, err := encodeEvent("orders-events", newTestEvent(), FormatCloudEventsStructured)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"specversion": "1.0",
+		"id": "event-1",
+		"type": "order.created",
+		"source": "orders-service",
+		"time": "2024-05-01T12:00:00Z",
+		"subject": "order-1",
+		"datacontenttype": "application/json",
+		"data": {"order_id": "order-1"}
+	}`, string(value))
+	assert.Contains(t, headers, sarama.RecordHeader{Key: []byte("content-type"), Value: []byte(cloudEventsContentType)})
+}
+
+func TestCloudEvents_ForeignProducer(t *testing.T) {
+	// Событие другой команды: без наших заголовков и без subject
+	message := consumed([]byte(`{"order_id": "order-9"}`), []sarama.RecordHeader{
+		{Key: []byte("ce_specversion"), Value: []byte("1.0")},
+		{Key: []byte("ce_id"), Value: []byte("a-1")},
+		{Key: []byte("ce_type"), Value: []byte("order.created")},
+		{Key: []byte("ce_source"), Value: []byte("/storefront")},
+		{Key: []byte("ce_time"), Value: []byte("2024-05-01T12:00:00.5+03:00")},
+	})
+
+	event, err := decodeEvent(message)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a-1", event.EventID)
+	assert.Equal(t, "/storefront", event.Metadata.Source)
+	assert.Equal(t, time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC).Unix(), event.Timestamp)
+	assert.Equal(t, "order-9", event.Data["order_id"])
+}
+
+func TestCloudEvents_Invalid(t *testing.T) {
+	_, err := decodeEvent(consumed([]byte(`{"specversion": "0.3", "id": "a-1", "type": "order.created", "source": "/x"}`), nil))
+	assert.EqualError(t, err, `unsupported cloudevents specversion "0.3"`)
+
+	_, err = decodeEvent(consumed([]byte(`{"specversion": "1.0", "id": "a-1", "type": "order.created"}`), nil))
+	assert.EqualError(t, err, "cloudevent requires id, type and source")
+}
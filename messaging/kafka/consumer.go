@@ -0,0 +1,272 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"messaging/broker"
+	"messaging/correlation"
+)
+
+type Consumer struct {
+	consumer      sarama.ConsumerGroup
+	groupID       string
+	topics        []string
+	eventHandlers map[string]EventHandler
+	retry         ConsumerRetry
+	poisonStore   PoisonStore
+	workers       int
+	maxInFlight   int
+}
+
+const (
+	defaultWorkers     = 8
+	defaultMaxInFlight = 256
+)
+
+type EventHandler = broker.EventHandler
+
+var _ broker.Subscriber = (*Consumer)(nil)
+
+type ConsumerGroupHandler struct {
+	groupID       string
+	eventHandlers map[string]EventHandler
+	retry         ConsumerRetry
+	poisonStore   PoisonStore
+	pauser        *partitionPauser
+	workers       int
+	maxInFlight   int
+}
+
+func NewConsumer(brokers []string, groupID string, topics []string) (*Consumer, error) {
+	config := sarama.NewConfig()
+	config.Consumer.Group.Rebalance.Strategy = sarama.NewBalanceStrategyRoundRobin()
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	config.Consumer.Group.Session.Timeout = 10 * time.Second   // 10 seconds
+	config.Consumer.Group.Heartbeat.Interval = 3 * time.Second // 3 seconds
+	// Сообщения отменённых транзакций не видны, незавершённые транзакции ждут фиксации
+	config.Consumer.IsolationLevel = sarama.ReadCommitted
+
+	consumer, err := sarama.NewConsumerGroup(brokers, groupID, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	return &Consumer{
+		consumer:      consumer,
+		groupID:       groupID,
+		topics:        topics,
+		eventHandlers: make(map[string]EventHandler),
+		retry:         defaultConsumerRetry,
+		workers:       defaultWorkers,
+		maxInFlight:   defaultMaxInFlight,
+	}, nil
+}
+
+func (c *Consumer) RegisterHandler(eventType string, handler EventHandler) {
+	c.eventHandlers[eventType] = handler
+}
+
+// SetRetry задаёт повторы обработчика до фиксации offset
+func (c *Consumer) SetRetry(retry ConsumerRetry) {
+	if retry.Attempts < 1 {
+		retry.Attempts = 1
+	}
+	c.retry = retry
+}
+
+// SetPoisonStore задаёт хранилище для сообщений, которые не удалось разобрать.
+// Без него такие сообщения только логируются и пропускаются
+func (c *Consumer) SetPoisonStore(store PoisonStore) {
+	c.poisonStore = store
+}
+
+// SetConcurrency задаёт число воркеров на партицию и сколько прочитанных сообщений
+// партиции может ждать фиксации offset. Сообщения с одним ключом всё равно идут по порядку
+func (c *Consumer) SetConcurrency(workers, maxInFlight int) {
+	if workers > 0 {
+		c.workers = workers
+	}
+	if maxInFlight > 0 {
+		c.maxInFlight = maxInFlight
+	}
+}
+
+func (c *Consumer) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	handler := &ConsumerGroupHandler{
+		groupID:       c.groupID,
+		eventHandlers: c.eventHandlers,
+		retry:         c.retry,
+		poisonStore:   c.poisonStore,
+		pauser:        newPartitionPauser(c.consumer),
+		workers:       c.workers,
+		maxInFlight:   c.maxInFlight,
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		for {
+			if err := c.consumer.Consume(ctx, c.topics, handler); err != nil {
+				log.Printf("Error from consumer: %v", err)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+
+	sigterm := make(chan os.Signal, 1)
+	signal.Notify(sigterm, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case <-ctx.Done():
+		log.Println("Consumer context cancelled")
+	case <-sigterm:
+		log.Println("Termination signal received")
+		cancel()
+	}
+
+	wg.Wait()
+	return c.consumer.Close()
+}
+
+func (h *ConsumerGroupHandler) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *ConsumerGroupHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim обрабатывает сообщения с одним ключом последовательно, а с разными — параллельно
+// в пуле из workers воркеров.
+// Сбой обработчика повторяется (см. process); если сессия закончилась раньше, чем сообщение
+// обработано, после переподключения чтение продолжается с последнего зафиксированного offset
+func (h *ConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	ctx := session.Context()
+	gauges := newPartitionGauges(h.groupID, claim.Topic(), claim.Partition())
+
+	dispatcher := newKeyDispatcher(h.workers, h.maxInFlight, gauges, func(message *sarama.ConsumerMessage) error {
+		return h.process(ctx, message, func() error {
+			return h.handleMessage(ctx, message)
+		})
+	}, func(message *sarama.ConsumerMessage) {
+		session.MarkMessage(message, "")
+	})
+
+	for {
+		select {
+		case message := <-claim.Messages():
+			if message == nil {
+				dispatcher.Wait()
+				return dispatcher.Err()
+			}
+			// Пока в работе maxInFlight сообщений, новые не читаются из claim
+			dispatcher.Dispatch(ctx, message)
+
+		case <-dispatcher.Failed():
+			dispatcher.Wait()
+			return dispatcher.Err()
+
+		case <-session.Context().Done():
+			dispatcher.Wait()
+			return nil
+		}
+	}
+}
+
+// process вызывает handle, пока сообщение не будет обработано или сессия не закончится.
+// Неудачная попытка повторяется через retry.Backoff; когда retry.Attempts исчерпаны,
+// чтение партиции приостанавливается на retry.Pause, и попытки начинаются заново.
+// Сообщение не пропускается: offset за ним не фиксируется, пока оно не обработано
+func (h *ConsumerGroupHandler) process(ctx context.Context, message *sarama.ConsumerMessage, handle func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := handle()
+		if err == nil {
+			return nil
+		}
+
+		if attempt < h.retry.Attempts {
+			delay := h.retry.delay(attempt)
+			log.Printf("Retrying offset %d of %s/%d in %s (attempt %d): %v", message.Offset, message.Topic, message.Partition, delay, attempt, err)
+			if sleep(ctx, delay) != nil {
+				return err
+			}
+			continue
+		}
+
+		log.Printf("Pausing %s/%d for %s: offset %d failed %d times: %v", message.Topic, message.Partition, h.retry.Pause, message.Offset, attempt, err)
+		h.pauser.Pause(message.Topic, message.Partition)
+		waitErr := sleep(ctx, h.retry.Pause)
+		h.pauser.Resume(message.Topic, message.Partition)
+		if waitErr != nil {
+			return err
+		}
+		attempt = 0
+	}
+}
+
+// handleMessage передаёт обработчику событие с заголовками в Metadata и тот же
+// correlation.Metadata в ctx. Сообщение, которое не разбирается, уходит в poison store,
+// а событие без обработчика пропускается: в топике есть события, которые сервису не нужны
+func (h *ConsumerGroupHandler) handleMessage(ctx context.Context, message *sarama.ConsumerMessage) error {
+	event, err := decodeEvent(message)
+	if err != nil {
+		return h.quarantine(ctx, message, err)
+	}
+	if event.PartitionKey == "" {
+		event.PartitionKey = string(message.Key)
+	}
+	// source из атрибутов CloudEvents, если продюсер не прислал заголовок source
+	source := event.Metadata.Source
+	event.Metadata = metadataFromHeaders(message.Headers)
+	if event.Metadata.Source == "" {
+		event.Metadata.Source = source
+	}
+	event.Metadata.EventID = event.EventID
+	ctx = correlation.WithMetadata(ctx, event.Metadata)
+
+	handler, exists := h.eventHandlers[event.EventType]
+	if !exists {
+		log.Printf("No handler registered for event type: %s", event.EventType)
+		return nil
+	}
+
+	if err := handler(ctx, event); err != nil {
+		log.Printf("Failed to handle event %s: %v", event.EventType, err)
+		return err
+	}
+
+	log.Printf("Successfully processed event %s with ID %s", event.EventType, event.EventID)
+	return nil
+}
+
+// quarantine откладывает сообщение, которое не разбирается: повторное чтение его не исправит.
+// Ошибка сохранения возвращается, чтобы сообщение не потерялось
+func (h *ConsumerGroupHandler) quarantine(ctx context.Context, message *sarama.ConsumerMessage, reason error) error {
+	log.Printf("Failed to decode message at offset %d of %s/%d: %v", message.Offset, message.Topic, message.Partition, reason)
+	if h.poisonStore == nil {
+		return nil
+	}
+
+	if err := h.poisonStore.Quarantine(ctx, newPoisonMessage(message, reason)); err != nil {
+		return fmt.Errorf("failed to quarantine message: %w", err)
+	}
+
+	log.Printf("Quarantined offset %d of %s/%d", message.Offset, message.Topic, message.Partition)
+	return nil
+}
@@ -0,0 +1,79 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sessionKey struct{}
+
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	ctx context.Context
+}
+
+func (s *fakeSession) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeSession) MarkMessage(message *sarama.ConsumerMessage, metadata string) {}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Topic() string {
+	return "orders-events"
+}
+
+func (c *fakeClaim) Partition() int32 {
+	return 0
+}
+
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return c.messages
+}
+
+func newClaim(t *testing.T) *fakeClaim {
+	value, headers, err := encodeEvent("orders-events", Event{EventType: "order.created", EventID: "event-1", PartitionKey: "order-1"}, FormatLegacy)
+	require.NoError(t, err)
+
+	message := &sarama.ConsumerMessage{Topic: "orders-events", Key: []byte("order-1"), Value: value}
+	for i := range headers {
+		message.Headers = append(message.Headers, &headers[i])
+	}
+
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, 1)}
+	claim.messages <- message
+	close(claim.messages)
+	return claim
+}
+
+// Обработчики получают контекст сессии: ребалансировка и остановка прерывают их работу
+func TestConsumerGroupHandler_HandlersReceiveSessionContext(t *testing.T) {
+	sessionCtx, cancel := context.WithCancel(context.WithValue(context.Background(), sessionKey{}, "session"))
+	defer cancel()
+	session := &fakeSession{ctx: sessionCtx}
+
+	var received context.Context
+	handlers := map[string]EventHandler{
+		"order.created": func(ctx context.Context, event Event) error {
+			received = ctx
+			return nil
+		},
+	}
+
+	t.Run("worker pool", func(t *testing.T) {
+		received = nil
+		handler := &ConsumerGroupHandler{groupID: "payments-service", eventHandlers: handlers, workers: 1, maxInFlight: 1}
+
+		require.NoError(t, handler.ConsumeClaim(session, newClaim(t)))
+		require.NotNil(t, received)
+		assert.Equal(t, "session", received.Value(sessionKey{}))
+	})
+}
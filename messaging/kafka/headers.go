@@ -0,0 +1,64 @@
+package kafka
+
+import (
+	"strconv"
+
+	"github.com/IBM/sarama"
+
+	"messaging/broker"
+	"messaging/correlation"
+)
+
+const (
+	HeaderCorrelationID = broker.HeaderCorrelationID
+	HeaderCausationID   = broker.HeaderCausationID
+	HeaderTraceparent   = broker.HeaderTraceparent
+	HeaderSource        = broker.HeaderSource
+	HeaderSchemaVersion = broker.HeaderSchemaVersion
+)
+
+func metadataHeaders(metadata correlation.Metadata) []sarama.RecordHeader {
+	values := map[string]string{
+		HeaderCorrelationID: metadata.CorrelationID,
+		HeaderCausationID:   metadata.CausationID,
+		HeaderTraceparent:   metadata.Traceparent,
+		HeaderSource:        metadata.Source,
+	}
+	if metadata.SchemaVersion > 0 {
+		values[HeaderSchemaVersion] = strconv.Itoa(metadata.SchemaVersion)
+	}
+
+	headers := make([]sarama.RecordHeader, 0, len(values))
+	for _, key := range []string{HeaderCorrelationID, HeaderCausationID, HeaderTraceparent, HeaderSource, HeaderSchemaVersion} {
+		if value := values[key]; value != "" {
+			headers = append(headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+		}
+	}
+	return headers
+}
+
+// metadataFromHeaders разбирает заголовки сообщения; у сообщений старых продюсеров
+// заголовков нет, и поля остаются пустыми
+func metadataFromHeaders(headers []*sarama.RecordHeader) correlation.Metadata {
+	var metadata correlation.Metadata
+	for _, header := range headers {
+		if header == nil {
+			continue
+		}
+
+		value := string(header.Value)
+		switch string(header.Key) {
+		case HeaderCorrelationID:
+			metadata.CorrelationID = value
+		case HeaderCausationID:
+			metadata.CausationID = value
+		case HeaderTraceparent:
+			metadata.Traceparent = value
+		case HeaderSource:
+			metadata.Source = value
+		case HeaderSchemaVersion:
+			metadata.SchemaVersion, _ = strconv.Atoi(value)
+		}
+	}
+	return metadata
+}
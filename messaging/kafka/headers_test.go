@@ -0,0 +1,34 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+
+	"messaging/correlation"
+)
+
+func TestMetadataHeaders_RoundTrip(t *testing.T) {
+	metadata := correlation.Metadata{
+		CorrelationID: "corr-1",
+		CausationID:   "event-0",
+		Traceparent:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		Source:        "orders-service",
+		SchemaVersion: 1,
+	}
+
+	headers := metadataHeaders(metadata)
+	assert.Len(t, headers, 5)
+
+	consumed := make([]*sarama.RecordHeader, 0, len(headers))
+	for i := range headers {
+		consumed = append(consumed, &headers[i])
+	}
+	assert.Equal(t, metadata, metadataFromHeaders(consumed))
+}
+
+func TestMetadataHeaders_SkipsEmpty(t *testing.T) {
+	assert.Empty(t, metadataHeaders(correlation.Metadata{}))
+	assert.Equal(t, correlation.Metadata{}, metadataFromHeaders(nil))
+}
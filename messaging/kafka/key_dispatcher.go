@@ -0,0 +1,179 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+
+	"github.com/IBM/sarama"
+)
+
+// keyDispatcher раздаёт сообщения одной партиции ограниченному пулу воркеров: сообщения
+// с одним ключом обрабатываются строго по порядку, с разными — параллельно.
+// Offset помечается только когда обработаны все сообщения партиции до него,
+// поэтому после падения или ребалансировки необработанные сообщения придут снова.
+// Непомеченных сообщений не больше maxInFlight: Dispatch ждёт, пока освободится место.
+// После первой ошибки обработчика новые сообщения не принимаются, а Failed закрывается
+type keyDispatcher struct {
+	handle func(*sarama.ConsumerMessage) error
+	mark   func(*sarama.ConsumerMessage)
+	gauges *partitionGauges
+
+	// slots ограничивает число непомеченных сообщений
+	slots chan struct{}
+	// ready — ключи с сообщениями, которые ждут воркера; ключ в ready или в работе не больше одного раза
+	ready chan string
+	stop  chan struct{}
+
+	mu sync.Mutex
+	// queues — очереди ключей, которые ждут воркера или в работе; голова очереди обрабатывается первой
+	queues map[string][]*sarama.ConsumerMessage
+	// inFlight — принятые, но ещё не помеченные сообщения в порядке offset
+	inFlight []*sarama.ConsumerMessage
+	handled  map[int64]bool
+	err      error
+	failed   chan struct{}
+	// pending — принятые сообщения, которые ещё не обработаны и не отброшены после ошибки
+	pending sync.WaitGroup
+	workers sync.WaitGroup
+}
+
+func newKeyDispatcher(workers, maxInFlight int, gauges *partitionGauges, handle func(*sarama.ConsumerMessage) error, mark func(*sarama.ConsumerMessage)) *keyDispatcher {
+	d := &keyDispatcher{
+		handle:  handle,
+		mark:    mark,
+		gauges:  gauges,
+		slots:   make(chan struct{}, maxInFlight),
+		ready:   make(chan string, maxInFlight),
+		stop:    make(chan struct{}),
+		queues:  make(map[string][]*sarama.ConsumerMessage),
+		handled: make(map[int64]bool),
+		failed:  make(chan struct{}),
+	}
+
+	d.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go d.work()
+	}
+	return d
+}
+
+// Dispatch принимает сообщение в обработку. Если в работе уже maxInFlight сообщений,
+// ждёт, пока их offset будут помечены. false — сообщение не принято: обработка
+// остановлена ошибкой или ctx завершён
+func (d *keyDispatcher) Dispatch(ctx context.Context, message *sarama.ConsumerMessage) bool {
+	select {
+	case d.slots <- struct{}{}:
+	case <-d.failed:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+
+	key := string(message.Key)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.err != nil {
+		<-d.slots
+		return false
+	}
+
+	d.pending.Add(1)
+	d.inFlight = append(d.inFlight, message)
+	d.gauges.inFlight(len(d.inFlight))
+	d.gauges.queued(1)
+
+	queue, queued := d.queues[key]
+	d.queues[key] = append(queue, message)
+	if !queued {
+		// Ключей в ready не больше, чем непомеченных сообщений, поэтому запись не блокируется
+		d.ready <- key
+	}
+	return true
+}
+
+// Wait дожидается обработки всех принятых сообщений и останавливает воркеров
+func (d *keyDispatcher) Wait() {
+	d.pending.Wait()
+	close(d.stop)
+	d.workers.Wait()
+	d.gauges.delete()
+}
+
+func (d *keyDispatcher) Failed() <-chan struct{} {
+	return d.failed
+}
+
+// Err возвращает первую ошибку обработчика
+func (d *keyDispatcher) Err() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
+
+func (d *keyDispatcher) work() {
+	defer d.workers.Done()
+
+	for {
+		select {
+		case key := <-d.ready:
+			d.run(key)
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// run обрабатывает голову очереди ключа и, если в очереди остались сообщения,
+// возвращает ключ в ready, чтобы другие ключи не ждали, пока очередь опустеет
+func (d *keyDispatcher) run(key string) {
+	d.mu.Lock()
+	message := d.queues[key][0]
+	d.gauges.queued(-1)
+	d.gauges.busy(1)
+	d.mu.Unlock()
+
+	err := d.handle(message)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.gauges.busy(-1)
+
+	if err != nil {
+		// Остальные сообщения ключа нельзя обрабатывать в обход упавшего
+		dropped := len(d.queues[key])
+		delete(d.queues, key)
+		d.gauges.queued(-(dropped - 1))
+		if d.err == nil {
+			d.err = err
+			close(d.failed)
+		}
+		for i := 0; i < dropped; i++ {
+			d.pending.Done()
+		}
+		return
+	}
+
+	d.queues[key] = d.queues[key][1:]
+	if len(d.queues[key]) == 0 {
+		delete(d.queues, key)
+	} else {
+		d.ready <- key
+	}
+	d.complete(message)
+	d.pending.Done()
+}
+
+func (d *keyDispatcher) complete(message *sarama.ConsumerMessage) {
+	d.handled[message.Offset] = true
+
+	for len(d.inFlight) > 0 && d.handled[d.inFlight[0].Offset] {
+		head := d.inFlight[0]
+		d.mark(head)
+		delete(d.handled, head.Offset)
+		d.inFlight = d.inFlight[1:]
+		<-d.slots
+	}
+	d.gauges.inFlight(len(d.inFlight))
+}
@@ -0,0 +1,186 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMessage(key string, offset int64) *sarama.ConsumerMessage {
+	return &sarama.ConsumerMessage{Key: []byte(key), Offset: offset}
+}
+
+func TestKeyDispatcher_SameKeyIsSequential(t *testing.T) {
+	var mu sync.Mutex
+	var handled []int64
+
+	dispatcher := newKeyDispatcher(4, 16, nil, func(message *sarama.ConsumerMessage) error {
+		mu.Lock()
+		handled = append(handled, message.Offset)
+		mu.Unlock()
+		return nil
+	}, func(*sarama.ConsumerMessage) {})
+
+	for offset := int64(0); offset < 100; offset++ {
+		dispatcher.Dispatch(context.Background(), newMessage("order-1", offset))
+	}
+	dispatcher.Wait()
+
+	assert.Len(t, handled, 100)
+	for i, offset := range handled {
+		assert.Equal(t, int64(i), offset)
+	}
+}
+
+func TestKeyDispatcher_MarksOffsetsInOrder(t *testing.T) {
+	release := make(chan struct{})
+	secondHandled := make(chan struct{})
+
+	var mu sync.Mutex
+	var marked []int64
+
+	dispatcher := newKeyDispatcher(4, 16, nil, func(message *sarama.ConsumerMessage) error {
+		switch string(message.Key) {
+		case "order-1":
+			<-release
+		case "order-2":
+			close(secondHandled)
+		}
+		return nil
+	}, func(message *sarama.ConsumerMessage) {
+		mu.Lock()
+		marked = append(marked, message.Offset)
+		mu.Unlock()
+	})
+
+	dispatcher.Dispatch(context.Background(), newMessage("order-1", 0))
+	dispatcher.Dispatch(context.Background(), newMessage("order-2", 1))
+
+	// Другой ключ не ждёт медленное сообщение, но его offset не помечается раньше
+	<-secondHandled
+	mu.Lock()
+	assert.Empty(t, marked)
+	mu.Unlock()
+
+	close(release)
+	dispatcher.Wait()
+
+	assert.Equal(t, []int64{0, 1}, marked)
+}
+
+func TestKeyDispatcher_StopsOnHandlerError(t *testing.T) {
+	handlerErr := errors.New("db is down")
+
+	var mu sync.Mutex
+	var handled, marked []int64
+
+	dispatcher := newKeyDispatcher(4, 16, nil, func(message *sarama.ConsumerMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, message.Offset)
+		if message.Offset == 1 {
+			return handlerErr
+		}
+		return nil
+	}, func(message *sarama.ConsumerMessage) {
+		mu.Lock()
+		marked = append(marked, message.Offset)
+		mu.Unlock()
+	})
+
+	dispatcher.Dispatch(context.Background(), newMessage("order-1", 0))
+	dispatcher.Dispatch(context.Background(), newMessage("order-1", 1))
+	dispatcher.Dispatch(context.Background(), newMessage("order-1", 2))
+	<-dispatcher.Failed()
+	assert.False(t, dispatcher.Dispatch(context.Background(), newMessage("order-2", 3)))
+	dispatcher.Wait()
+
+	assert.ErrorIs(t, dispatcher.Err(), handlerErr)
+	assert.Equal(t, []int64{0, 1}, handled)
+	assert.Equal(t, []int64{0}, marked)
+}
+
+func TestKeyDispatcher_BoundsWorkers(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 6)
+
+	dispatcher := newKeyDispatcher(2, 16, nil, func(message *sarama.ConsumerMessage) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}, func(*sarama.ConsumerMessage) {})
+
+	for offset := int64(0); offset < 6; offset++ {
+		dispatcher.Dispatch(context.Background(), newMessage(fmt.Sprintf("order-%d", offset), offset))
+	}
+
+	<-started
+	<-started
+	select {
+	case <-started:
+		t.Fatal("third handler started while both workers are busy")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	dispatcher.Wait()
+	assert.Len(t, started, 4)
+}
+
+func TestKeyDispatcher_LimitsInFlight(t *testing.T) {
+	release := make(chan struct{})
+	dispatcher := newKeyDispatcher(4, 2, nil, func(message *sarama.ConsumerMessage) error {
+		<-release
+		return nil
+	}, func(*sarama.ConsumerMessage) {})
+
+	assert.True(t, dispatcher.Dispatch(context.Background(), newMessage("order-1", 0)))
+	assert.True(t, dispatcher.Dispatch(context.Background(), newMessage("order-2", 1)))
+
+	// Третье сообщение ждёт, пока освободится место, и отказывается по ctx
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.False(t, dispatcher.Dispatch(ctx, newMessage("order-3", 2)))
+
+	close(release)
+	assert.True(t, dispatcher.Dispatch(context.Background(), newMessage("order-3", 2)))
+	dispatcher.Wait()
+}
+
+func TestKeyDispatcher_ReportsGauges(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	gauges := newPartitionGauges("test-group", "gauges-topic", 7)
+	labels := []string{"test-group", "gauges-topic", "7"}
+	series := testutil.CollectAndCount(inFlightMessages) + testutil.CollectAndCount(queuedMessages) + testutil.CollectAndCount(busyWorkers)
+
+	dispatcher := newKeyDispatcher(1, 16, gauges, func(message *sarama.ConsumerMessage) error {
+		if message.Offset == 0 {
+			close(started)
+		}
+		<-release
+		return nil
+	}, func(*sarama.ConsumerMessage) {})
+
+	dispatcher.Dispatch(context.Background(), newMessage("order-1", 0))
+	dispatcher.Dispatch(context.Background(), newMessage("order-1", 1))
+	dispatcher.Dispatch(context.Background(), newMessage("order-2", 2))
+	<-started
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(inFlightMessages.WithLabelValues(labels...)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(queuedMessages.WithLabelValues(labels...)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(busyWorkers.WithLabelValues(labels...)))
+
+	close(release)
+	dispatcher.Wait()
+
+	// После остановки серии партиции удалены
+	assert.Equal(t, series, testutil.CollectAndCount(inFlightMessages)+testutil.CollectAndCount(queuedMessages)+testutil.CollectAndCount(busyWorkers))
+}
@@ -0,0 +1,60 @@
+package kafka
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Метрики регистрируются в prometheus.DefaultRegisterer и отдаются через promhttp.Handler()
+var (
+	queuedMessages = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_consumer_queued_messages",
+		Help: "Messages of a partition waiting for a free worker.",
+	}, []string{"group", "topic", "partition"})
+	inFlightMessages = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_consumer_inflight_messages",
+		Help: "Messages of a partition read from Kafka whose offset is not committed yet.",
+	}, []string{"group", "topic", "partition"})
+	busyWorkers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_consumer_busy_workers",
+		Help: "Workers of a partition currently running a handler.",
+	}, []string{"group", "topic", "partition"})
+)
+
+// partitionGauges — метрики очереди одной партиции; nil отключает их, например в тестах
+type partitionGauges struct {
+	labels []string
+}
+
+func newPartitionGauges(group, topic string, partition int32) *partitionGauges {
+	return &partitionGauges{labels: []string{group, topic, strconv.Itoa(int(partition))}}
+}
+
+func (g *partitionGauges) queued(delta int) {
+	if g != nil {
+		queuedMessages.WithLabelValues(g.labels...).Add(float64(delta))
+	}
+}
+
+func (g *partitionGauges) inFlight(count int) {
+	if g != nil {
+		inFlightMessages.WithLabelValues(g.labels...).Set(float64(count))
+	}
+}
+
+func (g *partitionGauges) busy(delta int) {
+	if g != nil {
+		busyWorkers.WithLabelValues(g.labels...).Add(float64(delta))
+	}
+}
+
+// delete убирает серии партиции, когда она перестаёт читаться этой репликой
+func (g *partitionGauges) delete() {
+	if g != nil {
+		queuedMessages.DeleteLabelValues(g.labels...)
+		inFlightMessages.DeleteLabelValues(g.labels...)
+		busyWorkers.DeleteLabelValues(g.labels...)
+	}
+}
@@ -0,0 +1,46 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// PoisonMessage — сообщение, которое консьюмер не смог разобрать, в исходном виде
+type PoisonMessage struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+	Error     string
+	Timestamp time.Time
+}
+
+// PoisonStore сохраняет poison-сообщения. Пока Quarantine возвращает ошибку,
+// offset сообщения не фиксируется, и оно повторяется как сбой обработчика
+type PoisonStore interface {
+	Quarantine(ctx context.Context, message PoisonMessage) error
+}
+
+func newPoisonMessage(message *sarama.ConsumerMessage, reason error) PoisonMessage {
+	headers := make(map[string]string, len(message.Headers))
+	for _, header := range message.Headers {
+		if header != nil {
+			headers[string(header.Key)] = string(header.Value)
+		}
+	}
+
+	return PoisonMessage{
+		Topic:     message.Topic,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+		Key:       message.Key,
+		Value:     message.Value,
+		Headers:   headers,
+		Error:     reason.Error(),
+		Timestamp: message.Timestamp,
+	}
+}
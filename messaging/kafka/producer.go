@@ -0,0 +1,230 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/IBM/sarama"
+
+	"messaging/broker"
+)
+
+type Producer struct {
+	producer sarama.SyncProducer
+	// newProducer пересоздаёт транзакционный продюсер, которого брокер отсёк (fenced)
+	newProducer   func() (sarama.SyncProducer, error)
+	transactional bool
+	// formats — формат сообщений по топикам, по умолчанию FormatLegacy
+	formats map[string]Format
+	// txMu — у продюсера одна открытая транзакция за раз; под ним же заменяется отсечённый продюсер
+	txMu sync.Mutex
+}
+
+// Event и TopicEvent — общие типы всех транспортов, Producer реализует broker.Publisher
+type (
+	Event      = broker.Event
+	TopicEvent = broker.TopicEvent
+)
+
+var _ broker.Publisher = (*Producer)(nil)
+
+func NewProducer(brokers []string) (*Producer, error) {
+	return newProducer(brokers, newProducerConfig())
+}
+
+// NewTransactionalProducer создаёт идемпотентный продюсер с transactional.id.
+// transactionalID должен быть своим у каждой реплики и не меняться при её перезапуске:
+// только тогда брокер отсекает ("fence") зависший старый экземпляр с тем же id
+func NewTransactionalProducer(brokers []string, transactionalID string) (*Producer, error) {
+	config := newProducerConfig()
+	config.Producer.Idempotent = true
+	config.Producer.Transaction.ID = transactionalID
+	config.Net.MaxOpenRequests = 1
+
+	return newProducer(brokers, config)
+}
+
+func newProducerConfig() *sarama.Config {
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 5
+	config.Producer.Partitioner = sarama.NewHashPartitioner
+	return config
+}
+
+func newProducer(brokers []string, config *sarama.Config) (*Producer, error) {
+	factory := func() (sarama.SyncProducer, error) {
+		return sarama.NewSyncProducer(brokers, config)
+	}
+
+	producer, err := factory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create producer: %w", err)
+	}
+
+	return &Producer{
+		producer:      producer,
+		newProducer:   factory,
+		transactional: producer.IsTransactional(),
+		formats:       make(map[string]Format),
+	}, nil
+}
+
+// SetFormat задаёт формат сообщений топика. Вызывается до начала публикации
+func (p *Producer) SetFormat(topic string, format Format) {
+	p.formats[topic] = format
+}
+
+func (p *Producer) IsTransactional() bool {
+	return p.transactional
+}
+
+// PublishEvent отправляет одно событие. Внутри RunInTransaction событие попадает
+// в открытую транзакцию, иначе транзакционный продюсер открывает отдельную
+func (p *Producer) PublishEvent(ctx context.Context, topic string, event Event) error {
+	msg, err := p.newProducerMessage(topic, event)
+	if err != nil {
+		return err
+	}
+
+	if !p.IsTransactional() || p.inTransaction(ctx) {
+		return p.send(msg)
+	}
+
+	return p.RunInTransaction(ctx, func(ctx context.Context, tx *Txn) error {
+		return p.send(msg)
+	})
+}
+
+// PublishBatch отправляет события пачкой. Транзакционный продюсер публикует
+// их одной транзакцией: консьюмеры с read_committed увидят все события или ни одного
+func (p *Producer) PublishBatch(ctx context.Context, events []TopicEvent) error {
+	msgs := make([]*sarama.ProducerMessage, 0, len(events))
+	for _, event := range events {
+		msg, err := p.newProducerMessage(event.Topic, event.Event)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if !p.IsTransactional() || p.inTransaction(ctx) {
+		return p.sendBatch(msgs)
+	}
+
+	return p.RunInTransaction(ctx, func(ctx context.Context, tx *Txn) error {
+		return p.sendBatch(msgs)
+	})
+}
+
+// RunInTransaction выполняет fn в транзакции Kafka. События, отправленные этим продюсером
+// с переданным ctx, и offset'ы из tx.AddOffset фиксируются атомарно; ошибка fn откатывает всё
+func (p *Producer) RunInTransaction(ctx context.Context, fn func(ctx context.Context, tx *Txn) error) error {
+	if !p.IsTransactional() {
+		return errors.New("producer is not transactional")
+	}
+
+	p.txMu.Lock()
+	defer p.txMu.Unlock()
+
+	if err := p.recoverFenced(); err != nil {
+		return err
+	}
+
+	if err := p.producer.BeginTxn(); err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	tx := &Txn{producer: p}
+	if err := fn(contextWithTxn(ctx, tx), tx); err != nil {
+		if abortErr := p.producer.AbortTxn(); abortErr != nil {
+			log.Printf("Failed to abort transaction: %v", abortErr)
+		}
+		return err
+	}
+
+	if err := p.producer.CommitTxn(); err != nil {
+		if abortErr := p.producer.AbortTxn(); abortErr != nil {
+			log.Printf("Failed to abort transaction: %v", abortErr)
+		}
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// recoverFenced пересоздаёт продюсер после фатальной ошибки транзакции (ProducerFenced,
+// истёкшая транзакция): sarama больше не выполняет на нём транзакций. Новый экземпляр
+// с тем же transactional.id получает у брокера следующую эпоху. Вызывается под txMu
+func (p *Producer) recoverFenced() error {
+	if p.producer.TxnStatus()&sarama.ProducerTxnFlagFatalError == 0 {
+		return nil
+	}
+
+	log.Printf("Transactional producer is in fatal state, recreating it")
+
+	producer, err := p.newProducer()
+	if err != nil {
+		return fmt.Errorf("failed to recreate fenced producer: %w", err)
+	}
+
+	if err := p.producer.Close(); err != nil {
+		log.Printf("Failed to close fenced producer: %v", err)
+	}
+	p.producer = producer
+	return nil
+}
+
+func (p *Producer) inTransaction(ctx context.Context) bool {
+	tx := TxnFromContext(ctx)
+	return tx != nil && tx.producer == p
+}
+
+func (p *Producer) Close() error {
+	p.txMu.Lock()
+	defer p.txMu.Unlock()
+
+	return p.producer.Close()
+}
+
+func (p *Producer) send(msg *sarama.ProducerMessage) error {
+	partition, offset, err := p.producer.SendMessage(msg)
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	log.Printf("Event published to topic %s, partition %d, offset %d", msg.Topic, partition, offset)
+	return nil
+}
+
+func (p *Producer) sendBatch(msgs []*sarama.ProducerMessage) error {
+	if err := p.producer.SendMessages(msgs); err != nil {
+		return fmt.Errorf("failed to send messages: %w", err)
+	}
+
+	log.Printf("Published batch of %d events", len(msgs))
+	return nil
+}
+
+func (p *Producer) newProducerMessage(topic string, event Event) (*sarama.ProducerMessage, error) {
+	format, ok := p.formats[topic]
+	if !ok {
+		format = FormatLegacy
+	}
+
+	value, headers, err := encodeEvent(topic, event, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	return &sarama.ProducerMessage{
+		Topic:   topic,
+		Value:   sarama.ByteEncoder(value),
+		Key:     sarama.StringEncoder(event.Key()),
+		Headers: headers,
+	}, nil
+}
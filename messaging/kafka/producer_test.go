@@ -0,0 +1,60 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fencedProducer — продюсер, которого брокер отсёк: транзакции на нём больше не выполняются
+type fencedProducer struct {
+	sarama.SyncProducer
+	closed bool
+}
+
+func (p *fencedProducer) TxnStatus() sarama.ProducerTxnStatusFlag {
+	return sarama.ProducerTxnFlagInError | sarama.ProducerTxnFlagFatalError
+}
+
+func (p *fencedProducer) BeginTxn() error {
+	return sarama.ErrProducerFenced
+}
+
+func (p *fencedProducer) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestProducer_RunInTransaction_RecreatesFencedProducer(t *testing.T) {
+	config := newProducerConfig()
+	config.Version = sarama.V2_1_0_0
+	config.Producer.Idempotent = true
+	config.Producer.Transaction.ID = "orders-service-orders-service-0-outbox"
+	config.Net.MaxOpenRequests = 1
+
+	fresh := mocks.NewSyncProducer(t, config)
+	fresh.ExpectSendMessageAndSucceed()
+
+	fenced := &fencedProducer{}
+	producer := &Producer{
+		producer:      fenced,
+		transactional: true,
+		formats:       make(map[string]Format),
+		newProducer: func() (sarama.SyncProducer, error) {
+			return fresh, nil
+		},
+	}
+
+	err := producer.RunInTransaction(context.Background(), func(ctx context.Context, tx *Txn) error {
+		return producer.send(&sarama.ProducerMessage{Topic: "orders-events", Value: sarama.StringEncoder("{}")})
+	})
+	require.NoError(t, err)
+
+	assert.True(t, fenced.closed)
+	assert.Same(t, sarama.SyncProducer(fresh), producer.producer)
+	assert.NoError(t, producer.Close())
+}
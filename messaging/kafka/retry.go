@@ -0,0 +1,95 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ConsumerRetry — повторы обработчика внутри консьюмера, пока offset сообщения не зафиксирован
+type ConsumerRetry struct {
+	// Attempts — число попыток подряд, после которого чтение партиции приостанавливается
+	Attempts int
+	// Backoff возвращает задержку после attempt неудачных попыток; nil — без задержки
+	Backoff func(attempt int) time.Duration
+	// Pause — на сколько приостановить партицию, когда попытки исчерпаны
+	Pause time.Duration
+}
+
+var defaultConsumerRetry = ConsumerRetry{
+	Attempts: 1,
+	Pause:    30 * time.Second,
+}
+
+func (r ConsumerRetry) delay(attempt int) time.Duration {
+	if r.Backoff == nil {
+		return 0
+	}
+	return r.Backoff(attempt)
+}
+
+type partitionGroup interface {
+	Pause(partitions map[string][]int32)
+	Resume(partitions map[string][]int32)
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+// partitionPauser приостанавливает чтение партиции, пока в ней есть хотя бы одно
+// сообщение, исчерпавшее попытки: при нескольких ключах партиция возобновляется
+// только после последнего из них
+type partitionPauser struct {
+	group partitionGroup
+
+	mu     sync.Mutex
+	paused map[topicPartition]int
+}
+
+func newPartitionPauser(group partitionGroup) *partitionPauser {
+	return &partitionPauser{
+		group:  group,
+		paused: make(map[topicPartition]int),
+	}
+}
+
+func (p *partitionPauser) Pause(topic string, partition int32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := topicPartition{topic, partition}
+	if p.paused[key] == 0 {
+		p.group.Pause(map[string][]int32{topic: {partition}})
+	}
+	p.paused[key]++
+}
+
+func (p *partitionPauser) Resume(topic string, partition int32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := topicPartition{topic, partition}
+	p.paused[key]--
+	if p.paused[key] <= 0 {
+		delete(p.paused, key)
+		p.group.Resume(map[string][]int32{topic: {partition}})
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,151 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePartitionGroup struct {
+	mu      sync.Mutex
+	paused  []map[string][]int32
+	resumed []map[string][]int32
+}
+
+func (g *fakePartitionGroup) Pause(partitions map[string][]int32) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.paused = append(g.paused, partitions)
+}
+
+func (g *fakePartitionGroup) Resume(partitions map[string][]int32) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.resumed = append(g.resumed, partitions)
+}
+
+type fakePoisonStore struct {
+	messages []PoisonMessage
+	err      error
+}
+
+func (s *fakePoisonStore) Quarantine(ctx context.Context, message PoisonMessage) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+func TestConsumerGroupHandler_ProcessRetriesWithBackoff(t *testing.T) {
+	group := &fakePartitionGroup{}
+	var delays []int
+	handler := &ConsumerGroupHandler{
+		retry: ConsumerRetry{
+			Attempts: 3,
+			Backoff: func(attempt int) time.Duration {
+				delays = append(delays, attempt)
+				return time.Millisecond
+			},
+			Pause: time.Minute,
+		},
+		pauser: newPartitionPauser(group),
+	}
+
+	calls := 0
+	err := handler.process(context.Background(), &sarama.ConsumerMessage{Topic: "payments-events", Partition: 2}, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("database is down")
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []int{1, 2}, delays)
+	assert.Empty(t, group.paused)
+}
+
+func TestConsumerGroupHandler_ProcessPausesPartition(t *testing.T) {
+	group := &fakePartitionGroup{}
+	handler := &ConsumerGroupHandler{
+		retry:  ConsumerRetry{Attempts: 2, Pause: 10 * time.Millisecond},
+		pauser: newPartitionPauser(group),
+	}
+
+	calls := 0
+	err := handler.process(context.Background(), &sarama.ConsumerMessage{Topic: "payments-events", Partition: 2}, func() error {
+		calls++
+		if calls <= 2 {
+			return errors.New("database is down")
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []map[string][]int32{{"payments-events": {2}}}, group.paused)
+	assert.Equal(t, []map[string][]int32{{"payments-events": {2}}}, group.resumed)
+}
+
+func TestConsumerGroupHandler_ProcessStopsWithSession(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := &ConsumerGroupHandler{
+		retry:  ConsumerRetry{Attempts: 1, Pause: time.Minute},
+		pauser: newPartitionPauser(&fakePartitionGroup{}),
+	}
+
+	handlerErr := errors.New("database is down")
+	err := handler.process(ctx, &sarama.ConsumerMessage{}, func() error {
+		cancel()
+		return handlerErr
+	})
+
+	assert.ErrorIs(t, err, handlerErr)
+}
+
+func TestPartitionPauser_ResumesAfterLastKey(t *testing.T) {
+	group := &fakePartitionGroup{}
+	pauser := newPartitionPauser(group)
+
+	pauser.Pause("orders-events", 0)
+	pauser.Pause("orders-events", 0)
+	pauser.Resume("orders-events", 0)
+	assert.Len(t, group.paused, 1)
+	assert.Empty(t, group.resumed)
+
+	pauser.Resume("orders-events", 0)
+	assert.Len(t, group.resumed, 1)
+}
+
+func TestConsumerGroupHandler_QuarantinesUndecodableMessage(t *testing.T) {
+	store := &fakePoisonStore{}
+	handler := &ConsumerGroupHandler{poisonStore: store}
+
+	message := &sarama.ConsumerMessage{
+		Topic:     "payments-events",
+		Partition: 1,
+		Offset:    42,
+		Key:       []byte("order-1"),
+		Value:     []byte("{not json"),
+		Headers:   []*sarama.RecordHeader{{Key: []byte("source"), Value: []byte("payments-service")}},
+	}
+
+	assert.NoError(t, handler.handleMessage(context.Background(), message))
+	assert.Len(t, store.messages, 1)
+	assert.Equal(t, "payments-events", store.messages[0].Topic)
+	assert.Equal(t, int32(1), store.messages[0].Partition)
+	assert.Equal(t, int64(42), store.messages[0].Offset)
+	assert.Equal(t, []byte("{not json"), store.messages[0].Value)
+	assert.Equal(t, "payments-service", store.messages[0].Headers["source"])
+	assert.NotEmpty(t, store.messages[0].Error)
+
+	store.err = errors.New("database is down")
+	assert.Error(t, handler.handleMessage(context.Background(), message))
+}
@@ -0,0 +1,20 @@
+package kafka
+
+import "context"
+
+// Txn — открытая транзакция продюсера, см. Producer.RunInTransaction
+type Txn struct {
+	producer *Producer
+}
+
+type txnContextKey struct{}
+
+func contextWithTxn(ctx context.Context, tx *Txn) context.Context {
+	return context.WithValue(ctx, txnContextKey{}, tx)
+}
+
+// TxnFromContext возвращает транзакцию, в которой выполняется fn из RunInTransaction, или nil
+func TxnFromContext(ctx context.Context) *Txn {
+	tx, _ := ctx.Value(txnContextKey{}).(*Txn)
+	return tx
+}
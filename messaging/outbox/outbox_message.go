@@ -0,0 +1,106 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"contracts/events"
+	"messaging/correlation"
+)
+
+type OutboxMessageStatus string
+
+const (
+	OutboxMessageStatusPending    OutboxMessageStatus = "pending"
+	OutboxMessageStatusProcessing OutboxMessageStatus = "processing"
+	OutboxMessageStatusSent       OutboxMessageStatus = "sent"
+	OutboxMessageStatusFailed     OutboxMessageStatus = "failed"
+	OutboxMessageStatusDeadLetter OutboxMessageStatus = "dead_letter"
+)
+
+// OutboxMessage.PartitionKey — идентификатор агрегата (заказа, пользователя):
+// события одного агрегата попадают в одну партицию Kafka и читаются по порядку
+type OutboxMessage struct {
+	ID           string
+	EventType    string
+	PartitionKey string
+	Payload      json.RawMessage
+	Status       OutboxMessageStatus
+	SentAt       *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	RetryCount   int
+	MaxRetries   int
+	// Идентификаторы цепочки событий, уходят в заголовки сообщения Kafka
+	CorrelationID string
+	CausationID   string
+	Traceparent   string
+	// SchemaVersion — версия контракта события из contracts/events, по которой собран Payload
+	SchemaVersion int
+}
+
+func NewOutboxMessage(eventType, partitionKey string, payload json.RawMessage) (*OutboxMessage, error) {
+	v7, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxMessage{
+		ID:            v7.String(),
+		EventType:     eventType,
+		PartitionKey:  partitionKey,
+		Payload:       payload,
+		Status:        OutboxMessageStatusPending,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+		RetryCount:    0,
+		MaxRetries:    3,
+		SchemaVersion: events.LatestVersion(eventType),
+	}, nil
+}
+
+// Correlate привязывает сообщение к цепочке событий, в которой оно возникло.
+// Сообщение вне цепочки начинает свою: correlation_id равен id сообщения
+func (m *OutboxMessage) Correlate(metadata correlation.Metadata) {
+	m.CorrelationID = metadata.CorrelationID
+	if m.CorrelationID == "" {
+		m.CorrelationID = m.ID
+	}
+	m.CausationID = metadata.EventID
+	m.Traceparent = metadata.Traceparent
+}
+
+func (m *OutboxMessage) MarkAsSent() {
+	now := time.Now()
+	m.Status = OutboxMessageStatusSent
+	m.SentAt = &now
+	m.UpdatedAt = now
+}
+
+func (m *OutboxMessage) MarkAsFailed() {
+	m.Status = OutboxMessageStatusFailed
+	m.RetryCount++
+	m.UpdatedAt = time.Now()
+}
+
+func (m *OutboxMessage) CanRetry() bool {
+	return m.RetryCount < m.MaxRetries
+}
+
+func (m *OutboxMessage) IsSent() bool {
+	return m.Status == OutboxMessageStatusSent
+}
+
+func (m *OutboxMessage) IsFailed() bool {
+	return m.Status == OutboxMessageStatusFailed
+}
+
+func (m *OutboxMessage) IsDeadLetter() bool {
+	return m.Status == OutboxMessageStatusDeadLetter
+}
+
+func (m *OutboxMessage) IsPending() bool {
+	return m.Status == OutboxMessageStatusPending
+}
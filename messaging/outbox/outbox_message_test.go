@@ -6,28 +6,32 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"messaging/correlation"
 )
 
 func TestNewOutboxMessage(t *testing.T) {
 	eventType := "order.created"
 	payload := json.RawMessage(`{"order_id": "123"}`)
-	msg, err := NewOutboxMessage(eventType, payload)
+	msg, err := NewOutboxMessage(eventType, "order-123", payload)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, msg)
 	assert.NotEmpty(t, msg.ID)
 	assert.Equal(t, eventType, msg.EventType)
+	assert.Equal(t, "order-123", msg.PartitionKey)
 	assert.Equal(t, payload, msg.Payload)
 	assert.True(t, msg.IsPending())
 	assert.Equal(t, 0, msg.RetryCount)
 	assert.Equal(t, 3, msg.MaxRetries)
+	assert.Equal(t, 1, msg.SchemaVersion)
 	assert.WithinDuration(t, time.Now(), msg.CreatedAt, time.Second)
 	assert.WithinDuration(t, time.Now(), msg.UpdatedAt, time.Second)
 	assert.Nil(t, msg.SentAt)
 }
 
 func TestOutboxMessage_StateTransitions(t *testing.T) {
-	msg, _ := NewOutboxMessage("order.updated", json.RawMessage(`{}`))
+	msg, _ := NewOutboxMessage("order.updated", "order-123", json.RawMessage(`{}`))
 
 	msg.MarkAsSent()
 	assert.True(t, msg.IsSent())
@@ -38,7 +42,7 @@ func TestOutboxMessage_StateTransitions(t *testing.T) {
 }
 
 func TestOutboxMessage_Retries(t *testing.T) {
-	msg, _ := NewOutboxMessage("order.failed", json.RawMessage(`{}`))
+	msg, _ := NewOutboxMessage("order.failed", "order-123", json.RawMessage(`{}`))
 	msg.MaxRetries = 2
 
 	assert.True(t, msg.CanRetry())
@@ -52,3 +56,21 @@ func TestOutboxMessage_Retries(t *testing.T) {
 	assert.Equal(t, 2, msg.RetryCount)
 	assert.False(t, msg.CanRetry())
 }
+
+func TestOutboxMessage_Correlate(t *testing.T) {
+	msg, _ := NewOutboxMessage("order.updated", "order-123", json.RawMessage(`{}`))
+	msg.Correlate(correlation.Metadata{})
+	assert.Equal(t, msg.ID, msg.CorrelationID)
+	assert.Empty(t, msg.CausationID)
+
+	msg, _ = NewOutboxMessage("order.updated", "order-123", json.RawMessage(`{}`))
+	msg.Correlate(correlation.Metadata{
+		CorrelationID: "corr-1",
+		CausationID:   "event-0",
+		EventID:       "event-1",
+		Traceparent:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	})
+	assert.Equal(t, "corr-1", msg.CorrelationID)
+	assert.Equal(t, "event-1", msg.CausationID)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", msg.Traceparent)
+}
@@ -0,0 +1,31 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrLeaseLost — аренда сообщения истекла, и его могла захватить другая реплика
+var ErrLeaseLost = errors.New("outbox message lease lost")
+
+// Repository хранит outbox сервиса. StoreWithTx сохраняет сообщение в транзакции
+// бизнес-изменения: событие уходит в брокер, только если изменение зафиксировано
+type Repository interface {
+	Store(ctx context.Context, message *OutboxMessage) error
+	StoreWithTx(ctx context.Context, tx *sql.Tx, message *OutboxMessage) error
+	ClaimPendingMessages(ctx context.Context, limit int, lease time.Duration) ([]*OutboxMessage, error)
+	ClaimFailedMessages(ctx context.Context, limit int, lease time.Duration) ([]*OutboxMessage, error)
+	// MarkAsSent, MarkAsFailed и MarkAsDeadLetter меняют только захваченное сообщение
+	// с действующей арендой, иначе возвращают ErrLeaseLost
+	MarkAsSent(ctx context.Context, id string) error
+	MarkAsFailed(ctx context.Context, id string, reason string, nextAttemptAt time.Time) error
+	MarkAsDeadLetter(ctx context.Context, id, reason string) error
+}
+
+// Notifier сигналит, что в outbox появились сообщения к отправке
+type Notifier interface {
+	Notifications() <-chan struct{}
+	Close() error
+}
@@ -0,0 +1,40 @@
+package poison
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Message — сообщение Kafka, которое консьюмер не смог разобрать. Хранится байт в байт
+// вместе с координатами в топике, чтобы его можно было изучить и отправить заново
+type Message struct {
+	ID          string
+	Topic       string
+	Partition   int32
+	Offset      int64
+	Key         []byte
+	Value       []byte
+	Headers     map[string]string
+	Error       string
+	PublishedAt time.Time
+	CreatedAt   time.Time
+}
+
+func NewMessage(topic string, partition int32, offset int64, value []byte, reason string) (*Message, error) {
+	v7, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		ID:        v7.String(),
+		Topic:     topic,
+		Partition: partition,
+		Offset:    offset,
+		Value:     value,
+		Headers:   map[string]string{},
+		Error:     reason,
+		CreatedAt: time.Now(),
+	}, nil
+}
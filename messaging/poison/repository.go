@@ -0,0 +1,8 @@
+package poison
+
+import "context"
+
+type Repository interface {
+	// Store сохраняет сообщение; повторное сохранение того же offset ничего не меняет
+	Store(ctx context.Context, message *Message) error
+}
@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"messaging/deadletter"
+)
+
+type DeadLetterRepository struct {
+	db *sql.DB
+}
+
+func NewDeadLetterRepository(db *sql.DB) deadletter.Repository {
+	return &DeadLetterRepository{db: db}
+}
+
+// deadLetterTable возвращает таблицу источника и выражение для event_id (в outbox его нет)
+func deadLetterTable(source deadletter.Source) (string, string, error) {
+	switch source {
+	case deadletter.SourceOutbox:
+		return "outbox_messages", "''", nil
+	case deadletter.SourceInbox:
+		return "inbox_messages", "event_id", nil
+	default:
+		return "", "", deadletter.ErrInvalidSource
+	}
+}
+
+func (r *DeadLetterRepository) List(ctx context.Context, source deadletter.Source, limit int) ([]*deadletter.DeadLetter, error) {
+	table, eventID, err := deadLetterTable(source)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, %s, event_type, payload, COALESCE(last_error, ''), attempts, retry_count, created_at, updated_at, dead_lettered_at
+		FROM %s
+		WHERE status = 'dead_letter'
+		ORDER BY dead_lettered_at DESC
+		LIMIT $1`, eventID, table)
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	deadLetters := make([]*deadletter.DeadLetter, 0)
+	for rows.Next() {
+		deadLetter, err := scanDeadLetter(rows, source)
+		if err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+
+	return deadLetters, rows.Err()
+}
+
+func (r *DeadLetterRepository) Get(ctx context.Context, source deadletter.Source, id string) (*deadletter.DeadLetter, error) {
+	table, eventID, err := deadLetterTable(source)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, %s, event_type, payload, COALESCE(last_error, ''), attempts, retry_count, created_at, updated_at, dead_lettered_at
+		FROM %s
+		WHERE id = $1 AND status = 'dead_letter'`, eventID, table)
+
+	deadLetter, err := scanDeadLetter(r.db.QueryRowContext(ctx, query, id), source)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, deadletter.ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return deadLetter, nil
+}
+
+func (r *DeadLetterRepository) UpdatePayload(ctx context.Context, source deadletter.Source, id string, payload json.RawMessage) error {
+	table, _, err := deadLetterTable(source)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET payload = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'dead_letter'`, table)
+
+	result, err := r.db.ExecContext(ctx, query, id, payload)
+	if err != nil {
+		return fmt.Errorf("failed to update dead letter payload: %w", err)
+	}
+
+	return checkDeadLetterAffected(result)
+}
+
+// Requeue возвращает сообщение в pending с чистым счётчиком попыток; история попыток сохраняется
+func (r *DeadLetterRepository) Requeue(ctx context.Context, source deadletter.Source, id string) error {
+	table, _, err := deadLetterTable(source)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET status = 'pending', retry_count = 0, next_attempt_at = NULL, locked_until = NULL,
+		    dead_lettered_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'dead_letter'`, table)
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to requeue dead letter: %w", err)
+	}
+
+	return checkDeadLetterAffected(result)
+}
+
+func checkDeadLetterAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return deadletter.ErrDeadLetterNotFound
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDeadLetter(row rowScanner, source deadletter.Source) (*deadletter.DeadLetter, error) {
+	deadLetter := &deadletter.DeadLetter{Source: source}
+	var attempts []byte
+
+	err := row.Scan(
+		&deadLetter.ID,
+		&deadLetter.EventID,
+		&deadLetter.EventType,
+		&deadLetter.Payload,
+		&deadLetter.LastError,
+		&attempts,
+		&deadLetter.RetryCount,
+		&deadLetter.CreatedAt,
+		&deadLetter.UpdatedAt,
+		&deadLetter.DeadLetteredAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+	}
+
+	if err := json.Unmarshal(attempts, &deadLetter.Attempts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dead letter attempts: %w", err)
+	}
+
+	return deadLetter, nil
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"messaging/deadletter"
+)
+
+var deadLetterColumns = []string{"id", "event_id", "event_type", "payload", "last_error", "attempts", "retry_count", "created_at", "updated_at", "dead_lettered_at"}
+
+func TestDeadLetterRepository_List(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewDeadLetterRepository(db)
+	now := time.Now()
+
+	// В outbox нет event_id, поэтому запрос подставляет пустую строку
+	mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, '', event_type") + ".*FROM outbox_messages").
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows(deadLetterColumns).
+			AddRow("message-1", "", "order.updated", []byte(`{"order_id":"order-1"}`), "no route", []byte(`[{"at":"2026-01-01T00:00:00Z","error":"no route"}]`), 3, now, now, now))
+
+	deadLetters, err := repo.List(context.Background(), deadletter.SourceOutbox, 10)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, deadletter.SourceOutbox, deadLetters[0].Source)
+	assert.Equal(t, "order.updated", deadLetters[0].EventType)
+	require.Len(t, deadLetters[0].Attempts, 1)
+	assert.Equal(t, "no route", deadLetters[0].Attempts[0].Error)
+
+	_, err = repo.List(context.Background(), deadletter.Source("orders"), 10)
+	assert.ErrorIs(t, err, deadletter.ErrInvalidSource)
+
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestDeadLetterRepository_GetNotFound(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewDeadLetterRepository(db)
+
+	mockSQL.ExpectQuery(regexp.QuoteMeta("SELECT id, event_id, event_type") + ".*FROM inbox_messages").
+		WithArgs("message-1").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.Get(context.Background(), deadletter.SourceInbox, "message-1")
+	assert.ErrorIs(t, err, deadletter.ErrDeadLetterNotFound)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestDeadLetterRepository_UpdatePayloadAndRequeue(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewDeadLetterRepository(db)
+	ctx := context.Background()
+	payload := json.RawMessage(`{"order_id":"order-1"}`)
+
+	mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE inbox_messages")).
+		WithArgs("message-1", payload).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.UpdatePayload(ctx, deadletter.SourceInbox, "message-1", payload))
+
+	mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE outbox_messages") + ".*SET status = 'pending', retry_count = 0").
+		WithArgs("message-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Requeue(ctx, deadletter.SourceOutbox, "message-1"))
+
+	// Сообщение уже вернули в очередь: повторный requeue его не находит
+	mockSQL.ExpectExec(regexp.QuoteMeta("UPDATE outbox_messages")).
+		WithArgs("message-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.Requeue(ctx, deadletter.SourceOutbox, "message-1"), deadletter.ErrDeadLetterNotFound)
+
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
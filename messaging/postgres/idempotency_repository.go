@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"messaging/idempotency"
+)
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) idempotency.Repository {
+	return &IdempotencyRepository{db: db}
+}
+
+func (r *IdempotencyRepository) Reserve(ctx context.Context, record *idempotency.Record, expiredBefore time.Time) (bool, error) {
+	// Просроченная запись (в том числе зависшая после падения) перезанимается
+	query := `
+		INSERT INTO idempotency_keys (scope, idempotency_key, request_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (scope, idempotency_key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+		    status_code = NULL,
+		    content_type = NULL,
+		    response_body = NULL,
+		    created_at = EXCLUDED.created_at,
+		    completed_at = NULL
+		WHERE idempotency_keys.created_at < $5
+	`
+
+	result, err := r.db.ExecContext(ctx, query, record.Scope, record.Key, record.RequestHash, record.CreatedAt, expiredBefore)
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return affected == 1, nil
+}
+
+func (r *IdempotencyRepository) Get(ctx context.Context, scope, key string) (*idempotency.Record, error) {
+	query := `
+		SELECT scope, idempotency_key, request_hash, status_code, content_type, response_body, created_at, completed_at
+		FROM idempotency_keys
+		WHERE scope = $1 AND idempotency_key = $2
+	`
+
+	var record idempotency.Record
+	var statusCode sql.NullInt64
+	var contentType sql.NullString
+	var completedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, query, scope, key).Scan(
+		&record.Scope,
+		&record.Key,
+		&record.RequestHash,
+		&statusCode,
+		&contentType,
+		&record.ResponseBody,
+		&record.CreatedAt,
+		&completedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, idempotency.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+
+	record.StatusCode = int(statusCode.Int64)
+	record.ContentType = contentType.String
+	if completedAt.Valid {
+		record.CompletedAt = &completedAt.Time
+	}
+
+	return &record, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, record *idempotency.Record) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, content_type = $2, response_body = $3, completed_at = $4
+		WHERE scope = $5 AND idempotency_key = $6 AND request_hash = $7
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		record.StatusCode,
+		record.ContentType,
+		record.ResponseBody,
+		record.CompletedAt,
+		record.Scope,
+		record.Key,
+		record.RequestHash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency record: %w", err)
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepository) Delete(ctx context.Context, scope, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE scope = $1 AND idempotency_key = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, scope, key); err != nil {
+		return fmt.Errorf("failed to delete idempotency record: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,290 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"messaging/inbox"
+)
+
+type InboxRepository struct {
+	db *sql.DB
+}
+
+func NewInboxRepository(db *sql.DB) inbox.Repository {
+	return &InboxRepository{db: db}
+}
+
+const insertInboxMessageQuery = `
+	INSERT INTO inbox_messages (id, event_id, event_type, partition_key, payload, status, processed_at, created_at, updated_at, retry_count,
+	                            max_retries, correlation_id, causation_id, traceparent, source, schema_version)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	ON CONFLICT (event_id) DO NOTHING`
+
+// Store сохраняет сообщение одной командой: дубликат события, полученный повторно
+// или параллельно другой репликой, не вставляется, и Store возвращает false
+func (r *InboxRepository) Store(ctx context.Context, message *inbox.InboxMessage) (bool, error) {
+	result, err := r.db.ExecContext(ctx, insertInboxMessageQuery, inboxMessageArgs(message)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to store inbox message: %w", err)
+	}
+
+	return inserted(result)
+}
+
+func (r *InboxRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, message *inbox.InboxMessage) (bool, error) {
+	result, err := tx.ExecContext(ctx, insertInboxMessageQuery, inboxMessageArgs(message)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to store inbox message with tx: %w", err)
+	}
+
+	return inserted(result)
+}
+
+func inboxMessageArgs(message *inbox.InboxMessage) []any {
+	return []any{
+		message.ID, message.EventID, message.EventType, message.PartitionKey, message.Payload, message.Status,
+		message.ProcessedAt, message.CreatedAt, message.UpdatedAt, message.RetryCount, message.MaxRetries,
+		message.CorrelationID, message.CausationID, message.Traceparent, message.Source, message.SchemaVersion,
+	}
+}
+
+func inserted(result sql.Result) (bool, error) {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *InboxRepository) GetByEventID(ctx context.Context, eventID string) (*inbox.InboxMessage, error) {
+	query := `
+		SELECT id, event_id, event_type, partition_key, payload, status, processed_at, created_at, updated_at, retry_count, max_retries,
+		       correlation_id, causation_id, traceparent, source, schema_version
+		FROM inbox_messages
+		WHERE event_id = $1`
+
+	row := r.db.QueryRowContext(ctx, query, eventID)
+
+	message := &inbox.InboxMessage{}
+	err := row.Scan(&message.ID, &message.EventID, &message.EventType, &message.PartitionKey, &message.Payload, &message.Status,
+		&message.ProcessedAt, &message.CreatedAt, &message.UpdatedAt, &message.RetryCount, &message.MaxRetries,
+		&message.CorrelationID, &message.CausationID, &message.Traceparent, &message.Source, &message.SchemaVersion)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("inbox message not found for event: %s", eventID)
+		}
+		return nil, fmt.Errorf("failed to get inbox message by event ID: %w", err)
+	}
+
+	return message, nil
+}
+
+// notBehindEarlierInboxMessage пропускает сообщение, пока более раннее сообщение с тем же
+// partition_key ещё не обработано: иначе повтор упавшего события или другая реплика
+// обработали бы более позднее событие агрегата раньше него
+const notBehindEarlierInboxMessage = `(message.partition_key = '' OR NOT EXISTS (
+					SELECT 1
+					FROM inbox_messages earlier
+					WHERE earlier.partition_key = message.partition_key
+					  AND earlier.status IN ('pending', 'processing', 'failed')
+					  AND (earlier.created_at, earlier.id) < (message.created_at, message.id)
+				))`
+
+// ClaimPendingMessages захватывает пачку новых сообщений и сообщений с истёкшей арендой,
+// чтобы одно событие обрабатывала только одна реплика
+func (r *InboxRepository) ClaimPendingMessages(ctx context.Context, limit int, lease time.Duration) ([]*inbox.InboxMessage, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE inbox_messages
+			SET status = 'processing', locked_until = NOW() + $2 * INTERVAL '1 millisecond', updated_at = NOW()
+			WHERE id IN (
+				SELECT id
+				FROM inbox_messages message
+				WHERE (status = 'pending' OR (status = 'processing' AND locked_until < NOW()))
+				  AND ` + notBehindEarlierInboxMessage + `
+				ORDER BY created_at ASC
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, event_id, event_type, partition_key, payload, status, processed_at, created_at, updated_at, retry_count, max_retries,
+			          correlation_id, causation_id, traceparent, source, schema_version
+		)
+		SELECT id, event_id, event_type, partition_key, payload, status, processed_at, created_at, updated_at, retry_count, max_retries,
+		       correlation_id, causation_id, traceparent, source, schema_version
+		FROM claimed
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pending inbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanMessages(rows)
+}
+
+func (r *InboxRepository) ClaimFailedMessages(ctx context.Context, limit int, lease time.Duration) ([]*inbox.InboxMessage, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE inbox_messages
+			SET status = 'processing', locked_until = NOW() + $2 * INTERVAL '1 millisecond', updated_at = NOW()
+			WHERE id IN (
+				SELECT id
+				FROM inbox_messages message
+				WHERE status = 'failed' AND next_attempt_at <= NOW()
+				  AND ` + notBehindEarlierInboxMessage + `
+				ORDER BY next_attempt_at ASC
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, event_id, event_type, partition_key, payload, status, processed_at, created_at, updated_at, retry_count, max_retries,
+			          correlation_id, causation_id, traceparent, source, schema_version
+		)
+		SELECT id, event_id, event_type, partition_key, payload, status, processed_at, created_at, updated_at, retry_count, max_retries,
+		       correlation_id, causation_id, traceparent, source, schema_version
+		FROM claimed
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim failed inbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanMessages(rows)
+}
+
+// LockWithTx берёт блокировку строки в транзакции обработчика. Пока она держится,
+// другая реплика не захватит сообщение повторно, а истёкшая аренда означает,
+// что сообщение могли уже отдать другой реплике, и обрабатывать его нельзя
+func (r *InboxRepository) LockWithTx(ctx context.Context, tx *sql.Tx, id string) error {
+	query := `
+		SELECT id
+		FROM inbox_messages
+		WHERE id = $1 AND status = 'processing' AND locked_until > NOW()
+		FOR UPDATE`
+
+	var lockedID string
+	err := tx.QueryRowContext(ctx, query, id).Scan(&lockedID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %s", inbox.ErrLeaseLost, id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock inbox message: %w", err)
+	}
+
+	return nil
+}
+
+// Отметка проходит только для сообщения в processing: если его уже обработала
+// другая реплика, отметка не найдёт строку, и транзакция обработчика откатится
+const markInboxMessageProcessedQuery = `
+	UPDATE inbox_messages
+	SET status = 'processed', processed_at = NOW(), locked_until = NULL, updated_at = NOW()
+	WHERE id = $1 AND status = 'processing'`
+
+func (r *InboxRepository) MarkAsProcessed(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, markInboxMessageProcessedQuery, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark inbox message as processed: %w", err)
+	}
+
+	return requireProcessingInboxMessage(result, id)
+}
+
+// MarkAsProcessedWithTx отмечает сообщение обработанным в транзакции обработчика,
+// чтобы отметка и изменения, сделанные по событию, фиксировались вместе
+func (r *InboxRepository) MarkAsProcessedWithTx(ctx context.Context, tx *sql.Tx, id string) error {
+	result, err := tx.ExecContext(ctx, markInboxMessageProcessedQuery, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark inbox message as processed with tx: %w", err)
+	}
+
+	return requireProcessingInboxMessage(result, id)
+}
+
+func requireProcessingInboxMessage(result sql.Result, id string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", inbox.ErrLeaseLost, id)
+	}
+
+	return nil
+}
+
+// MarkAsFailed записывает попытку в историю и возвращает сообщение в failed;
+// ClaimFailedMessages возьмёт его не раньше nextAttemptAt
+func (r *InboxRepository) MarkAsFailed(ctx context.Context, id string, reason string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE inbox_messages
+		SET status = 'failed', retry_count = retry_count + 1, next_attempt_at = $3, locked_until = NULL,
+		    last_error = $2, attempts = attempts || jsonb_build_array(jsonb_build_object('at', NOW(), 'error', $2::text)),
+		    updated_at = NOW()
+		WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id, reason, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark inbox message as failed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("inbox message not found: %s", id)
+	}
+
+	return nil
+}
+
+// MarkAsDeadLetter убирает сообщение из автоматических повторов, сохраняя причину и историю попыток
+func (r *InboxRepository) MarkAsDeadLetter(ctx context.Context, id string, reason string) error {
+	query := `
+		UPDATE inbox_messages
+		SET status = 'dead_letter', retry_count = retry_count + 1, locked_until = NULL, next_attempt_at = NULL,
+		    last_error = $2, attempts = attempts || jsonb_build_array(jsonb_build_object('at', NOW(), 'error', $2::text)),
+		    dead_lettered_at = NOW(), updated_at = NOW()
+		WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id, reason)
+	if err != nil {
+		return fmt.Errorf("failed to mark inbox message as dead letter: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("inbox message not found: %s", id)
+	}
+
+	return nil
+}
+
+func (r *InboxRepository) scanMessages(rows *sql.Rows) ([]*inbox.InboxMessage, error) {
+	var messages []*inbox.InboxMessage
+	for rows.Next() {
+		message := &inbox.InboxMessage{}
+		err := rows.Scan(&message.ID, &message.EventID, &message.EventType, &message.PartitionKey, &message.Payload, &message.Status,
+			&message.ProcessedAt, &message.CreatedAt, &message.UpdatedAt, &message.RetryCount, &message.MaxRetries,
+			&message.CorrelationID, &message.CausationID, &message.Traceparent, &message.Source, &message.SchemaVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan inbox message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
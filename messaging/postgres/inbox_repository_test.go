@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"messaging/inbox"
+)
+
+func TestInboxRepository_LockWithTx(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewInboxRepository(db)
+	ctx := context.Background()
+	lock := regexp.QuoteMeta("WHERE id = $1 AND status = 'processing' AND locked_until > NOW()") + `\s+FOR UPDATE`
+
+	mockSQL.ExpectBegin()
+	mockSQL.ExpectQuery(lock).WithArgs("inbox-1").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("inbox-1"))
+	// Аренда истекла: сообщение уже могла захватить другая реплика
+	mockSQL.ExpectQuery(lock).WithArgs("inbox-2").WillReturnError(sql.ErrNoRows)
+	mockSQL.ExpectRollback()
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	assert.NoError(t, repo.LockWithTx(ctx, tx, "inbox-1"))
+	assert.ErrorIs(t, repo.LockWithTx(ctx, tx, "inbox-2"), inbox.ErrLeaseLost)
+	assert.NoError(t, tx.Rollback())
+
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestInboxRepository_MarkAsProcessedWithTx_AlreadyProcessed(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewInboxRepository(db)
+
+	// Сообщение уже отметила другая реплика: строка в processing не находится
+	mockSQL.ExpectBegin()
+	mockSQL.ExpectExec(regexp.QuoteMeta("WHERE id = $1 AND status = 'processing'")).
+		WithArgs("inbox-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mockSQL.ExpectRollback()
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.MarkAsProcessedWithTx(context.Background(), tx, "inbox-1"), inbox.ErrLeaseLost)
+	assert.NoError(t, tx.Rollback())
+
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestInboxRepository_StoreKeepsPartitionKey(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewInboxRepository(db)
+
+	message, err := inbox.NewInboxMessage("event-1", "payment.completed", json.RawMessage(`{"order_id":"order-1"}`))
+	require.NoError(t, err)
+	message.PartitionKey = "order-1"
+
+	mockSQL.ExpectExec(regexp.QuoteMeta("INSERT INTO inbox_messages (id, event_id, event_type, partition_key,")).
+		WithArgs(message.ID, "event-1", "payment.completed", "order-1", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	stored, err := repo.Store(context.Background(), message)
+	require.NoError(t, err)
+	assert.True(t, stored)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+// Оба запроса захвата пропускают сообщение, пока более раннее событие его ключа не обработано
+func TestInboxRepository_ClaimKeepsPartitionKeyOrder(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewInboxRepository(db)
+	ctx := context.Background()
+	columns := []string{"id", "event_id", "event_type", "partition_key", "payload", "status", "processed_at", "created_at", "updated_at", "retry_count",
+		"max_retries", "correlation_id", "causation_id", "traceparent", "source", "schema_version"}
+	earlier := `[\s\S]*` + regexp.QuoteMeta("earlier.status IN ('pending', 'processing', 'failed')") +
+		`\s+AND ` + regexp.QuoteMeta("(earlier.created_at, earlier.id) < (message.created_at, message.id)")
+	pending := regexp.QuoteMeta("WHERE (status = 'pending'") + earlier
+	failed := regexp.QuoteMeta("WHERE status = 'failed'") + earlier
+	now := time.Now()
+
+	mockSQL.ExpectQuery(pending).
+		WithArgs(10, int64(30000)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("inbox-1", "event-1", "payment.completed", "order-1", []byte(`{}`), "processing", nil, now, now, 0, 3, "", "", "", "", 1))
+	mockSQL.ExpectQuery(failed).
+		WithArgs(10, int64(30000)).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	messages, err := repo.ClaimPendingMessages(ctx, 10, 30*time.Second)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "order-1", messages[0].PartitionKey)
+
+	_, err = repo.ClaimFailedMessages(ctx, 10, 30*time.Second)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"log"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// MigrationsTable — таблица версий схемы модуля, отдельная от schema_migrations сервиса
+const MigrationsTable = "messaging_schema_migrations"
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// RunMigrations создаёт таблицы outbox, inbox и poison_messages и применяет к ним
+// изменения схемы модуля. Сервис вызывает её после собственных миграций: таблицы, которые
+// создали они, модуль не пересоздаёт, а дополняет до своей схемы (003_adopt_service_tables)
+func RunMigrations(db *sql.DB) error {
+	driver, err := postgres.WithInstance(db, &postgres.Config{MigrationsTable: MigrationsTable})
+	if err != nil {
+		return fmt.Errorf("could not create postgres driver: %w", err)
+	}
+
+	d, err := iofs.New(migrationFiles, "migrations")
+	if err != nil {
+		return fmt.Errorf("could not create iofs source: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", d, "postgres", driver)
+	if err != nil {
+		return fmt.Errorf("could not create migrate instance: %w", err)
+	}
+
+	log.Println("Running messaging migrations...")
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("could not run messaging migrations: %w", err)
+	}
+
+	return nil
+}
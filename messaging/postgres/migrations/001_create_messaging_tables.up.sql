@@ -0,0 +1,132 @@
+-- Outbox, inbox and poison message tables shared by every service that uses the messaging module.
+-- Services that already created these tables with their own migrations keep them untouched:
+-- each table, together with its indexes and triggers, is created only when it does not exist yet.
+CREATE OR REPLACE FUNCTION messaging_set_updated_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+-- Wake up outbox publishers as soon as a pending message appears (new or requeued).
+-- The payload is empty, so notifications of one transaction collapse into one.
+CREATE OR REPLACE FUNCTION notify_outbox_messages()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('outbox_messages', '');
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+DO $$
+BEGIN
+    IF to_regclass('outbox_messages') IS NULL THEN
+        CREATE TABLE outbox_messages
+        (
+            id               UUID PRIMARY KEY,
+            event_type       VARCHAR(50)  NOT NULL,
+            partition_key    VARCHAR(255) NOT NULL DEFAULT '',
+            payload          JSONB        NOT NULL,
+            status           VARCHAR(20)  NOT NULL DEFAULT 'pending'
+                CONSTRAINT outbox_messages_status_check CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'dead_letter')),
+            sent_at          TIMESTAMP WITH TIME ZONE,
+            created_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+            updated_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+            retry_count      INT          NOT NULL DEFAULT 0,
+            max_retries      INT          NOT NULL DEFAULT 3,
+            last_error       TEXT,
+            locked_until     TIMESTAMP WITH TIME ZONE,
+            next_attempt_at  TIMESTAMP WITH TIME ZONE,
+            attempts         JSONB        NOT NULL DEFAULT '[]',
+            dead_lettered_at TIMESTAMP WITH TIME ZONE,
+            correlation_id   VARCHAR(255) NOT NULL DEFAULT '',
+            causation_id     VARCHAR(255) NOT NULL DEFAULT '',
+            traceparent      VARCHAR(255) NOT NULL DEFAULT '',
+            schema_version   INTEGER      NOT NULL DEFAULT 1
+        );
+
+        CREATE INDEX idx_outbox_status ON outbox_messages (status);
+        CREATE INDEX idx_outbox_event_type ON outbox_messages (event_type);
+        CREATE INDEX idx_outbox_created_at ON outbox_messages (created_at);
+        CREATE INDEX idx_outbox_locked_until ON outbox_messages (locked_until) WHERE status = 'processing';
+        CREATE INDEX idx_outbox_next_attempt_at ON outbox_messages (next_attempt_at) WHERE status = 'failed';
+        CREATE INDEX idx_outbox_dead_lettered_at ON outbox_messages (dead_lettered_at) WHERE status = 'dead_letter';
+        CREATE INDEX idx_outbox_correlation_id ON outbox_messages (correlation_id);
+
+        CREATE TRIGGER update_outbox_messages_updated_at
+            BEFORE UPDATE
+            ON outbox_messages
+            FOR EACH ROW
+            EXECUTE FUNCTION messaging_set_updated_at();
+
+        CREATE TRIGGER notify_outbox_messages_pending
+            AFTER INSERT OR UPDATE OF status
+            ON outbox_messages
+            FOR EACH ROW
+            WHEN (NEW.status = 'pending')
+            EXECUTE FUNCTION notify_outbox_messages();
+    END IF;
+
+    IF to_regclass('inbox_messages') IS NULL THEN
+        CREATE TABLE inbox_messages
+        (
+            id               UUID PRIMARY KEY,
+            event_id         VARCHAR(36)  NOT NULL UNIQUE,
+            event_type       VARCHAR(50)  NOT NULL,
+            payload          JSONB        NOT NULL,
+            status           VARCHAR(20)  NOT NULL DEFAULT 'pending'
+                CONSTRAINT inbox_messages_status_check CHECK (status IN ('pending', 'processing', 'processed', 'failed', 'dead_letter')),
+            processed_at     TIMESTAMP WITH TIME ZONE,
+            created_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+            updated_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+            retry_count      INT          NOT NULL DEFAULT 0,
+            max_retries      INT          NOT NULL DEFAULT 3,
+            last_error       TEXT,
+            locked_until     TIMESTAMP WITH TIME ZONE,
+            next_attempt_at  TIMESTAMP WITH TIME ZONE,
+            attempts         JSONB        NOT NULL DEFAULT '[]',
+            dead_lettered_at TIMESTAMP WITH TIME ZONE,
+            correlation_id   VARCHAR(255) NOT NULL DEFAULT '',
+            causation_id     VARCHAR(255) NOT NULL DEFAULT '',
+            traceparent      VARCHAR(255) NOT NULL DEFAULT '',
+            source           VARCHAR(255) NOT NULL DEFAULT '',
+            schema_version   INTEGER      NOT NULL DEFAULT 0
+        );
+
+        CREATE INDEX idx_inbox_status ON inbox_messages (status);
+        CREATE INDEX idx_inbox_event_type ON inbox_messages (event_type);
+        CREATE INDEX idx_inbox_created_at ON inbox_messages (created_at);
+        CREATE INDEX idx_inbox_locked_until ON inbox_messages (locked_until) WHERE status = 'processing';
+        CREATE INDEX idx_inbox_next_attempt_at ON inbox_messages (next_attempt_at) WHERE status = 'failed';
+        CREATE INDEX idx_inbox_dead_lettered_at ON inbox_messages (dead_lettered_at) WHERE status = 'dead_letter';
+        CREATE INDEX idx_inbox_correlation_id ON inbox_messages (correlation_id);
+
+        CREATE TRIGGER update_inbox_messages_updated_at
+            BEFORE UPDATE
+            ON inbox_messages
+            FOR EACH ROW
+            EXECUTE FUNCTION messaging_set_updated_at();
+    END IF;
+
+    -- Kafka messages the consumer could not decode, kept byte for byte with their position in the topic
+    IF to_regclass('poison_messages') IS NULL THEN
+        CREATE TABLE poison_messages
+        (
+            id              UUID PRIMARY KEY,
+            topic           VARCHAR(255) NOT NULL,
+            kafka_partition INTEGER      NOT NULL,
+            kafka_offset    BIGINT       NOT NULL,
+            message_key     BYTEA,
+            message_value   BYTEA,
+            headers         JSONB        NOT NULL DEFAULT '{}',
+            error           TEXT         NOT NULL,
+            published_at    TIMESTAMP WITH TIME ZONE,
+            created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+            UNIQUE (topic, kafka_partition, kafka_offset)
+        );
+
+        CREATE INDEX idx_poison_messages_created_at ON poison_messages (created_at);
+    END IF;
+END
+$$;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_inbox_partition_key_created_at;
+DROP INDEX IF EXISTS idx_outbox_partition_key_created_at;
+
+ALTER TABLE inbox_messages DROP COLUMN IF EXISTS partition_key;
@@ -0,0 +1,8 @@
+-- Messages with the same partition key are claimed one after another: a row is not claimed
+-- while an earlier row with its key is still pending, processing or failed.
+ALTER TABLE inbox_messages ADD COLUMN partition_key VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_outbox_partition_key_created_at ON outbox_messages (partition_key, created_at)
+    WHERE status IN ('pending', 'processing', 'failed');
+CREATE INDEX idx_inbox_partition_key_created_at ON inbox_messages (partition_key, created_at)
+    WHERE status IN ('pending', 'processing', 'failed');
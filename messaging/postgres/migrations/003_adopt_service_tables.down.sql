@@ -0,0 +1,3 @@
+-- Adoption only fills in what the service migrations left out; the tables keep the module schema,
+-- and the service migrations that created them are rolled back by the services themselves.
+SELECT 1;
@@ -0,0 +1,140 @@
+-- Adopt outbox, inbox and poison message tables created by service migrations (orders 001/008-015,
+-- payments 001/007-014, or only the initial 001 of either service): every column, constraint, index
+-- and trigger of the module schema is added when missing, and service-named indexes are renamed
+-- to the module names. Tables created by 001 of this module already match and stay unchanged.
+-- Ids created as VARCHAR(36) by payments are kept: the module reads and writes ids as text.
+CREATE OR REPLACE FUNCTION messaging_set_updated_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE OR REPLACE FUNCTION notify_outbox_messages()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('outbox_messages', '');
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS partition_key VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS last_error TEXT;
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS attempts JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS dead_lettered_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS correlation_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS causation_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS traceparent VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS schema_version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE inbox_messages ADD COLUMN IF NOT EXISTS partition_key VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE inbox_messages ADD COLUMN IF NOT EXISTS last_error TEXT;
+ALTER TABLE inbox_messages ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE inbox_messages ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE inbox_messages ADD COLUMN IF NOT EXISTS attempts JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE inbox_messages ADD COLUMN IF NOT EXISTS dead_lettered_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE inbox_messages ADD COLUMN IF NOT EXISTS correlation_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE inbox_messages ADD COLUMN IF NOT EXISTS causation_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE inbox_messages ADD COLUMN IF NOT EXISTS traceparent VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE inbox_messages ADD COLUMN IF NOT EXISTS source VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE inbox_messages ADD COLUMN IF NOT EXISTS schema_version INTEGER NOT NULL DEFAULT 0;
+
+-- payments created both tables without a status check
+ALTER TABLE outbox_messages DROP CONSTRAINT IF EXISTS outbox_messages_status_check;
+ALTER TABLE outbox_messages
+    ADD CONSTRAINT outbox_messages_status_check CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'dead_letter'));
+ALTER TABLE inbox_messages DROP CONSTRAINT IF EXISTS inbox_messages_status_check;
+ALTER TABLE inbox_messages
+    ADD CONSTRAINT inbox_messages_status_check CHECK (status IN ('pending', 'processing', 'processed', 'failed', 'dead_letter'));
+
+-- payments named its indexes idx_{table}_{column}; event_id is already indexed by its unique constraint
+DO $$
+DECLARE
+    renames CONSTANT TEXT[][] := ARRAY[
+        ['idx_outbox_messages_status', 'idx_outbox_status'],
+        ['idx_outbox_messages_event_type', 'idx_outbox_event_type'],
+        ['idx_outbox_messages_created_at', 'idx_outbox_created_at'],
+        ['idx_outbox_messages_locked_until', 'idx_outbox_locked_until'],
+        ['idx_outbox_messages_dead_lettered_at', 'idx_outbox_dead_lettered_at'],
+        ['idx_inbox_messages_status', 'idx_inbox_status'],
+        ['idx_inbox_messages_event_type', 'idx_inbox_event_type'],
+        ['idx_inbox_messages_created_at', 'idx_inbox_created_at'],
+        ['idx_inbox_messages_locked_until', 'idx_inbox_locked_until'],
+        ['idx_inbox_messages_dead_lettered_at', 'idx_inbox_dead_lettered_at']
+    ];
+BEGIN
+    FOR i IN 1 .. array_length(renames, 1) LOOP
+        IF to_regclass(renames[i][1]) IS NOT NULL AND to_regclass(renames[i][2]) IS NULL THEN
+            EXECUTE format('ALTER INDEX %I RENAME TO %I', renames[i][1], renames[i][2]);
+        END IF;
+    END LOOP;
+END
+$$;
+
+DROP INDEX IF EXISTS idx_inbox_event_id;
+DROP INDEX IF EXISTS idx_inbox_messages_event_id;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_status ON outbox_messages (status);
+CREATE INDEX IF NOT EXISTS idx_outbox_event_type ON outbox_messages (event_type);
+CREATE INDEX IF NOT EXISTS idx_outbox_created_at ON outbox_messages (created_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_locked_until ON outbox_messages (locked_until) WHERE status = 'processing';
+CREATE INDEX IF NOT EXISTS idx_outbox_next_attempt_at ON outbox_messages (next_attempt_at) WHERE status = 'failed';
+CREATE INDEX IF NOT EXISTS idx_outbox_dead_lettered_at ON outbox_messages (dead_lettered_at) WHERE status = 'dead_letter';
+CREATE INDEX IF NOT EXISTS idx_outbox_correlation_id ON outbox_messages (correlation_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_partition_key_created_at ON outbox_messages (partition_key, created_at)
+    WHERE status IN ('pending', 'processing', 'failed');
+
+CREATE INDEX IF NOT EXISTS idx_inbox_status ON inbox_messages (status);
+CREATE INDEX IF NOT EXISTS idx_inbox_event_type ON inbox_messages (event_type);
+CREATE INDEX IF NOT EXISTS idx_inbox_created_at ON inbox_messages (created_at);
+CREATE INDEX IF NOT EXISTS idx_inbox_locked_until ON inbox_messages (locked_until) WHERE status = 'processing';
+CREATE INDEX IF NOT EXISTS idx_inbox_next_attempt_at ON inbox_messages (next_attempt_at) WHERE status = 'failed';
+CREATE INDEX IF NOT EXISTS idx_inbox_dead_lettered_at ON inbox_messages (dead_lettered_at) WHERE status = 'dead_letter';
+CREATE INDEX IF NOT EXISTS idx_inbox_correlation_id ON inbox_messages (correlation_id);
+CREATE INDEX IF NOT EXISTS idx_inbox_partition_key_created_at ON inbox_messages (partition_key, created_at)
+    WHERE status IN ('pending', 'processing', 'failed');
+
+-- updated_at is kept by the module function; orders used its own update_updated_at_column(),
+-- payments had no trigger at all
+DROP TRIGGER IF EXISTS update_outbox_messages_updated_at ON outbox_messages;
+CREATE TRIGGER update_outbox_messages_updated_at
+    BEFORE UPDATE
+    ON outbox_messages
+    FOR EACH ROW
+    EXECUTE FUNCTION messaging_set_updated_at();
+
+DROP TRIGGER IF EXISTS update_inbox_messages_updated_at ON inbox_messages;
+CREATE TRIGGER update_inbox_messages_updated_at
+    BEFORE UPDATE
+    ON inbox_messages
+    FOR EACH ROW
+    EXECUTE FUNCTION messaging_set_updated_at();
+
+DROP TRIGGER IF EXISTS notify_outbox_messages_pending ON outbox_messages;
+CREATE TRIGGER notify_outbox_messages_pending
+    AFTER INSERT OR UPDATE OF status
+    ON outbox_messages
+    FOR EACH ROW
+    WHEN (NEW.status = 'pending')
+    EXECUTE FUNCTION notify_outbox_messages();
+
+-- Kafka messages the consumer could not decode, kept byte for byte with their position in the topic
+CREATE TABLE IF NOT EXISTS poison_messages
+(
+    id              UUID PRIMARY KEY,
+    topic           VARCHAR(255) NOT NULL,
+    kafka_partition INTEGER      NOT NULL,
+    kafka_offset    BIGINT       NOT NULL,
+    message_key     BYTEA,
+    message_value   BYTEA,
+    headers         JSONB        NOT NULL DEFAULT '{}',
+    error           TEXT         NOT NULL,
+    published_at    TIMESTAMP WITH TIME ZONE,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (topic, kafka_partition, kafka_offset)
+);
+
+CREATE INDEX IF NOT EXISTS idx_poison_messages_created_at ON poison_messages (created_at);
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// OutboxChannel — канал NOTIFY, в который пишет триггер на outbox_messages
+const OutboxChannel = "outbox_messages"
+
+// OutboxListener подписывается на OutboxChannel и превращает уведомления в сигналы
+// "есть что отправить". Несколько уведомлений подряд схлопываются в один сигнал.
+type OutboxListener struct {
+	listener      *pq.Listener
+	notifications chan struct{}
+}
+
+func NewOutboxListener(dsn string) (*OutboxListener, error) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Outbox listener connection event %d: %v", event, err)
+		}
+	})
+
+	if err := listener.Listen(OutboxChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen %s: %w", OutboxChannel, err)
+	}
+
+	l := &OutboxListener{
+		listener:      listener,
+		notifications: make(chan struct{}, 1),
+	}
+	go l.forward()
+
+	return l, nil
+}
+
+func (l *OutboxListener) Notifications() <-chan struct{} {
+	return l.notifications
+}
+
+func (l *OutboxListener) Close() error {
+	return l.listener.Close()
+}
+
+func (l *OutboxListener) forward() {
+	defer close(l.notifications)
+
+	// После переподключения pq присылает nil: уведомления за время разрыва потеряны,
+	// поэтому тоже будим publisher
+	for range l.listener.Notify {
+		select {
+		case l.notifications <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"messaging/outbox"
+)
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) outbox.Repository {
+	return &OutboxRepository{db: db}
+}
+
+const insertOutboxMessageQuery = `
+	INSERT INTO outbox_messages (id, event_type, partition_key, payload, status, sent_at, created_at, updated_at, retry_count, max_retries,
+	                             correlation_id, causation_id, traceparent, schema_version)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+func (r *OutboxRepository) Store(ctx context.Context, message *outbox.OutboxMessage) error {
+	if _, err := r.db.ExecContext(ctx, insertOutboxMessageQuery, outboxMessageArgs(message)...); err != nil {
+		return fmt.Errorf("failed to store outbox message: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, message *outbox.OutboxMessage) error {
+	if _, err := tx.ExecContext(ctx, insertOutboxMessageQuery, outboxMessageArgs(message)...); err != nil {
+		return fmt.Errorf("failed to store outbox message with tx: %w", err)
+	}
+
+	return nil
+}
+
+func outboxMessageArgs(message *outbox.OutboxMessage) []any {
+	return []any{
+		message.ID,
+		message.EventType,
+		message.PartitionKey,
+		message.Payload,
+		string(message.Status),
+		message.SentAt,
+		message.CreatedAt,
+		message.UpdatedAt,
+		message.RetryCount,
+		message.MaxRetries,
+		message.CorrelationID,
+		message.CausationID,
+		message.Traceparent,
+		message.SchemaVersion,
+	}
+}
+
+// notBehindEarlierOutboxMessage пропускает сообщение, пока более раннее сообщение с тем же
+// partition_key не отправлено: порядок событий агрегата сохраняется и при повторах,
+// и когда outbox разбирают несколько реплик
+const notBehindEarlierOutboxMessage = `(message.partition_key = '' OR NOT EXISTS (
+					SELECT 1
+					FROM outbox_messages earlier
+					WHERE earlier.partition_key = message.partition_key
+					  AND earlier.status IN ('pending', 'processing', 'failed')
+					  AND (earlier.created_at, earlier.id) < (message.created_at, message.id)
+				))`
+
+// ClaimPendingMessages захватывает пачку новых сообщений и сообщений с истёкшей арендой.
+// SKIP LOCKED не даёт двум репликам взять одну строку, а аренда возвращает в работу
+// сообщения реплики, упавшей до отметки об отправке
+func (r *OutboxRepository) ClaimPendingMessages(ctx context.Context, limit int, lease time.Duration) ([]*outbox.OutboxMessage, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE outbox_messages
+			SET status = 'processing', locked_until = NOW() + $2 * INTERVAL '1 millisecond', updated_at = NOW()
+			WHERE id IN (
+				SELECT id
+				FROM outbox_messages message
+				WHERE (status = 'pending' OR (status = 'processing' AND locked_until < NOW()))
+				  AND ` + notBehindEarlierOutboxMessage + `
+				ORDER BY created_at ASC
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, event_type, partition_key, payload, status, sent_at, created_at, updated_at, retry_count, max_retries,
+			          correlation_id, causation_id, traceparent, schema_version
+		)
+		SELECT id, event_type, partition_key, payload, status, sent_at, created_at, updated_at, retry_count, max_retries,
+		       correlation_id, causation_id, traceparent, schema_version
+		FROM claimed
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pending outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanMessages(rows)
+}
+
+// MarkAsSent отмечает отправку. Если аренда истекла, сообщение могла захватить другая
+// реплика: отметка не проходит, и сообщение будет отправлено повторно
+func (r *OutboxRepository) MarkAsSent(ctx context.Context, id string) error {
+	query := `
+		UPDATE outbox_messages
+		SET status = 'sent', sent_at = NOW(), locked_until = NULL, updated_at = NOW()
+		WHERE id = $1 AND ` + leasedOutboxMessage + `
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message as sent: %w", err)
+	}
+
+	return requireLeasedOutboxMessage(result, id)
+}
+
+// MarkAsFailed записывает попытку в историю и возвращает сообщение в failed;
+// ClaimFailedMessages возьмёт его не раньше nextAttemptAt
+func (r *OutboxRepository) MarkAsFailed(ctx context.Context, id string, reason string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE outbox_messages
+		SET status = 'failed', retry_count = retry_count + 1, next_attempt_at = $3, locked_until = NULL,
+		    last_error = $2, attempts = attempts || jsonb_build_array(jsonb_build_object('at', NOW(), 'error', $2::text)),
+		    updated_at = NOW()
+		WHERE id = $1 AND ` + leasedOutboxMessage + `
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, reason, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message as failed: %w", err)
+	}
+
+	return requireLeasedOutboxMessage(result, id)
+}
+
+func (r *OutboxRepository) MarkAsDeadLetter(ctx context.Context, id, reason string) error {
+	query := `
+		UPDATE outbox_messages
+		SET status = 'dead_letter', retry_count = retry_count + 1, locked_until = NULL, next_attempt_at = NULL,
+		    last_error = $2, attempts = attempts || jsonb_build_array(jsonb_build_object('at', NOW(), 'error', $2::text)),
+		    dead_lettered_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND ` + leasedOutboxMessage + `
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, reason)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message as dead letter: %w", err)
+	}
+
+	return requireLeasedOutboxMessage(result, id)
+}
+
+// leasedOutboxMessage — сообщение всё ещё захвачено этой попыткой: оно в processing,
+// и его аренда не истекла
+const leasedOutboxMessage = `status = 'processing' AND locked_until > NOW()`
+
+func requireLeasedOutboxMessage(result sql.Result, id string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %s", outbox.ErrLeaseLost, id)
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) ClaimFailedMessages(ctx context.Context, limit int, lease time.Duration) ([]*outbox.OutboxMessage, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE outbox_messages
+			SET status = 'processing', locked_until = NOW() + $2 * INTERVAL '1 millisecond', updated_at = NOW()
+			WHERE id IN (
+				SELECT id
+				FROM outbox_messages message
+				WHERE status = 'failed' AND next_attempt_at <= NOW()
+				  AND ` + notBehindEarlierOutboxMessage + `
+				ORDER BY next_attempt_at ASC
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, event_type, partition_key, payload, status, sent_at, created_at, updated_at, retry_count, max_retries,
+			          correlation_id, causation_id, traceparent, schema_version
+		)
+		SELECT id, event_type, partition_key, payload, status, sent_at, created_at, updated_at, retry_count, max_retries,
+		       correlation_id, causation_id, traceparent, schema_version
+		FROM claimed
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim failed outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanMessages(rows)
+}
+
+func (r *OutboxRepository) scanMessages(rows *sql.Rows) ([]*outbox.OutboxMessage, error) {
+	var messages []*outbox.OutboxMessage
+
+	for rows.Next() {
+		var message outbox.OutboxMessage
+		var status string
+		var sentAt sql.NullTime
+
+		err := rows.Scan(
+			&message.ID,
+			&message.EventType,
+			&message.PartitionKey,
+			&message.Payload,
+			&status,
+			&sentAt,
+			&message.CreatedAt,
+			&message.UpdatedAt,
+			&message.RetryCount,
+			&message.MaxRetries,
+			&message.CorrelationID,
+			&message.CausationID,
+			&message.Traceparent,
+			&message.SchemaVersion,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+
+		message.Status = outbox.OutboxMessageStatus(status)
+		if sentAt.Valid {
+			message.SentAt = &sentAt.Time
+		}
+
+		messages = append(messages, &message)
+	}
+
+	return messages, rows.Err()
+}
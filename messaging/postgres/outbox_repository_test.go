@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"messaging/outbox"
+)
+
+func TestOutboxRepository_StoreAndStoreWithTx(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	ctx := context.Background()
+	insert := regexp.QuoteMeta("INSERT INTO outbox_messages")
+
+	message, err := outbox.NewOutboxMessage("order.created", "order-1", json.RawMessage(`{"order_id":"order-1"}`))
+	assert.NoError(t, err)
+
+	mockSQL.ExpectExec(insert).
+		WithArgs(message.ID, "order.created", "order-1", sqlmock.AnyArg(), "pending", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), 0, 3, "", "", "", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Store(ctx, message))
+
+	mockSQL.ExpectBegin()
+	mockSQL.ExpectExec(insert).WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+		sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+		sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mockSQL.ExpectCommit()
+
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+	assert.NoError(t, repo.StoreWithTx(ctx, tx, message))
+	assert.NoError(t, tx.Commit())
+
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+// Отметки проходят только для захваченного сообщения с действующей арендой
+func TestOutboxRepository_MarksRequireLease(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	ctx := context.Background()
+	leased := regexp.QuoteMeta("WHERE id = $1 AND status = 'processing' AND locked_until > NOW()")
+	nextAttemptAt := time.Now().Add(time.Minute)
+
+	mockSQL.ExpectExec(`UPDATE outbox_messages\s+SET status = 'sent'[\s\S]*` + leased).
+		WithArgs("message-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Аренда истекла: сообщение уже могла захватить и отправить другая реплика
+	mockSQL.ExpectExec(`UPDATE outbox_messages\s+SET status = 'sent'[\s\S]*` + leased).
+		WithArgs("message-2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mockSQL.ExpectExec(`UPDATE outbox_messages\s+SET status = 'failed'[\s\S]*` + leased).
+		WithArgs("message-2", "broker unavailable", nextAttemptAt).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mockSQL.ExpectExec(`UPDATE outbox_messages\s+SET status = 'dead_letter'[\s\S]*` + leased).
+		WithArgs("message-2", "no route").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.MarkAsSent(ctx, "message-1"))
+	assert.ErrorIs(t, repo.MarkAsSent(ctx, "message-2"), outbox.ErrLeaseLost)
+	assert.ErrorIs(t, repo.MarkAsFailed(ctx, "message-2", "broker unavailable", nextAttemptAt), outbox.ErrLeaseLost)
+	assert.ErrorIs(t, repo.MarkAsDeadLetter(ctx, "message-2", "no route"), outbox.ErrLeaseLost)
+
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+// Оба запроса захвата пропускают сообщение, пока более раннее сообщение его ключа не отправлено
+func TestOutboxRepository_ClaimKeepsPartitionKeyOrder(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewOutboxRepository(db)
+	ctx := context.Background()
+	columns := []string{"id", "event_type", "partition_key", "payload", "status", "sent_at", "created_at", "updated_at", "retry_count", "max_retries",
+		"correlation_id", "causation_id", "traceparent", "schema_version"}
+	earlier := `[\s\S]*` + regexp.QuoteMeta("earlier.status IN ('pending', 'processing', 'failed')") +
+		`\s+AND ` + regexp.QuoteMeta("(earlier.created_at, earlier.id) < (message.created_at, message.id)")
+	pending := regexp.QuoteMeta("WHERE (status = 'pending'") + earlier
+	failed := regexp.QuoteMeta("WHERE status = 'failed'") + earlier
+
+	mockSQL.ExpectQuery(pending).
+		WithArgs(10, int64(30000)).
+		WillReturnRows(sqlmock.NewRows(columns))
+	mockSQL.ExpectQuery(failed).
+		WithArgs(10, int64(30000)).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	_, err = repo.ClaimPendingMessages(ctx, 10, 30*time.Second)
+	assert.NoError(t, err)
+	_, err = repo.ClaimFailedMessages(ctx, 10, 30*time.Second)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
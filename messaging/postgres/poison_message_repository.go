@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"messaging/poison"
+)
+
+type PoisonMessageRepository struct {
+	db *sql.DB
+}
+
+func NewPoisonMessageRepository(db *sql.DB) poison.Repository {
+	return &PoisonMessageRepository{db: db}
+}
+
+func (r *PoisonMessageRepository) Store(ctx context.Context, message *poison.Message) error {
+	headers, err := json.Marshal(message.Headers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal poison message headers: %w", err)
+	}
+
+	publishedAt := sql.NullTime{Time: message.PublishedAt, Valid: !message.PublishedAt.IsZero()}
+
+	// После ребалансировки сообщение может прийти повторно, пока его offset не зафиксирован
+	query := `
+		INSERT INTO poison_messages (id, topic, kafka_partition, kafka_offset, message_key, message_value, headers, error, published_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (topic, kafka_partition, kafka_offset) DO NOTHING`
+
+	_, err = r.db.ExecContext(ctx, query,
+		message.ID, message.Topic, message.Partition, message.Offset, message.Key, message.Value,
+		headers, message.Error, publishedAt, message.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store poison message: %w", err)
+	}
+
+	return nil
+}
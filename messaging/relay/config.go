@@ -0,0 +1,227 @@
+// Package relay доставляет события между outbox, брокером и inbox сервиса:
+// OutboxPublisher публикует сообщения outbox, InboxProcessor принимает события
+// в inbox и применяет их обработчиками сервиса в транзакции.
+package relay
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"messaging/broker"
+	"messaging/broker/memory"
+	"messaging/broker/rabbitmq"
+	"messaging/kafka"
+	"messaging/poison"
+)
+
+const (
+	// ModeOutbox — события доставляются только через outbox, продюсер обычный
+	ModeOutbox = "outbox"
+	// ModeTransactional — продюсер транзакционный: пачки outbox публикуются атомарно.
+	// Режим касается только публикации: inbox фиксирует offset после записи события в БД
+	ModeTransactional = "transactional"
+)
+
+// Транспорт, через который outbox публикует, а inbox получает события
+const (
+	TransportKafka = "kafka"
+	// TransportMemory — шина в памяти одного процесса для тестов: издатели и подписчики
+	// должны делить Config.Bus, сервисы в отдельных процессах с ней не запускаются
+	TransportMemory   = "memory"
+	TransportRabbitMQ = "rabbitmq"
+)
+
+type Config struct {
+	// Transport — брокер outbox и inbox, см. NewPublisher и NewSubscriber
+	Transport       string
+	RabbitMQURL     string
+	Mode            string
+	TransactionalID string
+	// Source — имя сервиса в заголовке source публикуемых событий
+	Source    string
+	Brokers   []string
+	Publisher Publisher
+	Consumer  Consumer
+	Routes    []Route
+	Retry     *RetryPolicy
+	// Formats — формат сообщений по топикам (kafka.Format), проверяется в NewProducer
+	Formats map[string]string
+	// Bus — шина транспорта memory, одна на все издатели и подписчики процесса.
+	// Без неё транспорт memory не создаётся: события уходили бы в шину без подписчиков
+	Bus *memory.Bus
+}
+
+type Publisher struct {
+	Interval         time.Duration
+	FallbackInterval time.Duration
+	BatchSize        int
+	Lease            time.Duration
+}
+
+type Consumer struct {
+	GroupID string
+	// Retry — повторы обработки сообщения до фиксации offset, Pause — пауза партиции после них
+	Retry Backoff
+	Pause time.Duration
+	// Workers — воркеры на партицию, MaxInFlight — лимит сообщений партиции до фиксации offset
+	Workers     int
+	MaxInFlight int
+}
+
+// NewConfig возвращает настройки сервиса source с брокерами Kafka brokers и значениями
+// по умолчанию: доставка через outbox, группа "{source}-group". Маршруты Routes
+// задаёт сам сервис
+func NewConfig(source string, brokers []string) *Config {
+	return &Config{
+		Transport:       TransportKafka,
+		Mode:            ModeOutbox,
+		TransactionalID: source,
+		Source:          source,
+		Brokers:         brokers,
+		Publisher: Publisher{
+			Interval:         time.Second,
+			FallbackInterval: 10 * time.Second,
+			BatchSize:        50,
+			Lease:            30 * time.Second,
+		},
+		Consumer: Consumer{
+			GroupID: source + "-group",
+			Retry: Backoff{
+				MaxAttempts:  3,
+				InitialDelay: 200 * time.Millisecond,
+				MaxDelay:     5 * time.Second,
+				Multiplier:   2,
+			},
+			Pause:       30 * time.Second,
+			Workers:     8,
+			MaxInFlight: 256,
+		},
+		Retry: NewRetryPolicy(Backoff{
+			MaxAttempts:  5,
+			InitialDelay: time.Second,
+			MaxDelay:     5 * time.Minute,
+			Multiplier:   2,
+		}, nil),
+	}
+}
+
+func (c *Config) GetBrokers() []string {
+	return c.Brokers
+}
+
+// NewProducer создаёт продюсер под выбранный режим. name различает transactional.id
+// нескольких продюсеров одной реплики
+func (c *Config) NewProducer(name string) (*kafka.Producer, error) {
+	formats := make(map[string]kafka.Format, len(c.Formats))
+	for topic, value := range c.Formats {
+		format, err := kafka.ParseFormat(value)
+		if err != nil {
+			return nil, fmt.Errorf("topic %s: %w", topic, err)
+		}
+		formats[topic] = format
+	}
+
+	var producer *kafka.Producer
+	var err error
+	switch c.Mode {
+	case ModeOutbox:
+		producer, err = kafka.NewProducer(c.Brokers)
+	case ModeTransactional:
+		producer, err = kafka.NewTransactionalProducer(c.Brokers, c.TransactionalID+"-"+name)
+	default:
+		return nil, fmt.Errorf("unknown kafka mode %q", c.Mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for topic, format := range formats {
+		producer.SetFormat(topic, format)
+	}
+	return producer, nil
+}
+
+// NewConsumer создаёт консьюмер группы сервиса с повторами и пулом воркеров из Consumer
+func (c *Config) NewConsumer(topics ...string) (*kafka.Consumer, error) {
+	consumer, err := kafka.NewConsumer(c.Brokers, c.Consumer.GroupID, topics)
+	if err != nil {
+		return nil, err
+	}
+
+	consumer.SetRetry(kafka.ConsumerRetry{
+		Attempts: c.Consumer.Retry.MaxAttempts,
+		Backoff:  c.Consumer.Retry.Delay,
+		Pause:    c.Consumer.Pause,
+	})
+	consumer.SetConcurrency(c.Consumer.Workers, c.Consumer.MaxInFlight)
+	return consumer, nil
+}
+
+// NewPublisher создаёт издателя событий выбранного транспорта. name различает
+// transactional.id нескольких продюсеров Kafka одной реплики
+func (c *Config) NewPublisher(name string) (broker.Publisher, error) {
+	if c.Transport != TransportKafka && c.Mode == ModeTransactional {
+		return nil, fmt.Errorf("kafka mode %q requires kafka transport", c.Mode)
+	}
+
+	switch c.Transport {
+	case TransportKafka:
+		producer, err := c.NewProducer(name)
+		if err != nil {
+			return nil, err
+		}
+		return producer, nil
+	case TransportMemory:
+		bus, err := c.bus()
+		if err != nil {
+			return nil, err
+		}
+		return bus.Publisher(), nil
+	case TransportRabbitMQ:
+		publisher, err := rabbitmq.NewPublisher(c.RabbitMQURL)
+		if err != nil {
+			return nil, err
+		}
+		return publisher, nil
+	default:
+		return nil, fmt.Errorf("unknown broker transport %q", c.Transport)
+	}
+}
+
+// NewSubscriber создаёт подписчика группы сервиса на топики выбранного транспорта.
+// Сообщения Kafka и RabbitMQ, которые не удалось разобрать, сохраняются в poisonRepo
+func (c *Config) NewSubscriber(poisonRepo poison.Repository, topics ...string) (broker.Subscriber, error) {
+	switch c.Transport {
+	case TransportKafka:
+		consumer, err := c.NewConsumer(topics...)
+		if err != nil {
+			return nil, err
+		}
+		if poisonRepo != nil {
+			consumer.SetPoisonStore(&poisonStore{repo: poisonRepo})
+		}
+		return consumer, nil
+	case TransportMemory:
+		bus, err := c.bus()
+		if err != nil {
+			return nil, err
+		}
+		return bus.Subscriber(topics...), nil
+	case TransportRabbitMQ:
+		subscriber := rabbitmq.NewSubscriber(c.RabbitMQURL, c.Consumer.GroupID, topics)
+		if poisonRepo != nil {
+			subscriber.SetPoisonStore(&rabbitmqPoisonStore{repo: poisonRepo})
+		}
+		return subscriber, nil
+	default:
+		return nil, fmt.Errorf("unknown broker transport %q", c.Transport)
+	}
+}
+
+func (c *Config) bus() (*memory.Bus, error) {
+	if c.Bus == nil {
+		return nil, errors.New("memory transport needs a Bus shared by publishers and subscribers of one process: use kafka or rabbitmq for services running as separate processes")
+	}
+	return c.Bus, nil
+}
@@ -0,0 +1,45 @@
+package relay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_NewProducerUnknownMode(t *testing.T) {
+	cfg := &Config{Mode: "exactly-once", Brokers: []string{"localhost:9092"}}
+
+	producer, err := cfg.NewProducer("outbox")
+
+	assert.Nil(t, producer)
+	assert.EqualError(t, err, `unknown kafka mode "exactly-once"`)
+}
+
+func TestConfig_NewProducerUnknownFormat(t *testing.T) {
+	cfg := &Config{
+		Mode:    ModeOutbox,
+		Brokers: []string{"localhost:9092"},
+		Formats: map[string]string{"orders-events": "avro"},
+	}
+
+	producer, err := cfg.NewProducer("outbox")
+
+	assert.Nil(t, producer)
+	assert.EqualError(t, err, `topic orders-events: unknown event format "avro"`)
+}
+
+func TestNewConfig_Defaults(t *testing.T) {
+	cfg := NewConfig("shipping-service", []string{"localhost:9092"})
+
+	assert.Equal(t, TransportKafka, cfg.Transport)
+	assert.Equal(t, ModeOutbox, cfg.Mode)
+	assert.Equal(t, "shipping-service", cfg.Source)
+	assert.Equal(t, "shipping-service-group", cfg.Consumer.GroupID)
+	assert.Equal(t, 50, cfg.Publisher.BatchSize)
+
+	_, ok := cfg.Retry.NextAttemptAt("shipment.created", 4, time.Now())
+	assert.True(t, ok)
+	_, ok = cfg.Retry.NextAttemptAt("shipment.created", 5, time.Now())
+	assert.False(t, ok)
+}
@@ -0,0 +1,101 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/gofrs/uuid"
+
+	"messaging/deadletter"
+)
+
+const (
+	defaultDeadLettersLimit = 50
+	maxDeadLettersLimit     = 500
+)
+
+type DeadLetterService struct {
+	deadLetterRepository deadletter.Repository
+}
+
+func NewDeadLetterService(deadLetterRepository deadletter.Repository) *DeadLetterService {
+	return &DeadLetterService{
+		deadLetterRepository: deadLetterRepository,
+	}
+}
+
+func (s *DeadLetterService) ListDeadLetters(ctx context.Context, source string, limit int) ([]*deadletter.DeadLetter, error) {
+	parsedSource, err := deadletter.ParseSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultDeadLettersLimit
+	}
+	if limit > maxDeadLettersLimit {
+		limit = maxDeadLettersLimit
+	}
+
+	return s.deadLetterRepository.List(ctx, parsedSource, limit)
+}
+
+func (s *DeadLetterService) GetDeadLetter(ctx context.Context, source, id string) (*deadletter.DeadLetter, error) {
+	parsedSource, err := parseDeadLetterRef(source, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.deadLetterRepository.Get(ctx, parsedSource, id)
+}
+
+// UpdateDeadLetterPayload заменяет payload сообщения, например чтобы исправить данные перед повтором
+func (s *DeadLetterService) UpdateDeadLetterPayload(ctx context.Context, source, id string, payload json.RawMessage) (*deadletter.DeadLetter, error) {
+	parsedSource, err := parseDeadLetterRef(source, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := deadletter.ValidatePayload(payload); err != nil {
+		return nil, err
+	}
+
+	if err := s.deadLetterRepository.UpdatePayload(ctx, parsedSource, id, payload); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Dead letter payload updated: Source=%s, ID=%s", parsedSource, id)
+
+	return s.deadLetterRepository.Get(ctx, parsedSource, id)
+}
+
+// RequeueDeadLetter возвращает сообщение в очередь: publisher или inbox processor подхватят его как новое
+func (s *DeadLetterService) RequeueDeadLetter(ctx context.Context, source, id string) error {
+	parsedSource, err := parseDeadLetterRef(source, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.deadLetterRepository.Requeue(ctx, parsedSource, id); err != nil {
+		return fmt.Errorf("failed to requeue dead letter %s: %w", id, err)
+	}
+
+	log.Printf("Dead letter requeued: Source=%s, ID=%s", parsedSource, id)
+	return nil
+}
+
+// parseDeadLetterRef проверяет источник и id; id, который не является UUID, точно не найдётся
+func parseDeadLetterRef(source, id string) (deadletter.Source, error) {
+	parsedSource, err := deadletter.ParseSource(source)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := uuid.FromString(id); err != nil {
+		return "", deadletter.ErrDeadLetterNotFound
+	}
+
+	return parsedSource, nil
+}
@@ -0,0 +1,108 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"messaging/deadletter"
+)
+
+type MockDeadLetterRepository struct {
+	mock.Mock
+}
+
+func (m *MockDeadLetterRepository) List(ctx context.Context, source deadletter.Source, limit int) ([]*deadletter.DeadLetter, error) {
+	args := m.Called(ctx, source, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*deadletter.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterRepository) Get(ctx context.Context, source deadletter.Source, id string) (*deadletter.DeadLetter, error) {
+	args := m.Called(ctx, source, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*deadletter.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterRepository) UpdatePayload(ctx context.Context, source deadletter.Source, id string, payload json.RawMessage) error {
+	args := m.Called(ctx, source, id, payload)
+	return args.Error(0)
+}
+
+func (m *MockDeadLetterRepository) Requeue(ctx context.Context, source deadletter.Source, id string) error {
+	args := m.Called(ctx, source, id)
+	return args.Error(0)
+}
+
+const testDeadLetterID = "0190a6e4-8a3b-7c1e-9f2d-3b4c5d6e7f80"
+
+func TestDeadLetterService_ListDeadLetters(t *testing.T) {
+	mockRepo := new(MockDeadLetterRepository)
+	svc := NewDeadLetterService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("List", ctx, deadletter.SourceInbox, defaultDeadLettersLimit).Return([]*deadletter.DeadLetter{}, nil).Once()
+	mockRepo.On("List", ctx, deadletter.SourceOutbox, maxDeadLettersLimit).Return([]*deadletter.DeadLetter{}, nil).Once()
+
+	_, err := svc.ListDeadLetters(ctx, "inbox", 0)
+	assert.NoError(t, err)
+
+	_, err = svc.ListDeadLetters(ctx, "outbox", 10000)
+	assert.NoError(t, err)
+
+	_, err = svc.ListDeadLetters(ctx, "payments", 10)
+	assert.ErrorIs(t, err, deadletter.ErrInvalidSource)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeadLetterService_UpdateDeadLetterPayload(t *testing.T) {
+	mockRepo := new(MockDeadLetterRepository)
+	svc := NewDeadLetterService(mockRepo)
+	ctx := context.Background()
+
+	t.Run("rejects non-object payload", func(t *testing.T) {
+		_, err := svc.UpdateDeadLetterPayload(ctx, "inbox", testDeadLetterID, json.RawMessage(`"text"`))
+		assert.ErrorIs(t, err, deadletter.ErrInvalidPayload)
+		mockRepo.AssertNotCalled(t, "UpdatePayload", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns updated dead letter", func(t *testing.T) {
+		payload := json.RawMessage(`{"order_id":"order-1","user_id":"user-1"}`)
+		updated := &deadletter.DeadLetter{ID: testDeadLetterID, Source: deadletter.SourceInbox, Payload: payload}
+		mockRepo.On("UpdatePayload", ctx, deadletter.SourceInbox, testDeadLetterID, payload).Return(nil).Once()
+		mockRepo.On("Get", ctx, deadletter.SourceInbox, testDeadLetterID).Return(updated, nil).Once()
+
+		result, err := svc.UpdateDeadLetterPayload(ctx, "inbox", testDeadLetterID, payload)
+
+		assert.NoError(t, err)
+		assert.Equal(t, updated, result)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestDeadLetterService_RequeueDeadLetter(t *testing.T) {
+	mockRepo := new(MockDeadLetterRepository)
+	svc := NewDeadLetterService(mockRepo)
+	ctx := context.Background()
+
+	t.Run("malformed id is not found", func(t *testing.T) {
+		err := svc.RequeueDeadLetter(ctx, "outbox", "not-a-uuid")
+		assert.ErrorIs(t, err, deadletter.ErrDeadLetterNotFound)
+	})
+
+	t.Run("missing dead letter", func(t *testing.T) {
+		mockRepo.On("Requeue", ctx, deadletter.SourceOutbox, testDeadLetterID).Return(deadletter.ErrDeadLetterNotFound).Once()
+
+		err := svc.RequeueDeadLetter(ctx, "outbox", testDeadLetterID)
+		assert.ErrorIs(t, err, deadletter.ErrDeadLetterNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,123 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"messaging/deadletter"
+)
+
+type DeadLettersServicer interface {
+	ListDeadLetters(ctx context.Context, source string, limit int) ([]*deadletter.DeadLetter, error)
+	GetDeadLetter(ctx context.Context, source, id string) (*deadletter.DeadLetter, error)
+	UpdateDeadLetterPayload(ctx context.Context, source, id string, payload json.RawMessage) (*deadletter.DeadLetter, error)
+	RequeueDeadLetter(ctx context.Context, source, id string) error
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// DeadLettersHandler — HTTP-интерфейс DeadLetterService. Аутентификации у него нет,
+// поэтому сервисы отдают его только на внутреннем admin-порту, а не через gateway
+type DeadLettersHandler struct {
+	deadLettersService DeadLettersServicer
+}
+
+func NewDeadLettersHandler(deadLettersService DeadLettersServicer) *DeadLettersHandler {
+	return &DeadLettersHandler{
+		deadLettersService: deadLettersService,
+	}
+}
+
+// RegisterRoutes регистрирует эндпоинты /admin/dead-letters в mux
+func (h *DeadLettersHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/dead-letters/{source}", h.ListDeadLetters)
+	mux.HandleFunc("GET /admin/dead-letters/{source}/{id}", h.GetDeadLetter)
+	mux.HandleFunc("PUT /admin/dead-letters/{source}/{id}/payload", h.UpdateDeadLetterPayload)
+	mux.HandleFunc("POST /admin/dead-letters/{source}/{id}/requeue", h.RequeueDeadLetter)
+}
+
+// ListDeadLetters возвращает сообщения outbox или inbox в статусе dead_letter, новые первыми
+func (h *DeadLettersHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "Invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	deadLetters, err := h.deadLettersService.ListDeadLetters(r.Context(), r.PathValue("source"), limit)
+	if err != nil {
+		writeDeadLetterError(w, err, "Failed to list dead letters")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deadLetters)
+}
+
+// GetDeadLetter возвращает сообщение с последней ошибкой и историей попыток
+func (h *DeadLettersHandler) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	deadLetter, err := h.deadLettersService.GetDeadLetter(r.Context(), r.PathValue("source"), r.PathValue("id"))
+	if err != nil {
+		writeDeadLetterError(w, err, "Failed to get dead letter")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deadLetter)
+}
+
+// UpdateDeadLetterPayload заменяет payload сообщения; тело запроса — новый payload целиком
+func (h *DeadLettersHandler) UpdateDeadLetterPayload(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	deadLetter, err := h.deadLettersService.UpdateDeadLetterPayload(r.Context(), r.PathValue("source"), r.PathValue("id"), payload)
+	if err != nil {
+		writeDeadLetterError(w, err, "Failed to update dead letter payload")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deadLetter)
+}
+
+// RequeueDeadLetter возвращает сообщение в очередь с обнулённым счётчиком попыток
+func (h *DeadLettersHandler) RequeueDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if err := h.deadLettersService.RequeueDeadLetter(r.Context(), r.PathValue("source"), r.PathValue("id")); err != nil {
+		writeDeadLetterError(w, err, "Failed to requeue dead letter")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeDeadLetterError(w http.ResponseWriter, err error, fallback string) {
+	status := http.StatusInternalServerError
+	message := fallback
+	switch {
+	case errors.Is(err, deadletter.ErrInvalidSource), errors.Is(err, deadletter.ErrInvalidPayload):
+		status = http.StatusBadRequest
+		message = err.Error()
+	case errors.Is(err, deadletter.ErrDeadLetterNotFound):
+		status = http.StatusNotFound
+		message = err.Error()
+	}
+
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,164 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"messaging/deadletter"
+)
+
+type MockDeadLettersService struct {
+	mock.Mock
+}
+
+func (m *MockDeadLettersService) ListDeadLetters(ctx context.Context, source string, limit int) ([]*deadletter.DeadLetter, error) {
+	args := m.Called(ctx, source, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*deadletter.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLettersService) GetDeadLetter(ctx context.Context, source, id string) (*deadletter.DeadLetter, error) {
+	args := m.Called(ctx, source, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*deadletter.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLettersService) UpdateDeadLetterPayload(ctx context.Context, source, id string, payload json.RawMessage) (*deadletter.DeadLetter, error) {
+	args := m.Called(ctx, source, id, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*deadletter.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLettersService) RequeueDeadLetter(ctx context.Context, source, id string) error {
+	args := m.Called(ctx, source, id)
+	return args.Error(0)
+}
+
+func TestDeadLettersHandler_ListDeadLetters(t *testing.T) {
+	mockService := new(MockDeadLettersService)
+	handler := NewDeadLettersHandler(mockService)
+
+	t.Run("success", func(t *testing.T) {
+		expected := []*deadletter.DeadLetter{{
+			ID:        "message-1",
+			Source:    deadletter.SourceOutbox,
+			EventType: "order.updated",
+			LastError: "no route for event type: order.updated",
+			Attempts:  []deadletter.Attempt{{Error: "no route for event type: order.updated"}},
+		}}
+		mockService.On("ListDeadLetters", mock.Anything, "outbox", 10).Return(expected, nil).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/dead-letters/outbox?limit=10", nil)
+		req.SetPathValue("source", "outbox")
+		rr := httptest.NewRecorder()
+
+		handler.ListDeadLetters(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var resp []*deadletter.DeadLetter
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Len(t, resp, 1)
+		assert.Equal(t, "order.updated", resp[0].EventType)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid source", func(t *testing.T) {
+		mockService.On("ListDeadLetters", mock.Anything, "orders", 0).Return(nil, deadletter.ErrInvalidSource).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/dead-letters/orders", nil)
+		req.SetPathValue("source", "orders")
+		rr := httptest.NewRecorder()
+
+		handler.ListDeadLetters(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestDeadLettersHandler_UpdateDeadLetterPayload(t *testing.T) {
+	mockService := new(MockDeadLettersService)
+	handler := NewDeadLettersHandler(mockService)
+
+	t.Run("invalid payload", func(t *testing.T) {
+		payload := json.RawMessage(`[1]`)
+		mockService.On("UpdateDeadLetterPayload", mock.Anything, "inbox", "message-1", payload).
+			Return(nil, deadletter.ErrInvalidPayload).Once()
+
+		req := httptest.NewRequest(http.MethodPut, "/admin/dead-letters/inbox/message-1/payload", strings.NewReader(`[1]`))
+		req.SetPathValue("source", "inbox")
+		req.SetPathValue("id", "message-1")
+		rr := httptest.NewRecorder()
+
+		handler.UpdateDeadLetterPayload(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestDeadLettersHandler_RequeueDeadLetter(t *testing.T) {
+	mockService := new(MockDeadLettersService)
+	handler := NewDeadLettersHandler(mockService)
+
+	t.Run("success", func(t *testing.T) {
+		mockService.On("RequeueDeadLetter", mock.Anything, "inbox", "message-1").Return(nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/dead-letters/inbox/message-1/requeue", nil)
+		req.SetPathValue("source", "inbox")
+		req.SetPathValue("id", "message-1")
+		rr := httptest.NewRecorder()
+
+		handler.RequeueDeadLetter(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService.On("RequeueDeadLetter", mock.Anything, "outbox", "missing").Return(deadletter.ErrDeadLetterNotFound).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/dead-letters/outbox/missing/requeue", nil)
+		req.SetPathValue("source", "outbox")
+		req.SetPathValue("id", "missing")
+		rr := httptest.NewRecorder()
+
+		handler.RequeueDeadLetter(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestDeadLettersHandler_RegisterRoutes(t *testing.T) {
+	mockService := new(MockDeadLettersService)
+	mux := http.NewServeMux()
+	NewDeadLettersHandler(mockService).RegisterRoutes(mux)
+
+	expected := &deadletter.DeadLetter{ID: "message-1", Source: deadletter.SourceInbox}
+	mockService.On("GetDeadLetter", mock.Anything, "inbox", "message-1").Return(expected, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/dead-letters/inbox/message-1", nil)
+	rr := httptest.NewRecorder()
+
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp deadletter.DeadLetter
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, "message-1", resp.ID)
+	mockService.AssertExpectations(t)
+}
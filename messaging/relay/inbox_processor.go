@@ -0,0 +1,265 @@
+package relay
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"contracts/events"
+	"messaging/broker"
+	"messaging/correlation"
+	"messaging/inbox"
+	"messaging/poison"
+)
+
+// InboxHandler применяет событие в транзакции tx. Процессор сам открывает её,
+// отмечает в ней сообщение обработанным и фиксирует, поэтому обработчик не вызывает
+// Commit: изменения по событию и отметка об обработке сохраняются только вместе
+type InboxHandler func(ctx context.Context, tx *sql.Tx, message *inbox.InboxMessage) error
+
+type InboxProcessor struct {
+	db         *sql.DB
+	inboxRepo  inbox.Repository
+	subscriber broker.Subscriber
+	config     *Config
+	ticker     *time.Ticker
+	// wake будит обработку сразу после сохранения нового события, не дожидаясь тика
+	wake     chan struct{}
+	done     chan bool
+	stopOnce sync.Once
+	handlers map[string]InboxHandler
+}
+
+// NewInboxProcessor подписывает группу сервиса на topics. События попадают в inbox,
+// только если для их типа зарегистрирован обработчик, см. RegisterHandler
+func NewInboxProcessor(
+	db *sql.DB,
+	inboxRepo inbox.Repository,
+	poisonRepo poison.Repository,
+	config *Config,
+	topics ...string,
+) (*InboxProcessor, error) {
+	subscriber, err := config.NewSubscriber(poisonRepo, topics...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InboxProcessor{
+		db:         db,
+		inboxRepo:  inboxRepo,
+		subscriber: subscriber,
+		config:     config,
+		wake:       make(chan struct{}, 1),
+		done:       make(chan bool),
+		handlers:   make(map[string]InboxHandler),
+	}, nil
+}
+
+// RegisterHandler подписывает процессор на события eventType и назначает им обработчик.
+// Вызывается до Start
+func (p *InboxProcessor) RegisterHandler(eventType string, handler InboxHandler) {
+	p.handlers[eventType] = handler
+	p.subscriber.RegisterHandler(eventType, p.handleEvent)
+}
+
+// Start обрабатывает новые события сразу после их сохранения в inbox. Тик раз
+// в Publisher.Interval подбирает повторы, просроченные аренды и события,
+// сохранённые другой репликой
+func (p *InboxProcessor) Start(ctx context.Context) {
+	go func() {
+		if err := p.subscriber.Start(ctx); err != nil {
+			log.Printf("Error starting %s subscriber: %v", p.config.Transport, err)
+		}
+	}()
+
+	p.ticker = time.NewTicker(p.config.Publisher.Interval)
+
+	go func() {
+		for {
+			select {
+			case <-p.wake:
+				p.processPendingMessages(ctx)
+			case <-p.ticker.C:
+				p.processPendingMessages(ctx)
+				p.processFailedMessages(ctx)
+			case <-p.done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop останавливает обработку inbox; повторный вызов ничего не делает
+func (p *InboxProcessor) Stop() {
+	p.stopOnce.Do(func() {
+		if p.ticker != nil {
+			p.ticker.Stop()
+		}
+		close(p.done)
+	})
+}
+
+func (p *InboxProcessor) handleEvent(ctx context.Context, event broker.Event) error {
+	payload, err := json.Marshal(event.Data)
+	if err != nil {
+		log.Printf("Error marshaling event data: %v", err)
+		return err
+	}
+
+	inboxMessage, err := inbox.NewInboxMessage(event.EventID, event.EventType, payload)
+	if err != nil {
+		log.Printf("Error creating inbox message: %v", err)
+		return err
+	}
+	inboxMessage.PartitionKey = event.PartitionKey
+	inboxMessage.ApplyMetadata(event.Metadata)
+
+	// Дубликат отсекает уникальный event_id в самой вставке: между проверкой
+	// и вставкой нет окна, в котором другая реплика успела бы сохранить то же событие
+	stored, err := p.inboxRepo.Store(ctx, inboxMessage)
+	if err != nil {
+		log.Printf("Error storing inbox message: %v", err)
+		return err
+	}
+
+	if !stored {
+		log.Printf("Event %s already received, skipping", event.EventID)
+		return nil
+	}
+
+	log.Printf("Stored inbox message for event %s", event.EventID)
+
+	// Сигнал уже ждёт в канале: обработка и так заберёт это сообщение
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// processPendingMessages забирает сообщения, пока пачки не опустеют: за проход берётся
+// только первое сообщение каждого partition_key, следующее ждёт его обработки
+func (p *InboxProcessor) processPendingMessages(ctx context.Context) {
+	for ctx.Err() == nil {
+		messages, err := p.inboxRepo.ClaimPendingMessages(ctx, p.config.Publisher.BatchSize, p.config.Publisher.Lease)
+		if err != nil {
+			log.Printf("Error claiming pending inbox messages: %v", err)
+			return
+		}
+
+		if len(messages) == 0 {
+			return
+		}
+
+		for _, message := range messages {
+			p.processMessage(ctx, message)
+		}
+	}
+}
+
+func (p *InboxProcessor) processFailedMessages(ctx context.Context) {
+	messages, err := p.inboxRepo.ClaimFailedMessages(ctx, p.config.Publisher.BatchSize, p.config.Publisher.Lease)
+	if err != nil {
+		log.Printf("Error claiming failed inbox messages: %v", err)
+		return
+	}
+
+	for _, message := range messages {
+		p.processMessage(ctx, message)
+	}
+}
+
+func (p *InboxProcessor) processMessage(ctx context.Context, message *inbox.InboxMessage) {
+	handler, exists := p.handlers[message.EventType]
+	if !exists {
+		// Повтор не поможет, пока обработчик не появится в коде
+		p.markAsDeadLetter(ctx, message, fmt.Errorf("no handler found for event type: %s", message.EventType))
+		return
+	}
+
+	// Сообщение старой версии обработчик получает уже в актуальной схеме
+	payload, version, err := events.Upcast(message.EventType, message.SchemaVersion, message.Payload)
+	if err == nil {
+		err = events.Validate(message.EventType, version, payload)
+	}
+	if err != nil {
+		p.markAsDeadLetter(ctx, message, err)
+		return
+	}
+	message.Payload = payload
+	message.SchemaVersion = version
+
+	// События, которые опубликует обработчик, продолжат цепочку полученного
+	if err := p.handle(correlation.WithMetadata(ctx, message.Metadata()), handler, message); err != nil {
+		if errors.Is(err, inbox.ErrLeaseLost) {
+			// Сообщением теперь владеет другая реплика, его попытку не засчитываем
+			log.Printf("Skipping inbox message %s: %v", message.ID, err)
+			return
+		}
+		p.markAsFailed(ctx, message, err)
+		return
+	}
+
+	log.Printf("Successfully processed inbox message %s", message.ID)
+}
+
+// handle выполняет обработчик и отметку об обработке в одной транзакции.
+// Действия, отложенные обработчиком через inbox.AfterCommit, выполняются после фиксации
+func (p *InboxProcessor) handle(ctx context.Context, handler InboxHandler, message *inbox.InboxMessage) error {
+	ctx, runAfterCommit := inbox.WithAfterCommit(ctx)
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := p.inboxRepo.LockWithTx(ctx, tx, message.ID); err != nil {
+		return err
+	}
+
+	if err := handler(ctx, tx, message); err != nil {
+		return err
+	}
+
+	if err := p.inboxRepo.MarkAsProcessedWithTx(ctx, tx, message.ID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	runAfterCommit()
+	return nil
+}
+
+// markAsFailed планирует следующую попытку по политике повторов,
+// а когда попытки исчерпаны, переводит сообщение в dead_letter
+func (p *InboxProcessor) markAsFailed(ctx context.Context, message *inbox.InboxMessage, reason error) {
+	attempts := message.RetryCount + 1
+	nextAttemptAt, ok := p.config.Retry.NextAttemptAt(message.EventType, attempts, time.Now())
+	if !ok {
+		p.markAsDeadLetter(ctx, message, fmt.Errorf("retries exhausted after %d attempts: %w", attempts, reason))
+		return
+	}
+
+	log.Printf("Error processing inbox message %s (attempt %d), next attempt at %s: %v", message.ID, attempts, nextAttemptAt.Format(time.RFC3339), reason)
+	if err := p.inboxRepo.MarkAsFailed(ctx, message.ID, reason.Error(), nextAttemptAt); err != nil {
+		log.Printf("Error marking inbox message %s as failed: %v", message.ID, err)
+	}
+}
+
+func (p *InboxProcessor) markAsDeadLetter(ctx context.Context, message *inbox.InboxMessage, reason error) {
+	log.Printf("Moving inbox message %s to dead letter: %v", message.ID, reason)
+	if err := p.inboxRepo.MarkAsDeadLetter(ctx, message.ID, reason.Error()); err != nil {
+		log.Printf("Error moving inbox message %s to dead letter: %v", message.ID, err)
+	}
+}
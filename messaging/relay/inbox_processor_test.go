@@ -0,0 +1,267 @@
+package relay
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"contracts/events"
+	"contracts/money"
+	"messaging/broker"
+	"messaging/broker/memory"
+	"messaging/inbox"
+)
+
+type MockInboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockInboxRepository) Store(ctx context.Context, message *inbox.InboxMessage) (bool, error) {
+	args := m.Called(ctx, message)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockInboxRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, message *inbox.InboxMessage) (bool, error) {
+	args := m.Called(ctx, tx, message)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockInboxRepository) GetByEventID(ctx context.Context, eventID string) (*inbox.InboxMessage, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*inbox.InboxMessage), args.Error(1)
+}
+
+func (m *MockInboxRepository) ClaimPendingMessages(ctx context.Context, limit int, lease time.Duration) ([]*inbox.InboxMessage, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]*inbox.InboxMessage), args.Error(1)
+}
+
+func (m *MockInboxRepository) ClaimFailedMessages(ctx context.Context, limit int, lease time.Duration) ([]*inbox.InboxMessage, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]*inbox.InboxMessage), args.Error(1)
+}
+
+func (m *MockInboxRepository) LockWithTx(ctx context.Context, tx *sql.Tx, id string) error {
+	args := m.Called(ctx, tx, id)
+	return args.Error(0)
+}
+
+func (m *MockInboxRepository) MarkAsProcessed(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockInboxRepository) MarkAsProcessedWithTx(ctx context.Context, tx *sql.Tx, id string) error {
+	args := m.Called(ctx, tx, id)
+	return args.Error(0)
+}
+
+func (m *MockInboxRepository) MarkAsFailed(ctx context.Context, id string, reason string, nextAttemptAt time.Time) error {
+	args := m.Called(ctx, id, reason, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (m *MockInboxRepository) MarkAsDeadLetter(ctx context.Context, id string, reason string) error {
+	args := m.Called(ctx, id, reason)
+	return args.Error(0)
+}
+
+func TestInboxProcessor_HandleCommitsWithProcessedMark(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockInboxRepo := new(MockInboxRepository)
+	processor := &InboxProcessor{db: db, inboxRepo: mockInboxRepo}
+
+	ctx := context.Background()
+	message := &inbox.InboxMessage{ID: "inbox-1", EventType: "payment.completed"}
+
+	var handlerTx *sql.Tx
+	handler := func(ctx context.Context, tx *sql.Tx, message *inbox.InboxMessage) error {
+		handlerTx = tx
+		return nil
+	}
+
+	mockSQL.ExpectBegin()
+	mockInboxRepo.On("LockWithTx", mock.Anything, mock.Anything, "inbox-1").Return(nil)
+	mockInboxRepo.On("MarkAsProcessedWithTx", mock.Anything, mock.Anything, "inbox-1").Run(func(args mock.Arguments) {
+		assert.Same(t, handlerTx, args.Get(1))
+	}).Return(nil)
+	mockSQL.ExpectCommit()
+
+	assert.NoError(t, processor.handle(ctx, handler, message))
+	mockInboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestInboxProcessor_HandleRunsAfterCommitHooks(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockInboxRepo := new(MockInboxRepository)
+	processor := &InboxProcessor{db: db, inboxRepo: mockInboxRepo}
+	mockInboxRepo.On("LockWithTx", mock.Anything, mock.Anything, "inbox-1").Return(nil)
+	mockInboxRepo.On("MarkAsProcessedWithTx", mock.Anything, mock.Anything, "inbox-1").Return(nil)
+
+	notified := 0
+	handler := func(ctx context.Context, tx *sql.Tx, message *inbox.InboxMessage) error {
+		inbox.AfterCommit(ctx, func() { notified++ })
+		return nil
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectCommit()
+
+		assert.NoError(t, processor.handle(context.Background(), handler, &inbox.InboxMessage{ID: "inbox-1"}))
+		assert.Equal(t, 1, notified)
+	})
+
+	t.Run("failed commit", func(t *testing.T) {
+		mockSQL.ExpectBegin()
+		mockSQL.ExpectCommit().WillReturnError(errors.New("connection reset"))
+
+		// Изменения откатились, уведомлять не о чем
+		assert.Error(t, processor.handle(context.Background(), handler, &inbox.InboxMessage{ID: "inbox-1"}))
+		assert.Equal(t, 1, notified)
+	})
+
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestInboxProcessor_HandleRollsBackOnHandlerError(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockInboxRepo := new(MockInboxRepository)
+	processor := &InboxProcessor{db: db, inboxRepo: mockInboxRepo}
+
+	handlerErr := errors.New("order not found")
+	handler := func(ctx context.Context, tx *sql.Tx, message *inbox.InboxMessage) error {
+		return handlerErr
+	}
+
+	mockSQL.ExpectBegin()
+	mockInboxRepo.On("LockWithTx", mock.Anything, mock.Anything, "inbox-1").Return(nil)
+	mockSQL.ExpectRollback()
+
+	err = processor.handle(context.Background(), handler, &inbox.InboxMessage{ID: "inbox-1"})
+
+	assert.ErrorIs(t, err, handlerErr)
+	mockInboxRepo.AssertNotCalled(t, "MarkAsProcessedWithTx", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+// Аренда истекла и сообщение могла захватить другая реплика: обработчик не вызывается,
+// а попытка не засчитывается как ошибка
+func TestInboxProcessor_ProcessMessageSkipsLostLease(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockInboxRepo := new(MockInboxRepository)
+	processor := &InboxProcessor{db: db, inboxRepo: mockInboxRepo, handlers: make(map[string]InboxHandler)}
+
+	called := false
+	processor.handlers[events.PaymentCompleted] = func(ctx context.Context, tx *sql.Tx, message *inbox.InboxMessage) error {
+		called = true
+		return nil
+	}
+
+	mockSQL.ExpectBegin()
+	mockInboxRepo.On("LockWithTx", mock.Anything, mock.Anything, "inbox-1").Return(fmt.Errorf("%w: inbox-1", inbox.ErrLeaseLost))
+	mockSQL.ExpectRollback()
+
+	payload, err := json.Marshal(events.PaymentCompletedEvent{
+		PaymentID:   "payment-1",
+		OrderID:     "order-1",
+		UserID:      "user-1",
+		AmountMoney: money.New(1500, "RUB"),
+		Currency:    "RUB",
+	})
+	assert.NoError(t, err)
+
+	processor.processMessage(context.Background(), &inbox.InboxMessage{
+		ID:            "inbox-1",
+		EventType:     events.PaymentCompleted,
+		Payload:       payload,
+		SchemaVersion: events.LatestVersion(events.PaymentCompleted),
+	})
+
+	assert.False(t, called)
+	mockInboxRepo.AssertNotCalled(t, "MarkAsFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockInboxRepo.AssertNotCalled(t, "MarkAsDeadLetter", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestInboxProcessor_HandleEventSkipsDuplicate(t *testing.T) {
+	mockInboxRepo := new(MockInboxRepository)
+	processor := &InboxProcessor{inboxRepo: mockInboxRepo}
+
+	ctx := context.Background()
+	event := broker.Event{
+		EventType:    "payment.completed",
+		EventID:      "event-1",
+		PartitionKey: "order-1",
+		Data:         map[string]any{"order_id": "order-1"},
+	}
+
+	mockInboxRepo.On("Store", ctx, mock.MatchedBy(func(message *inbox.InboxMessage) bool {
+		return message.EventID == "event-1" && message.PartitionKey == "order-1"
+	})).Return(false, nil)
+
+	assert.NoError(t, processor.handleEvent(ctx, event))
+	mockInboxRepo.AssertExpectations(t)
+}
+
+// Новое событие обрабатывается сразу после сохранения, без ожидания тика
+func TestInboxProcessor_StoredEventWakesProcessing(t *testing.T) {
+	cfg := &Config{
+		Transport: TransportMemory,
+		Publisher: Publisher{Interval: time.Hour, BatchSize: 10, Lease: time.Minute},
+		Retry:     NewRetryPolicy(Backoff{MaxAttempts: 3}, nil),
+		Bus:       memory.NewBus(),
+	}
+
+	mockInboxRepo := new(MockInboxRepository)
+	processor, err := NewInboxProcessor(nil, mockInboxRepo, nil, cfg, "payments-events")
+	assert.NoError(t, err)
+
+	claimed := make(chan struct{}, 1)
+	mockInboxRepo.On("Store", mock.Anything, mock.Anything).Return(true, nil)
+	mockInboxRepo.On("ClaimPendingMessages", mock.Anything, 10, time.Minute).Run(func(args mock.Arguments) {
+		claimed <- struct{}{}
+	}).Return([]*inbox.InboxMessage{}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	processor.Start(ctx)
+	defer processor.Stop()
+
+	assert.NoError(t, processor.handleEvent(ctx, broker.Event{
+		EventType: events.PaymentCompleted,
+		EventID:   "event-1",
+		Data:      map[string]any{"order_id": "order-1"},
+	}))
+
+	select {
+	case <-claimed:
+	case <-time.After(time.Second):
+		t.Fatal("stored event was not processed before the next tick")
+	}
+	mockInboxRepo.AssertNotCalled(t, "ClaimFailedMessages", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,270 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"contracts/events"
+	"messaging/broker"
+	"messaging/correlation"
+	"messaging/outbox"
+)
+
+type OutboxPublisher struct {
+	outboxRepo outbox.Repository
+	notifier   outbox.Notifier
+	publisher  broker.Publisher
+	router     *TopicRouter
+	config     *Config
+	// nextRetryAt — ближайший повтор, запланированный этим publisher'ом
+	nextRetryAt time.Time
+	done        chan bool
+	stopOnce    sync.Once
+}
+
+func NewOutboxPublisher(
+	outboxRepo outbox.Repository,
+	notifier outbox.Notifier,
+	config *Config,
+) (*OutboxPublisher, error) {
+	router, err := NewTopicRouter(config.Routes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create topic router: %w", err)
+	}
+
+	publisher, err := config.NewPublisher("outbox")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s publisher: %w", config.Transport, err)
+	}
+
+	return &OutboxPublisher{
+		outboxRepo: outboxRepo,
+		notifier:   notifier,
+		publisher:  publisher,
+		router:     router,
+		config:     config,
+		done:       make(chan bool),
+	}, nil
+}
+
+// Start отправляет сообщения по NOTIFY из триггера на outbox_messages. Редкий опрос
+// подбирает пропущенные уведомления и просроченные аренды, а таймер повторов
+// срабатывает к ближайшему next_attempt_at
+func (p *OutboxPublisher) Start(ctx context.Context) {
+	go func() {
+		notifications := p.notifier.Notifications()
+		// Первый проход сразу после старта забирает накопившиеся сообщения
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+
+		for {
+			select {
+			case _, ok := <-notifications:
+				if !ok {
+					notifications = nil
+					continue
+				}
+				p.processPendingMessages(ctx)
+			case <-timer.C:
+				p.processPendingMessages(ctx)
+				if !p.nextRetryAt.After(time.Now()) {
+					p.nextRetryAt = time.Time{}
+				}
+				p.processFailedMessages(ctx)
+			case <-p.done:
+				return
+			case <-ctx.Done():
+				return
+			}
+			timer.Reset(p.nextPollIn())
+		}
+	}()
+}
+
+// Stop останавливает publisher; повторный вызов ничего не делает
+func (p *OutboxPublisher) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.done)
+		if err := p.notifier.Close(); err != nil {
+			log.Printf("Error closing outbox notifier: %v", err)
+		}
+		if p.publisher != nil {
+			p.publisher.Close()
+		}
+	})
+}
+
+func (p *OutboxPublisher) nextPollIn() time.Duration {
+	wait := p.config.Publisher.FallbackInterval
+	if !p.nextRetryAt.IsZero() {
+		wait = min(wait, max(time.Until(p.nextRetryAt), 0))
+	}
+	return wait
+}
+
+func (p *OutboxPublisher) processPendingMessages(ctx context.Context) {
+	// За проход берётся только первое сообщение каждого partition_key, следующее
+	// становится доступно после его отправки, поэтому забираем, пока пачки не опустеют
+	for ctx.Err() == nil {
+		messages, err := p.outboxRepo.ClaimPendingMessages(ctx, p.config.Publisher.BatchSize, p.config.Publisher.Lease)
+		if err != nil {
+			log.Printf("Error claiming pending messages: %v", err)
+			return
+		}
+
+		if len(messages) == 0 {
+			return
+		}
+
+		p.deliverAll(ctx, messages)
+	}
+}
+
+func (p *OutboxPublisher) processFailedMessages(ctx context.Context) {
+	for ctx.Err() == nil {
+		messages, err := p.outboxRepo.ClaimFailedMessages(ctx, p.config.Publisher.BatchSize, p.config.Publisher.Lease)
+		if err != nil {
+			log.Printf("Error claiming failed messages: %v", err)
+			return
+		}
+
+		p.deliverAll(ctx, messages)
+
+		if len(messages) < p.config.Publisher.BatchSize {
+			return
+		}
+	}
+}
+
+func (p *OutboxPublisher) deliverAll(ctx context.Context, messages []*outbox.OutboxMessage) {
+	if p.publisher.IsTransactional() {
+		p.deliverBatch(ctx, messages)
+		return
+	}
+
+	for _, message := range messages {
+		p.deliver(ctx, message)
+	}
+}
+
+func (p *OutboxPublisher) deliver(ctx context.Context, message *outbox.OutboxMessage) {
+	event, err := p.buildEvent(message)
+	if err == nil {
+		err = p.publisher.PublishEvent(ctx, event.Topic, event.Event)
+	}
+	if err != nil {
+		p.handleFailure(ctx, message, err)
+		return
+	}
+
+	p.markAsSent(ctx, message)
+}
+
+// deliverBatch публикует пачку одной транзакцией Kafka: либо все события пачки
+// становятся видны консьюмерам, либо ни одно, и тогда вся пачка уходит на повтор
+func (p *OutboxPublisher) deliverBatch(ctx context.Context, messages []*outbox.OutboxMessage) {
+	events := make([]broker.TopicEvent, 0, len(messages))
+	batch := make([]*outbox.OutboxMessage, 0, len(messages))
+	for _, message := range messages {
+		event, err := p.buildEvent(message)
+		if err != nil {
+			p.handleFailure(ctx, message, err)
+			continue
+		}
+		events = append(events, event)
+		batch = append(batch, message)
+	}
+
+	if len(batch) == 0 {
+		return
+	}
+
+	if err := p.publisher.PublishBatch(ctx, events); err != nil {
+		for _, message := range batch {
+			p.handleFailure(ctx, message, err)
+		}
+		return
+	}
+
+	for _, message := range batch {
+		p.markAsSent(ctx, message)
+	}
+}
+
+func (p *OutboxPublisher) handleFailure(ctx context.Context, message *outbox.OutboxMessage, err error) {
+	// Повтор не поможет сообщению без маршрута или с payload вне контракта
+	if errors.Is(err, ErrNoRoute) || events.IsContractViolation(err) {
+		p.markAsDeadLetter(ctx, message, err)
+		return
+	}
+
+	attempts := message.RetryCount + 1
+	nextAttemptAt, ok := p.config.Retry.NextAttemptAt(message.EventType, attempts, time.Now())
+	if !ok {
+		p.markAsDeadLetter(ctx, message, fmt.Errorf("retries exhausted after %d attempts: %w", attempts, err))
+		return
+	}
+
+	log.Printf("Error publishing message %s (attempt %d), next attempt at %s: %v", message.ID, attempts, nextAttemptAt.Format(time.RFC3339), err)
+	if markErr := p.outboxRepo.MarkAsFailed(ctx, message.ID, err.Error(), nextAttemptAt); markErr != nil {
+		log.Printf("Error marking message %s as failed: %v", message.ID, markErr)
+		return
+	}
+	if p.nextRetryAt.IsZero() || nextAttemptAt.Before(p.nextRetryAt) {
+		p.nextRetryAt = nextAttemptAt
+	}
+}
+
+// markAsSent только логирует ошибку: событие уже в брокере, а сообщение, которое не удалось
+// отметить, после истечения аренды будет отправлено повторно, и inbox получателя его отбросит
+func (p *OutboxPublisher) markAsSent(ctx context.Context, message *outbox.OutboxMessage) {
+	if err := p.outboxRepo.MarkAsSent(ctx, message.ID); err != nil {
+		log.Printf("Error marking message %s as sent: %v", message.ID, err)
+	}
+}
+
+func (p *OutboxPublisher) markAsDeadLetter(ctx context.Context, message *outbox.OutboxMessage, reason error) {
+	log.Printf("Moving message %s to dead letter: %v", message.ID, reason)
+	if err := p.outboxRepo.MarkAsDeadLetter(ctx, message.ID, reason.Error()); err != nil {
+		log.Printf("Error moving message %s to dead letter: %v", message.ID, err)
+	}
+}
+
+func (p *OutboxPublisher) buildEvent(message *outbox.OutboxMessage) (broker.TopicEvent, error) {
+	topic, err := p.router.Resolve(message.EventType)
+	if err != nil {
+		return broker.TopicEvent{}, err
+	}
+
+	if err := events.Validate(message.EventType, message.SchemaVersion, message.Payload); err != nil {
+		return broker.TopicEvent{}, err
+	}
+
+	var payloadMap map[string]any
+	if err := json.Unmarshal(message.Payload, &payloadMap); err != nil {
+		return broker.TopicEvent{}, fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	return broker.TopicEvent{
+		Topic: topic,
+		Event: broker.Event{
+			EventType:    message.EventType,
+			EventID:      message.ID,
+			PartitionKey: message.PartitionKey,
+			Data:         payloadMap,
+			Timestamp:    message.CreatedAt.Unix(),
+			Metadata: correlation.Metadata{
+				CorrelationID: message.CorrelationID,
+				CausationID:   message.CausationID,
+				Traceparent:   message.Traceparent,
+				Source:        p.config.Source,
+				SchemaVersion: message.SchemaVersion,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,48 @@
+package relay
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"contracts/events"
+	"messaging/outbox"
+)
+
+func TestOutboxPublisher_NextPollIn(t *testing.T) {
+	publisher := &OutboxPublisher{
+		config: &Config{Publisher: Publisher{FallbackInterval: 10 * time.Second}},
+	}
+
+	assert.Equal(t, 10*time.Second, publisher.nextPollIn())
+
+	publisher.nextRetryAt = time.Now().Add(time.Minute)
+	assert.Equal(t, 10*time.Second, publisher.nextPollIn())
+
+	publisher.nextRetryAt = time.Now().Add(2 * time.Second)
+	assert.InDelta(t, float64(2*time.Second), float64(publisher.nextPollIn()), float64(100*time.Millisecond))
+
+	publisher.nextRetryAt = time.Now().Add(-time.Second)
+	assert.Equal(t, time.Duration(0), publisher.nextPollIn())
+}
+
+func TestOutboxPublisher_BuildEventValidatesContract(t *testing.T) {
+	router, err := NewTopicRouter([]Route{{Pattern: "order.*", Topic: "orders-events"}})
+	assert.NoError(t, err)
+	publisher := &OutboxPublisher{router: router, config: &Config{Source: "orders-service"}}
+
+	payload, _ := json.Marshal(events.OrderUpdatedEvent{OrderID: "order-123", Status: "paid"})
+	message, _ := outbox.NewOutboxMessage(events.OrderUpdated, "order-123", payload)
+
+	event, err := publisher.buildEvent(message)
+	assert.NoError(t, err)
+	assert.Equal(t, "orders-events", event.Topic)
+	assert.Equal(t, 1, event.Event.Metadata.SchemaVersion)
+
+	message, _ = outbox.NewOutboxMessage(events.OrderUpdated, "order-123", json.RawMessage(`{"order_id": 123}`))
+	_, err = publisher.buildEvent(message)
+	assert.ErrorIs(t, err, events.ErrInvalidPayload)
+	assert.True(t, events.IsContractViolation(err))
+}
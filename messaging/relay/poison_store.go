@@ -0,0 +1,59 @@
+package relay
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"messaging/broker/rabbitmq"
+	"messaging/kafka"
+	"messaging/poison"
+)
+
+// poisonStore сохраняет сообщения, которые консьюмер не смог разобрать, в poison_messages
+type poisonStore struct {
+	repo poison.Repository
+}
+
+func (s *poisonStore) Quarantine(ctx context.Context, message kafka.PoisonMessage) error {
+	poisonMessage, err := poison.NewMessage(message.Topic, message.Partition, message.Offset, message.Value, message.Error)
+	if err != nil {
+		return fmt.Errorf("failed to create poison message: %w", err)
+	}
+	poisonMessage.Key = message.Key
+	poisonMessage.Headers = message.Headers
+	poisonMessage.PublishedAt = message.Timestamp
+
+	return s.repo.Store(ctx, poisonMessage)
+}
+
+// rabbitmqPartition — партиция poison-сообщений RabbitMQ: у очередей нет партиций и offset
+const rabbitmqPartition = -1
+
+// rabbitmqPoisonStore сохраняет в poison_messages сообщения RabbitMQ, которые подписчик
+// не смог разобрать. Топик — exchange, ключ — ключ маршрутизации, а вместо offset
+// хранится отпечаток id и тела сообщения: повторная доставка того же сообщения
+// не создаёт вторую запись
+type rabbitmqPoisonStore struct {
+	repo poison.Repository
+}
+
+func (s *rabbitmqPoisonStore) Quarantine(ctx context.Context, message rabbitmq.PoisonMessage) error {
+	poisonMessage, err := poison.NewMessage(message.Exchange, rabbitmqPartition, fingerprint(message), message.Body, message.Error)
+	if err != nil {
+		return fmt.Errorf("failed to create poison message: %w", err)
+	}
+	poisonMessage.Key = []byte(message.RoutingKey)
+	poisonMessage.Headers = message.Headers
+	poisonMessage.PublishedAt = message.Timestamp
+
+	return s.repo.Store(ctx, poisonMessage)
+}
+
+func fingerprint(message rabbitmq.PoisonMessage) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(message.MessageID))
+	hash.Write([]byte{0})
+	hash.Write(message.Body)
+	return int64(hash.Sum64() >> 1)
+}
@@ -0,0 +1,59 @@
+package relay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"messaging/broker/rabbitmq"
+	"messaging/poison"
+)
+
+type recordingPoisonRepository struct {
+	stored []*poison.Message
+}
+
+func (r *recordingPoisonRepository) Store(ctx context.Context, message *poison.Message) error {
+	r.stored = append(r.stored, message)
+	return nil
+}
+
+func TestRabbitMQPoisonStore_Quarantine(t *testing.T) {
+	repo := &recordingPoisonRepository{}
+	store := &rabbitmqPoisonStore{repo: repo}
+	publishedAt := time.Unix(1700000000, 0)
+
+	message := rabbitmq.PoisonMessage{
+		Exchange:   "orders-events",
+		RoutingKey: "order.created",
+		MessageID:  "event-1",
+		Body:       []byte(`{"event_type":`),
+		Headers:    map[string]string{"correlation_id": "corr-1"},
+		Error:      "unexpected end of JSON input",
+		Timestamp:  publishedAt,
+	}
+	require.NoError(t, store.Quarantine(context.Background(), message))
+	require.NoError(t, store.Quarantine(context.Background(), message))
+
+	other := message
+	other.MessageID = "event-2"
+	require.NoError(t, store.Quarantine(context.Background(), other))
+
+	require.Len(t, repo.stored, 3)
+	stored := repo.stored[0]
+	assert.Equal(t, "orders-events", stored.Topic)
+	assert.Equal(t, int32(rabbitmqPartition), stored.Partition)
+	assert.Equal(t, []byte("order.created"), stored.Key)
+	assert.Equal(t, message.Body, stored.Value)
+	assert.Equal(t, message.Headers, stored.Headers)
+	assert.Equal(t, message.Error, stored.Error)
+	assert.Equal(t, publishedAt, stored.PublishedAt)
+
+	// Повторная доставка попадает в тот же offset и отбрасывается ON CONFLICT, другое сообщение — нет
+	assert.GreaterOrEqual(t, stored.Offset, int64(0))
+	assert.Equal(t, stored.Offset, repo.stored[1].Offset)
+	assert.NotEqual(t, stored.Offset, repo.stored[2].Offset)
+}
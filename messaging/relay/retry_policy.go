@@ -0,0 +1,58 @@
+package relay
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff — сколько раз и с какими паузами повторять обработку сообщения
+type Backoff struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+	Jitter       float64
+}
+
+// Delay возвращает паузу после attempt-й неудачной попытки (attempt начинается с 1)
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := float64(b.InitialDelay) * math.Pow(b.Multiplier, float64(attempt-1))
+	if maxDelay := float64(b.MaxDelay); delay > maxDelay {
+		delay = maxDelay
+	}
+	if b.Jitter > 0 {
+		delay += delay * b.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// RetryPolicy — общая политика повторов для outbox и inbox с переопределениями по типу события
+type RetryPolicy struct {
+	defaults Backoff
+	events   map[string]Backoff
+}
+
+func NewRetryPolicy(defaults Backoff, events map[string]Backoff) *RetryPolicy {
+	return &RetryPolicy{
+		defaults: defaults,
+		events:   events,
+	}
+}
+
+func (p *RetryPolicy) For(eventType string) Backoff {
+	if backoff, ok := p.events[eventType]; ok {
+		return backoff
+	}
+	return p.defaults
+}
+
+// NextAttemptAt возвращает время следующей попытки после failedAttempts неудачных.
+// false означает, что попытки исчерпаны
+func (p *RetryPolicy) NextAttemptAt(eventType string, failedAttempts int, now time.Time) (time.Time, bool) {
+	backoff := p.For(eventType)
+	if failedAttempts >= backoff.MaxAttempts {
+		return time.Time{}, false
+	}
+	return now.Add(backoff.Delay(failedAttempts)), true
+}
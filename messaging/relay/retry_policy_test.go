@@ -0,0 +1,59 @@
+package relay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	backoff := Backoff{
+		MaxAttempts:  5,
+		InitialDelay: time.Second,
+		MaxDelay:     5 * time.Second,
+		Multiplier:   2,
+	}
+
+	assert.Equal(t, time.Second, backoff.Delay(1))
+	assert.Equal(t, 2*time.Second, backoff.Delay(2))
+	assert.Equal(t, 4*time.Second, backoff.Delay(3))
+	assert.Equal(t, 5*time.Second, backoff.Delay(4))
+}
+
+func TestBackoff_DelayWithJitter(t *testing.T) {
+	backoff := Backoff{
+		MaxAttempts:  5,
+		InitialDelay: 10 * time.Second,
+		MaxDelay:     time.Minute,
+		Multiplier:   2,
+		Jitter:       0.2,
+	}
+
+	for i := 0; i < 100; i++ {
+		delay := backoff.Delay(1)
+		assert.GreaterOrEqual(t, delay, 8*time.Second)
+		assert.LessOrEqual(t, delay, 12*time.Second)
+	}
+}
+
+func TestRetryPolicy_NextAttemptAt(t *testing.T) {
+	policy := NewRetryPolicy(
+		Backoff{MaxAttempts: 3, InitialDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2},
+		map[string]Backoff{
+			"payment.completed": {MaxAttempts: 5, InitialDelay: time.Second, MaxDelay: time.Minute, Multiplier: 2},
+		},
+	)
+	now := time.Now()
+
+	next, ok := policy.NextAttemptAt("payment.failed", 2, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(2*time.Second), next)
+
+	_, ok = policy.NextAttemptAt("payment.failed", 3, now)
+	assert.False(t, ok)
+
+	next, ok = policy.NextAttemptAt("payment.completed", 3, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(4*time.Second), next)
+}
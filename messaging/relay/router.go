@@ -0,0 +1,65 @@
+package relay
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+var ErrNoRoute = errors.New("no route for event type")
+
+// Route направляет события, тип которых подходит под Pattern, в топик Topic.
+// Pattern — точный тип события или шаблон с "*", например "order.*"
+type Route struct {
+	Pattern string
+	Topic   string
+}
+
+// TopicRouter выбирает топик для события по таблице маршрутов из конфига.
+// Точное совпадение приоритетнее шаблона, среди шаблонов побеждает первый подходящий
+type TopicRouter struct {
+	exact    map[string]string
+	patterns []Route
+}
+
+func NewTopicRouter(routes []Route) (*TopicRouter, error) {
+	router := &TopicRouter{exact: make(map[string]string)}
+
+	for _, route := range routes {
+		if route.Pattern == "" || route.Topic == "" {
+			return nil, fmt.Errorf("invalid route %q -> %q: event and topic are required", route.Pattern, route.Topic)
+		}
+		if _, err := path.Match(route.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid route pattern %q: %w", route.Pattern, err)
+		}
+
+		if !isPattern(route.Pattern) {
+			if _, exists := router.exact[route.Pattern]; !exists {
+				router.exact[route.Pattern] = route.Topic
+			}
+			continue
+		}
+		router.patterns = append(router.patterns, route)
+	}
+
+	return router, nil
+}
+
+func (r *TopicRouter) Resolve(eventType string) (string, error) {
+	if topic, ok := r.exact[eventType]; ok {
+		return topic, nil
+	}
+
+	for _, route := range r.patterns {
+		if matched, _ := path.Match(route.Pattern, eventType); matched {
+			return route.Topic, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrNoRoute, eventType)
+}
+
+func isPattern(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
@@ -0,0 +1,50 @@
+package relay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopicRouter_Resolve(t *testing.T) {
+	router, err := NewTopicRouter([]Route{
+		{Pattern: "order.*", Topic: "orders-events"},
+		{Pattern: "order.updated", Topic: "orders-updates"},
+		{Pattern: "*", Topic: "fallback"},
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		eventType string
+		topic     string
+	}{
+		{eventType: "order.created", topic: "orders-events"},
+		// Точное совпадение приоритетнее шаблона, даже если объявлено позже
+		{eventType: "order.updated", topic: "orders-updates"},
+		{eventType: "payment.completed", topic: "fallback"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.eventType, func(t *testing.T) {
+			topic, err := router.Resolve(tt.eventType)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.topic, topic)
+		})
+	}
+}
+
+func TestTopicRouter_NoRoute(t *testing.T) {
+	router, err := NewTopicRouter([]Route{{Pattern: "order.*", Topic: "orders-events"}})
+	assert.NoError(t, err)
+
+	_, err = router.Resolve("payment.completed")
+	assert.ErrorIs(t, err, ErrNoRoute)
+}
+
+func TestNewTopicRouter_InvalidRoutes(t *testing.T) {
+	_, err := NewTopicRouter([]Route{{Pattern: "order.*", Topic: ""}})
+	assert.Error(t, err)
+
+	_, err = NewTopicRouter([]Route{{Pattern: "order.[", Topic: "orders-events"}})
+	assert.Error(t, err)
+}
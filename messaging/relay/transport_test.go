@@ -0,0 +1,168 @@
+package relay
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"contracts/events"
+	"contracts/money"
+	"messaging/broker"
+	"messaging/broker/memory"
+	"messaging/correlation"
+	"messaging/inbox"
+	"messaging/outbox"
+)
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) Store(ctx context.Context, message *outbox.OutboxMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, message *outbox.OutboxMessage) error {
+	args := m.Called(ctx, tx, message)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) ClaimPendingMessages(ctx context.Context, limit int, lease time.Duration) ([]*outbox.OutboxMessage, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]*outbox.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkAsSent(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkAsFailed(ctx context.Context, id string, reason string, nextAttemptAt time.Time) error {
+	args := m.Called(ctx, id, reason, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkAsDeadLetter(ctx context.Context, id, reason string) error {
+	args := m.Called(ctx, id, reason)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) ClaimFailedMessages(ctx context.Context, limit int, lease time.Duration) ([]*outbox.OutboxMessage, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]*outbox.OutboxMessage), args.Error(1)
+}
+
+func TestConfig_UnknownTransport(t *testing.T) {
+	cfg := &Config{Transport: "carrier-pigeon", Mode: ModeOutbox}
+
+	publisher, err := cfg.NewPublisher("outbox")
+	assert.Nil(t, publisher)
+	assert.EqualError(t, err, `unknown broker transport "carrier-pigeon"`)
+
+	subscriber, err := cfg.NewSubscriber(nil, "payments-events")
+	assert.Nil(t, subscriber)
+	assert.EqualError(t, err, `unknown broker transport "carrier-pigeon"`)
+}
+
+// Шина в памяти есть только внутри процесса: без общей Bus издатель и подписчик
+// не создаются, иначе события сервисов в разных процессах молча терялись бы
+func TestConfig_MemoryTransportRequiresBus(t *testing.T) {
+	cfg := &Config{Transport: TransportMemory, Mode: ModeOutbox}
+
+	publisher, err := cfg.NewPublisher("outbox")
+	assert.Nil(t, publisher)
+	assert.ErrorContains(t, err, "memory transport needs a Bus")
+
+	subscriber, err := cfg.NewSubscriber(nil, "payments-events")
+	assert.Nil(t, subscriber)
+	assert.ErrorContains(t, err, "memory transport needs a Bus")
+}
+
+func TestConfig_TransactionalModeRequiresKafka(t *testing.T) {
+	cfg := &Config{Transport: TransportMemory, Mode: ModeTransactional}
+
+	_, err := cfg.NewPublisher("outbox")
+
+	assert.EqualError(t, err, `kafka mode "transactional" requires kafka transport`)
+}
+
+// Весь путь order.created → payments → payment.completed → inbox заказов на шине в памяти:
+// outbox publisher и inbox processor те же, что работают с Kafka
+func TestMemoryTransport_OrderPaymentRoundTrip(t *testing.T) {
+	cfg := &Config{
+		Transport: TransportMemory,
+		Source:    "orders-service",
+		Routes:    []Route{{Pattern: "order.*", Topic: "orders-events"}},
+		Retry:     NewRetryPolicy(Backoff{MaxAttempts: 3}, nil),
+		Bus:       memory.NewBus(),
+	}
+
+	outboxRepo := new(MockOutboxRepository)
+	publisher, err := NewOutboxPublisher(outboxRepo, nil, cfg)
+	assert.NoError(t, err)
+
+	inboxRepo := new(MockInboxRepository)
+	processor, err := NewInboxProcessor(nil, inboxRepo, nil, cfg, "payments-events")
+	assert.NoError(t, err)
+	processor.RegisterHandler(events.PaymentCompleted, func(ctx context.Context, tx *sql.Tx, message *inbox.InboxMessage) error {
+		return nil
+	})
+
+	// payments-service: на order.created отвечает payment.completed в той же цепочке
+	payments := cfg.Bus.Subscriber("orders-events")
+	payments.RegisterHandler(events.OrderCreated, func(ctx context.Context, event broker.Event) error {
+		metadata := correlation.FromContext(ctx)
+		return cfg.Bus.Publisher().PublishEvent(ctx, "payments-events", broker.Event{
+			EventType:    events.PaymentCompleted,
+			EventID:      "payment-event-1",
+			PartitionKey: event.PartitionKey,
+			Data:         map[string]any{"order_id": event.Data["order_id"], "payment_id": "payment-1"},
+			Metadata: correlation.Metadata{
+				CorrelationID: metadata.CorrelationID,
+				CausationID:   metadata.EventID,
+				Source:        "payments-service",
+				SchemaVersion: 1,
+			},
+		})
+	})
+
+	received := make(chan *inbox.InboxMessage, 1)
+	inboxRepo.On("Store", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		received <- args.Get(1).(*inbox.InboxMessage)
+	}).Return(true, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go payments.Start(ctx)
+	go processor.subscriber.Start(ctx)
+
+	payload, _ := json.Marshal(events.OrderCreatedEvent{
+		OrderID:     "order-1",
+		UserID:      "user-1",
+		AmountMoney: money.New(1500, "RUB"),
+		Currency:    "RUB",
+	})
+	message, _ := outbox.NewOutboxMessage(events.OrderCreated, "order-1", payload)
+	message.Correlate(correlation.Metadata{CorrelationID: "corr-1"})
+
+	outboxRepo.On("MarkAsSent", ctx, message.ID).Return(nil)
+	publisher.deliver(ctx, message)
+	outboxRepo.AssertExpectations(t)
+
+	select {
+	case stored := <-received:
+		assert.Equal(t, events.PaymentCompleted, stored.EventType)
+		assert.Equal(t, "payment-event-1", stored.EventID)
+		assert.JSONEq(t, `{"order_id": "order-1", "payment_id": "payment-1"}`, string(stored.Payload))
+		assert.Equal(t, "corr-1", stored.CorrelationID)
+		assert.Equal(t, message.ID, stored.CausationID)
+	case <-time.After(time.Second):
+		t.Fatal("payment.completed did not reach the orders inbox")
+	}
+}
@@ -1,6 +1,6 @@
 import { Button } from '@chakra-ui/react'
 import { FaPlus } from 'react-icons/fa'
-import { useCreateOrderMutation } from '../store/api/ordersApi'
+import { useCreateOrderMutation, useGetProductsQuery } from '../store/api/ordersApi'
 
 interface CreateOrderButtonProps {
     userId: string
@@ -8,10 +8,22 @@ interface CreateOrderButtonProps {
 
 export const CreateOrderButton = ({ userId }: CreateOrderButtonProps) => {
     const [createOrder, { isLoading }] = useCreateOrderMutation()
+    const { data: products = [] } = useGetProductsQuery()
 
     const handleCreateOrder = async () => {
+        if (products.length === 0) {
+            return
+        }
+
+        // Случайный товар из каталога, чтобы быстро создавать разные заказы
+        const product = products[Math.floor(Math.random() * products.length)]
+        const quantity = Math.floor(Math.random() * 3) + 1
+
         try {
-            await createOrder({ user_id: userId }).unwrap()
+            await createOrder({
+                user_id: userId,
+                items: [{ product_id: product.id, quantity }],
+            }).unwrap()
         } catch (error) {
             console.error('Failed to create order:', error)
         }
@@ -22,6 +34,7 @@ export const CreateOrderButton = ({ userId }: CreateOrderButtonProps) => {
             colorPalette="purple"
             onClick={handleCreateOrder}
             loading={isLoading}
+            disabled={products.length === 0}
         >
             <FaPlus />
             Create Order
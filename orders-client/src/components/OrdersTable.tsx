@@ -1,7 +1,7 @@
 import { useEffect } from 'react'
 import { useDispatch } from 'react-redux'
-import { Box, Text, Badge } from '@chakra-ui/react'
-import { useGetUserOrdersQuery, ordersApi } from '../store/api/ordersApi'
+import { Box, Text, Badge, Button } from '@chakra-ui/react'
+import { useGetUserOrdersQuery, useCompleteOrderMutation, ordersApi } from '../store/api/ordersApi'
 import { paymentsApi } from '../store/api/paymentsApi'
 import type { Order, OrderStatus } from '../types/api'
 import { ENV } from '../config/env'
@@ -13,6 +13,15 @@ interface OrdersTableProps {
 export const OrdersTable = ({ userId }: OrdersTableProps) => {
     const dispatch = useDispatch()
     const { data: orders = [], isLoading } = useGetUserOrdersQuery(userId)
+    const [completeOrder, { isLoading: isCompleting }] = useCompleteOrderMutation()
+
+    const handleCompleteOrder = async (orderId: string) => {
+        try {
+            await completeOrder(orderId).unwrap()
+        } catch (error) {
+            console.error('Failed to complete order:', error)
+        }
+    }
 
     useEffect(() => {
         const eventSource = new EventSource(`${ENV.API_URL}/orders-api/orders/stream?user_id=${userId}`)
@@ -60,6 +69,7 @@ export const OrdersTable = ({ userId }: OrdersTableProps) => {
             case 'payment_pending': return 'yellow'
             case 'payment_failed': return 'red'
             case 'created': return 'gray'
+            case 'cancelling': return 'orange'
             case 'cancelled': return 'red'
             default: return 'gray'
         }
@@ -86,6 +96,7 @@ export const OrdersTable = ({ userId }: OrdersTableProps) => {
                             <Box as="th" p={4} textAlign="left" fontWeight="semibold">Payment ID</Box>
                             <Box as="th" p={4} textAlign="left" fontWeight="semibold">Error Reason</Box>
                             <Box as="th" p={4} textAlign="left" fontWeight="semibold">Updated At</Box>
+                            <Box as="th" p={4} textAlign="left" fontWeight="semibold">Actions</Box>
                         </Box>
                     </Box>
                     <Box as="tbody">
@@ -123,6 +134,21 @@ export const OrdersTable = ({ userId }: OrdersTableProps) => {
                                 <Box as="td" p={4} fontFamily="mono" fontSize="sm">
                                     {new Date(order.updatedAt).toLocaleString()}
                                 </Box>
+                                <Box as="td" p={4}>
+                                    {order.status === 'paid' ? (
+                                        <Button
+                                            size="sm"
+                                            colorPalette="green"
+                                            variant="outline"
+                                            onClick={() => handleCompleteOrder(order.id)}
+                                            loading={isCompleting}
+                                        >
+                                            Complete
+                                        </Button>
+                                    ) : (
+                                        <Text color="gray.500">-</Text>
+                                    )}
+                                </Box>
                             </Box>
                         ))}
                         {orders.length === 0 && (
@@ -55,9 +55,11 @@ export const UserInfo = ({ userId }: UserInfoProps) => {
                         <Text fontSize="lg" fontWeight="semibold">
                             Balance: 
                         </Text>
-                        <Badge colorPalette="green" size="lg" px={3} py={1}>
-                            ${account.balance.toFixed(2)}
-                        </Badge>
+                        {(account.wallets ?? []).map((wallet) => (
+                            <Badge key={wallet.id} colorPalette="green" size="lg" px={3} py={1}>
+                                {((wallet.available_money ?? wallet.balance_money).minor_units / 100).toFixed(2)} {wallet.currency}
+                            </Badge>
+                        ))}
                     </HStack>
                 )}
 
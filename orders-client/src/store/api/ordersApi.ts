@@ -1,5 +1,5 @@
 import { createApi, fetchBaseQuery } from '@reduxjs/toolkit/query/react'
-import type { Order, CreateOrderRequest } from '../../types/api'
+import type { Order, CreateOrderRequest, CancelOrderRequest, Product } from '../../types/api'
 import { ENV } from '../../config/env'
 
 export const ordersApi = createApi({
@@ -7,7 +7,7 @@ export const ordersApi = createApi({
   baseQuery: fetchBaseQuery({
     baseUrl: `${ENV.API_URL}/orders-api`,
   }),
-  tagTypes: ['Order'],
+  tagTypes: ['Order', 'Product'],
   endpoints: (builder) => ({
     createOrder: builder.mutation<Order, CreateOrderRequest>({
       query: (body) => ({
@@ -18,6 +18,29 @@ export const ordersApi = createApi({
       invalidatesTags: ['Order'],
     }),
     
+    cancelOrder: builder.mutation<Order, CancelOrderRequest>({
+      query: ({ orderId, reason }) => ({
+        url: `/orders/${orderId}/cancel`,
+        method: 'POST',
+        body: { reason },
+      }),
+      invalidatesTags: (_result, _error, { orderId }) => [
+        { type: 'Order', id: orderId },
+        { type: 'Order', id: 'LIST' },
+      ],
+    }),
+    
+    completeOrder: builder.mutation<Order, string>({
+      query: (orderId) => ({
+        url: `/orders/${orderId}/complete`,
+        method: 'POST',
+      }),
+      invalidatesTags: (_result, _error, orderId) => [
+        { type: 'Order', id: orderId },
+        { type: 'Order', id: 'LIST' },
+      ],
+    }),
+    
     getOrder: builder.query<Order, string>({
       query: (orderId) => `/orders/${orderId}`,
       providesTags: (_result, _error, orderId) => [{ type: 'Order', id: orderId }],
@@ -38,6 +61,12 @@ export const ordersApi = createApi({
           : [{ type: 'Order', id: 'LIST' }],
     }),
     
+    getProducts: builder.query<Product[], void>({
+      query: () => '/products',
+      transformResponse: (response: Product[] | null) => response || [],
+      providesTags: ['Product'],
+    }),
+    
     healthCheck: builder.query<{ status: string }, void>({
       query: () => '/info',
     }),
@@ -46,7 +75,10 @@ export const ordersApi = createApi({
 
 export const {
   useCreateOrderMutation,
+  useCancelOrderMutation,
+  useCompleteOrderMutation,
   useGetOrderQuery,
   useGetUserOrdersQuery,
+  useGetProductsQuery,
   useHealthCheckQuery,
 } = ordersApi 
\ No newline at end of file
@@ -4,27 +4,88 @@ export type OrderStatus =
   | 'paid' 
   | 'payment_failed' 
   | 'completed' 
+  | 'cancelling'
   | 'cancelled'
 
+export interface Money {
+  minor_units: number
+  currency: string
+}
+
+export interface OrderItem {
+  id: string
+  orderID: string
+  productID: string
+  productName: string
+  quantity: number
+  unitPriceMoney: Money
+  amountMoney: Money
+  /** @deprecated use unitPriceMoney */
+  unitPrice: number
+  /** @deprecated use amountMoney */
+  amount: number
+}
+
 export interface Order {
   id: string
   userID: string
+  amountMoney: Money
+  /** @deprecated use amountMoney */
   amount: number
   currency: string
   status: OrderStatus
   paymentID: string
   errorReason: string
+  items: OrderItem[] | null
+  createdAt: string
+  updatedAt: string
+}
+
+export interface Product {
+  id: string
+  name: string
+  description: string
+  priceMoney: Money
+  /** @deprecated use priceMoney */
+  price: number
+  currency: string
+  active: boolean
   createdAt: string
   updatedAt: string
 }
 
+export interface LineItem {
+  product_id: string
+  quantity: number
+}
+
 export interface CreateOrderRequest {
   user_id: string
+  items: LineItem[]
+}
+
+export interface CancelOrderRequest {
+  orderId: string
+  reason?: string
+}
+
+export interface Wallet {
+  id: string
+  currency: string
+  balance_money: Money
+  available_money: Money
+  current_money: Money
+  updated_at: string
 }
 
 export interface Account {
   id: string
   user_id: string
+  balance_money: Money
+  available_money: Money
+  current_money: Money
+  wallets: Wallet[]
+  /** @deprecated use balance_money */
   balance: number
   created_at: string
   updated_at: string
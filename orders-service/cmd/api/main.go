@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	msgpostgres "messaging/postgres"
 	"net/http"
 	"orders-service/internal/application/di"
 	"orders-service/internal/infrastructure/config"
@@ -58,6 +59,9 @@ func runMigrations() {
 	if err := postgres.RunMigrations(db); err != nil {
 		log.Fatalf("failed to run migrations: %v", err)
 	}
+	if err := msgpostgres.RunMigrations(db); err != nil {
+		log.Fatalf("failed to run messaging migrations: %v", err)
+	}
 	fmt.Println("Migrations completed successfully.")
 }
 
@@ -73,6 +77,9 @@ func runServer() {
 
 	app.InboxProcessor.RegisterHandler("payment.completed", app.OrdersService.ProcessPaymentCompleted)
 	app.InboxProcessor.RegisterHandler("payment.failed", app.OrdersService.ProcessPaymentFailed)
+	app.InboxProcessor.RegisterHandler("payment.refunded", app.OrdersService.ProcessPaymentRefunded)
+	app.InboxProcessor.RegisterHandler("payment.authorized", app.OrdersService.ProcessPaymentAuthorized)
+	app.InboxProcessor.RegisterHandler("payment.released", app.OrdersService.ProcessPaymentReleased)
 
 	app.OutboxPublisher.Start(ctx)
 	defer app.OutboxPublisher.Stop()
@@ -86,6 +93,10 @@ func runServer() {
 		Addr:    fmt.Sprintf(":%d", app.Config.Server.Port),
 		Handler: app.Router.SetupRoutes(),
 	}
+	adminServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", app.Config.Server.AdminPort),
+		Handler: app.AdminRouter.SetupRoutes(),
+	}
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -100,6 +111,13 @@ func runServer() {
 		}
 	}()
 
+	go func() {
+		log.Printf("Starting admin API on internal port %d", app.Config.Server.AdminPort)
+		if err := adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start admin server: %v", err)
+		}
+	}()
+
 	<-quit
 	log.Println("Shutting down Orders Service...")
 
@@ -109,6 +127,9 @@ func runServer() {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
 
+	if err := adminServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Admin server forced to shutdown: %v", err)
+	}
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
@@ -53,7 +53,7 @@ const docTemplate = `{
         },
         "/orders": {
             "post": {
-                "description": "Create a new order with the provided details",
+                "description": "Create a new order from catalog line items. The amount is computed from current catalog prices",
                 "consumes": [
                     "application/json"
                 ],
@@ -65,6 +65,12 @@ const docTemplate = `{
                 ],
                 "summary": "Create a new order",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key to safely retry the request; a replay returns the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "Order creation request",
                         "name": "request",
@@ -81,6 +87,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/orders.Order"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Request with the same Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key was used with a different body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
                     }
                 }
             }
@@ -174,27 +198,267 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/orders/{order_id}/cancel": {
+            "post": {
+                "description": "Cancel an order. Unpaid orders are cancelled immediately, paid orders move to \"cancelling\" until the payment service confirms the refund or releases the hold",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Cancel an order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "order_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cancellation reason",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CancelOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/orders.Order"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{order_id}/complete": {
+            "post": {
+                "description": "Mark a paid order as completed. The amount held on the user's wallet is captured after completion",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Complete an order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "order_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/orders.Order"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Get all active products from the catalog",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "List products",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.Product"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new product to the catalog",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Create product",
+                "parameters": [
+                    {
+                        "description": "Product creation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateProductRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/products.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{product_id}": {
+            "get": {
+                "description": "Get a single product from the catalog",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Get product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/products.Product"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
+        "handler.CancelOrderRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
         "handler.CreateOrderRequest": {
             "type": "object",
             "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/orders.LineItem"
+                    }
+                },
                 "user_id": {
                     "type": "string"
                 }
             }
         },
-        "orders.Order": {
+        "handler.CreateProductRequest": {
             "type": "object",
             "properties": {
-                "amount": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "description": "Deprecated: use PriceMoney.",
                     "type": "number"
                 },
-                "createdAt": {
+                "price_money": {
+                    "$ref": "#/definitions/money.Money"
+                }
+            }
+        },
+        "handler.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "money.Money": {
+            "type": "object"
+        },
+        "orders.LineItem": {
+            "type": "object",
+            "properties": {
+                "product_id": {
                     "type": "string"
                 },
-                "currency": {
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "orders.Order": {
+            "type": "object",
+            "properties": {
+                "amountMoney": {
+                    "$ref": "#/definitions/money.Money"
+                },
+                "createdAt": {
                     "type": "string"
                 },
                 "errorReason": {
@@ -203,6 +467,12 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/orders.OrderItem"
+                    }
+                },
                 "paymentID": {
                     "type": "string"
                 },
@@ -217,6 +487,32 @@ const docTemplate = `{
                 }
             }
         },
+        "orders.OrderItem": {
+            "type": "object",
+            "properties": {
+                "amountMoney": {
+                    "$ref": "#/definitions/money.Money"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "orderID": {
+                    "type": "string"
+                },
+                "productID": {
+                    "type": "string"
+                },
+                "productName": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "unitPriceMoney": {
+                    "$ref": "#/definitions/money.Money"
+                }
+            }
+        },
         "orders.OrderStatus": {
             "type": "string",
             "enum": [
@@ -225,6 +521,7 @@ const docTemplate = `{
                 "paid",
                 "payment_failed",
                 "completed",
+                "cancelling",
                 "cancelled"
             ],
             "x-enum-varnames": [
@@ -233,8 +530,35 @@ const docTemplate = `{
                 "OrderStatusPaid",
                 "OrderStatusPaymentFailed",
                 "OrderStatusCompleted",
+                "OrderStatusCancelling",
                 "OrderStatusCancelled"
             ]
+        },
+        "products.Product": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "priceMoney": {
+                    "$ref": "#/definitions/money.Money"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
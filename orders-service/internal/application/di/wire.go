@@ -5,11 +5,15 @@ package di
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 
 	"github.com/google/wire"
 	"github.com/redis/go-redis/v9"
 
+	"messaging/idempotency"
+	msgpostgres "messaging/postgres"
+	"messaging/relay"
 	"orders-service/internal/application/service"
 	"orders-service/internal/infrastructure/brokers/kafka"
 	"orders-service/internal/infrastructure/config"
@@ -19,7 +23,6 @@ import (
 	"orders-service/internal/interfaces/api/handler"
 	"orders-service/internal/interfaces/api/router"
 	"orders-service/internal/interfaces/repository"
-	"orders-service/pkg/random"
 )
 
 func InitializeApplication() (*Application, func(), error) {
@@ -29,10 +32,16 @@ func InitializeApplication() (*Application, func(), error) {
 		NewPostgresConfig,
 		postgres.NewDb,
 		postgres.NewOrdersRepository,
-		postgres.NewOutboxRepository,
-		postgres.NewInboxRepository,
-		random.NewCryptoGenerator,
-		wire.Bind(new(random.Generator), new(*random.CryptoGenerator)),
+		msgpostgres.NewOutboxRepository,
+		msgpostgres.NewInboxRepository,
+		msgpostgres.NewPoisonMessageRepository,
+		postgres.NewRejectedTransitionsRepository,
+		postgres.NewProductsRepository,
+		postgres.NewOrderItemsRepository,
+		msgpostgres.NewIdempotencyRepository,
+		msgpostgres.NewDeadLetterRepository,
+		NewOutboxListener,
+		wire.Bind(new(repository.OutboxNotifier), new(*msgpostgres.OutboxListener)),
 		kafka.NewConfig,
 		NewOutboxPublisher,
 		NewInboxProcessor,
@@ -43,7 +52,14 @@ func InitializeApplication() (*Application, func(), error) {
 		sse.NewManager,
 		service.NewOrdersService,
 		wire.Bind(new(handler.OrdersServicer), new(*service.OrdersService)),
+		service.NewProductsService,
+		wire.Bind(new(handler.ProductsServicer), new(*service.ProductsService)),
+		relay.NewDeadLetterService,
+		wire.Bind(new(relay.DeadLettersServicer), new(*relay.DeadLetterService)),
+		relay.NewDeadLettersHandler,
+		NewIdempotencyMiddleware,
 		router.NewRouter,
+		router.NewAdminRouter,
 		NewApplication,
 	)
 	return &Application{}, func() {}, nil
@@ -63,6 +79,10 @@ func NewPostgresConfig(appConfig *config.Config) *postgres.Config {
 	}
 }
 
+func NewOutboxListener(postgresConfig *postgres.Config) (*msgpostgres.OutboxListener, error) {
+	return msgpostgres.NewOutboxListener(postgresConfig.DSN())
+}
+
 func NewRedisConfig(appConfig *config.Config) *redispubsub.Config {
 	return &redispubsub.Config{
 		Host:    appConfig.Redis.Host,
@@ -87,11 +107,19 @@ func NewRedisClient(redisConfig *redispubsub.Config) (*redis.Client, func(), err
 	return client, cleanup, nil
 }
 
+func NewIdempotencyMiddleware(
+	idempotencyRepo repository.IdempotencyRepository,
+	appConfig *config.Config,
+) *idempotency.Middleware {
+	return idempotency.NewMiddleware(idempotencyRepo, appConfig.GetIdempotencyTTL())
+}
+
 func NewOutboxPublisher(
 	outboxRepo repository.OutboxRepository,
+	notifier repository.OutboxNotifier,
 	kafkaConfig *kafka.Config,
-) *kafka.OutboxPublisher {
-	publisher, err := kafka.NewOutboxPublisher(outboxRepo, kafkaConfig)
+) *relay.OutboxPublisher {
+	publisher, err := relay.NewOutboxPublisher(outboxRepo, notifier, &kafkaConfig.Config)
 	if err != nil {
 		panic(err)
 	}
@@ -99,10 +127,12 @@ func NewOutboxPublisher(
 }
 
 func NewInboxProcessor(
+	db *sql.DB,
 	inboxRepo repository.InboxRepository,
+	poisonRepo repository.PoisonMessageRepository,
 	kafkaConfig *kafka.Config,
-) *kafka.InboxProcessor {
-	processor, err := kafka.NewInboxProcessor(inboxRepo, kafkaConfig)
+) *relay.InboxProcessor {
+	processor, err := relay.NewInboxProcessor(db, inboxRepo, poisonRepo, &kafkaConfig.Config, kafkaConfig.GetPaymentsEventsTopic())
 	if err != nil {
 		panic(err)
 	}
@@ -111,23 +141,26 @@ func NewInboxProcessor(
 
 type Application struct {
 	Router          *router.Router
+	AdminRouter     *router.AdminRouter
 	Config          *config.Config
-	OutboxPublisher *kafka.OutboxPublisher
-	InboxProcessor  *kafka.InboxProcessor
+	OutboxPublisher *relay.OutboxPublisher
+	InboxProcessor  *relay.InboxProcessor
 	OrdersService   *service.OrdersService
 	SSEManager      *sse.Manager
 }
 
 func NewApplication(
 	rtr *router.Router,
+	adminRtr *router.AdminRouter,
 	cfg *config.Config,
-	outboxPub *kafka.OutboxPublisher,
-	inboxProc *kafka.InboxProcessor,
+	outboxPub *relay.OutboxPublisher,
+	inboxProc *relay.InboxProcessor,
 	ordSvc *service.OrdersService,
 	sseMgr *sse.Manager,
 ) *Application {
 	return &Application{
 		Router:          rtr,
+		AdminRouter:     adminRtr,
 		Config:          cfg,
 		OutboxPublisher: outboxPub,
 		InboxProcessor:  inboxProc,
@@ -8,8 +8,12 @@ package di
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	redis2 "github.com/redis/go-redis/v9"
+	"messaging/idempotency"
+	postgres2 "messaging/postgres"
+	"messaging/relay"
 	"orders-service/internal/application/service"
 	"orders-service/internal/infrastructure/brokers/kafka"
 	"orders-service/internal/infrastructure/config"
@@ -18,7 +22,6 @@ import (
 	"orders-service/internal/infrastructure/sse"
 	"orders-service/internal/interfaces/api/router"
 	"orders-service/internal/interfaces/repository"
-	"orders-service/pkg/random"
 )
 
 // Injectors from wire.go:
@@ -32,23 +35,38 @@ func InitializeApplication() (*Application, func(), error) {
 		return nil, nil, err
 	}
 	ordersRepository := postgres.NewOrdersRepository(db)
-	outboxRepository := postgres.NewOutboxRepository(db)
-	cryptoGenerator := random.NewCryptoGenerator()
+	orderItemsRepository := postgres.NewOrderItemsRepository(db)
+	productsRepository := postgres.NewProductsRepository(db)
+	v := postgres2.NewOutboxRepository(db)
+	rejectedTransitionsRepository := postgres.NewRejectedTransitionsRepository(db)
 	redisConfig := NewRedisConfig(configConfig)
 	client, cleanup, err := NewRedisClient(redisConfig)
 	if err != nil {
 		return nil, nil, err
 	}
 	publisher := redis.NewPublisher(client, redisConfig)
-	ordersService := service.NewOrdersService(ordersRepository, outboxRepository, cryptoGenerator, publisher, db)
+	ordersService := service.NewOrdersService(ordersRepository, orderItemsRepository, productsRepository, v, rejectedTransitionsRepository, publisher, db)
+	productsService := service.NewProductsService(productsRepository)
 	subscriber := redis.NewSubscriber(client, redisConfig)
 	manager := sse.NewManager(subscriber)
-	routerRouter := router.NewRouter(ordersService, manager)
+	v2 := postgres2.NewIdempotencyRepository(db)
+	middleware := NewIdempotencyMiddleware(v2, configConfig)
+	routerRouter := router.NewRouter(ordersService, productsService, manager, middleware)
+	repository := postgres2.NewDeadLetterRepository(db)
+	deadLetterService := relay.NewDeadLetterService(repository)
+	deadLettersHandler := relay.NewDeadLettersHandler(deadLetterService)
+	adminRouter := router.NewAdminRouter(deadLettersHandler)
+	outboxListener, err := NewOutboxListener(postgresConfig)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
 	kafkaConfig := kafka.NewConfig(configConfig)
-	outboxPublisher := NewOutboxPublisher(outboxRepository, kafkaConfig)
-	inboxRepository := postgres.NewInboxRepository(db)
-	inboxProcessor := NewInboxProcessor(inboxRepository, kafkaConfig)
-	application := NewApplication(routerRouter, configConfig, outboxPublisher, inboxProcessor, ordersService, manager)
+	outboxPublisher := NewOutboxPublisher(v, outboxListener, kafkaConfig)
+	v3 := postgres2.NewInboxRepository(db)
+	v4 := postgres2.NewPoisonMessageRepository(db)
+	inboxProcessor := NewInboxProcessor(db, v3, v4, kafkaConfig)
+	application := NewApplication(routerRouter, adminRouter, configConfig, outboxPublisher, inboxProcessor, ordersService, manager)
 	return application, func() {
 		cleanup()
 	}, nil
@@ -70,6 +88,10 @@ func NewPostgresConfig(appConfig *config.Config) *postgres.Config {
 	}
 }
 
+func NewOutboxListener(postgresConfig *postgres.Config) (*postgres2.OutboxListener, error) {
+	return postgres2.NewOutboxListener(postgresConfig.DSN())
+}
+
 func NewRedisConfig(appConfig *config.Config) *redis.Config {
 	return &redis.Config{
 		Host:    appConfig.Redis.Host,
@@ -94,11 +116,19 @@ func NewRedisClient(redisConfig *redis.Config) (*redis2.Client, func(), error) {
 	return client, cleanup, nil
 }
 
+func NewIdempotencyMiddleware(
+	idempotencyRepo repository.IdempotencyRepository,
+	appConfig *config.Config,
+) *idempotency.Middleware {
+	return idempotency.NewMiddleware(idempotencyRepo, appConfig.GetIdempotencyTTL())
+}
+
 func NewOutboxPublisher(
 	outboxRepo repository.OutboxRepository,
+	notifier repository.OutboxNotifier,
 	kafkaConfig *kafka.Config,
-) *kafka.OutboxPublisher {
-	publisher, err := kafka.NewOutboxPublisher(outboxRepo, kafkaConfig)
+) *relay.OutboxPublisher {
+	publisher, err := relay.NewOutboxPublisher(outboxRepo, notifier, &kafkaConfig.Config)
 	if err != nil {
 		panic(err)
 	}
@@ -106,10 +136,12 @@ func NewOutboxPublisher(
 }
 
 func NewInboxProcessor(
+	db *sql.DB,
 	inboxRepo repository.InboxRepository,
+	poisonRepo repository.PoisonMessageRepository,
 	kafkaConfig *kafka.Config,
-) *kafka.InboxProcessor {
-	processor, err := kafka.NewInboxProcessor(inboxRepo, kafkaConfig)
+) *relay.InboxProcessor {
+	processor, err := relay.NewInboxProcessor(db, inboxRepo, poisonRepo, &kafkaConfig.Config, kafkaConfig.GetPaymentsEventsTopic())
 	if err != nil {
 		panic(err)
 	}
@@ -118,23 +150,26 @@ func NewInboxProcessor(
 
 type Application struct {
 	Router          *router.Router
+	AdminRouter     *router.AdminRouter
 	Config          *config.Config
-	OutboxPublisher *kafka.OutboxPublisher
-	InboxProcessor  *kafka.InboxProcessor
+	OutboxPublisher *relay.OutboxPublisher
+	InboxProcessor  *relay.InboxProcessor
 	OrdersService   *service.OrdersService
 	SSEManager      *sse.Manager
 }
 
 func NewApplication(
 	rtr *router.Router,
+	adminRtr *router.AdminRouter,
 	cfg *config.Config,
-	outboxPub *kafka.OutboxPublisher,
-	inboxProc *kafka.InboxProcessor,
+	outboxPub *relay.OutboxPublisher,
+	inboxProc *relay.InboxProcessor,
 	ordSvc *service.OrdersService,
 	sseMgr *sse.Manager,
 ) *Application {
 	return &Application{
 		Router:          rtr,
+		AdminRouter:     adminRtr,
 		Config:          cfg,
 		OutboxPublisher: outboxPub,
 		InboxProcessor:  inboxProc,
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"contracts/events"
+	"messaging/broker"
+	"messaging/broker/memory"
+	"messaging/correlation"
+	"messaging/inbox"
+	"messaging/inbox/inboxtest"
+	"messaging/outbox"
+	"messaging/relay"
+	"orders-service/internal/domain/orders"
+	"orders-service/internal/infrastructure/pubsub/redis"
+)
+
+// orderFlowTestdata — события сквозного сценария, общие с тестом payments
+var orderFlowTestdata = filepath.Join("..", "..", "..", "..", "contracts", "events", "testdata", "order_flow")
+
+func readOrderFlowEvent(t *testing.T, file string, event any) []byte {
+	payload, err := os.ReadFile(filepath.Join(orderFlowTestdata, file))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(payload, event))
+	return payload
+}
+
+// payment.authorized, который выпускает payments на order_created.json, проходит через шину
+// и inbox processor в настоящий ProcessPaymentAuthorized и переводит заказ в paid
+func TestOrdersService_PaymentAuthorizedFlow(t *testing.T) {
+	var created events.OrderCreatedEvent
+	readOrderFlowEvent(t, "order_created.json", &created)
+	var authorized events.PaymentAuthorizedEvent
+	payload := readOrderFlowEvent(t, "payment_authorized.json", &authorized)
+
+	db, mockSQL, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	redisClient, redisMock := redismock.NewClientMock()
+
+	mockOrdersRepo := new(MockOrdersRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	redisPublisher := redis.NewPublisher(redisClient, &redis.Config{Channel: "test"})
+	service := NewOrdersService(mockOrdersRepo, nil, nil, mockOutboxRepo, nil, redisPublisher, db)
+
+	bus := memory.NewBus()
+	cfg := &relay.Config{
+		Transport: relay.TransportMemory,
+		Source:    "orders-service",
+		Publisher: relay.Publisher{Interval: time.Hour, BatchSize: 10, Lease: time.Minute},
+		Retry:     relay.NewRetryPolicy(relay.Backoff{MaxAttempts: 1}, nil),
+		Bus:       bus,
+	}
+	inboxRepo := inboxtest.NewRepository()
+	processor, err := relay.NewInboxProcessor(db, inboxRepo, nil, cfg, "payments-events")
+	require.NoError(t, err)
+	processor.RegisterHandler(events.PaymentAuthorized, service.ProcessPaymentAuthorized)
+
+	order, _ := orders.NewOrder(created.UserID, created.AmountMoney)
+	order.ID = created.OrderID
+
+	var updated *outbox.OutboxMessage
+	mockSQL.ExpectBegin()
+	mockOrdersRepo.On("GetByIDWithTx", mock.Anything, mock.Anything, created.OrderID).Return(order, nil)
+	mockOrdersRepo.On("UpdateWithTx", mock.Anything, mock.Anything, order).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", mock.Anything, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Run(func(args mock.Arguments) {
+		updated = args.Get(2).(*outbox.OutboxMessage)
+	}).Return(nil)
+	mockSQL.ExpectCommit()
+	redisMock.CustomMatch(func(expected, actual []interface{}) error { return nil }).ExpectPublish("test", nil).SetVal(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	processor.Start(ctx)
+	defer processor.Stop()
+
+	var data map[string]any
+	require.NoError(t, json.Unmarshal(payload, &data))
+
+	require.NoError(t, bus.Publisher().PublishEvent(ctx, "payments-events", broker.Event{
+		EventType:    events.PaymentAuthorized,
+		EventID:      "payment-event-1",
+		PartitionKey: created.OrderID,
+		Data:         data,
+		Metadata: correlation.Metadata{
+			CorrelationID: "corr-1",
+			Source:        "payments-service",
+			SchemaVersion: events.LatestVersion(events.PaymentAuthorized),
+		},
+	}))
+
+	require.Eventually(t, func() bool {
+		status, _ := inboxRepo.Status("payment-event-1")
+		return status == inbox.InboxMessageStatusProcessed
+	}, time.Second, 10*time.Millisecond)
+
+	require.NotNil(t, updated)
+	assert.Equal(t, "order.updated", updated.EventType)
+	assert.Equal(t, "corr-1", updated.CorrelationID)
+
+	var got events.OrderUpdatedEvent
+	require.NoError(t, json.Unmarshal(updated.Payload, &got))
+	assert.Equal(t, events.OrderUpdatedEvent{OrderID: created.OrderID, Status: string(orders.OrderStatusPaid), PaymentID: authorized.PaymentID}, got)
+
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+	require.Eventually(t, func() bool {
+		return redisMock.ExpectationsWereMet() == nil
+	}, time.Second, 10*time.Millisecond)
+}
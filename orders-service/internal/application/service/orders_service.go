@@ -4,42 +4,53 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 
+	"contracts/events"
+	"messaging/correlation"
+	"messaging/inbox"
+	"messaging/outbox"
 	"orders-service/internal/domain/dto"
-	"orders-service/internal/domain/inbox"
 	"orders-service/internal/domain/orders"
-	"orders-service/internal/domain/outbox"
+	"orders-service/internal/domain/products"
 	"orders-service/internal/infrastructure/pubsub/redis"
 	"orders-service/internal/interfaces/repository"
-	"orders-service/pkg/random"
 )
 
 type OrdersService struct {
-	randomGenerator  random.Generator
-	ordersRepository repository.OrdersRepository
-	outboxRepository repository.OutboxRepository
-	redisPublisher   *redis.Publisher
-	db               *sql.DB
+	ordersRepository              repository.OrdersRepository
+	orderItemsRepository          repository.OrderItemsRepository
+	productsRepository            repository.ProductsRepository
+	outboxRepository              repository.OutboxRepository
+	rejectedTransitionsRepository repository.RejectedTransitionsRepository
+	redisPublisher                *redis.Publisher
+	db                            *sql.DB
 }
 
 func NewOrdersService(
 	ordersRepository repository.OrdersRepository,
+	orderItemsRepository repository.OrderItemsRepository,
+	productsRepository repository.ProductsRepository,
 	outboxRepository repository.OutboxRepository,
-	randomGenerator random.Generator,
+	rejectedTransitionsRepository repository.RejectedTransitionsRepository,
 	redisPublisher *redis.Publisher,
 	db *sql.DB,
 ) *OrdersService {
 	return &OrdersService{
-		ordersRepository: ordersRepository,
-		outboxRepository: outboxRepository,
-		randomGenerator:  randomGenerator,
-		redisPublisher:   redisPublisher,
-		db:               db,
+		ordersRepository:              ordersRepository,
+		orderItemsRepository:          orderItemsRepository,
+		productsRepository:            productsRepository,
+		outboxRepository:              outboxRepository,
+		rejectedTransitionsRepository: rejectedTransitionsRepository,
+		redisPublisher:                redisPublisher,
+		db:                            db,
 	}
 }
 
+// publishOrderUpdate уведомляет клиентов через SSE. Обработчики inbox откладывают его
+// через inbox.AfterCommit, чтобы клиенты не увидели статус, который потом откатится
 func (s *OrdersService) publishOrderUpdate(ctx context.Context, order *orders.Order) {
 	sseMessage := &dto.SSEMessage{
 		UserID:  order.UserID,
@@ -51,13 +62,80 @@ func (s *OrdersService) publishOrderUpdate(ctx context.Context, order *orders.Or
 	}
 }
 
-func (s *OrdersService) CreateOrder(ctx context.Context, userID string) (*orders.Order, error) {
-	amount, err := s.randomGenerator.FloatWithPrecision(100, 1000)
+// rejectTransition фиксирует недопустимый переход статуса в аудите и не возвращает ошибку,
+// чтобы повторное или запоздавшее событие считалось обработанным и не меняло заказ.
+func (s *OrdersService) rejectTransition(ctx context.Context, tx *sql.Tx, err error, inboxMessage *inbox.InboxMessage) error {
+	var transitionErr *orders.InvalidTransitionError
+	if !errors.As(err, &transitionErr) {
+		return err
+	}
+
+	rejected, err := orders.NewRejectedTransition(transitionErr, inboxMessage.EventType, inboxMessage.EventID)
+	if err != nil {
+		return fmt.Errorf("failed to create rejected transition: %w", err)
+	}
+
+	if err := s.rejectedTransitionsRepository.StoreWithTx(ctx, tx, rejected); err != nil {
+		return fmt.Errorf("failed to store rejected transition: %w", err)
+	}
+
+	log.Printf("Ignored %s for order %s: %v", inboxMessage.EventType, transitionErr.OrderID, transitionErr)
+	return nil
+}
+
+// priceItems собирает позиции заказа по ценам из каталога.
+// Повторяющиеся товары объединяются в одну позицию.
+func (s *OrdersService) priceItems(ctx context.Context, lineItems []orders.LineItem) ([]*orders.OrderItem, error) {
+	if len(lineItems) == 0 {
+		return nil, orders.ErrEmptyOrder
+	}
+
+	quantities := make(map[string]int, len(lineItems))
+	productIDs := make([]string, 0, len(lineItems))
+	for _, lineItem := range lineItems {
+		if lineItem.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: product %s", orders.ErrInvalidQuantity, lineItem.ProductID)
+		}
+		if _, ok := quantities[lineItem.ProductID]; !ok {
+			productIDs = append(productIDs, lineItem.ProductID)
+		}
+		quantities[lineItem.ProductID] += lineItem.Quantity
+	}
+
+	catalog, err := s.productsRepository.GetByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+
+	productsByID := make(map[string]*products.Product, len(catalog))
+	for _, product := range catalog {
+		productsByID[product.ID] = product
+	}
+
+	items := make([]*orders.OrderItem, 0, len(productIDs))
+	for _, productID := range productIDs {
+		product, ok := productsByID[productID]
+		if !ok || !product.Active {
+			return nil, fmt.Errorf("%w: %s", products.ErrProductNotFound, productID)
+		}
+
+		item, err := orders.NewOrderItem(product.ID, product.Name, quantities[productID], product.Price)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (s *OrdersService) CreateOrder(ctx context.Context, userID string, lineItems []orders.LineItem) (*orders.Order, error) {
+	items, err := s.priceItems(ctx, lineItems)
 	if err != nil {
 		return nil, err
 	}
 
-	order, err := orders.NewOrder(userID, amount)
+	order, err := orders.NewOrderWithItems(userID, items)
 	if err != nil {
 		return nil, err
 	}
@@ -78,11 +156,31 @@ func (s *OrdersService) CreateOrder(ctx context.Context, userID string) (*orders
 		return nil, fmt.Errorf("failed to store order: %w", err)
 	}
 
-	orderCreatedEvent := outbox.OrderCreatedEvent{
-		OrderID:  order.ID,
-		UserID:   order.UserID,
-		Amount:   order.Amount,
-		Currency: order.Currency,
+	err = s.orderItemsRepository.StoreWithTx(ctx, tx, order.Items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store order items: %w", err)
+	}
+
+	eventItems := make([]events.OrderItemEvent, 0, len(order.Items))
+	for _, item := range order.Items {
+		eventItems = append(eventItems, events.OrderItemEvent{
+			ProductID:      item.ProductID,
+			ProductName:    item.ProductName,
+			Quantity:       item.Quantity,
+			UnitPriceMoney: item.UnitPrice,
+			AmountMoney:    item.Amount,
+			UnitPrice:      item.UnitPrice.Float64(),
+			Amount:         item.Amount.Float64(),
+		})
+	}
+
+	orderCreatedEvent := events.OrderCreatedEvent{
+		OrderID:     order.ID,
+		UserID:      order.UserID,
+		AmountMoney: order.Amount,
+		Currency:    order.Amount.Currency(),
+		Items:       eventItems,
+		Amount:      order.Amount.Float64(),
 	}
 
 	payload, err := json.Marshal(orderCreatedEvent)
@@ -90,12 +188,13 @@ func (s *OrdersService) CreateOrder(ctx context.Context, userID string) (*orders
 		return nil, fmt.Errorf("failed to marshal order created event: %w", err)
 	}
 
-	outboxMessage, err := outbox.NewOutboxMessage("order.created", payload)
+	outboxMessage, err := outbox.NewOutboxMessage("order.created", order.ID, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create outbox message: %w", err)
 	}
+	outboxMessage.Correlate(correlation.FromContext(ctx))
 
-	err = s.outboxRepository.StoreMessage(ctx, tx, outboxMessage)
+	err = s.outboxRepository.StoreWithTx(ctx, tx, outboxMessage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to store outbox message: %w", err)
 	}
@@ -105,8 +204,8 @@ func (s *OrdersService) CreateOrder(ctx context.Context, userID string) (*orders
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	log.Printf("Order created successfully: OrderID=%s, UserID=%s, Amount=%.2f %s",
-		order.ID, order.UserID, order.Amount, order.Currency)
+	log.Printf("Order created successfully: OrderID=%s, UserID=%s, Amount=%s",
+		order.ID, order.UserID, order.Amount)
 
 	s.publishOrderUpdate(ctx, order)
 
@@ -114,19 +213,63 @@ func (s *OrdersService) CreateOrder(ctx context.Context, userID string) (*orders
 }
 
 func (s *OrdersService) GetOrder(ctx context.Context, orderID string) (*orders.Order, error) {
-	return s.ordersRepository.GetByID(ctx, orderID)
+	order, err := s.ordersRepository.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachItems(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
 }
 
 func (s *OrdersService) GetUserOrders(ctx context.Context, userID string) ([]*orders.Order, error) {
-	return s.ordersRepository.GetByUserID(ctx, userID)
+	ordersList, err := s.ordersRepository.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachItems(ctx, ordersList...); err != nil {
+		return nil, err
+	}
+
+	return ordersList, nil
+}
+
+func (s *OrdersService) attachItems(ctx context.Context, ordersList ...*orders.Order) error {
+	if len(ordersList) == 0 {
+		return nil
+	}
+
+	orderIDs := make([]string, 0, len(ordersList))
+	byID := make(map[string]*orders.Order, len(ordersList))
+	for _, order := range ordersList {
+		orderIDs = append(orderIDs, order.ID)
+		byID[order.ID] = order
+	}
+
+	items, err := s.orderItemsRepository.GetByOrderIDs(ctx, orderIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get order items: %w", err)
+	}
+
+	for _, item := range items {
+		if order, ok := byID[item.OrderID]; ok {
+			order.Items = append(order.Items, item)
+		}
+	}
+
+	return nil
 }
 
 func (s *OrdersService) UpdateOrderStatus(ctx context.Context, orderID string, status string) error {
 	return s.ordersRepository.UpdateStatus(ctx, orderID, status)
 }
 
-func (s *OrdersService) ProcessPaymentCompleted(ctx context.Context, inboxMessage *inbox.InboxMessage) error {
-	var paymentEvent inbox.PaymentCompletedEvent
+func (s *OrdersService) ProcessPaymentCompleted(ctx context.Context, tx *sql.Tx, inboxMessage *inbox.InboxMessage) error {
+	var paymentEvent events.PaymentCompletedEvent
 	if err := json.Unmarshal(inboxMessage.Payload, &paymentEvent); err != nil {
 		return fmt.Errorf("failed to unmarshal payment completed event: %w", err)
 	}
@@ -134,24 +277,38 @@ func (s *OrdersService) ProcessPaymentCompleted(ctx context.Context, inboxMessag
 	log.Printf("Processing payment completed event: OrderID=%s, PaymentID=%s, TransactionID=%s",
 		paymentEvent.OrderID, paymentEvent.PaymentID, paymentEvent.TransactionID)
 
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+	return s.markPaid(ctx, tx, inboxMessage, paymentEvent.OrderID, paymentEvent.PaymentID)
+}
+
+// ProcessPaymentAuthorized отмечает заказ оплаченным, когда сумма заблокирована на кошельке.
+// Списание произойдёт после выполнения заказа.
+func (s *OrdersService) ProcessPaymentAuthorized(ctx context.Context, tx *sql.Tx, inboxMessage *inbox.InboxMessage) error {
+	var paymentEvent events.PaymentAuthorizedEvent
+	if err := json.Unmarshal(inboxMessage.Payload, &paymentEvent); err != nil {
+		return fmt.Errorf("failed to unmarshal payment authorized event: %w", err)
 	}
-	defer tx.Rollback()
 
-	order, err := s.ordersRepository.GetByID(ctx, paymentEvent.OrderID)
+	log.Printf("Processing payment authorized event: OrderID=%s, PaymentID=%s, HoldExpiresAt=%s",
+		paymentEvent.OrderID, paymentEvent.PaymentID, paymentEvent.HoldExpiresAt)
+
+	return s.markPaid(ctx, tx, inboxMessage, paymentEvent.OrderID, paymentEvent.PaymentID)
+}
+
+func (s *OrdersService) markPaid(ctx context.Context, tx *sql.Tx, inboxMessage *inbox.InboxMessage, orderID, paymentID string) error {
+	order, err := s.ordersRepository.GetByIDWithTx(ctx, tx, orderID)
 	if err != nil {
 		return fmt.Errorf("failed to get order: %w", err)
 	}
 
-	order.MarkPaid(paymentEvent.PaymentID)
+	if err := order.MarkPaid(paymentID); err != nil {
+		return s.rejectTransition(ctx, tx, err, inboxMessage)
+	}
 
 	if err := s.ordersRepository.UpdateWithTx(ctx, tx, order); err != nil {
 		return fmt.Errorf("failed to update order: %w", err)
 	}
 
-	orderUpdatedEvent := outbox.OrderUpdatedEvent{
+	orderUpdatedEvent := events.OrderUpdatedEvent{
 		OrderID:   order.ID,
 		Status:    string(order.Status),
 		PaymentID: order.PaymentID,
@@ -162,28 +319,25 @@ func (s *OrdersService) ProcessPaymentCompleted(ctx context.Context, inboxMessag
 		return fmt.Errorf("failed to marshal order updated event: %w", err)
 	}
 
-	outboxMessage, err := outbox.NewOutboxMessage("order.updated", payload)
+	outboxMessage, err := outbox.NewOutboxMessage("order.updated", order.ID, payload)
 	if err != nil {
 		return fmt.Errorf("failed to create outbox message: %w", err)
 	}
+	outboxMessage.Correlate(correlation.FromContext(ctx))
 
-	if err := s.outboxRepository.StoreMessage(ctx, tx, outboxMessage); err != nil {
+	if err := s.outboxRepository.StoreWithTx(ctx, tx, outboxMessage); err != nil {
 		return fmt.Errorf("failed to store outbox message: %w", err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	log.Printf("Order marked as paid successfully: OrderID=%s, PaymentID=%s", order.ID, order.PaymentID)
 
-	s.publishOrderUpdate(ctx, order)
+	inbox.AfterCommit(ctx, func() { s.publishOrderUpdate(ctx, order) })
 
 	return nil
 }
 
-func (s *OrdersService) ProcessPaymentFailed(ctx context.Context, inboxMessage *inbox.InboxMessage) error {
-	var paymentEvent inbox.PaymentFailedEvent
+func (s *OrdersService) ProcessPaymentFailed(ctx context.Context, tx *sql.Tx, inboxMessage *inbox.InboxMessage) error {
+	var paymentEvent events.PaymentFailedEvent
 	if err := json.Unmarshal(inboxMessage.Payload, &paymentEvent); err != nil {
 		return fmt.Errorf("failed to unmarshal payment failed event: %w", err)
 	}
@@ -191,24 +345,20 @@ func (s *OrdersService) ProcessPaymentFailed(ctx context.Context, inboxMessage *
 	log.Printf("Processing payment failed event: OrderID=%s, PaymentID=%s, Error=%s",
 		paymentEvent.OrderID, paymentEvent.PaymentID, paymentEvent.ErrorMessage)
 
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	order, err := s.ordersRepository.GetByID(ctx, paymentEvent.OrderID)
+	order, err := s.ordersRepository.GetByIDWithTx(ctx, tx, paymentEvent.OrderID)
 	if err != nil {
 		return fmt.Errorf("failed to get order: %w", err)
 	}
 
-	order.MarkPaymentFailed(paymentEvent.ErrorMessage)
+	if err := order.MarkPaymentFailed(paymentEvent.ErrorMessage); err != nil {
+		return s.rejectTransition(ctx, tx, err, inboxMessage)
+	}
 
 	if err := s.ordersRepository.UpdateWithTx(ctx, tx, order); err != nil {
 		return fmt.Errorf("failed to update order: %w", err)
 	}
 
-	orderUpdatedEvent := outbox.OrderUpdatedEvent{
+	orderUpdatedEvent := events.OrderUpdatedEvent{
 		OrderID: order.ID,
 		Status:  string(order.Status),
 		Reason:  order.ErrorReason,
@@ -219,22 +369,274 @@ func (s *OrdersService) ProcessPaymentFailed(ctx context.Context, inboxMessage *
 		return fmt.Errorf("failed to marshal order updated event: %w", err)
 	}
 
-	outboxMessage, err := outbox.NewOutboxMessage("order.updated", payload)
+	outboxMessage, err := outbox.NewOutboxMessage("order.updated", order.ID, payload)
 	if err != nil {
 		return fmt.Errorf("failed to create outbox message: %w", err)
 	}
+	outboxMessage.Correlate(correlation.FromContext(ctx))
 
-	if err := s.outboxRepository.StoreMessage(ctx, tx, outboxMessage); err != nil {
+	if err := s.outboxRepository.StoreWithTx(ctx, tx, outboxMessage); err != nil {
 		return fmt.Errorf("failed to store outbox message: %w", err)
 	}
 
+	log.Printf("Order marked as payment failed: OrderID=%s, Reason=%s", order.ID, order.ErrorReason)
+
+	inbox.AfterCommit(ctx, func() { s.publishOrderUpdate(ctx, order) })
+
+	return nil
+}
+
+func (s *OrdersService) CancelOrder(ctx context.Context, orderID string, reason string) (*orders.Order, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	order, err := s.ordersRepository.GetByIDWithTx(ctx, tx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	if order.IsCancelling() || order.IsCancelled() {
+		return order, nil
+	}
+
+	if !order.CanBeCancelled() {
+		return nil, fmt.Errorf("%w: order %s is %s", orders.ErrOrderNotCancellable, order.ID, order.Status)
+	}
+
+	if order.RequiresRefund() {
+		err = order.MarkCancelling(reason)
+	} else {
+		err = order.MarkCancelled(reason)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", orders.ErrOrderNotCancellable, err)
+	}
+
+	if err := s.ordersRepository.UpdateWithTx(ctx, tx, order); err != nil {
+		return nil, fmt.Errorf("failed to update order: %w", err)
+	}
+
+	orderCancelledEvent := events.OrderCancelledEvent{
+		OrderID:     order.ID,
+		UserID:      order.UserID,
+		AmountMoney: order.Amount,
+		Currency:    order.Amount.Currency(),
+		PaymentID:   order.PaymentID,
+		Reason:      reason,
+		Amount:      order.Amount.Float64(),
+	}
+
+	payload, err := json.Marshal(orderCancelledEvent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal order cancelled event: %w", err)
+	}
+
+	outboxMessage, err := outbox.NewOutboxMessage("order.cancelled", order.ID, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create outbox message: %w", err)
+	}
+	outboxMessage.Correlate(correlation.FromContext(ctx))
+
+	if err := s.outboxRepository.StoreWithTx(ctx, tx, outboxMessage); err != nil {
+		return nil, fmt.Errorf("failed to store outbox message: %w", err)
+	}
+
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	log.Printf("Order marked as payment failed: OrderID=%s, Reason=%s", order.ID, order.ErrorReason)
+	log.Printf("Order cancellation accepted: OrderID=%s, Status=%s, Reason=%s", order.ID, order.Status, reason)
+
+	s.publishOrderUpdate(ctx, order)
+
+	return order, nil
+}
+
+// CompleteOrder отмечает оплаченный заказ выполненным; после этого платёжный сервис списывает заблокированную сумму.
+func (s *OrdersService) CompleteOrder(ctx context.Context, orderID string) (*orders.Order, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	order, err := s.ordersRepository.GetByIDWithTx(ctx, tx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	if order.IsCompleted() {
+		return order, nil
+	}
+
+	if err := order.MarkCompleted(); err != nil {
+		return nil, fmt.Errorf("%w: %v", orders.ErrOrderNotCompletable, err)
+	}
+
+	if err := s.ordersRepository.UpdateWithTx(ctx, tx, order); err != nil {
+		return nil, fmt.Errorf("failed to update order: %w", err)
+	}
+
+	orderCompletedEvent := events.OrderCompletedEvent{
+		OrderID:     order.ID,
+		UserID:      order.UserID,
+		AmountMoney: order.Amount,
+		Currency:    order.Amount.Currency(),
+		PaymentID:   order.PaymentID,
+		Amount:      order.Amount.Float64(),
+	}
+
+	payload, err := json.Marshal(orderCompletedEvent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal order completed event: %w", err)
+	}
+
+	outboxMessage, err := outbox.NewOutboxMessage("order.completed", order.ID, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create outbox message: %w", err)
+	}
+	outboxMessage.Correlate(correlation.FromContext(ctx))
+
+	if err := s.outboxRepository.StoreWithTx(ctx, tx, outboxMessage); err != nil {
+		return nil, fmt.Errorf("failed to store outbox message: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Order completed: OrderID=%s, PaymentID=%s", order.ID, order.PaymentID)
 
 	s.publishOrderUpdate(ctx, order)
 
+	return order, nil
+}
+
+func (s *OrdersService) ProcessPaymentRefunded(ctx context.Context, tx *sql.Tx, inboxMessage *inbox.InboxMessage) error {
+	var refundEvent events.PaymentRefundedEvent
+	if err := json.Unmarshal(inboxMessage.Payload, &refundEvent); err != nil {
+		return fmt.Errorf("failed to unmarshal payment refunded event: %w", err)
+	}
+
+	log.Printf("Processing payment refunded event: OrderID=%s, PaymentID=%s, TransactionID=%s",
+		refundEvent.OrderID, refundEvent.PaymentID, refundEvent.TransactionID)
+
+	order, err := s.ordersRepository.GetByIDWithTx(ctx, tx, refundEvent.OrderID)
+	if err != nil {
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+
+	return s.finishCancellation(ctx, tx, inboxMessage, order, refundEvent.Reason)
+}
+
+// ProcessPaymentReleased завершает отмену заказа, блокировка по которому снята без списания.
+// Если заказ ещё оплачен, отмену никто не запрашивал: истёк срок блокировки (см. expireAuthorization)
+func (s *OrdersService) ProcessPaymentReleased(ctx context.Context, tx *sql.Tx, inboxMessage *inbox.InboxMessage) error {
+	var releasedEvent events.PaymentReleasedEvent
+	if err := json.Unmarshal(inboxMessage.Payload, &releasedEvent); err != nil {
+		return fmt.Errorf("failed to unmarshal payment released event: %w", err)
+	}
+
+	log.Printf("Processing payment released event: OrderID=%s, PaymentID=%s, Reason=%s",
+		releasedEvent.OrderID, releasedEvent.PaymentID, releasedEvent.Reason)
+
+	order, err := s.ordersRepository.GetByIDWithTx(ctx, tx, releasedEvent.OrderID)
+	if err != nil {
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+
+	if order.IsPaid() {
+		return s.expireAuthorization(ctx, tx, order, releasedEvent.Reason)
+	}
+
+	return s.finishCancellation(ctx, tx, inboxMessage, order, releasedEvent.Reason)
+}
+
+// expireAuthorization сохраняет в заказе причину снятия блокировки, но заказ не отменяет:
+// пользователь по-прежнему может его выполнить (платёжный сервис заблокирует и спишет сумму заново)
+// или отменить сам
+func (s *OrdersService) expireAuthorization(ctx context.Context, tx *sql.Tx, order *orders.Order, reason string) error {
+	if reason == "" {
+		reason = orders.AuthorizationExpiredReason
+	}
+	order.RecordAuthorizationExpired(reason)
+
+	if err := s.ordersRepository.UpdateWithTx(ctx, tx, order); err != nil {
+		return fmt.Errorf("failed to update order: %w", err)
+	}
+
+	orderUpdatedEvent := events.OrderUpdatedEvent{
+		OrderID:   order.ID,
+		Status:    string(order.Status),
+		PaymentID: order.PaymentID,
+		Reason:    order.ErrorReason,
+	}
+
+	payload, err := json.Marshal(orderUpdatedEvent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal order updated event: %w", err)
+	}
+
+	outboxMessage, err := outbox.NewOutboxMessage("order.updated", order.ID, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox message: %w", err)
+	}
+	outboxMessage.Correlate(correlation.FromContext(ctx))
+
+	if err := s.outboxRepository.StoreWithTx(ctx, tx, outboxMessage); err != nil {
+		return fmt.Errorf("failed to store outbox message: %w", err)
+	}
+
+	log.Printf("Payment authorization expired, order stays paid: OrderID=%s, PaymentID=%s, Reason=%s", order.ID, order.PaymentID, reason)
+
+	inbox.AfterCommit(ctx, func() { s.publishOrderUpdate(ctx, order) })
+
+	return nil
+}
+
+// finishCancellation переводит заказ в cancelled после того, как платёжный сервис вернул деньги или снял блокировку.
+func (s *OrdersService) finishCancellation(ctx context.Context, tx *sql.Tx, inboxMessage *inbox.InboxMessage, order *orders.Order, eventReason string) error {
+	reason := order.ErrorReason
+	if reason == "" {
+		reason = eventReason
+	}
+
+	if err := order.MarkCancelled(reason); err != nil {
+		return s.rejectTransition(ctx, tx, err, inboxMessage)
+	}
+
+	if err := s.ordersRepository.UpdateWithTx(ctx, tx, order); err != nil {
+		return fmt.Errorf("failed to update order: %w", err)
+	}
+
+	orderUpdatedEvent := events.OrderUpdatedEvent{
+		OrderID:   order.ID,
+		Status:    string(order.Status),
+		PaymentID: order.PaymentID,
+		Reason:    order.ErrorReason,
+	}
+
+	payload, err := json.Marshal(orderUpdatedEvent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal order updated event: %w", err)
+	}
+
+	outboxMessage, err := outbox.NewOutboxMessage("order.updated", order.ID, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox message: %w", err)
+	}
+	outboxMessage.Correlate(correlation.FromContext(ctx))
+
+	if err := s.outboxRepository.StoreWithTx(ctx, tx, outboxMessage); err != nil {
+		return fmt.Errorf("failed to store outbox message: %w", err)
+	}
+
+	log.Printf("Order cancelled after payment was returned: OrderID=%s, PaymentID=%s", order.ID, order.PaymentID)
+
+	inbox.AfterCommit(ctx, func() { s.publishOrderUpdate(ctx, order) })
+
 	return nil
 }
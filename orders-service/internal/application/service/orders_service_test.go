@@ -4,16 +4,21 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-redis/redismock/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
-	"orders-service/internal/domain/inbox"
+	"contracts/events"
+	"contracts/money"
+	"messaging/inbox"
+	"messaging/outbox"
 	"orders-service/internal/domain/orders"
-	"orders-service/internal/domain/outbox"
+	"orders-service/internal/domain/products"
 	"orders-service/internal/infrastructure/pubsub/redis"
 )
 
@@ -40,6 +45,14 @@ func (m *MockOrdersRepository) GetByID(ctx context.Context, orderID string) (*or
 	return args.Get(0).(*orders.Order), args.Error(1)
 }
 
+func (m *MockOrdersRepository) GetByIDWithTx(ctx context.Context, tx *sql.Tx, orderID string) (*orders.Order, error) {
+	args := m.Called(ctx, tx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*orders.Order), args.Error(1)
+}
+
 func (m *MockOrdersRepository) GetByUserID(ctx context.Context, userID string) ([]*orders.Order, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -67,13 +80,18 @@ type MockOutboxRepository struct {
 	mock.Mock
 }
 
-func (m *MockOutboxRepository) StoreMessage(ctx context.Context, tx *sql.Tx, message *outbox.OutboxMessage) error {
+func (m *MockOutboxRepository) Store(ctx context.Context, message *outbox.OutboxMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, message *outbox.OutboxMessage) error {
 	args := m.Called(ctx, tx, message)
 	return args.Error(0)
 }
 
-func (m *MockOutboxRepository) GetPendingMessages(ctx context.Context, limit int) ([]*outbox.OutboxMessage, error) {
-	args := m.Called(ctx, limit)
+func (m *MockOutboxRepository) ClaimPendingMessages(ctx context.Context, limit int, lease time.Duration) ([]*outbox.OutboxMessage, error) {
+	args := m.Called(ctx, limit, lease)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -85,26 +103,81 @@ func (m *MockOutboxRepository) MarkAsSent(ctx context.Context, messageID string)
 	return args.Error(0)
 }
 
-func (m *MockOutboxRepository) MarkAsFailed(ctx context.Context, messageID string) error {
-	args := m.Called(ctx, messageID)
+func (m *MockOutboxRepository) MarkAsFailed(ctx context.Context, messageID string, reason string, nextAttemptAt time.Time) error {
+	args := m.Called(ctx, messageID, reason, nextAttemptAt)
 	return args.Error(0)
 }
 
-func (m *MockOutboxRepository) GetFailedMessages(ctx context.Context, maxRetries int, limit int) ([]*outbox.OutboxMessage, error) {
-	args := m.Called(ctx, maxRetries, limit)
+func (m *MockOutboxRepository) MarkAsDeadLetter(ctx context.Context, messageID string, reason string) error {
+	args := m.Called(ctx, messageID, reason)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) ClaimFailedMessages(ctx context.Context, limit int, lease time.Duration) ([]*outbox.OutboxMessage, error) {
+	args := m.Called(ctx, limit, lease)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*outbox.OutboxMessage), args.Error(1)
 }
 
-type MockRandomGenerator struct {
+type MockRejectedTransitionsRepository struct {
+	mock.Mock
+}
+
+func (m *MockRejectedTransitionsRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, transition *orders.RejectedTransition) error {
+	args := m.Called(ctx, tx, transition)
+	return args.Error(0)
+}
+
+type MockProductsRepository struct {
+	mock.Mock
+}
+
+func (m *MockProductsRepository) Store(ctx context.Context, product *products.Product) error {
+	args := m.Called(ctx, product)
+	return args.Error(0)
+}
+
+func (m *MockProductsRepository) GetByID(ctx context.Context, productID string) (*products.Product, error) {
+	args := m.Called(ctx, productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*products.Product), args.Error(1)
+}
+
+func (m *MockProductsRepository) GetByIDs(ctx context.Context, productIDs []string) ([]*products.Product, error) {
+	args := m.Called(ctx, productIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*products.Product), args.Error(1)
+}
+
+func (m *MockProductsRepository) List(ctx context.Context) ([]*products.Product, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*products.Product), args.Error(1)
+}
+
+type MockOrderItemsRepository struct {
 	mock.Mock
 }
 
-func (m *MockRandomGenerator) FloatWithPrecision(min, max float64) (float64, error) {
-	args := m.Called(min, max)
-	return args.Get(0).(float64), args.Error(1)
+func (m *MockOrderItemsRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, items []*orders.OrderItem) error {
+	args := m.Called(ctx, tx, items)
+	return args.Error(0)
+}
+
+func (m *MockOrderItemsRepository) GetByOrderIDs(ctx context.Context, orderIDs []string) ([]*orders.OrderItem, error) {
+	args := m.Called(ctx, orderIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*orders.OrderItem), args.Error(1)
 }
 
 func TestOrdersService_CreateOrder(t *testing.T) {
@@ -118,34 +191,70 @@ func TestOrdersService_CreateOrder(t *testing.T) {
 
 	mockOrdersRepo := new(MockOrdersRepository)
 	mockOutboxRepo := new(MockOutboxRepository)
-	mockRandomGen := new(MockRandomGenerator)
+	mockProductsRepo := new(MockProductsRepository)
+	mockOrderItemsRepo := new(MockOrderItemsRepository)
 	redisPublisher := redis.NewPublisher(redisClient, &redis.Config{Channel: "test"})
 
-	service := NewOrdersService(mockOrdersRepo, mockOutboxRepo, mockRandomGen, redisPublisher, db)
+	service := NewOrdersService(mockOrdersRepo, mockOrderItemsRepo, mockProductsRepo, mockOutboxRepo, nil, redisPublisher, db)
 
 	userID := "test-user"
-	expectedAmount := 123.45
 	ctx := context.Background()
 
-	mockRandomGen.On("FloatWithPrecision", 100.0, 1000.0).Return(expectedAmount, nil)
+	keyboard := &products.Product{ID: "product-1", Name: "Keyboard", Price: money.New(4999, money.USD), Active: true}
+	mouse := &products.Product{ID: "product-2", Name: "Mouse", Price: money.New(1010, money.USD), Active: true}
+	lineItems := []orders.LineItem{
+		{ProductID: "product-1", Quantity: 1},
+		{ProductID: "product-2", Quantity: 3},
+		{ProductID: "product-1", Quantity: 1},
+	}
+
+	mockProductsRepo.On("GetByIDs", ctx, []string{"product-1", "product-2"}).Return([]*products.Product{keyboard, mouse}, nil)
 	mockSQL.ExpectBegin()
 	mockOrdersRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*orders.Order")).Return(nil)
-	mockOutboxRepo.On("StoreMessage", ctx, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Return(nil)
+	mockOrderItemsRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("[]*orders.OrderItem")).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Run(func(args mock.Arguments) {
+		msg := args.Get(2).(*outbox.OutboxMessage)
+		var event events.OrderCreatedEvent
+		assert.NoError(t, json.Unmarshal(msg.Payload, &event))
+		assert.Equal(t, money.New(13028, money.USD), event.AmountMoney)
+		assert.Equal(t, 130.28, event.Amount)
+		assert.Len(t, event.Items, 2)
+		assert.Equal(t, "product-1", event.Items[0].ProductID)
+		assert.Equal(t, 2, event.Items[0].Quantity)
+	}).Return(nil)
 	mockSQL.ExpectCommit()
 	redisMock.ExpectPublish("test", mock.Anything).SetVal(0)
 
-	order, err := service.CreateOrder(ctx, userID)
+	order, err := service.CreateOrder(ctx, userID, lineItems)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, order)
 	assert.Equal(t, userID, order.UserID)
-	assert.Equal(t, expectedAmount, order.Amount)
+	assert.Equal(t, money.New(13028, money.USD), order.Amount)
+	assert.Len(t, order.Items, 2)
 	mockOrdersRepo.AssertExpectations(t)
+	mockOrderItemsRepo.AssertExpectations(t)
 	mockOutboxRepo.AssertExpectations(t)
-	mockRandomGen.AssertExpectations(t)
+	mockProductsRepo.AssertExpectations(t)
 	assert.NoError(t, mockSQL.ExpectationsWereMet())
 }
 
+func TestOrdersService_CreateOrder_UnknownProduct(t *testing.T) {
+	mockProductsRepo := new(MockProductsRepository)
+
+	service := NewOrdersService(nil, nil, mockProductsRepo, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	mockProductsRepo.On("GetByIDs", ctx, []string{"missing"}).Return([]*products.Product{}, nil)
+
+	_, err := service.CreateOrder(ctx, "test-user", []orders.LineItem{{ProductID: "missing", Quantity: 1}})
+
+	assert.ErrorIs(t, err, products.ErrProductNotFound)
+
+	_, err = service.CreateOrder(ctx, "test-user", nil)
+	assert.ErrorIs(t, err, orders.ErrEmptyOrder)
+}
+
 func TestOrdersService_ProcessPaymentCompleted(t *testing.T) {
 	db, mockSQL, err := sqlmock.New()
 	if err != nil {
@@ -159,14 +268,14 @@ func TestOrdersService_ProcessPaymentCompleted(t *testing.T) {
 	mockOutboxRepo := new(MockOutboxRepository)
 	redisPublisher := redis.NewPublisher(redisClient, &redis.Config{Channel: "test"})
 
-	service := NewOrdersService(mockOrdersRepo, mockOutboxRepo, nil, redisPublisher, db)
+	service := NewOrdersService(mockOrdersRepo, nil, nil, mockOutboxRepo, nil, redisPublisher, db)
 
 	ctx := context.Background()
 	orderID := "test-order-id"
 	paymentID := "test-payment-id"
 	transactionID := "test-transaction-id"
 
-	paymentEvent := inbox.PaymentCompletedEvent{
+	paymentEvent := events.PaymentCompletedEvent{
 		OrderID:       orderID,
 		PaymentID:     paymentID,
 		TransactionID: transactionID,
@@ -177,21 +286,21 @@ func TestOrdersService_ProcessPaymentCompleted(t *testing.T) {
 		Payload: payload,
 	}
 
-	order, _ := orders.NewOrder("test-user", 100)
+	order, _ := orders.NewOrder("test-user", money.New(10000, money.USD))
 	order.ID = orderID
 
 	mockSQL.ExpectBegin()
-	mockOrdersRepo.On("GetByID", ctx, orderID).Return(order, nil)
+	mockOrdersRepo.On("GetByIDWithTx", ctx, mock.Anything, orderID).Return(order, nil)
 	mockOrdersRepo.On("UpdateWithTx", ctx, mock.Anything, mock.AnythingOfType("*orders.Order")).Run(func(args mock.Arguments) {
 		arg := args.Get(2).(*orders.Order)
 		assert.Equal(t, orders.OrderStatusPaid, arg.Status)
 		assert.Equal(t, paymentID, arg.PaymentID)
 	}).Return(nil)
-	mockOutboxRepo.On("StoreMessage", ctx, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Return(nil)
-	mockSQL.ExpectCommit()
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Return(nil)
 	redisMock.ExpectPublish("test", mock.Anything).SetVal(0)
 
-	err = service.ProcessPaymentCompleted(ctx, inboxMsg)
+	tx, _ := db.Begin()
+	err = service.ProcessPaymentCompleted(ctx, tx, inboxMsg)
 
 	assert.NoError(t, err)
 	mockOrdersRepo.AssertExpectations(t)
@@ -199,6 +308,43 @@ func TestOrdersService_ProcessPaymentCompleted(t *testing.T) {
 	assert.NoError(t, mockSQL.ExpectationsWereMet())
 }
 
+// Уведомление SSE уходит только после фиксации транзакции inbox
+func TestOrdersService_ProcessPaymentCompleted_PublishesAfterCommit(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	redisClient, redisMock := redismock.NewClientMock()
+
+	mockOrdersRepo := new(MockOrdersRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	redisPublisher := redis.NewPublisher(redisClient, &redis.Config{Channel: "test"})
+
+	service := NewOrdersService(mockOrdersRepo, nil, nil, mockOutboxRepo, nil, redisPublisher, db)
+
+	ctx, runAfterCommit := inbox.WithAfterCommit(context.Background())
+	payload, _ := json.Marshal(events.PaymentCompletedEvent{OrderID: "order-1", PaymentID: "payment-1"})
+	inboxMsg := &inbox.InboxMessage{ID: "inbox-1", Payload: payload}
+
+	order, _ := orders.NewOrder("user-1", money.New(10000, money.USD))
+	order.ID = "order-1"
+
+	mockSQL.ExpectBegin()
+	mockOrdersRepo.On("GetByIDWithTx", ctx, mock.Anything, "order-1").Return(order, nil)
+	mockOrdersRepo.On("UpdateWithTx", ctx, mock.Anything, mock.AnythingOfType("*orders.Order")).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Return(nil)
+	redisMock.CustomMatch(func(expected, actual []interface{}) error { return nil }).ExpectPublish("test", nil).SetVal(0)
+
+	tx, _ := db.Begin()
+	assert.NoError(t, service.ProcessPaymentCompleted(ctx, tx, inboxMsg))
+	assert.Error(t, redisMock.ExpectationsWereMet())
+
+	runAfterCommit()
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
 func TestOrdersService_ProcessPaymentFailed(t *testing.T) {
 	db, mockSQL, err := sqlmock.New()
 	if err != nil {
@@ -212,14 +358,14 @@ func TestOrdersService_ProcessPaymentFailed(t *testing.T) {
 	mockOutboxRepo := new(MockOutboxRepository)
 	redisPublisher := redis.NewPublisher(redisClient, &redis.Config{Channel: "test"})
 
-	service := NewOrdersService(mockOrdersRepo, mockOutboxRepo, nil, redisPublisher, db)
+	service := NewOrdersService(mockOrdersRepo, nil, nil, mockOutboxRepo, nil, redisPublisher, db)
 
 	ctx := context.Background()
 	orderID := "test-order-id"
 	paymentID := "test-payment-id"
 	errorMessage := "payment provider error"
 
-	paymentEvent := inbox.PaymentFailedEvent{
+	paymentEvent := events.PaymentFailedEvent{
 		OrderID:      orderID,
 		PaymentID:    paymentID,
 		ErrorMessage: errorMessage,
@@ -230,22 +376,429 @@ func TestOrdersService_ProcessPaymentFailed(t *testing.T) {
 		Payload: payload,
 	}
 
-	order, _ := orders.NewOrder("test-user", 100)
+	order, _ := orders.NewOrder("test-user", money.New(10000, money.USD))
 	order.ID = orderID
 
 	mockSQL.ExpectBegin()
-	mockOrdersRepo.On("GetByID", ctx, orderID).Return(order, nil)
+	mockOrdersRepo.On("GetByIDWithTx", ctx, mock.Anything, orderID).Return(order, nil)
 	mockOrdersRepo.On("UpdateWithTx", ctx, mock.Anything, mock.AnythingOfType("*orders.Order")).Run(func(args mock.Arguments) {
 		arg := args.Get(2).(*orders.Order)
 		assert.Equal(t, orders.OrderStatusPaymentFailed, arg.Status)
 		assert.NotNil(t, arg.ErrorReason)
 		assert.Equal(t, errorMessage, arg.ErrorReason)
 	}).Return(nil)
-	mockOutboxRepo.On("StoreMessage", ctx, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Return(nil)
+	redisMock.ExpectPublish("test", mock.Anything).SetVal(0)
+
+	tx, _ := db.Begin()
+	err = service.ProcessPaymentFailed(ctx, tx, inboxMsg)
+
+	assert.NoError(t, err)
+	mockOrdersRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestOrdersService_ProcessPaymentFailed_AfterPaid(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mockOrdersRepo := new(MockOrdersRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	mockRejectedRepo := new(MockRejectedTransitionsRepository)
+
+	service := NewOrdersService(mockOrdersRepo, nil, nil, mockOutboxRepo, mockRejectedRepo, nil, db)
+
+	ctx := context.Background()
+
+	order, _ := orders.NewOrder("test-user", money.New(10000, money.USD))
+	order.MarkPaid("test-payment-id")
+
+	payload, _ := json.Marshal(events.PaymentFailedEvent{
+		OrderID:      order.ID,
+		PaymentID:    "test-payment-id",
+		ErrorMessage: "duplicate failure",
+	})
+	inboxMsg := &inbox.InboxMessage{
+		ID:        "test-inbox-id",
+		EventID:   "test-event-id",
+		EventType: "payment.failed",
+		Payload:   payload,
+	}
+
+	mockSQL.ExpectBegin()
+	mockOrdersRepo.On("GetByIDWithTx", ctx, mock.Anything, order.ID).Return(order, nil)
+	mockRejectedRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*orders.RejectedTransition")).Run(func(args mock.Arguments) {
+		arg := args.Get(2).(*orders.RejectedTransition)
+		assert.Equal(t, order.ID, arg.OrderID)
+		assert.Equal(t, orders.OrderStatusPaid, arg.FromStatus)
+		assert.Equal(t, orders.OrderStatusPaymentFailed, arg.ToStatus)
+		assert.Equal(t, "payment.failed", arg.EventType)
+		assert.Equal(t, "test-event-id", arg.EventID)
+	}).Return(nil)
+
+	tx, _ := db.Begin()
+	err = service.ProcessPaymentFailed(ctx, tx, inboxMsg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, orders.OrderStatusPaid, order.Status)
+	mockOrdersRepo.AssertNotCalled(t, "UpdateWithTx", mock.Anything, mock.Anything, mock.Anything)
+	mockOutboxRepo.AssertNotCalled(t, "StoreWithTx", mock.Anything, mock.Anything, mock.Anything)
+	mockRejectedRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestOrdersService_CancelOrder_Created(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	redisClient, redisMock := redismock.NewClientMock()
+
+	mockOrdersRepo := new(MockOrdersRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	redisPublisher := redis.NewPublisher(redisClient, &redis.Config{Channel: "test"})
+
+	service := NewOrdersService(mockOrdersRepo, nil, nil, mockOutboxRepo, nil, redisPublisher, db)
+
+	ctx := context.Background()
+	order, _ := orders.NewOrder("test-user", money.New(10000, money.USD))
+
+	mockSQL.ExpectBegin()
+	mockOrdersRepo.On("GetByIDWithTx", ctx, mock.Anything, order.ID).Return(order, nil)
+	mockOrdersRepo.On("UpdateWithTx", ctx, mock.Anything, order).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
+		return msg.EventType == "order.cancelled"
+	})).Return(nil)
+	mockSQL.ExpectCommit()
+	redisMock.ExpectPublish("test", mock.Anything).SetVal(0)
+
+	cancelled, err := service.CancelOrder(ctx, order.ID, "changed my mind")
+
+	assert.NoError(t, err)
+	assert.Equal(t, orders.OrderStatusCancelled, cancelled.Status)
+	assert.Equal(t, "changed my mind", cancelled.ErrorReason)
+	mockOrdersRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestOrdersService_CancelOrder_PaidWaitsForRefund(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	redisClient, redisMock := redismock.NewClientMock()
+
+	mockOrdersRepo := new(MockOrdersRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	redisPublisher := redis.NewPublisher(redisClient, &redis.Config{Channel: "test"})
+
+	service := NewOrdersService(mockOrdersRepo, nil, nil, mockOutboxRepo, nil, redisPublisher, db)
+
+	ctx := context.Background()
+	order, _ := orders.NewOrder("test-user", money.New(10000, money.USD))
+	order.MarkPaid("test-payment-id")
+
+	mockSQL.ExpectBegin()
+	mockOrdersRepo.On("GetByIDWithTx", ctx, mock.Anything, order.ID).Return(order, nil)
+	mockOrdersRepo.On("UpdateWithTx", ctx, mock.Anything, order).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
+		var event events.OrderCancelledEvent
+		_ = json.Unmarshal(msg.Payload, &event)
+		return msg.EventType == "order.cancelled" && event.PaymentID == "test-payment-id" && event.AmountMoney == money.New(10000, money.USD)
+	})).Return(nil)
+	mockSQL.ExpectCommit()
+	redisMock.ExpectPublish("test", mock.Anything).SetVal(0)
+
+	cancelled, err := service.CancelOrder(ctx, order.ID, "changed my mind")
+
+	assert.NoError(t, err)
+	assert.Equal(t, orders.OrderStatusCancelling, cancelled.Status)
+	mockOrdersRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestOrdersService_CancelOrder_NotCancellable(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mockOrdersRepo := new(MockOrdersRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	service := NewOrdersService(mockOrdersRepo, nil, nil, mockOutboxRepo, nil, nil, db)
+
+	ctx := context.Background()
+	order, _ := orders.NewOrder("test-user", money.New(10000, money.USD))
+	order.MarkPaid("test-payment-id")
+	order.MarkCompleted()
+
+	mockSQL.ExpectBegin()
+	mockOrdersRepo.On("GetByIDWithTx", ctx, mock.Anything, order.ID).Return(order, nil)
+	mockSQL.ExpectRollback()
+
+	_, err = service.CancelOrder(ctx, order.ID, "too late")
+
+	assert.True(t, errors.Is(err, orders.ErrOrderNotCancellable))
+	mockOutboxRepo.AssertNotCalled(t, "StoreWithTx")
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestOrdersService_ProcessPaymentRefunded(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	redisClient, redisMock := redismock.NewClientMock()
+
+	mockOrdersRepo := new(MockOrdersRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	redisPublisher := redis.NewPublisher(redisClient, &redis.Config{Channel: "test"})
+
+	service := NewOrdersService(mockOrdersRepo, nil, nil, mockOutboxRepo, nil, redisPublisher, db)
+
+	ctx := context.Background()
+	order, _ := orders.NewOrder("test-user", money.New(10000, money.USD))
+	order.MarkPaid("test-payment-id")
+	order.MarkCancelling("changed my mind")
+
+	refundEvent := events.PaymentRefundedEvent{
+		PaymentID:   "test-payment-id",
+		OrderID:     order.ID,
+		AmountMoney: money.New(10000, money.USD),
+	}
+	payload, _ := json.Marshal(refundEvent)
+	inboxMsg := &inbox.InboxMessage{ID: "test-inbox-id", Payload: payload}
+
+	mockSQL.ExpectBegin()
+	mockOrdersRepo.On("GetByIDWithTx", ctx, mock.Anything, order.ID).Return(order, nil)
+	mockOrdersRepo.On("UpdateWithTx", ctx, mock.Anything, mock.AnythingOfType("*orders.Order")).Run(func(args mock.Arguments) {
+		arg := args.Get(2).(*orders.Order)
+		assert.Equal(t, orders.OrderStatusCancelled, arg.Status)
+		assert.Equal(t, "changed my mind", arg.ErrorReason)
+	}).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Return(nil)
+	redisMock.ExpectPublish("test", mock.Anything).SetVal(0)
+
+	tx, _ := db.Begin()
+	err = service.ProcessPaymentRefunded(ctx, tx, inboxMsg)
+
+	assert.NoError(t, err)
+	mockOrdersRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestOrdersService_ProcessPaymentAuthorized(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	redisClient, redisMock := redismock.NewClientMock()
+
+	mockOrdersRepo := new(MockOrdersRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	redisPublisher := redis.NewPublisher(redisClient, &redis.Config{Channel: "test"})
+
+	service := NewOrdersService(mockOrdersRepo, nil, nil, mockOutboxRepo, nil, redisPublisher, db)
+
+	ctx := context.Background()
+	order, _ := orders.NewOrder("test-user", money.New(10000, money.USD))
+
+	authorizedEvent := events.PaymentAuthorizedEvent{
+		PaymentID:     "test-payment-id",
+		OrderID:       order.ID,
+		AmountMoney:   money.New(10000, money.USD),
+		HoldExpiresAt: time.Now().Add(30 * time.Minute),
+	}
+	payload, _ := json.Marshal(authorizedEvent)
+	inboxMsg := &inbox.InboxMessage{ID: "test-inbox-id", Payload: payload}
+
+	mockSQL.ExpectBegin()
+	mockOrdersRepo.On("GetByIDWithTx", ctx, mock.Anything, order.ID).Return(order, nil)
+	mockOrdersRepo.On("UpdateWithTx", ctx, mock.Anything, mock.AnythingOfType("*orders.Order")).Run(func(args mock.Arguments) {
+		arg := args.Get(2).(*orders.Order)
+		assert.Equal(t, orders.OrderStatusPaid, arg.Status)
+		assert.Equal(t, "test-payment-id", arg.PaymentID)
+	}).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Return(nil)
+	redisMock.ExpectPublish("test", mock.Anything).SetVal(0)
+
+	tx, _ := db.Begin()
+	err = service.ProcessPaymentAuthorized(ctx, tx, inboxMsg)
+
+	assert.NoError(t, err)
+	mockOrdersRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestOrdersService_CompleteOrder(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	redisClient, redisMock := redismock.NewClientMock()
+
+	mockOrdersRepo := new(MockOrdersRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	redisPublisher := redis.NewPublisher(redisClient, &redis.Config{Channel: "test"})
+
+	service := NewOrdersService(mockOrdersRepo, nil, nil, mockOutboxRepo, nil, redisPublisher, db)
+
+	ctx := context.Background()
+	order, _ := orders.NewOrder("test-user", money.New(10000, money.USD))
+	order.MarkPaid("test-payment-id")
+
+	mockSQL.ExpectBegin()
+	mockOrdersRepo.On("GetByIDWithTx", ctx, mock.Anything, order.ID).Return(order, nil)
+	mockOrdersRepo.On("UpdateWithTx", ctx, mock.Anything, order).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
+		return msg.EventType == "order.completed"
+	})).Return(nil)
 	mockSQL.ExpectCommit()
 	redisMock.ExpectPublish("test", mock.Anything).SetVal(0)
 
-	err = service.ProcessPaymentFailed(ctx, inboxMsg)
+	completed, err := service.CompleteOrder(ctx, order.ID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, orders.OrderStatusCompleted, completed.Status)
+	mockOrdersRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestOrdersService_CompleteOrder_NotPaid(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mockOrdersRepo := new(MockOrdersRepository)
+	service := NewOrdersService(mockOrdersRepo, nil, nil, nil, nil, nil, db)
+
+	ctx := context.Background()
+	order, _ := orders.NewOrder("test-user", money.New(10000, money.USD))
+
+	mockSQL.ExpectBegin()
+	mockOrdersRepo.On("GetByIDWithTx", ctx, mock.Anything, order.ID).Return(order, nil)
+	mockSQL.ExpectRollback()
+
+	_, err = service.CompleteOrder(ctx, order.ID)
+
+	assert.ErrorIs(t, err, orders.ErrOrderNotCompletable)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestOrdersService_ProcessPaymentReleased_HoldExpired(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	redisClient, redisMock := redismock.NewClientMock()
+
+	mockOrdersRepo := new(MockOrdersRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	redisPublisher := redis.NewPublisher(redisClient, &redis.Config{Channel: "test"})
+
+	service := NewOrdersService(mockOrdersRepo, nil, nil, mockOutboxRepo, nil, redisPublisher, db)
+
+	ctx := context.Background()
+	order, _ := orders.NewOrder("test-user", money.New(10000, money.USD))
+	order.MarkPaid("test-payment-id")
+
+	releasedEvent := events.PaymentReleasedEvent{
+		PaymentID:   "test-payment-id",
+		OrderID:     order.ID,
+		AmountMoney: money.New(10000, money.USD),
+		Reason:      "authorization expired",
+	}
+	payload, _ := json.Marshal(releasedEvent)
+	inboxMsg := &inbox.InboxMessage{ID: "test-inbox-id", Payload: payload}
+
+	mockSQL.ExpectBegin()
+	mockOrdersRepo.On("GetByIDWithTx", ctx, mock.Anything, order.ID).Return(order, nil)
+	mockOrdersRepo.On("UpdateWithTx", ctx, mock.Anything, mock.AnythingOfType("*orders.Order")).Run(func(args mock.Arguments) {
+		arg := args.Get(2).(*orders.Order)
+		// Отмену никто не запрашивал: заказ остаётся оплаченным, причина сохраняется
+		assert.Equal(t, orders.OrderStatusPaid, arg.Status)
+		assert.Equal(t, "authorization expired", arg.ErrorReason)
+	}).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
+		return msg.EventType == "order.updated"
+	})).Return(nil)
+	redisMock.ExpectPublish("test", mock.Anything).SetVal(0)
+
+	tx, _ := db.Begin()
+	err = service.ProcessPaymentReleased(ctx, tx, inboxMsg)
+
+	assert.NoError(t, err)
+	mockOrdersRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestOrdersService_ProcessPaymentReleased_UserCancel(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	redisClient, redisMock := redismock.NewClientMock()
+
+	mockOrdersRepo := new(MockOrdersRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	redisPublisher := redis.NewPublisher(redisClient, &redis.Config{Channel: "test"})
+
+	service := NewOrdersService(mockOrdersRepo, nil, nil, mockOutboxRepo, nil, redisPublisher, db)
+
+	ctx := context.Background()
+	order, _ := orders.NewOrder("test-user", money.New(10000, money.USD))
+	order.MarkPaid("test-payment-id")
+	order.MarkCancelling("changed my mind")
+
+	releasedEvent := events.PaymentReleasedEvent{
+		PaymentID:   "test-payment-id",
+		OrderID:     order.ID,
+		AmountMoney: money.New(10000, money.USD),
+		Reason:      "changed my mind",
+	}
+	payload, _ := json.Marshal(releasedEvent)
+	inboxMsg := &inbox.InboxMessage{ID: "test-inbox-id", Payload: payload}
+
+	mockSQL.ExpectBegin()
+	mockOrdersRepo.On("GetByIDWithTx", ctx, mock.Anything, order.ID).Return(order, nil)
+	mockOrdersRepo.On("UpdateWithTx", ctx, mock.Anything, mock.AnythingOfType("*orders.Order")).Run(func(args mock.Arguments) {
+		arg := args.Get(2).(*orders.Order)
+		assert.Equal(t, orders.OrderStatusCancelled, arg.Status)
+		assert.Equal(t, "changed my mind", arg.ErrorReason)
+	}).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Return(nil)
+	redisMock.ExpectPublish("test", mock.Anything).SetVal(0)
+
+	tx, _ := db.Begin()
+	err = service.ProcessPaymentReleased(ctx, tx, inboxMsg)
 
 	assert.NoError(t, err)
 	mockOrdersRepo.AssertExpectations(t)
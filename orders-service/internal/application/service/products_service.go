@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"contracts/money"
+	"orders-service/internal/domain/products"
+	"orders-service/internal/interfaces/repository"
+)
+
+type ProductsService struct {
+	productsRepository repository.ProductsRepository
+}
+
+func NewProductsService(productsRepository repository.ProductsRepository) *ProductsService {
+	return &ProductsService{
+		productsRepository: productsRepository,
+	}
+}
+
+func (s *ProductsService) CreateProduct(ctx context.Context, name, description string, price money.Money) (*products.Product, error) {
+	product, err := products.NewProduct(name, description, price)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.productsRepository.Store(ctx, product); err != nil {
+		return nil, fmt.Errorf("failed to store product: %w", err)
+	}
+
+	log.Printf("Product created: ProductID=%s, Name=%s, Price=%s",
+		product.ID, product.Name, product.Price)
+
+	return product, nil
+}
+
+func (s *ProductsService) GetProduct(ctx context.Context, productID string) (*products.Product, error) {
+	return s.productsRepository.GetByID(ctx, productID)
+}
+
+func (s *ProductsService) ListProducts(ctx context.Context) ([]*products.Product, error) {
+	return s.productsRepository.List(ctx)
+}
@@ -0,0 +1,96 @@
+package orders
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+
+	"contracts/money"
+)
+
+var (
+	ErrEmptyOrder      = errors.New("order must contain at least one item")
+	ErrInvalidQuantity = errors.New("item quantity must be positive")
+	ErrMixedCurrencies = errors.New("order items must share one currency")
+)
+
+// LineItem — позиция из запроса на создание заказа, цена берётся из каталога.
+type LineItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+type OrderItem struct {
+	ID          string      `json:"id"`
+	OrderID     string      `json:"orderID"`
+	ProductID   string      `json:"productID"`
+	ProductName string      `json:"productName"`
+	Quantity    int         `json:"quantity"`
+	UnitPrice   money.Money `json:"unitPriceMoney"`
+	Amount      money.Money `json:"amountMoney"`
+}
+
+func NewOrderItem(productID, productName string, quantity int, unitPrice money.Money) (*OrderItem, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("%w: product %s", ErrInvalidQuantity, productID)
+	}
+
+	v7, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderItem{
+		ID:          v7.String(),
+		ProductID:   productID,
+		ProductName: productName,
+		Quantity:    quantity,
+		UnitPrice:   unitPrice,
+		Amount:      unitPrice.Multiply(int64(quantity)),
+	}, nil
+}
+
+// MarshalJSON дополнительно отдаёт устаревшие float-поля unitPrice/amount.
+func (i OrderItem) MarshalJSON() ([]byte, error) {
+	type orderItem OrderItem
+	return json.Marshal(struct {
+		orderItem
+		LegacyUnitPrice float64 `json:"unitPrice"`
+		LegacyAmount    float64 `json:"amount"`
+	}{
+		orderItem:       orderItem(i),
+		LegacyUnitPrice: i.UnitPrice.Float64(),
+		LegacyAmount:    i.Amount.Float64(),
+	})
+}
+
+// NewOrderWithItems создаёт заказ, сумма которого считается по позициям.
+// Все позиции должны быть в одной валюте.
+func NewOrderWithItems(userID string, items []*OrderItem) (*Order, error) {
+	if len(items) == 0 {
+		return nil, ErrEmptyOrder
+	}
+
+	amount := money.Zero(items[0].Amount.Currency())
+	for _, item := range items {
+		var err error
+		amount, err = amount.Add(item.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMixedCurrencies, err)
+		}
+	}
+
+	order, err := NewOrder(userID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		item.OrderID = order.ID
+	}
+	order.Items = items
+
+	return order, nil
+}
@@ -1,9 +1,13 @@
 package orders
 
 import (
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/gofrs/uuid"
+
+	"contracts/money"
 )
 
 type OrderStatus string
@@ -14,22 +18,36 @@ const (
 	OrderStatusPaid           OrderStatus = "paid"
 	OrderStatusPaymentFailed  OrderStatus = "payment_failed"
 	OrderStatusCompleted      OrderStatus = "completed"
+	OrderStatusCancelling     OrderStatus = "cancelling"
 	OrderStatusCancelled      OrderStatus = "cancelled"
 )
 
+// AuthorizationExpiredReason — причина по умолчанию, когда платёжный сервис снял блокировку по сроку
+const AuthorizationExpiredReason = "payment authorization expired"
+
+var (
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrOrderNotCancellable = errors.New("order cannot be cancelled")
+	ErrOrderNotCompletable = errors.New("order cannot be completed")
+)
+
 type Order struct {
-	ID          string      `json:"id"`
-	UserID      string      `json:"userID"`
-	Amount      float64     `json:"amount"`
-	Currency    string      `json:"currency"`
-	Status      OrderStatus `json:"status"`
-	PaymentID   string      `json:"paymentID"`
-	ErrorReason string      `json:"errorReason"`
-	CreatedAt   time.Time   `json:"createdAt"`
-	UpdatedAt   time.Time   `json:"updatedAt"`
-}
-
-func NewOrder(userID string, amount float64) (*Order, error) {
+	ID          string       `json:"id"`
+	UserID      string       `json:"userID"`
+	Amount      money.Money  `json:"amountMoney"`
+	Status      OrderStatus  `json:"status"`
+	PaymentID   string       `json:"paymentID"`
+	ErrorReason string       `json:"errorReason"`
+	Items       []*OrderItem `json:"items"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+}
+
+func NewOrder(userID string, amount money.Money) (*Order, error) {
+	if err := money.ValidateCurrency(amount.Currency()); err != nil {
+		return nil, err
+	}
+
 	v7, err := uuid.NewV7()
 	if err != nil {
 		return nil, err
@@ -39,37 +57,82 @@ func NewOrder(userID string, amount float64) (*Order, error) {
 		ID:        v7.String(),
 		UserID:    userID,
 		Amount:    amount,
-		Currency:  "USD",
 		Status:    OrderStatusCreated,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}, nil
 }
 
-func (o *Order) MarkPaymentPending() {
-	o.Status = OrderStatusPaymentPending
+// MarshalJSON дополнительно отдаёт устаревшие поля amount/currency,
+// чтобы старые клиенты продолжали работать до перехода на amountMoney.
+func (o Order) MarshalJSON() ([]byte, error) {
+	type order Order
+	return json.Marshal(struct {
+		order
+		LegacyAmount float64 `json:"amount"`
+		Currency     string  `json:"currency"`
+	}{
+		order:        order(o),
+		LegacyAmount: o.Amount.Float64(),
+		Currency:     o.Amount.Currency(),
+	})
+}
+
+func (o *Order) MarkPaymentPending() error {
+	if err := o.transitionTo(OrderStatusPaymentPending); err != nil {
+		return err
+	}
 	o.UpdatedAt = time.Now()
+	return nil
 }
 
-func (o *Order) MarkPaid(paymentID string) {
-	o.Status = OrderStatusPaid
+func (o *Order) MarkPaid(paymentID string) error {
+	if err := o.transitionTo(OrderStatusPaid); err != nil {
+		return err
+	}
 	o.PaymentID = paymentID
 	o.UpdatedAt = time.Now()
+	return nil
 }
 
-func (o *Order) MarkPaymentFailed(reason string) {
-	o.Status = OrderStatusPaymentFailed
+func (o *Order) MarkPaymentFailed(reason string) error {
+	if err := o.transitionTo(OrderStatusPaymentFailed); err != nil {
+		return err
+	}
 	o.ErrorReason = reason
 	o.UpdatedAt = time.Now()
+	return nil
 }
 
-func (o *Order) MarkCompleted() {
-	o.Status = OrderStatusCompleted
+func (o *Order) MarkCompleted() error {
+	if err := o.transitionTo(OrderStatusCompleted); err != nil {
+		return err
+	}
 	o.UpdatedAt = time.Now()
+	return nil
 }
 
-func (o *Order) MarkCancelled(reason string) {
-	o.Status = OrderStatusCancelled
+func (o *Order) MarkCancelling(reason string) error {
+	if err := o.transitionTo(OrderStatusCancelling); err != nil {
+		return err
+	}
+	o.ErrorReason = reason
+	o.UpdatedAt = time.Now()
+	return nil
+}
+
+func (o *Order) MarkCancelled(reason string) error {
+	if err := o.transitionTo(OrderStatusCancelled); err != nil {
+		return err
+	}
+	o.ErrorReason = reason
+	o.UpdatedAt = time.Now()
+	return nil
+}
+
+// RecordAuthorizationExpired сохраняет причину снятия блокировки оплаты по сроку. Статус
+// не меняется: оплаченный заказ можно выполнить (сумма будет заблокирована заново) или отменить
+func (o *Order) RecordAuthorizationExpired(reason string) {
 	o.ErrorReason = reason
 	o.UpdatedAt = time.Now()
 }
@@ -94,6 +157,23 @@ func (o *Order) IsCompleted() bool {
 	return o.Status == OrderStatusCompleted
 }
 
+func (o *Order) IsCancelling() bool {
+	return o.Status == OrderStatusCancelling
+}
+
 func (o *Order) IsCancelled() bool {
 	return o.Status == OrderStatusCancelled
 }
+
+func (o *Order) RequiresRefund() bool {
+	return o.Status == OrderStatusPaid
+}
+
+// CanBeCancelled сообщает, может ли пользователь запустить отмену заказа.
+// Заказ в статусе cancelling уже ждёт возврата средств и повторно не отменяется.
+func (o *Order) CanBeCancelled() bool {
+	if o.IsCancelling() {
+		return false
+	}
+	return o.CanTransitionTo(OrderStatusCancelled) || o.CanTransitionTo(OrderStatusCancelling)
+}
@@ -1,52 +1,111 @@
 package orders
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"contracts/money"
 )
 
 func TestOrder_StateTransitions(t *testing.T) {
-	order, err := NewOrder("user-123", 100.50)
+	order, err := NewOrder("user-123", money.New(10050, money.USD))
 	assert.NoError(t, err)
 	assert.True(t, order.IsCreated())
 	assert.Equal(t, OrderStatusCreated, order.Status)
 
-	order.MarkPaymentPending()
+	assert.NoError(t, order.MarkPaymentPending())
 	assert.True(t, order.IsPaymentPending())
 	assert.Equal(t, OrderStatusPaymentPending, order.Status)
 
-	order.MarkPaid("payment-abc")
+	assert.NoError(t, order.MarkPaid("payment-abc"))
 	assert.True(t, order.IsPaid())
 	assert.Equal(t, OrderStatusPaid, order.Status)
 	assert.Equal(t, "payment-abc", order.PaymentID)
 
-	order.MarkCompleted()
+	assert.NoError(t, order.MarkCompleted())
 	assert.True(t, order.IsCompleted())
 	assert.Equal(t, OrderStatusCompleted, order.Status)
 }
 
 func TestOrder_FailureAndCancellation(t *testing.T) {
-	order, err := NewOrder("user-123", 100.50)
+	order, err := NewOrder("user-123", money.New(10050, money.USD))
 	assert.NoError(t, err)
 
 	failReason := "insufficient funds"
-	order.MarkPaymentFailed(failReason)
+	assert.NoError(t, order.MarkPaymentFailed(failReason))
 	assert.True(t, order.IsPaymentFailed())
 	assert.Equal(t, OrderStatusPaymentFailed, order.Status)
 	assert.Equal(t, failReason, order.ErrorReason)
 
 	cancelReason := "user cancelled"
-	order.MarkCancelled(cancelReason)
+	assert.NoError(t, order.MarkCancelled(cancelReason))
 	assert.True(t, order.IsCancelled())
 	assert.Equal(t, OrderStatusCancelled, order.Status)
 	assert.Equal(t, cancelReason, order.ErrorReason)
 }
 
+func TestOrder_Cancellation(t *testing.T) {
+	order, err := NewOrder("user-123", money.New(10050, money.USD))
+	assert.NoError(t, err)
+	assert.True(t, order.CanBeCancelled())
+	assert.False(t, order.RequiresRefund())
+
+	assert.NoError(t, order.MarkPaid("payment-abc"))
+	assert.True(t, order.CanBeCancelled())
+	assert.True(t, order.RequiresRefund())
+
+	assert.NoError(t, order.MarkCancelling("changed my mind"))
+	assert.True(t, order.IsCancelling())
+	assert.False(t, order.CanBeCancelled())
+	assert.Equal(t, "changed my mind", order.ErrorReason)
+
+	assert.NoError(t, order.MarkCancelled(order.ErrorReason))
+	assert.True(t, order.IsCancelled())
+	assert.False(t, order.CanBeCancelled())
+
+	completed, _ := NewOrder("user-123", money.New(1000, money.USD))
+	assert.NoError(t, completed.MarkPaid("payment-def"))
+	assert.NoError(t, completed.MarkCompleted())
+	assert.False(t, completed.CanBeCancelled())
+}
+
+func TestOrder_InvalidTransitions(t *testing.T) {
+	order, err := NewOrder("user-123", money.New(10050, money.USD))
+	assert.NoError(t, err)
+
+	err = order.MarkCompleted()
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	assert.True(t, order.IsCreated())
+
+	assert.NoError(t, order.MarkPaid("payment-abc"))
+
+	err = order.MarkPaymentFailed("late failure")
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	assert.True(t, order.IsPaid())
+	assert.Empty(t, order.ErrorReason)
+
+	var transitionErr *InvalidTransitionError
+	assert.ErrorAs(t, err, &transitionErr)
+	assert.Equal(t, OrderStatusPaid, transitionErr.From)
+	assert.Equal(t, OrderStatusPaymentFailed, transitionErr.To)
+
+	assert.NoError(t, order.MarkCancelling("changed my mind"))
+	assert.NoError(t, order.MarkCancelled(order.ErrorReason))
+
+	err = order.MarkPaid("payment-abc")
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	assert.True(t, order.IsCancelled())
+
+	assert.True(t, CanTransition(OrderStatusPaymentPending, OrderStatusPaid))
+	assert.False(t, CanTransition(OrderStatusCompleted, OrderStatusCancelled))
+}
+
 func TestNewOrder(t *testing.T) {
 	userID := "user-456"
-	amount := 250.75
+	amount := money.New(25075, money.USD)
 	order, err := NewOrder(userID, amount)
 
 	assert.NoError(t, err)
@@ -54,8 +113,66 @@ func TestNewOrder(t *testing.T) {
 	assert.NotEmpty(t, order.ID)
 	assert.Equal(t, userID, order.UserID)
 	assert.Equal(t, amount, order.Amount)
-	assert.Equal(t, "USD", order.Currency)
+	assert.Equal(t, "USD", order.Amount.Currency())
 	assert.Equal(t, OrderStatusCreated, order.Status)
 	assert.WithinDuration(t, time.Now(), order.CreatedAt, time.Second)
 	assert.WithinDuration(t, time.Now(), order.UpdatedAt, time.Second)
 }
+
+func TestNewOrderWithItems(t *testing.T) {
+	keyboard, err := NewOrderItem("product-1", "Keyboard", 2, money.New(4999, money.USD))
+	assert.NoError(t, err)
+	mouse, err := NewOrderItem("product-2", "Mouse", 3, money.New(1010, money.USD))
+	assert.NoError(t, err)
+
+	order, err := NewOrderWithItems("user-123", []*OrderItem{keyboard, mouse})
+
+	assert.NoError(t, err)
+	assert.Equal(t, money.New(9998, money.USD), keyboard.Amount)
+	assert.Equal(t, money.New(3030, money.USD), mouse.Amount)
+	assert.Equal(t, money.New(13028, money.USD), order.Amount)
+	assert.Len(t, order.Items, 2)
+	assert.Equal(t, order.ID, keyboard.OrderID)
+	assert.Equal(t, order.ID, mouse.OrderID)
+}
+
+func TestNewOrderWithItems_Invalid(t *testing.T) {
+	_, err := NewOrderWithItems("user-123", nil)
+	assert.ErrorIs(t, err, ErrEmptyOrder)
+
+	usd, _ := NewOrderItem("product-1", "Keyboard", 1, money.New(4999, money.USD))
+	eur, _ := NewOrderItem("product-2", "Mouse", 1, money.New(1010, money.EUR))
+	_, err = NewOrderWithItems("user-123", []*OrderItem{usd, eur})
+	assert.ErrorIs(t, err, ErrMixedCurrencies)
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+
+	_, err = NewOrderItem("product-1", "Keyboard", 0, money.New(4999, money.USD))
+	assert.ErrorIs(t, err, ErrInvalidQuantity)
+}
+
+func TestNewOrderWithItems_NonUSD(t *testing.T) {
+	lamp, err := NewOrderItem("product-7", "Desk Lamp", 2, money.New(3990, money.EUR))
+	assert.NoError(t, err)
+
+	order, err := NewOrderWithItems("user-123", []*OrderItem{lamp})
+
+	assert.NoError(t, err)
+	assert.Equal(t, money.New(7980, money.EUR), order.Amount)
+
+	_, err = NewOrder("user-123", money.New(1000, "JPY"))
+	assert.ErrorIs(t, err, money.ErrUnsupportedCurrency)
+}
+
+func TestOrder_MarshalJSON_LegacyFields(t *testing.T) {
+	order, err := NewOrder("user-123", money.New(12345, money.USD))
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(order)
+	assert.NoError(t, err)
+
+	var raw map[string]any
+	assert.NoError(t, json.Unmarshal(data, &raw))
+	assert.Equal(t, 123.45, raw["amount"])
+	assert.Equal(t, "USD", raw["currency"])
+	assert.Equal(t, map[string]any{"minor_units": 12345.0, "currency": "USD"}, raw["amountMoney"])
+}
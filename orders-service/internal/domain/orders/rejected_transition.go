@@ -0,0 +1,36 @@
+package orders
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type RejectedTransition struct {
+	ID         string
+	OrderID    string
+	FromStatus OrderStatus
+	ToStatus   OrderStatus
+	EventType  string
+	EventID    string
+	Reason     string
+	CreatedAt  time.Time
+}
+
+func NewRejectedTransition(transitionErr *InvalidTransitionError, eventType, eventID string) (*RejectedTransition, error) {
+	v7, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RejectedTransition{
+		ID:         v7.String(),
+		OrderID:    transitionErr.OrderID,
+		FromStatus: transitionErr.From,
+		ToStatus:   transitionErr.To,
+		EventType:  eventType,
+		EventID:    eventID,
+		Reason:     transitionErr.Error(),
+		CreatedAt:  time.Now(),
+	}, nil
+}
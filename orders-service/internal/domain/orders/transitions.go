@@ -0,0 +1,55 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidTransition = errors.New("invalid order status transition")
+
+type InvalidTransitionError struct {
+	OrderID string
+	From    OrderStatus
+	To      OrderStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("%s: order %s cannot move from %s to %s", ErrInvalidTransition, e.OrderID, e.From, e.To)
+}
+
+func (e *InvalidTransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// transitions describes every status an order may move to from its current status.
+// Statuses without outgoing transitions are terminal.
+var transitions = map[OrderStatus][]OrderStatus{
+	OrderStatusCreated:        {OrderStatusPaymentPending, OrderStatusPaid, OrderStatusPaymentFailed, OrderStatusCancelled},
+	OrderStatusPaymentPending: {OrderStatusPaid, OrderStatusPaymentFailed, OrderStatusCancelled},
+	OrderStatusPaymentFailed:  {OrderStatusCancelled},
+	OrderStatusPaid:           {OrderStatusCompleted, OrderStatusCancelling},
+	OrderStatusCancelling:     {OrderStatusCancelled},
+	OrderStatusCompleted:      {},
+	OrderStatusCancelled:      {},
+}
+
+func CanTransition(from, to OrderStatus) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *Order) CanTransitionTo(to OrderStatus) bool {
+	return CanTransition(o.Status, to)
+}
+
+func (o *Order) transitionTo(to OrderStatus) error {
+	if !o.CanTransitionTo(to) {
+		return &InvalidTransitionError{OrderID: o.ID, From: o.Status, To: to}
+	}
+	o.Status = to
+	return nil
+}
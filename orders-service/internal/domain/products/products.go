@@ -0,0 +1,69 @@
+package products
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"contracts/money"
+)
+
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrInvalidProduct  = errors.New("invalid product")
+)
+
+type Product struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"priceMoney"`
+	Active      bool        `json:"active"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+}
+
+func NewProduct(name, description string, price money.Money) (*Product, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.Join(ErrInvalidProduct, errors.New("name is required"))
+	}
+	if !price.IsPositive() {
+		return nil, errors.Join(ErrInvalidProduct, errors.New("price must be positive"))
+	}
+	if err := money.ValidateCurrency(price.Currency()); err != nil {
+		return nil, errors.Join(ErrInvalidProduct, err)
+	}
+
+	v7, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Product{
+		ID:          v7.String(),
+		Name:        name,
+		Description: description,
+		Price:       price,
+		Active:      true,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}, nil
+}
+
+// MarshalJSON дополнительно отдаёт устаревшие поля price/currency.
+func (p Product) MarshalJSON() ([]byte, error) {
+	type product Product
+	return json.Marshal(struct {
+		product
+		LegacyPrice float64 `json:"price"`
+		Currency    string  `json:"currency"`
+	}{
+		product:     product(p),
+		LegacyPrice: p.Price.Float64(),
+		Currency:    p.Price.Currency(),
+	})
+}
@@ -0,0 +1,31 @@
+package products
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"contracts/money"
+)
+
+func TestNewProduct(t *testing.T) {
+	product, err := NewProduct("  Keyboard ", "Mechanical keyboard", money.New(12050, money.USD))
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, product.ID)
+	assert.Equal(t, "Keyboard", product.Name)
+	assert.Equal(t, money.New(12050, money.USD), product.Price)
+	assert.True(t, product.Active)
+}
+
+func TestNewProduct_Invalid(t *testing.T) {
+	_, err := NewProduct("", "no name", money.New(1000, money.USD))
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+
+	_, err = NewProduct("Mouse", "free mouse", money.Zero(money.USD))
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+
+	_, err = NewProduct("Mouse", "yen mouse", money.New(500000, "JPY"))
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	assert.ErrorIs(t, err, money.ErrUnsupportedCurrency)
+}
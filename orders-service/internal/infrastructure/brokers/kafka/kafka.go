@@ -2,28 +2,18 @@ package kafka
 
 import (
 	"context"
+	"messaging/kafka"
+	"messaging/relay"
 	"orders-service/internal/infrastructure/config"
-	"orders-service/pkg/kafka"
 	"time"
 
 	"github.com/gofrs/uuid"
 )
 
+// Config — настройки messaging/relay сервиса и его топики
 type Config struct {
-	Brokers   []string
-	Topics    Topics
-	Publisher Publisher
-	Consumer  Consumer
-}
-
-type Publisher struct {
-	Interval   time.Duration
-	BatchSize  int
-	MaxRetries int
-}
-
-type Consumer struct {
-	GroupID string
+	relay.Config
+	Topics Topics
 }
 
 type Topics struct {
@@ -31,10 +21,6 @@ type Topics struct {
 	PaymentsEvents string
 }
 
-func (c *Config) GetBrokers() []string {
-	return c.Brokers
-}
-
 func (c *Config) GetOrdersEventsTopic() string {
 	return c.Topics.OrdersEvents
 }
@@ -45,22 +31,68 @@ func (c *Config) GetPaymentsEventsTopic() string {
 
 func NewConfig(mainConfig *config.Config) *Config {
 	return &Config{
-		Brokers: mainConfig.Kafka.Brokers,
+		Config: relay.Config{
+			Transport:       mainConfig.GetBrokerTransport(),
+			RabbitMQURL:     mainConfig.Broker.RabbitMQ.URL,
+			Mode:            mainConfig.GetKafkaMode(),
+			TransactionalID: mainConfig.GetTransactionalID(),
+			Source:          "orders-service",
+			Brokers:         mainConfig.Kafka.Brokers,
+			Publisher: relay.Publisher{
+				Interval:         mainConfig.GetPublisherInterval(),
+				FallbackInterval: mainConfig.GetPublisherFallbackInterval(),
+				BatchSize:        mainConfig.GetPublisherBatchSize(),
+				Lease:            mainConfig.GetPublisherLease(),
+			},
+			Consumer: relay.Consumer{
+				GroupID:     mainConfig.GetConsumerGroupID(),
+				Retry:       newBackoff(mainConfig.GetConsumerRetry()),
+				Pause:       mainConfig.GetConsumerPause(),
+				Workers:     mainConfig.GetConsumerWorkers(),
+				MaxInFlight: mainConfig.GetConsumerMaxInFlight(),
+			},
+			Routes:  newRoutes(mainConfig.GetKafkaRoutes()),
+			Retry:   newRetryPolicy(mainConfig),
+			Formats: mainConfig.GetKafkaFormats(),
+		},
 		Topics: Topics{
 			OrdersEvents:   "orders-events",
 			PaymentsEvents: "payments-events",
 		},
-		Publisher: Publisher{
-			Interval:   mainConfig.GetPublisherInterval(),
-			BatchSize:  mainConfig.GetPublisherBatchSize(),
-			MaxRetries: mainConfig.GetPublisherMaxRetries(),
-		},
-		Consumer: Consumer{
-			GroupID: "orders-service-group",
-		},
 	}
 }
 
+func newRetryPolicy(mainConfig *config.Config) *relay.RetryPolicy {
+	events := make(map[string]relay.Backoff)
+	for eventType, backoff := range mainConfig.GetRetryEvents() {
+		events[eventType] = newBackoff(backoff)
+	}
+	return relay.NewRetryPolicy(newBackoff(mainConfig.GetRetryDefault()), events)
+}
+
+func newBackoff(backoff config.RetryBackoff) relay.Backoff {
+	var jitter float64
+	if backoff.Jitter != nil {
+		jitter = *backoff.Jitter
+	}
+
+	return relay.Backoff{
+		MaxAttempts:  backoff.MaxAttempts,
+		InitialDelay: time.Duration(backoff.InitialDelayMs) * time.Millisecond,
+		MaxDelay:     time.Duration(backoff.MaxDelayMs) * time.Millisecond,
+		Multiplier:   backoff.Multiplier,
+		Jitter:       jitter,
+	}
+}
+
+func newRoutes(routes []config.KafkaRoute) []relay.Route {
+	result := make([]relay.Route, 0, len(routes))
+	for _, route := range routes {
+		result = append(result, relay.Route{Pattern: route.Event, Topic: route.Topic})
+	}
+	return result
+}
+
 type OrderEventService struct {
 	producer    *kafka.Producer
 	consumer    *kafka.Consumer
@@ -68,16 +100,12 @@ type OrderEventService struct {
 }
 
 func NewOrderEventService(kafkaConfig *Config) (*OrderEventService, error) {
-	producer, err := kafka.NewProducer(kafkaConfig.Brokers)
+	producer, err := kafkaConfig.NewProducer("events")
 	if err != nil {
 		return nil, err
 	}
 
-	consumer, err := kafka.NewConsumer(
-		kafkaConfig.Brokers,
-		kafkaConfig.Consumer.GroupID,
-		[]string{kafkaConfig.Topics.PaymentsEvents},
-	)
+	consumer, err := kafkaConfig.NewConsumer(kafkaConfig.Topics.PaymentsEvents)
 	if err != nil {
 		return nil, err
 	}
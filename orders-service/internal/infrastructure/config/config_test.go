@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGetRetryEvents_JitterOverride(t *testing.T) {
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+retry:
+  default:
+    jitter: 0.2
+  events:
+    order.created:
+      jitter: 0
+    order.updated:
+      max_attempts: 2
+`), &cfg))
+
+	events := cfg.GetRetryEvents()
+
+	// Явный ноль отключает разброс, незаданный jitter берётся из retry.default
+	require.NotNil(t, events["order.created"].Jitter)
+	assert.Equal(t, 0.0, *events["order.created"].Jitter)
+	require.NotNil(t, events["order.updated"].Jitter)
+	assert.Equal(t, 0.2, *events["order.updated"].Jitter)
+	assert.Equal(t, 2, events["order.updated"].MaxAttempts)
+}
+
+func TestGetBrokerTransport_EnvOverride(t *testing.T) {
+	cfg := Config{Broker: Broker{Transport: "kafka"}}
+	assert.Equal(t, "kafka", cfg.GetBrokerTransport())
+
+	t.Setenv("BROKER_TRANSPORT", "rabbitmq")
+	assert.Equal(t, "rabbitmq", cfg.GetBrokerTransport())
+}
+
+func TestGetConsumerGroupID(t *testing.T) {
+	var cfg Config
+	assert.Equal(t, "orders-service-group", cfg.GetConsumerGroupID())
+
+	require.NoError(t, yaml.Unmarshal([]byte(`
+kafka:
+  consumer:
+    group_id: orders-service-canary
+`), &cfg))
+	assert.Equal(t, "orders-service-canary", cfg.GetConsumerGroupID())
+}
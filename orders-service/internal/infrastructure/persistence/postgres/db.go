@@ -15,13 +15,15 @@ type Config struct {
 	Name string
 }
 
-func NewDb(config *Config) (*sql.DB, error) {
-	dsn := fmt.Sprintf(
+func (c *Config) DSN() string {
+	return fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		config.Host, config.Port, config.User, config.Pass, config.Name,
+		c.Host, c.Port, c.User, c.Pass, c.Name,
 	)
+}
 
-	db, err := sql.Open("postgres", dsn)
+func NewDb(config *Config) (*sql.DB, error) {
+	db, err := sql.Open("postgres", config.DSN())
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,7 @@
+-- Orders still waiting for a refund go back to paid
+UPDATE orders SET status = 'paid' WHERE status = 'cancelling';
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+
+ALTER TABLE orders
+    ADD CONSTRAINT orders_status_check CHECK (status IN ('created', 'payment_pending', 'paid', 'payment_failed', 'completed', 'cancelled'));
@@ -0,0 +1,5 @@
+-- Allow paid orders to wait for a refund before being cancelled
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+
+ALTER TABLE orders
+    ADD CONSTRAINT orders_status_check CHECK (status IN ('created', 'payment_pending', 'paid', 'payment_failed', 'completed', 'cancelling', 'cancelled'));
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_rejected_order_transitions_order_id;
+
+DROP TABLE IF EXISTS rejected_order_transitions;
@@ -0,0 +1,14 @@
+-- Audit trail of status changes refused by the order state machine
+CREATE TABLE rejected_order_transitions
+(
+    id          UUID PRIMARY KEY,
+    order_id    UUID        NOT NULL REFERENCES orders (id),
+    from_status VARCHAR(50) NOT NULL,
+    to_status   VARCHAR(50) NOT NULL,
+    event_type  VARCHAR(50) NOT NULL,
+    event_id    VARCHAR(36) NOT NULL,
+    reason      TEXT        NOT NULL,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_rejected_order_transitions_order_id ON rejected_order_transitions (order_id);
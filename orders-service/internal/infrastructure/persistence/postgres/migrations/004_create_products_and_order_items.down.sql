@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS order_items;
+DROP TRIGGER IF EXISTS update_products_updated_at ON products;
+DROP TABLE IF EXISTS products;
@@ -0,0 +1,40 @@
+CREATE TABLE products
+(
+    id          UUID PRIMARY KEY,
+    name        VARCHAR(255)   NOT NULL,
+    description TEXT           NOT NULL DEFAULT '',
+    price       DECIMAL(15, 2) NOT NULL CHECK (price > 0),
+    currency    VARCHAR(3)     NOT NULL DEFAULT 'USD',
+    active      BOOLEAN        NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE order_items
+(
+    id           UUID PRIMARY KEY,
+    order_id     UUID           NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_id   UUID           NOT NULL REFERENCES products (id),
+    product_name VARCHAR(255)   NOT NULL,
+    quantity     INT            NOT NULL CHECK (quantity > 0),
+    unit_price   DECIMAL(15, 2) NOT NULL CHECK (unit_price >= 0),
+    amount       DECIMAL(15, 2) NOT NULL CHECK (amount >= 0)
+);
+
+CREATE INDEX idx_products_active ON products (active);
+CREATE INDEX idx_order_items_order_id ON order_items (order_id);
+CREATE INDEX idx_order_items_product_id ON order_items (product_id);
+
+CREATE TRIGGER update_products_updated_at
+    BEFORE UPDATE
+    ON products
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Стартовый каталог
+INSERT INTO products (id, name, description, price)
+VALUES ('0190a0b0-0000-7000-8000-000000000001', 'Mechanical Keyboard', 'Hot-swappable 75% keyboard', 129.90),
+       ('0190a0b0-0000-7000-8000-000000000002', 'Wireless Mouse', 'Ergonomic mouse with USB-C charging', 59.50),
+       ('0190a0b0-0000-7000-8000-000000000003', '27" Monitor', '1440p IPS monitor', 349.00),
+       ('0190a0b0-0000-7000-8000-000000000004', 'USB-C Hub', '7-in-1 hub with HDMI and Ethernet', 45.00),
+       ('0190a0b0-0000-7000-8000-000000000005', 'Noise Cancelling Headphones', 'Over-ear bluetooth headphones', 249.99);
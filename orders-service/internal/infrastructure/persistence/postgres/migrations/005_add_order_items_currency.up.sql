@@ -0,0 +1,2 @@
+ALTER TABLE order_items
+    ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'USD';
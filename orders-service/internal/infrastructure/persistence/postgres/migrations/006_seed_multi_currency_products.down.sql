@@ -0,0 +1,5 @@
+DELETE FROM products
+WHERE id IN ('0190a0b0-0000-7000-8000-000000000006',
+             '0190a0b0-0000-7000-8000-000000000007',
+             '0190a0b0-0000-7000-8000-000000000008',
+             '0190a0b0-0000-7000-8000-000000000009');
@@ -0,0 +1,7 @@
+-- Товары в других валютах: заказ выставляется в валюте его позиций
+INSERT INTO products (id, name, description, price, currency)
+VALUES ('0190a0b0-0000-7000-8000-000000000006', 'Espresso Machine', 'Compact 15-bar espresso machine', 189.00, 'EUR'),
+       ('0190a0b0-0000-7000-8000-000000000007', 'Desk Lamp', 'Dimmable LED desk lamp', 39.90, 'EUR'),
+       ('0190a0b0-0000-7000-8000-000000000008', 'Fountain Pen', 'Steel nib fountain pen', 24.50, 'GBP'),
+       ('0190a0b0-0000-7000-8000-000000000009', 'Tea Set', 'Porcelain tea set for four', 3490.00, 'RUB')
+ON CONFLICT (id) DO NOTHING;
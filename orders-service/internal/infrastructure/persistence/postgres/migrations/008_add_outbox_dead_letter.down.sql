@@ -0,0 +1,7 @@
+UPDATE outbox_messages SET status = 'failed' WHERE status = 'dead_letter';
+
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS last_error;
+
+ALTER TABLE outbox_messages DROP CONSTRAINT IF EXISTS outbox_messages_status_check;
+ALTER TABLE outbox_messages
+    ADD CONSTRAINT outbox_messages_status_check CHECK (status IN ('pending', 'sent', 'failed'));
@@ -0,0 +1,6 @@
+-- Messages that cannot be delivered by retrying (e.g. no topic route) are parked as dead_letter
+ALTER TABLE outbox_messages DROP CONSTRAINT IF EXISTS outbox_messages_status_check;
+ALTER TABLE outbox_messages
+    ADD CONSTRAINT outbox_messages_status_check CHECK (status IN ('pending', 'sent', 'failed', 'dead_letter'));
+
+ALTER TABLE outbox_messages ADD COLUMN last_error TEXT;
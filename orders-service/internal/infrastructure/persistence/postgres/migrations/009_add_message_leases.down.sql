@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_inbox_locked_until;
+DROP INDEX IF EXISTS idx_outbox_locked_until;
+
+UPDATE inbox_messages SET status = 'pending' WHERE status = 'processing';
+ALTER TABLE inbox_messages DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE inbox_messages DROP CONSTRAINT IF EXISTS inbox_messages_status_check;
+ALTER TABLE inbox_messages
+    ADD CONSTRAINT inbox_messages_status_check CHECK (status IN ('pending', 'processed', 'failed'));
+
+UPDATE outbox_messages SET status = 'pending' WHERE status = 'processing';
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE outbox_messages DROP CONSTRAINT IF EXISTS outbox_messages_status_check;
+ALTER TABLE outbox_messages
+    ADD CONSTRAINT outbox_messages_status_check CHECK (status IN ('pending', 'sent', 'failed', 'dead_letter'));
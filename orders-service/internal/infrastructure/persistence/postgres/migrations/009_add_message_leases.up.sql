@@ -0,0 +1,13 @@
+-- Replicas claim outbox/inbox rows by switching them to 'processing' until locked_until
+ALTER TABLE outbox_messages DROP CONSTRAINT IF EXISTS outbox_messages_status_check;
+ALTER TABLE outbox_messages
+    ADD CONSTRAINT outbox_messages_status_check CHECK (status IN ('pending', 'processing', 'sent', 'failed', 'dead_letter'));
+ALTER TABLE outbox_messages ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE inbox_messages DROP CONSTRAINT IF EXISTS inbox_messages_status_check;
+ALTER TABLE inbox_messages
+    ADD CONSTRAINT inbox_messages_status_check CHECK (status IN ('pending', 'processing', 'processed', 'failed'));
+ALTER TABLE inbox_messages ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_outbox_locked_until ON outbox_messages (locked_until) WHERE status = 'processing';
+CREATE INDEX idx_inbox_locked_until ON inbox_messages (locked_until) WHERE status = 'processing';
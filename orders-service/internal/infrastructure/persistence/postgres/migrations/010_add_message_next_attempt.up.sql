@@ -0,0 +1,10 @@
+-- Failed outbox/inbox messages are retried no earlier than next_attempt_at (exponential backoff)
+ALTER TABLE outbox_messages ADD COLUMN next_attempt_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE inbox_messages ADD COLUMN next_attempt_at TIMESTAMP WITH TIME ZONE;
+
+-- Already failed messages that still have attempts left are due right away
+UPDATE outbox_messages SET next_attempt_at = NOW() WHERE status = 'failed' AND retry_count < max_retries;
+UPDATE inbox_messages SET next_attempt_at = NOW() WHERE status = 'failed' AND retry_count < max_retries;
+
+CREATE INDEX idx_outbox_next_attempt_at ON outbox_messages (next_attempt_at) WHERE status = 'failed';
+CREATE INDEX idx_inbox_next_attempt_at ON inbox_messages (next_attempt_at) WHERE status = 'failed';
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_inbox_dead_lettered_at;
+DROP INDEX IF EXISTS idx_outbox_dead_lettered_at;
+
+UPDATE inbox_messages SET status = 'failed' WHERE status = 'dead_letter';
+ALTER TABLE inbox_messages DROP COLUMN IF EXISTS dead_lettered_at;
+ALTER TABLE inbox_messages DROP COLUMN IF EXISTS attempts;
+ALTER TABLE inbox_messages DROP COLUMN IF EXISTS last_error;
+ALTER TABLE inbox_messages DROP CONSTRAINT IF EXISTS inbox_messages_status_check;
+ALTER TABLE inbox_messages
+    ADD CONSTRAINT inbox_messages_status_check CHECK (status IN ('pending', 'processing', 'processed', 'failed'));
+
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS dead_lettered_at;
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS attempts;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS notify_outbox_messages_pending ON outbox_messages;
+
+DROP FUNCTION IF EXISTS notify_outbox_messages();
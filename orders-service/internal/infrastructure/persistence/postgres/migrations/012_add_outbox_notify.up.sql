@@ -0,0 +1,16 @@
+-- Wake up outbox publishers as soon as a pending message appears (new or requeued).
+-- The payload is empty, so notifications of one transaction collapse into one.
+CREATE OR REPLACE FUNCTION notify_outbox_messages()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('outbox_messages', '');
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER notify_outbox_messages_pending
+    AFTER INSERT OR UPDATE OF status
+    ON outbox_messages
+    FOR EACH ROW
+    WHEN (NEW.status = 'pending')
+    EXECUTE FUNCTION notify_outbox_messages();
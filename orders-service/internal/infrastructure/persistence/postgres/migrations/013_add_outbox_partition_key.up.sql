@@ -0,0 +1,6 @@
+-- Aggregate id used as the Kafka message key, so events of one aggregate share a partition
+ALTER TABLE outbox_messages ADD COLUMN partition_key VARCHAR(255) NOT NULL DEFAULT '';
+
+UPDATE outbox_messages
+SET partition_key = COALESCE(payload ->> 'order_id', payload ->> 'user_id', '')
+WHERE status <> 'sent';
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_inbox_correlation_id;
+DROP INDEX IF EXISTS idx_outbox_correlation_id;
+
+ALTER TABLE inbox_messages DROP COLUMN IF EXISTS schema_version;
+ALTER TABLE inbox_messages DROP COLUMN IF EXISTS source;
+ALTER TABLE inbox_messages DROP COLUMN IF EXISTS traceparent;
+ALTER TABLE inbox_messages DROP COLUMN IF EXISTS causation_id;
+ALTER TABLE inbox_messages DROP COLUMN IF EXISTS correlation_id;
+
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS schema_version;
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS traceparent;
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS causation_id;
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS correlation_id;
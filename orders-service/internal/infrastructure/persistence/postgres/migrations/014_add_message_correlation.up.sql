@@ -0,0 +1,14 @@
+-- Correlation, causation and trace context travel with a message through the outbox and inbox
+ALTER TABLE outbox_messages ADD COLUMN correlation_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE outbox_messages ADD COLUMN causation_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE outbox_messages ADD COLUMN traceparent VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE outbox_messages ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE inbox_messages ADD COLUMN correlation_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE inbox_messages ADD COLUMN causation_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE inbox_messages ADD COLUMN traceparent VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE inbox_messages ADD COLUMN source VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE inbox_messages ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_outbox_correlation_id ON outbox_messages (correlation_id);
+CREATE INDEX idx_inbox_correlation_id ON inbox_messages (correlation_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_poison_messages_created_at;
+
+DROP TABLE IF EXISTS poison_messages;
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"contracts/money"
+	"orders-service/internal/domain/orders"
+	"orders-service/internal/interfaces/repository"
+)
+
+type OrderItemsRepository struct {
+	db *sql.DB
+}
+
+func NewOrderItemsRepository(db *sql.DB) repository.OrderItemsRepository {
+	return &OrderItemsRepository{db: db}
+}
+
+func (r *OrderItemsRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, items []*orders.OrderItem) error {
+	query := `
+		INSERT INTO order_items (id, order_id, product_id, product_name, quantity, unit_price, amount, currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	for _, item := range items {
+		_, err := tx.ExecContext(ctx, query,
+			item.ID,
+			item.OrderID,
+			item.ProductID,
+			item.ProductName,
+			item.Quantity,
+			item.UnitPrice.Decimal(),
+			item.Amount.Decimal(),
+			item.Amount.Currency(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store order item: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *OrderItemsRepository) GetByOrderIDs(ctx context.Context, orderIDs []string) ([]*orders.OrderItem, error) {
+	query := `
+		SELECT id, order_id, product_id, product_name, quantity, unit_price, amount, currency
+		FROM order_items
+		WHERE order_id::text = ANY($1)
+		ORDER BY product_name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(orderIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*orders.OrderItem
+
+	for rows.Next() {
+		var item orders.OrderItem
+		var unitPrice, amount, currency string
+		err := rows.Scan(
+			&item.ID,
+			&item.OrderID,
+			&item.ProductID,
+			&item.ProductName,
+			&item.Quantity,
+			&unitPrice,
+			&amount,
+			&currency,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order item: %w", err)
+		}
+
+		if item.UnitPrice, err = money.Parse(unitPrice, currency); err != nil {
+			return nil, fmt.Errorf("failed to parse order item unit price: %w", err)
+		}
+		if item.Amount, err = money.Parse(amount, currency); err != nil {
+			return nil, fmt.Errorf("failed to parse order item amount: %w", err)
+		}
+
+		items = append(items, &item)
+	}
+
+	return items, rows.Err()
+}
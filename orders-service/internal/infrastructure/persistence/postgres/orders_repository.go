@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"contracts/money"
 	"orders-service/internal/domain/orders"
 	"orders-service/internal/interfaces/repository"
 )
@@ -26,8 +27,8 @@ func (r *OrdersRepository) Store(ctx context.Context, order *orders.Order) error
 	_, err := r.db.ExecContext(ctx, query,
 		order.ID,
 		order.UserID,
-		order.Amount,
-		order.Currency,
+		order.Amount.Decimal(),
+		order.Amount.Currency(),
 		order.Status,
 		order.PaymentID,
 		order.ErrorReason,
@@ -51,8 +52,8 @@ func (r *OrdersRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, order *o
 	_, err := tx.ExecContext(ctx, query,
 		order.ID,
 		order.UserID,
-		order.Amount,
-		order.Currency,
+		order.Amount.Decimal(),
+		order.Amount.Currency(),
 		order.Status,
 		order.PaymentID,
 		order.ErrorReason,
@@ -77,11 +78,12 @@ func (r *OrdersRepository) GetByID(ctx context.Context, orderID string) (*orders
 	row := r.db.QueryRowContext(ctx, query, orderID)
 
 	var order orders.Order
+	var amount, currency string
 	err := row.Scan(
 		&order.ID,
 		&order.UserID,
-		&order.Amount,
-		&order.Currency,
+		&amount,
+		&currency,
 		&order.Status,
 		&order.PaymentID,
 		&order.ErrorReason,
@@ -91,11 +93,55 @@ func (r *OrdersRepository) GetByID(ctx context.Context, orderID string) (*orders
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("order not found: %s", orderID)
+			return nil, fmt.Errorf("%w: %s", orders.ErrOrderNotFound, orderID)
 		}
 		return nil, fmt.Errorf("failed to get order by ID: %w", err)
 	}
 
+	order.Amount, err = money.Parse(amount, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse order amount: %w", err)
+	}
+
+	return &order, nil
+}
+
+func (r *OrdersRepository) GetByIDWithTx(ctx context.Context, tx *sql.Tx, orderID string) (*orders.Order, error) {
+	query := `
+		SELECT id, user_id, amount, currency, status, payment_id, error_reason, created_at, updated_at
+		FROM orders
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	row := tx.QueryRowContext(ctx, query, orderID)
+
+	var order orders.Order
+	var amount, currency string
+	err := row.Scan(
+		&order.ID,
+		&order.UserID,
+		&amount,
+		&currency,
+		&order.Status,
+		&order.PaymentID,
+		&order.ErrorReason,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: %s", orders.ErrOrderNotFound, orderID)
+		}
+		return nil, fmt.Errorf("failed to get order by ID with tx: %w", err)
+	}
+
+	order.Amount, err = money.Parse(amount, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse order amount: %w", err)
+	}
+
 	return &order, nil
 }
 
@@ -117,11 +163,12 @@ func (r *OrdersRepository) GetByUserID(ctx context.Context, userID string) ([]*o
 
 	for rows.Next() {
 		var order orders.Order
+		var amount, currency string
 		err := rows.Scan(
 			&order.ID,
 			&order.UserID,
-			&order.Amount,
-			&order.Currency,
+			&amount,
+			&currency,
 			&order.Status,
 			&order.PaymentID,
 			&order.ErrorReason,
@@ -132,6 +179,11 @@ func (r *OrdersRepository) GetByUserID(ctx context.Context, userID string) ([]*o
 			return nil, fmt.Errorf("failed to scan order: %w", err)
 		}
 
+		order.Amount, err = money.Parse(amount, currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse order amount: %w", err)
+		}
+
 		ordersList = append(ordersList, &order)
 	}
 
@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"contracts/money"
+	"orders-service/internal/domain/products"
+	"orders-service/internal/interfaces/repository"
+)
+
+type ProductsRepository struct {
+	db *sql.DB
+}
+
+func NewProductsRepository(db *sql.DB) repository.ProductsRepository {
+	return &ProductsRepository{db: db}
+}
+
+func (r *ProductsRepository) Store(ctx context.Context, product *products.Product) error {
+	query := `
+		INSERT INTO products (id, name, description, price, currency, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		product.ID,
+		product.Name,
+		product.Description,
+		product.Price.Decimal(),
+		product.Price.Currency(),
+		product.Active,
+		product.CreatedAt,
+		product.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to store product: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ProductsRepository) GetByID(ctx context.Context, productID string) (*products.Product, error) {
+	query := `
+		SELECT id, name, description, price, currency, active, created_at, updated_at
+		FROM products
+		WHERE id = $1
+	`
+
+	product, err := scanProduct(r.db.QueryRowContext(ctx, query, productID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", products.ErrProductNotFound, productID)
+		}
+		return nil, fmt.Errorf("failed to get product by ID: %w", err)
+	}
+
+	return product, nil
+}
+
+func (r *ProductsRepository) GetByIDs(ctx context.Context, productIDs []string) ([]*products.Product, error) {
+	query := `
+		SELECT id, name, description, price, currency, active, created_at, updated_at
+		FROM products
+		WHERE id::text = ANY($1)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(productIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products by IDs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanProducts(rows)
+}
+
+func (r *ProductsRepository) List(ctx context.Context) ([]*products.Product, error) {
+	query := `
+		SELECT id, name, description, price, currency, active, created_at, updated_at
+		FROM products
+		WHERE active = TRUE
+		ORDER BY name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
+	defer rows.Close()
+
+	return scanProducts(rows)
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanProduct(row rowScanner) (*products.Product, error) {
+	var product products.Product
+	var price, currency string
+	err := row.Scan(
+		&product.ID,
+		&product.Name,
+		&product.Description,
+		&price,
+		&currency,
+		&product.Active,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	product.Price, err = money.Parse(price, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+func scanProducts(rows *sql.Rows) ([]*products.Product, error) {
+	var productsList []*products.Product
+
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+
+		productsList = append(productsList, product)
+	}
+
+	return productsList, rows.Err()
+}
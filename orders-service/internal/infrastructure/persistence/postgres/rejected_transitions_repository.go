@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"orders-service/internal/domain/orders"
+	"orders-service/internal/interfaces/repository"
+)
+
+type RejectedTransitionsRepository struct {
+	db *sql.DB
+}
+
+func NewRejectedTransitionsRepository(db *sql.DB) repository.RejectedTransitionsRepository {
+	return &RejectedTransitionsRepository{db: db}
+}
+
+func (r *RejectedTransitionsRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, transition *orders.RejectedTransition) error {
+	query := `
+		INSERT INTO rejected_order_transitions (id, order_id, from_status, to_status, event_type, event_id, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		transition.ID,
+		transition.OrderID,
+		transition.FromStatus,
+		transition.ToStatus,
+		transition.EventType,
+		transition.EventID,
+		transition.Reason,
+		transition.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to store rejected transition: %w", err)
+	}
+
+	return nil
+}
@@ -2,8 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 
+	"contracts/money"
+	"orders-service/internal/domain/orders"
+	"orders-service/internal/domain/products"
 	"orders-service/internal/infrastructure/sse"
 )
 
@@ -20,17 +25,22 @@ func NewOrdersHandler(ordersService OrdersServicer, sseManager *sse.Manager) *Or
 }
 
 type CreateOrderRequest struct {
-	UserID string `json:"user_id"`
+	UserID string            `json:"user_id"`
+	Items  []orders.LineItem `json:"items"`
 }
 
 // CreateOrder handles new order requests
 // @Summary Create a new order
-// @Description Create a new order with the provided details
+// @Description Create a new order from catalog line items. The amount is computed from current catalog prices
 // @Tags Orders
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Key to safely retry the request; a replay returns the original response"
 // @Param request body CreateOrderRequest true "Order creation request"
 // @Success 200 {object} orders.Order
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Request with the same Idempotency-Key is in progress"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key was used with a different body"
 // @Router /orders [post]
 func (h *OrdersHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	var req CreateOrderRequest
@@ -41,10 +51,20 @@ func (h *OrdersHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	order, err := h.ordersService.CreateOrder(r.Context(), req.UserID)
+	order, err := h.ordersService.CreateOrder(r.Context(), req.UserID, req.Items)
 	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, orders.ErrEmptyOrder),
+			errors.Is(err, orders.ErrInvalidQuantity),
+			errors.Is(err, orders.ErrMixedCurrencies),
+			errors.Is(err, money.ErrUnsupportedCurrency),
+			errors.Is(err, products.ErrProductNotFound):
+			status = http.StatusBadRequest
+		}
+
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -54,6 +74,10 @@ func (h *OrdersHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(order)
 }
 
+type CancelOrderRequest struct {
+	Reason string `json:"reason"`
+}
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
@@ -118,6 +142,100 @@ func (h *OrdersHandler) GetOrderStatus(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(order)
 }
 
+// CancelOrder отменяет заказ пользователя
+// @Summary Cancel an order
+// @Description Cancel an order. Unpaid orders are cancelled immediately, paid orders move to "cancelling" until the payment service confirms the refund or releases the hold
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param order_id path string true "Order ID"
+// @Param request body CancelOrderRequest false "Cancellation reason"
+// @Success 200 {object} orders.Order
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /orders/{order_id}/cancel [post]
+func (h *OrdersHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("id")
+	if orderID == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Order ID is required"})
+		return
+	}
+
+	var req CancelOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if req.Reason == "" {
+		req.Reason = "Cancelled by user"
+	}
+
+	order, err := h.ordersService.CancelOrder(r.Context(), orderID, req.Reason)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, orders.ErrOrderNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, orders.ErrOrderNotCancellable):
+			status = http.StatusConflict
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(order)
+}
+
+// CompleteOrder отмечает заказ выполненным
+// @Summary Complete an order
+// @Description Mark a paid order as completed. The amount held on the user's wallet is captured after completion
+// @Tags Orders
+// @Produce json
+// @Param order_id path string true "Order ID"
+// @Success 200 {object} orders.Order
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /orders/{order_id}/complete [post]
+func (h *OrdersHandler) CompleteOrder(w http.ResponseWriter, r *http.Request) {
+	orderID := r.PathValue("id")
+	if orderID == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Order ID is required"})
+		return
+	}
+
+	order, err := h.ordersService.CompleteOrder(r.Context(), orderID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, orders.ErrOrderNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, orders.ErrOrderNotCompletable):
+			status = http.StatusConflict
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(order)
+}
+
 // StreamOrderUpdates обрабатывает SSE подключения для отслеживания обновлений заказов
 // @Summary Stream order status updates
 // @Description Establish SSE connection to receive real-time order status updates for a user
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"orders-service/internal/domain/orders"
+	"orders-service/internal/domain/products"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -18,8 +19,8 @@ type MockOrdersService struct {
 	mock.Mock
 }
 
-func (m *MockOrdersService) CreateOrder(ctx context.Context, userID string) (*orders.Order, error) {
-	args := m.Called(ctx, userID)
+func (m *MockOrdersService) CreateOrder(ctx context.Context, userID string, items []orders.LineItem) (*orders.Order, error) {
+	args := m.Called(ctx, userID, items)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -42,6 +43,22 @@ func (m *MockOrdersService) GetOrder(ctx context.Context, orderID string) (*orde
 	return args.Get(0).(*orders.Order), args.Error(1)
 }
 
+func (m *MockOrdersService) CancelOrder(ctx context.Context, orderID string, reason string) (*orders.Order, error) {
+	args := m.Called(ctx, orderID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*orders.Order), args.Error(1)
+}
+
+func (m *MockOrdersService) CompleteOrder(ctx context.Context, orderID string) (*orders.Order, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*orders.Order), args.Error(1)
+}
+
 func TestOrdersHandler_CreateOrder(t *testing.T) {
 	mockService := new(MockOrdersService)
 	handler := NewOrdersHandler(mockService, nil) // sseManager is not used in this handler
@@ -49,9 +66,10 @@ func TestOrdersHandler_CreateOrder(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		userID := "user-123"
 		order := &orders.Order{ID: "order-456", UserID: userID}
-		mockService.On("CreateOrder", mock.Anything, userID).Return(order, nil).Once()
+		items := []orders.LineItem{{ProductID: "product-1", Quantity: 2}}
+		mockService.On("CreateOrder", mock.Anything, userID, items).Return(order, nil).Once()
 
-		reqBody, _ := json.Marshal(CreateOrderRequest{UserID: userID})
+		reqBody, _ := json.Marshal(CreateOrderRequest{UserID: userID, Items: items})
 		req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBuffer(reqBody))
 		rr := httptest.NewRecorder()
 
@@ -74,9 +92,24 @@ func TestOrdersHandler_CreateOrder(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
 	})
 
+	t.Run("unknown product", func(t *testing.T) {
+		userID := "user-123"
+		items := []orders.LineItem{{ProductID: "missing", Quantity: 1}}
+		mockService.On("CreateOrder", mock.Anything, userID, items).Return(nil, products.ErrProductNotFound).Once()
+
+		reqBody, _ := json.Marshal(CreateOrderRequest{UserID: userID, Items: items})
+		req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBuffer(reqBody))
+		rr := httptest.NewRecorder()
+
+		handler.CreateOrder(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("internal server error", func(t *testing.T) {
 		userID := "user-123"
-		mockService.On("CreateOrder", mock.Anything, userID).Return(nil, errors.New("service error")).Once()
+		mockService.On("CreateOrder", mock.Anything, userID, mock.Anything).Return(nil, errors.New("service error")).Once()
 
 		reqBody, _ := json.Marshal(CreateOrderRequest{UserID: userID})
 		req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBuffer(reqBody))
@@ -183,3 +216,108 @@ func TestOrdersHandler_GetOrderStatus(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 }
+
+func TestOrdersHandler_CancelOrder(t *testing.T) {
+	mockService := new(MockOrdersService)
+	handler := NewOrdersHandler(mockService, nil)
+
+	t.Run("success", func(t *testing.T) {
+		orderID := "order-456"
+		cancelled := &orders.Order{ID: orderID, Status: orders.OrderStatusCancelled, ErrorReason: "too expensive"}
+		mockService.On("CancelOrder", mock.Anything, orderID, "too expensive").Return(cancelled, nil).Once()
+
+		reqBody, _ := json.Marshal(CancelOrderRequest{Reason: "too expensive"})
+		req := httptest.NewRequest(http.MethodPost, "/orders/"+orderID+"/cancel", bytes.NewBuffer(reqBody))
+		req.SetPathValue("id", orderID)
+		rr := httptest.NewRecorder()
+
+		handler.CancelOrder(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var respOrder *orders.Order
+		err := json.Unmarshal(rr.Body.Bytes(), &respOrder)
+		assert.NoError(t, err)
+		assert.Equal(t, cancelled, respOrder)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("empty body uses default reason", func(t *testing.T) {
+		orderID := "order-789"
+		mockService.On("CancelOrder", mock.Anything, orderID, "Cancelled by user").Return(&orders.Order{ID: orderID}, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/orders/"+orderID+"/cancel", nil)
+		req.SetPathValue("id", orderID)
+		rr := httptest.NewRecorder()
+
+		handler.CancelOrder(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		orderID := "missing"
+		mockService.On("CancelOrder", mock.Anything, orderID, mock.Anything).Return(nil, orders.ErrOrderNotFound).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/orders/"+orderID+"/cancel", nil)
+		req.SetPathValue("id", orderID)
+		rr := httptest.NewRecorder()
+
+		handler.CancelOrder(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("not cancellable", func(t *testing.T) {
+		orderID := "completed"
+		mockService.On("CancelOrder", mock.Anything, orderID, mock.Anything).Return(nil, orders.ErrOrderNotCancellable).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/orders/"+orderID+"/cancel", nil)
+		req.SetPathValue("id", orderID)
+		rr := httptest.NewRecorder()
+
+		handler.CancelOrder(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestOrdersHandler_CompleteOrder(t *testing.T) {
+	mockService := new(MockOrdersService)
+	handler := NewOrdersHandler(mockService, nil)
+
+	t.Run("success", func(t *testing.T) {
+		orderID := "order-456"
+		completed := &orders.Order{ID: orderID, Status: orders.OrderStatusCompleted}
+		mockService.On("CompleteOrder", mock.Anything, orderID).Return(completed, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/orders/"+orderID+"/complete", nil)
+		req.SetPathValue("id", orderID)
+		rr := httptest.NewRecorder()
+
+		handler.CompleteOrder(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var respOrder *orders.Order
+		err := json.Unmarshal(rr.Body.Bytes(), &respOrder)
+		assert.NoError(t, err)
+		assert.Equal(t, orders.OrderStatusCompleted, respOrder.Status)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("not completable", func(t *testing.T) {
+		orderID := "unpaid"
+		mockService.On("CompleteOrder", mock.Anything, orderID).Return(nil, orders.ErrOrderNotCompletable).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/orders/"+orderID+"/complete", nil)
+		req.SetPathValue("id", orderID)
+		rr := httptest.NewRecorder()
+
+		handler.CompleteOrder(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
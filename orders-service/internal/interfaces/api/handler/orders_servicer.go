@@ -6,7 +6,9 @@ import (
 )
 
 type OrdersServicer interface {
-	CreateOrder(ctx context.Context, userID string) (*orders.Order, error)
+	CreateOrder(ctx context.Context, userID string, items []orders.LineItem) (*orders.Order, error)
 	GetUserOrders(ctx context.Context, userID string) ([]*orders.Order, error)
 	GetOrder(ctx context.Context, orderID string) (*orders.Order, error)
+	CancelOrder(ctx context.Context, orderID string, reason string) (*orders.Order, error)
+	CompleteOrder(ctx context.Context, orderID string) (*orders.Order, error)
 }
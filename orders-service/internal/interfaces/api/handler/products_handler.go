@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"contracts/money"
+	"orders-service/internal/domain/products"
+)
+
+type ProductsHandler struct {
+	productsService ProductsServicer
+}
+
+func NewProductsHandler(productsService ProductsServicer) *ProductsHandler {
+	return &ProductsHandler{
+		productsService: productsService,
+	}
+}
+
+type CreateProductRequest struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	PriceMoney  *money.Money `json:"price_money"`
+
+	// Deprecated: use PriceMoney.
+	Price float64 `json:"price"`
+}
+
+// ListProducts возвращает активные товары каталога
+// @Summary List products
+// @Description Get all active products from the catalog
+// @Tags Products
+// @Produce json
+// @Success 200 {array} products.Product
+// @Router /products [get]
+func (h *ProductsHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
+	productsList, err := h.productsService.ListProducts(r.Context())
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to list products"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(productsList)
+}
+
+// GetProduct возвращает товар по идентификатору
+// @Summary Get product
+// @Description Get a single product from the catalog
+// @Tags Products
+// @Produce json
+// @Param product_id path string true "Product ID"
+// @Success 200 {object} products.Product
+// @Failure 404 {object} ErrorResponse
+// @Router /products/{product_id} [get]
+func (h *ProductsHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
+	productID := r.PathValue("id")
+	if productID == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Product ID is required"})
+		return
+	}
+
+	product, err := h.productsService.GetProduct(r.Context(), productID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, products.ErrProductNotFound) {
+			status = http.StatusNotFound
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(product)
+}
+
+// CreateProduct добавляет товар в каталог
+// @Summary Create product
+// @Description Add a new product to the catalog
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param request body CreateProductRequest true "Product creation request"
+// @Success 201 {object} products.Product
+// @Failure 400 {object} ErrorResponse
+// @Router /products [post]
+func (h *ProductsHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	var req CreateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	price := money.FromFloat(req.Price, money.USD)
+	if req.PriceMoney != nil {
+		price = *req.PriceMoney
+	}
+
+	product, err := h.productsService.CreateProduct(r.Context(), req.Name, req.Description, price)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, products.ErrInvalidProduct) {
+			status = http.StatusBadRequest
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(product)
+}
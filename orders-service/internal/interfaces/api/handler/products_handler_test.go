@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"contracts/money"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"orders-service/internal/domain/products"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProductsService struct {
+	mock.Mock
+}
+
+func (m *MockProductsService) CreateProduct(ctx context.Context, name, description string, price money.Money) (*products.Product, error) {
+	args := m.Called(ctx, name, description, price)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*products.Product), args.Error(1)
+}
+
+func (m *MockProductsService) GetProduct(ctx context.Context, productID string) (*products.Product, error) {
+	args := m.Called(ctx, productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*products.Product), args.Error(1)
+}
+
+func (m *MockProductsService) ListProducts(ctx context.Context) ([]*products.Product, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*products.Product), args.Error(1)
+}
+
+func TestProductsHandler_ListProducts(t *testing.T) {
+	mockService := new(MockProductsService)
+	handler := NewProductsHandler(mockService)
+
+	expected := []*products.Product{{ID: "product-1", Name: "Keyboard", Price: money.New(4999, money.USD)}}
+	mockService.On("ListProducts", mock.Anything).Return(expected, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/products", nil)
+	rr := httptest.NewRecorder()
+
+	handler.ListProducts(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp []*products.Product
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, expected, resp)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductsHandler_GetProduct(t *testing.T) {
+	mockService := new(MockProductsService)
+	handler := NewProductsHandler(mockService)
+
+	t.Run("not found", func(t *testing.T) {
+		mockService.On("GetProduct", mock.Anything, "missing").Return(nil, products.ErrProductNotFound).Once()
+
+		req := httptest.NewRequest(http.MethodGet, "/products/missing", nil)
+		req.SetPathValue("id", "missing")
+		rr := httptest.NewRecorder()
+
+		handler.GetProduct(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestProductsHandler_CreateProduct(t *testing.T) {
+	mockService := new(MockProductsService)
+	handler := NewProductsHandler(mockService)
+
+	t.Run("success", func(t *testing.T) {
+		product := &products.Product{ID: "product-1", Name: "Keyboard", Price: money.New(4999, money.USD)}
+		mockService.On("CreateProduct", mock.Anything, "Keyboard", "", money.New(4999, money.USD)).Return(product, nil).Once()
+
+		price := money.New(4999, money.USD)
+		reqBody, _ := json.Marshal(CreateProductRequest{Name: "Keyboard", PriceMoney: &price})
+		req := httptest.NewRequest(http.MethodPost, "/products", bytes.NewBuffer(reqBody))
+		rr := httptest.NewRecorder()
+
+		handler.CreateProduct(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("legacy float price", func(t *testing.T) {
+		product := &products.Product{ID: "product-2", Name: "Mouse", Price: money.New(1010, money.USD)}
+		mockService.On("CreateProduct", mock.Anything, "Mouse", "", money.New(1010, money.USD)).Return(product, nil).Once()
+
+		req := httptest.NewRequest(http.MethodPost, "/products", bytes.NewBufferString(`{"name":"Mouse","price":10.10}`))
+		rr := httptest.NewRecorder()
+
+		handler.CreateProduct(rr, req)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid product", func(t *testing.T) {
+		mockService.On("CreateProduct", mock.Anything, "", "", money.Zero(money.USD)).Return(nil, products.ErrInvalidProduct).Once()
+
+		reqBody, _ := json.Marshal(CreateProductRequest{})
+		req := httptest.NewRequest(http.MethodPost, "/products", bytes.NewBuffer(reqBody))
+		rr := httptest.NewRecorder()
+
+		handler.CreateProduct(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
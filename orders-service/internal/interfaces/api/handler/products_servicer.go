@@ -0,0 +1,13 @@
+package handler
+
+import (
+	"context"
+	"contracts/money"
+	"orders-service/internal/domain/products"
+)
+
+type ProductsServicer interface {
+	CreateProduct(ctx context.Context, name, description string, price money.Money) (*products.Product, error)
+	GetProduct(ctx context.Context, productID string) (*products.Product, error)
+	ListProducts(ctx context.Context) ([]*products.Product, error)
+}
@@ -0,0 +1,28 @@
+package router
+
+import (
+	"net/http"
+	"orders-service/internal/interfaces/api/middleware"
+
+	"messaging/relay"
+)
+
+// AdminRouter обслуживает служебные эндпоинты на отдельном порту (server.admin_port),
+// который не публикуется наружу и не проксируется gateway
+type AdminRouter struct {
+	deadLettersHandler *relay.DeadLettersHandler
+}
+
+func NewAdminRouter(deadLettersHandler *relay.DeadLettersHandler) *AdminRouter {
+	return &AdminRouter{
+		deadLettersHandler: deadLettersHandler,
+	}
+}
+
+func (r *AdminRouter) SetupRoutes() http.Handler {
+	mux := http.NewServeMux()
+
+	r.deadLettersHandler.RegisterRoutes(mux)
+
+	return middleware.Correlation(mux)
+}
@@ -1,22 +1,30 @@
 package router
 
 import (
+	"messaging/idempotency"
 	"net/http"
 	"orders-service/internal/infrastructure/sse"
 	"orders-service/internal/interfaces/api/handler"
+	"orders-service/internal/interfaces/api/middleware"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type Router struct {
-	infoHandler   *handler.InfoHandler
-	docsHandler   *handler.DocsHandler
-	ordersHandler *handler.OrdersHandler
+	infoHandler     *handler.InfoHandler
+	docsHandler     *handler.DocsHandler
+	ordersHandler   *handler.OrdersHandler
+	productsHandler *handler.ProductsHandler
+	idempotency     *idempotency.Middleware
 }
 
-func NewRouter(ordersService handler.OrdersServicer, sseManager *sse.Manager) *Router {
+func NewRouter(ordersService handler.OrdersServicer, productsService handler.ProductsServicer, sseManager *sse.Manager, idempotencyMiddleware *idempotency.Middleware) *Router {
 	return &Router{
-		infoHandler:   handler.NewInfoHandler(),
-		docsHandler:   handler.NewDocsHandler(),
-		ordersHandler: handler.NewOrdersHandler(ordersService, sseManager),
+		infoHandler:     handler.NewInfoHandler(),
+		docsHandler:     handler.NewDocsHandler(),
+		ordersHandler:   handler.NewOrdersHandler(ordersService, sseManager),
+		productsHandler: handler.NewProductsHandler(productsService),
+		idempotency:     idempotencyMiddleware,
 	}
 }
 
@@ -29,13 +37,22 @@ func (r *Router) SetupRoutes() http.Handler {
 	mux.HandleFunc("GET /orders-api/docs/swagger.json", r.docsHandler.Swagger)
 	mux.HandleFunc("GET /orders-api/scalar", r.docsHandler.ScalarReference)
 
-	mux.HandleFunc("POST /orders-api/orders", r.ordersHandler.CreateOrder)
+	mux.HandleFunc("GET /orders-api/products", r.productsHandler.ListProducts)
+	mux.HandleFunc("POST /orders-api/products", r.productsHandler.CreateProduct)
+	mux.HandleFunc("GET /orders-api/products/{id}", r.productsHandler.GetProduct)
+
+	mux.HandleFunc("POST /orders-api/orders", r.idempotency.Wrap(r.ordersHandler.CreateOrder, idempotency.BodyField("user_id")))
 
 	mux.HandleFunc("GET /orders-api/orders/{id}", r.ordersHandler.GetOrderStatus)
+	mux.HandleFunc("POST /orders-api/orders/{id}/cancel", r.ordersHandler.CancelOrder)
+	mux.HandleFunc("POST /orders-api/orders/{id}/complete", r.ordersHandler.CompleteOrder)
 	mux.HandleFunc("GET /orders-api/orders/user/{id}", r.ordersHandler.GetUserOrders)
 
 	// SSE endpoint for real-time order updates
 	mux.HandleFunc("GET /orders-api/orders/stream", r.ordersHandler.StreamOrderUpdates)
 
-	return mux
+	// Метрики очередей консьюмера Kafka для Prometheus
+	mux.Handle("GET /metrics", promhttp.Handler())
+
+	return middleware.Correlation(mux)
 }
@@ -6,8 +6,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 
+	"contracts/money"
+	"messaging/idempotency"
 	"orders-service/internal/domain/orders"
+	"orders-service/internal/domain/products"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -17,8 +21,8 @@ type MockOrdersService struct {
 	mock.Mock
 }
 
-func (m *MockOrdersService) CreateOrder(ctx context.Context, userID string) (*orders.Order, error) {
-	args := m.Called(ctx, userID)
+func (m *MockOrdersService) CreateOrder(ctx context.Context, userID string, items []orders.LineItem) (*orders.Order, error) {
+	args := m.Called(ctx, userID, items)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -41,10 +45,55 @@ func (m *MockOrdersService) GetOrder(ctx context.Context, orderID string) (*orde
 	return args.Get(0).(*orders.Order), args.Error(1)
 }
 
+func (m *MockOrdersService) CancelOrder(ctx context.Context, orderID string, reason string) (*orders.Order, error) {
+	args := m.Called(ctx, orderID, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*orders.Order), args.Error(1)
+}
+
+func (m *MockOrdersService) CompleteOrder(ctx context.Context, orderID string) (*orders.Order, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*orders.Order), args.Error(1)
+}
+
+type MockProductsService struct {
+	mock.Mock
+}
+
+func (m *MockProductsService) CreateProduct(ctx context.Context, name, description string, price money.Money) (*products.Product, error) {
+	args := m.Called(ctx, name, description, price)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*products.Product), args.Error(1)
+}
+
+func (m *MockProductsService) GetProduct(ctx context.Context, productID string) (*products.Product, error) {
+	args := m.Called(ctx, productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*products.Product), args.Error(1)
+}
+
+func (m *MockProductsService) ListProducts(ctx context.Context) ([]*products.Product, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*products.Product), args.Error(1)
+}
+
 func TestRouter_SetupRoutes(t *testing.T) {
 	mockOrdersService := new(MockOrdersService)
+	mockProductsService := new(MockProductsService)
 
-	router := NewRouter(mockOrdersService, nil)
+	router := NewRouter(mockOrdersService, mockProductsService, nil, idempotency.NewMiddleware(nil, time.Hour))
 	server := httptest.NewServer(router.SetupRoutes())
 	defer server.Close()
 
@@ -60,10 +109,21 @@ func TestRouter_SetupRoutes(t *testing.T) {
 		{"CreateOrder", http.MethodPost, "/orders-api/orders", http.StatusBadRequest},
 		{"GetOrderStatus", http.MethodGet, "/orders-api/orders/some-id", http.StatusNotFound},
 		{"GetUserOrders", http.MethodGet, "/orders-api/orders/user/some-id", http.StatusInternalServerError},
+		{"CancelOrder", http.MethodPost, "/orders-api/orders/some-id/cancel", http.StatusNotFound},
+		{"CompleteOrder", http.MethodPost, "/orders-api/orders/some-id/complete", http.StatusNotFound},
+		{"ListProducts", http.MethodGet, "/orders-api/products", http.StatusOK},
+		{"GetProduct", http.MethodGet, "/orders-api/products/some-id", http.StatusNotFound},
+		{"CreateProduct", http.MethodPost, "/orders-api/products", http.StatusBadRequest},
+		// Dead letters доступны только на внутреннем admin-порту
+		{"DeadLetters", http.MethodGet, "/orders-api/admin/dead-letters/outbox", http.StatusNotFound},
 	}
 
 	mockOrdersService.On("GetOrder", mock.Anything, "some-id").Return(nil, assert.AnError)
 	mockOrdersService.On("GetUserOrders", mock.Anything, "some-id").Return(nil, assert.AnError)
+	mockOrdersService.On("CancelOrder", mock.Anything, "some-id", mock.Anything).Return(nil, orders.ErrOrderNotFound)
+	mockOrdersService.On("CompleteOrder", mock.Anything, "some-id").Return(nil, orders.ErrOrderNotFound)
+	mockProductsService.On("ListProducts", mock.Anything).Return([]*products.Product{}, nil)
+	mockProductsService.On("GetProduct", mock.Anything, "some-id").Return(nil, products.ErrProductNotFound)
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
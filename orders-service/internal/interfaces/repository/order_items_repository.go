@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"orders-service/internal/domain/orders"
+)
+
+type OrderItemsRepository interface {
+	StoreWithTx(ctx context.Context, tx *sql.Tx, items []*orders.OrderItem) error
+	GetByOrderIDs(ctx context.Context, orderIDs []string) ([]*orders.OrderItem, error)
+}
@@ -10,6 +10,7 @@ type OrdersRepository interface {
 	Store(ctx context.Context, order *orders.Order) error
 	StoreWithTx(ctx context.Context, tx *sql.Tx, order *orders.Order) error
 	GetByID(ctx context.Context, orderID string) (*orders.Order, error)
+	GetByIDWithTx(ctx context.Context, tx *sql.Tx, orderID string) (*orders.Order, error)
 	GetByUserID(ctx context.Context, userID string) ([]*orders.Order, error)
 	Update(ctx context.Context, order *orders.Order) error
 	UpdateWithTx(ctx context.Context, tx *sql.Tx, order *orders.Order) error
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"orders-service/internal/domain/products"
+)
+
+type ProductsRepository interface {
+	Store(ctx context.Context, product *products.Product) error
+	GetByID(ctx context.Context, productID string) (*products.Product, error)
+	GetByIDs(ctx context.Context, productIDs []string) ([]*products.Product, error)
+	List(ctx context.Context) ([]*products.Product, error)
+}
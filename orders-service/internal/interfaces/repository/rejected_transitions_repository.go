@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"orders-service/internal/domain/orders"
+)
+
+type RejectedTransitionsRepository interface {
+	StoreWithTx(ctx context.Context, tx *sql.Tx, transition *orders.RejectedTransition) error
+}
@@ -1,61 +0,0 @@
-package kafka
-
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"log"
-
-	"github.com/IBM/sarama"
-)
-
-type Producer struct {
-	producer sarama.SyncProducer
-}
-
-type Event struct {
-	EventType string                 `json:"event_type"`
-	EventID   string                 `json:"event_id"`
-	Data      map[string]interface{} `json:"data"`
-	Timestamp int64                  `json:"timestamp"`
-}
-
-func NewProducer(brokers []string) (*Producer, error) {
-	config := sarama.NewConfig()
-	config.Producer.Return.Successes = true
-	config.Producer.RequiredAcks = sarama.WaitForAll
-	config.Producer.Retry.Max = 5
-	config.Producer.Partitioner = sarama.NewRandomPartitioner
-
-	producer, err := sarama.NewSyncProducer(brokers, config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create producer: %w", err)
-	}
-
-	return &Producer{producer: producer}, nil
-}
-
-func (p *Producer) PublishEvent(ctx context.Context, topic string, event Event) error {
-	eventBytes, err := json.Marshal(event)
-	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
-	}
-
-	msg := &sarama.ProducerMessage{
-		Topic: topic,
-		Value: sarama.StringEncoder(eventBytes),
-		Key:   sarama.StringEncoder(event.EventID),
-	}
-
-	partition, offset, err := p.producer.SendMessage(msg)
-	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
-	}
-
-	log.Printf("Event published to topic %s, partition %d, offset %d", topic, partition, offset)
-	return nil
-}
-
-func (p *Producer) Close() error {
-	return p.producer.Close()
-}
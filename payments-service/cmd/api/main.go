@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	msgpostgres "messaging/postgres"
 	"net/http"
 	"os"
 	"os/signal"
@@ -58,6 +59,9 @@ func runMigrations() {
 	if err := postgres.RunMigrations(db); err != nil {
 		log.Fatalf("failed to run migrations: %v", err)
 	}
+	if err := msgpostgres.RunMigrations(db); err != nil {
+		log.Fatalf("failed to run messaging migrations: %v", err)
+	}
 	fmt.Println("Migrations completed successfully.")
 }
 
@@ -71,6 +75,9 @@ func runServer() {
 	defer cancel()
 
 	app.InboxProcessor.RegisterHandler("order.created", app.PaymentsService.ProcessOrderCreated)
+	app.InboxProcessor.RegisterHandler("order.cancelled", app.PaymentsService.ProcessOrderCancelled)
+	app.InboxProcessor.RegisterHandler("order.completed", app.PaymentsService.ProcessOrderCompleted)
+	app.InboxProcessor.RegisterHandler("account.topped_up", app.PaymentsService.ProcessAccountToppedUp)
 
 	log.Println("Starting inbox processor...")
 	app.InboxProcessor.Start(ctx)
@@ -78,10 +85,17 @@ func runServer() {
 	log.Println("Starting outbox publisher...")
 	app.OutboxPublisher.Start(ctx)
 
+	log.Println("Starting hold sweeper...")
+	app.HoldSweeper.Start(ctx)
+
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", app.Config.Server.Port),
 		Handler: app.Router.SetupRoutes(),
 	}
+	adminServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", app.Config.Server.AdminPort),
+		Handler: app.AdminRouter.SetupRoutes(),
+	}
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -93,6 +107,13 @@ func runServer() {
 		}
 	}()
 
+	go func() {
+		log.Printf("Starting admin server on internal port %d", app.Config.Server.AdminPort)
+		if err := adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start admin server: %v", err)
+		}
+	}()
+
 	<-quit
 	log.Println("Shutting down server...")
 
@@ -102,9 +123,15 @@ func runServer() {
 	log.Println("Stopping outbox publisher...")
 	app.OutboxPublisher.Stop()
 
+	log.Println("Stopping hold sweeper...")
+	app.HoldSweeper.Stop()
+
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
 
+	if err := adminServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Admin server forced to shutdown: %v", err)
+	}
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
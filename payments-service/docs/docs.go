@@ -58,7 +58,7 @@ const docTemplate = `{
         },
         "/accounts/{user_id}": {
             "get": {
-                "description": "Get account details by user ID",
+                "description": "Get the primary wallet and all currency wallets by user ID",
                 "produces": [
                     "application/json"
                 ],
@@ -99,7 +99,7 @@ const docTemplate = `{
         },
         "/accounts/{user_id}/topup": {
             "post": {
-                "description": "Add funds to an existing account",
+                "description": "Add funds to the user's wallet in the amount currency; the wallet is opened on first top-up",
                 "consumes": [
                     "application/json"
                 ],
@@ -118,6 +118,12 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Key to safely retry the request; a replay returns the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "Top up request",
                         "name": "request",
@@ -141,6 +147,77 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Request with the same Idempotency-Key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key was used with a different body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{user_id}/transactions": {
+            "get": {
+                "description": "Ledger entries of all user wallets, newest first, with cursor pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "List account transactions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TransactionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -179,12 +256,22 @@ const docTemplate = `{
         "handler.AccountInfoResponse": {
             "type": "object",
             "properties": {
+                "available_money": {
+                    "$ref": "#/definitions/money.Money"
+                },
                 "balance": {
+                    "description": "Deprecated: use BalanceMoney.",
                     "type": "number"
                 },
+                "balance_money": {
+                    "$ref": "#/definitions/money.Money"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "current_money": {
+                    "$ref": "#/definitions/money.Money"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -193,6 +280,12 @@ const docTemplate = `{
                 },
                 "user_id": {
                     "type": "string"
+                },
+                "wallets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.WalletResponse"
+                    }
                 }
             }
         },
@@ -200,8 +293,12 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "balance": {
+                    "description": "Deprecated: use BalanceMoney.",
                     "type": "number"
                 },
+                "balance_money": {
+                    "$ref": "#/definitions/money.Money"
+                },
                 "created_at": {
                     "type": "string"
                 },
@@ -225,16 +322,30 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "amount": {
+                    "description": "Deprecated: use AmountMoney.",
                     "type": "number"
+                },
+                "amount_money": {
+                    "$ref": "#/definitions/money.Money"
                 }
             }
         },
         "handler.TopUpAccountResponse": {
             "type": "object",
             "properties": {
+                "available_money": {
+                    "$ref": "#/definitions/money.Money"
+                },
                 "balance": {
+                    "description": "Deprecated: use BalanceMoney.",
                     "type": "number"
                 },
+                "balance_money": {
+                    "$ref": "#/definitions/money.Money"
+                },
+                "current_money": {
+                    "$ref": "#/definitions/money.Money"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -245,6 +356,78 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "handler.TransactionResponse": {
+            "type": "object",
+            "properties": {
+                "amount_money": {
+                    "$ref": "#/definitions/money.Money"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "direction": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "string"
+                },
+                "wallet_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.TransactionsResponse": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.TransactionResponse"
+                    }
+                }
+            }
+        },
+        "handler.WalletResponse": {
+            "type": "object",
+            "properties": {
+                "available_money": {
+                    "$ref": "#/definitions/money.Money"
+                },
+                "balance_money": {
+                    "$ref": "#/definitions/money.Money"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "current_money": {
+                    "$ref": "#/definitions/money.Money"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "money.Money": {
+            "type": "object"
         }
     }
 }`
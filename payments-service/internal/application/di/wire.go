@@ -6,12 +6,17 @@ package di
 import (
 	"database/sql"
 
+	"messaging/idempotency"
+	msgpostgres "messaging/postgres"
+	"messaging/relay"
 	"payments-service/internal/application/service"
 	"payments-service/internal/infrastructure/brokers/kafka"
 	"payments-service/internal/infrastructure/config"
+	staticfx "payments-service/internal/infrastructure/fx"
 	"payments-service/internal/infrastructure/persistence/postgres"
 	"payments-service/internal/interfaces/api/handler"
 	"payments-service/internal/interfaces/api/router"
+	"payments-service/internal/interfaces/fx"
 	"payments-service/internal/interfaces/repository"
 	"payments-service/pkg/random"
 
@@ -20,9 +25,13 @@ import (
 
 var RepositorySet = wire.NewSet(
 	postgres.NewAccountRepository,
+	postgres.NewLedgerRepository,
 	postgres.NewPaymentsRepository,
-	postgres.NewInboxRepository,
-	postgres.NewOutboxRepository,
+	msgpostgres.NewInboxRepository,
+	msgpostgres.NewPoisonMessageRepository,
+	msgpostgres.NewOutboxRepository,
+	msgpostgres.NewIdempotencyRepository,
+	msgpostgres.NewDeadLetterRepository,
 )
 
 var RandomSet = wire.NewSet(
@@ -30,17 +39,31 @@ var RandomSet = wire.NewSet(
 	wire.Bind(new(random.Generator), new(*random.CryptoGenerator)),
 )
 
+var FXSet = wire.NewSet(
+	NewRateProvider,
+	wire.Bind(new(fx.RateProvider), new(*staticfx.StaticRateProvider)),
+)
+
 var ServiceSet = wire.NewSet(
+	NewHoldConfig,
+	NewFundsWaitConfig,
 	service.NewPaymentsService,
 	service.NewAccountService,
+	service.NewHoldSweeper,
+	relay.NewDeadLetterService,
+	wire.Bind(new(relay.DeadLettersServicer), new(*relay.DeadLetterService)),
 )
 
 var HandlerSet = wire.NewSet(
 	handler.NewAccountsHandler,
+	relay.NewDeadLettersHandler,
+	NewIdempotencyMiddleware,
 )
 
 var KafkaSet = wire.NewSet(
 	kafka.NewConfig,
+	NewOutboxListener,
+	wire.Bind(new(repository.OutboxNotifier), new(*msgpostgres.OutboxListener)),
 	NewOutboxPublisher,
 	NewInboxProcessor,
 )
@@ -52,10 +75,12 @@ func InitializeApplication() (*Application, error) {
 		NewPostgresConfig,
 		RepositorySet,
 		RandomSet,
+		FXSet,
 		ServiceSet,
 		HandlerSet,
 		KafkaSet,
 		router.NewRouter,
+		router.NewAdminRouter,
 		postgres.NewDb,
 		wire.Bind(new(service.DBTX), new(*sql.DB)),
 		NewApplication,
@@ -78,11 +103,46 @@ func NewPostgresConfig(config *config.Config) *postgres.Config {
 	}
 }
 
+func NewOutboxListener(config *postgres.Config) (*msgpostgres.OutboxListener, error) {
+	return msgpostgres.NewOutboxListener(config.DSN())
+}
+
+func NewRateProvider(config *config.Config) *staticfx.StaticRateProvider {
+	provider, err := staticfx.LoadStaticRateProvider(config.GetFXRatesPath())
+	if err != nil {
+		panic(err)
+	}
+	return provider
+}
+
+func NewHoldConfig(config *config.Config) service.HoldConfig {
+	return service.HoldConfig{
+		Expiry:        config.GetHoldExpiry(),
+		SweepInterval: config.GetHoldSweepInterval(),
+		BatchSize:     config.GetHoldBatchSize(),
+	}
+}
+
+func NewIdempotencyMiddleware(
+	idempotencyRepo repository.IdempotencyRepository,
+	config *config.Config,
+) *idempotency.Middleware {
+	return idempotency.NewMiddleware(idempotencyRepo, config.GetIdempotencyTTL())
+}
+
+func NewFundsWaitConfig(config *config.Config) service.FundsWaitConfig {
+	return service.FundsWaitConfig{
+		Wait:      config.GetFundsWait(),
+		BatchSize: config.GetFundsWaitBatchSize(),
+	}
+}
+
 func NewOutboxPublisher(
 	outboxRepo repository.OutboxRepository,
+	notifier repository.OutboxNotifier,
 	kafkaConfig *kafka.Config,
-) *kafka.OutboxPublisher {
-	publisher, err := kafka.NewOutboxPublisher(outboxRepo, kafkaConfig)
+) *relay.OutboxPublisher {
+	publisher, err := relay.NewOutboxPublisher(outboxRepo, notifier, &kafkaConfig.Config)
 	if err != nil {
 		panic(err)
 	}
@@ -90,10 +150,14 @@ func NewOutboxPublisher(
 }
 
 func NewInboxProcessor(
+	db *sql.DB,
 	inboxRepo repository.InboxRepository,
+	poisonRepo repository.PoisonMessageRepository,
 	kafkaConfig *kafka.Config,
-) *kafka.InboxProcessor {
-	processor, err := kafka.NewInboxProcessor(inboxRepo, kafkaConfig)
+) *relay.InboxProcessor {
+	// Собственные события нужны, чтобы после пополнения повторить отложенные платежи
+	processor, err := relay.NewInboxProcessor(db, inboxRepo, poisonRepo, &kafkaConfig.Config,
+		kafkaConfig.GetOrdersEventsTopic(), kafkaConfig.GetPaymentsEventsTopic())
 	if err != nil {
 		panic(err)
 	}
@@ -102,28 +166,34 @@ func NewInboxProcessor(
 
 type Application struct {
 	Router          *router.Router
+	AdminRouter     *router.AdminRouter
 	Config          *config.Config
 	PaymentsService *service.PaymentsService
 	AccountService  *service.AccountService
-	OutboxPublisher *kafka.OutboxPublisher
-	InboxProcessor  *kafka.InboxProcessor
+	HoldSweeper     *service.HoldSweeper
+	OutboxPublisher *relay.OutboxPublisher
+	InboxProcessor  *relay.InboxProcessor
 	DB              *sql.DB
 }
 
 func NewApplication(
 	router *router.Router,
+	adminRouter *router.AdminRouter,
 	config *config.Config,
 	paymentsService *service.PaymentsService,
 	accountService *service.AccountService,
-	outboxPublisher *kafka.OutboxPublisher,
-	inboxProcessor *kafka.InboxProcessor,
+	holdSweeper *service.HoldSweeper,
+	outboxPublisher *relay.OutboxPublisher,
+	inboxProcessor *relay.InboxProcessor,
 	db *sql.DB,
 ) *Application {
 	return &Application{
 		Router:          router,
+		AdminRouter:     adminRouter,
 		Config:          config,
 		PaymentsService: paymentsService,
 		AccountService:  accountService,
+		HoldSweeper:     holdSweeper,
 		OutboxPublisher: outboxPublisher,
 		InboxProcessor:  inboxProcessor,
 		DB:              db,
@@ -9,12 +9,17 @@ package di
 import (
 	"database/sql"
 	"github.com/google/wire"
+	"messaging/idempotency"
+	postgres2 "messaging/postgres"
+	"messaging/relay"
 	"payments-service/internal/application/service"
 	"payments-service/internal/infrastructure/brokers/kafka"
 	"payments-service/internal/infrastructure/config"
+	fx2 "payments-service/internal/infrastructure/fx"
 	"payments-service/internal/infrastructure/persistence/postgres"
 	"payments-service/internal/interfaces/api/handler"
 	"payments-service/internal/interfaces/api/router"
+	"payments-service/internal/interfaces/fx"
 	"payments-service/internal/interfaces/repository"
 	"payments-service/pkg/random"
 )
@@ -30,32 +35,55 @@ func InitializeApplication() (*Application, error) {
 		return nil, err
 	}
 	accountRepository := postgres.NewAccountRepository(db)
-	accountService := service.NewAccountService(accountRepository)
+	ledgerRepository := postgres.NewLedgerRepository(db)
+	v := postgres2.NewOutboxRepository(db)
+	accountService := service.NewAccountService(db, accountRepository, ledgerRepository, v)
 	accountsHandler := handler.NewAccountsHandler(accountService)
-	routerRouter := router.NewRouter(accountsHandler)
+	v2 := postgres2.NewIdempotencyRepository(db)
+	middleware := NewIdempotencyMiddleware(v2, configConfig)
+	routerRouter := router.NewRouter(accountsHandler, middleware)
+	repository := postgres2.NewDeadLetterRepository(db)
+	deadLetterService := relay.NewDeadLetterService(repository)
+	deadLettersHandler := relay.NewDeadLettersHandler(deadLetterService)
+	adminRouter := router.NewAdminRouter(deadLettersHandler)
 	paymentsRepository := postgres.NewPaymentsRepository(db)
-	inboxRepository := postgres.NewInboxRepository(db)
-	outboxRepository := postgres.NewOutboxRepository(db)
+	v3 := postgres2.NewInboxRepository(db)
 	cryptoGenerator := random.NewCryptoGenerator()
-	paymentsService := service.NewPaymentsService(db, paymentsRepository, accountRepository, inboxRepository, outboxRepository, cryptoGenerator)
+	staticRateProvider := NewRateProvider(configConfig)
+	holdConfig := NewHoldConfig(configConfig)
+	fundsWaitConfig := NewFundsWaitConfig(configConfig)
+	paymentsService := service.NewPaymentsService(db, paymentsRepository, accountRepository, ledgerRepository, v3, v, cryptoGenerator, staticRateProvider, holdConfig, fundsWaitConfig)
+	holdSweeper := service.NewHoldSweeper(paymentsService, holdConfig)
+	outboxListener, err := NewOutboxListener(postgresConfig)
+	if err != nil {
+		return nil, err
+	}
 	kafkaConfig := kafka.NewConfig(configConfig)
-	outboxPublisher := NewOutboxPublisher(outboxRepository, kafkaConfig)
-	inboxProcessor := NewInboxProcessor(inboxRepository, kafkaConfig)
-	application := NewApplication(routerRouter, configConfig, paymentsService, accountService, outboxPublisher, inboxProcessor, db)
+	outboxPublisher := NewOutboxPublisher(v, outboxListener, kafkaConfig)
+	v4 := postgres2.NewPoisonMessageRepository(db)
+	inboxProcessor := NewInboxProcessor(db, v3, v4, kafkaConfig)
+	application := NewApplication(routerRouter, adminRouter, configConfig, paymentsService, accountService, holdSweeper, outboxPublisher, inboxProcessor, db)
 	return application, nil
 }
 
 // wire.go:
 
-var RepositorySet = wire.NewSet(postgres.NewAccountRepository, postgres.NewPaymentsRepository, postgres.NewInboxRepository, postgres.NewOutboxRepository)
+var RepositorySet = wire.NewSet(postgres.NewAccountRepository, postgres.NewLedgerRepository, postgres.NewPaymentsRepository, postgres2.NewInboxRepository, postgres2.NewPoisonMessageRepository, postgres2.NewOutboxRepository, postgres2.NewIdempotencyRepository, postgres2.NewDeadLetterRepository)
 
 var RandomSet = wire.NewSet(random.NewCryptoGenerator, wire.Bind(new(random.Generator), new(*random.CryptoGenerator)))
 
-var ServiceSet = wire.NewSet(service.NewPaymentsService, service.NewAccountService)
+var FXSet = wire.NewSet(
+	NewRateProvider, wire.Bind(new(fx.RateProvider), new(*fx2.StaticRateProvider)),
+)
+
+var ServiceSet = wire.NewSet(
+	NewHoldConfig,
+	NewFundsWaitConfig, service.NewPaymentsService, service.NewAccountService, service.NewHoldSweeper, relay.NewDeadLetterService, wire.Bind(new(relay.DeadLettersServicer), new(*relay.DeadLetterService)),
+)
 
-var HandlerSet = wire.NewSet(handler.NewAccountsHandler)
+var HandlerSet = wire.NewSet(handler.NewAccountsHandler, relay.NewDeadLettersHandler, NewIdempotencyMiddleware)
 
-var KafkaSet = wire.NewSet(kafka.NewConfig, NewOutboxPublisher,
+var KafkaSet = wire.NewSet(kafka.NewConfig, NewOutboxListener, wire.Bind(new(repository.OutboxNotifier), new(*postgres2.OutboxListener)), NewOutboxPublisher,
 	NewInboxProcessor,
 )
 
@@ -73,11 +101,45 @@ func NewPostgresConfig(config2 *config.Config) *postgres.Config {
 	}
 }
 
+func NewOutboxListener(config2 *postgres.Config) (*postgres2.OutboxListener, error) {
+	return postgres2.NewOutboxListener(config2.DSN())
+}
+
+func NewRateProvider(config2 *config.Config) *fx2.StaticRateProvider {
+	provider, err := fx2.LoadStaticRateProvider(config2.GetFXRatesPath())
+	if err != nil {
+		panic(err)
+	}
+	return provider
+}
+
+func NewHoldConfig(config2 *config.Config) service.HoldConfig {
+	return service.HoldConfig{
+		Expiry:        config2.GetHoldExpiry(),
+		SweepInterval: config2.GetHoldSweepInterval(),
+		BatchSize:     config2.GetHoldBatchSize(),
+	}
+}
+
+func NewIdempotencyMiddleware(
+	idempotencyRepo repository.IdempotencyRepository, config2 *config.Config,
+) *idempotency.Middleware {
+	return idempotency.NewMiddleware(idempotencyRepo, config2.GetIdempotencyTTL())
+}
+
+func NewFundsWaitConfig(config2 *config.Config) service.FundsWaitConfig {
+	return service.FundsWaitConfig{
+		Wait:      config2.GetFundsWait(),
+		BatchSize: config2.GetFundsWaitBatchSize(),
+	}
+}
+
 func NewOutboxPublisher(
 	outboxRepo repository.OutboxRepository,
+	notifier repository.OutboxNotifier,
 	kafkaConfig *kafka.Config,
-) *kafka.OutboxPublisher {
-	publisher, err := kafka.NewOutboxPublisher(outboxRepo, kafkaConfig)
+) *relay.OutboxPublisher {
+	publisher, err := relay.NewOutboxPublisher(outboxRepo, notifier, &kafkaConfig.Config)
 	if err != nil {
 		panic(err)
 	}
@@ -85,10 +147,14 @@ func NewOutboxPublisher(
 }
 
 func NewInboxProcessor(
+	db *sql.DB,
 	inboxRepo repository.InboxRepository,
+	poisonRepo repository.PoisonMessageRepository,
 	kafkaConfig *kafka.Config,
-) *kafka.InboxProcessor {
-	processor, err := kafka.NewInboxProcessor(inboxRepo, kafkaConfig)
+) *relay.InboxProcessor {
+
+	processor, err := relay.NewInboxProcessor(db, inboxRepo, poisonRepo, &kafkaConfig.Config,
+		kafkaConfig.GetOrdersEventsTopic(), kafkaConfig.GetPaymentsEventsTopic())
 	if err != nil {
 		panic(err)
 	}
@@ -97,26 +163,32 @@ func NewInboxProcessor(
 
 type Application struct {
 	Router          *router.Router
+	AdminRouter     *router.AdminRouter
 	Config          *config.Config
 	PaymentsService *service.PaymentsService
 	AccountService  *service.AccountService
-	OutboxPublisher *kafka.OutboxPublisher
-	InboxProcessor  *kafka.InboxProcessor
+	HoldSweeper     *service.HoldSweeper
+	OutboxPublisher *relay.OutboxPublisher
+	InboxProcessor  *relay.InboxProcessor
 	DB              *sql.DB
 }
 
-func NewApplication(router2 *router.Router, config2 *config.Config,
+func NewApplication(router2 *router.Router,
+	adminRouter *router.AdminRouter, config2 *config.Config,
 	paymentsService *service.PaymentsService,
 	accountService *service.AccountService,
-	outboxPublisher *kafka.OutboxPublisher,
-	inboxProcessor *kafka.InboxProcessor,
+	holdSweeper *service.HoldSweeper,
+	outboxPublisher *relay.OutboxPublisher,
+	inboxProcessor *relay.InboxProcessor,
 	db *sql.DB,
 ) *Application {
 	return &Application{
 		Router:          router2,
+		AdminRouter:     adminRouter,
 		Config:          config2,
 		PaymentsService: paymentsService,
 		AccountService:  accountService,
+		HoldSweeper:     holdSweeper,
 		OutboxPublisher: outboxPublisher,
 		InboxProcessor:  inboxProcessor,
 		DB:              db,
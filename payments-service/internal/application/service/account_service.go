@@ -2,24 +2,51 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 
+	"contracts/events"
+	"contracts/money"
+	"messaging/correlation"
+	"messaging/outbox"
 	"payments-service/internal/domain/account"
+	"payments-service/internal/domain/ledger"
 	"payments-service/internal/interfaces/repository"
 
 	"github.com/gofrs/uuid"
 )
 
+const (
+	defaultTransactionsPageSize = 20
+	maxTransactionsPageSize     = 100
+)
+
 type AccountService struct {
+	db          DBTX
 	accountRepo repository.AccountRepository
+	ledgerRepo  repository.LedgerRepository
+	outboxRepo  repository.OutboxRepository
 }
 
-func NewAccountService(accountRepo repository.AccountRepository) *AccountService {
+func NewAccountService(
+	db DBTX,
+	accountRepo repository.AccountRepository,
+	ledgerRepo repository.LedgerRepository,
+	outboxRepo repository.OutboxRepository,
+) *AccountService {
 	return &AccountService{
+		db:          db,
 		accountRepo: accountRepo,
+		ledgerRepo:  ledgerRepo,
+		outboxRepo:  outboxRepo,
 	}
 }
 
+// CreateAccount заводит пользователя с пустым кошельком в USD.
+// Кошельки в других валютах открываются при первом пополнении.
 func (s *AccountService) CreateAccount(ctx context.Context) (*account.Account, error) {
 	userIDUUID, err := uuid.NewV7()
 	if err != nil {
@@ -29,7 +56,7 @@ func (s *AccountService) CreateAccount(ctx context.Context) (*account.Account, e
 
 	userID := userIDUUID.String()
 
-	acc, err := account.NewAccount(userID)
+	acc, err := account.NewAccount(userID, money.USD)
 	if err != nil {
 		log.Printf("Error creating account: %v", err)
 		return nil, err
@@ -44,10 +71,29 @@ func (s *AccountService) CreateAccount(ctx context.Context) (*account.Account, e
 	return acc, nil
 }
 
-func (s *AccountService) TopUpAccount(ctx context.Context, userID string, amount float64) (*account.Account, error) {
-	acc, err := s.accountRepo.GetByUserID(ctx, userID)
+// TopUpAccount пополняет кошелёк в валюте amount; если такого кошелька
+// у пользователя ещё нет, он создаётся. Кошелёк читается с блокировкой строки,
+// поэтому пополнение не теряется при одновременном списании. Проводка в журнале
+// и событие account.topped_up пишутся в той же транзакции.
+func (s *AccountService) TopUpAccount(ctx context.Context, userID string, amount money.Money) (*account.Account, error) {
+	if err := money.ValidateCurrency(amount.Currency()); err != nil {
+		log.Printf("Error topping up account for user %s: %v", userID, err)
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	isNew := false
+	acc, err := s.accountRepo.GetByUserIDAndCurrencyWithTx(ctx, tx, userID, amount.Currency())
+	if errors.Is(err, account.ErrAccountNotFound) {
+		acc, isNew, err = s.openWallet(ctx, tx, userID, amount.Currency())
+	}
 	if err != nil {
-		log.Printf("Error getting account for user %s: %v", userID, err)
+		log.Printf("Error getting %s account for user %s: %v", amount.Currency(), userID, err)
 		return nil, err
 	}
 
@@ -56,22 +102,139 @@ func (s *AccountService) TopUpAccount(ctx context.Context, userID string, amount
 		return nil, err
 	}
 
-	if err := s.accountRepo.Update(ctx, acc); err != nil {
-		log.Printf("Error updating account %s: %v", acc.ID, err)
+	if isNew {
+		err = s.accountRepo.StoreWithTx(ctx, tx, acc)
+	} else {
+		err = s.accountRepo.UpdateWithTx(ctx, tx, acc)
+	}
+	if err != nil {
+		log.Printf("Error saving account %s: %v", acc.ID, err)
+		return nil, err
+	}
+
+	txn, err := ledger.NewTopUp(acc, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ledger transaction: %w", err)
+	}
+
+	if err := postLedger(ctx, tx, s.ledgerRepo, txn, acc); err != nil {
+		log.Printf("Error posting top-up for account %s: %v", acc.ID, err)
 		return nil, err
 	}
 
-	log.Printf("Account topped up successfully: ID=%s, Amount=%.2f, New Balance=%.2f", acc.ID, amount, acc.Balance)
+	if err := s.storeToppedUpEvent(ctx, tx, acc, amount, txn.ID); err != nil {
+		log.Printf("Error storing top-up event for account %s: %v", acc.ID, err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Account topped up successfully: ID=%s, Amount=%s, New Balance=%s", acc.ID, amount, acc.Balance)
 	return acc, nil
 }
 
-func (s *AccountService) GetAccountInfo(ctx context.Context, userID string) (*account.Account, error) {
-	acc, err := s.accountRepo.GetByUserID(ctx, userID)
+// GetAccountInfo возвращает все кошельки пользователя, первым идёт основной.
+func (s *AccountService) GetAccountInfo(ctx context.Context, userID string) ([]*account.Account, error) {
+	wallets, err := s.accountRepo.ListByUserID(ctx, userID)
 	if err != nil {
-		log.Printf("Error getting account for user %s: %v", userID, err)
+		log.Printf("Error getting accounts for user %s: %v", userID, err)
 		return nil, err
 	}
 
-	log.Printf("Account info retrieved: ID=%s, UserID=%s, Balance=%.2f", acc.ID, acc.UserID, acc.Balance)
-	return acc, nil
+	if len(wallets) == 0 {
+		return nil, fmt.Errorf("%w for user %s", account.ErrAccountNotFound, userID)
+	}
+
+	log.Printf("Account info retrieved: UserID=%s, Wallets=%d", userID, len(wallets))
+	return wallets, nil
+}
+
+// GetTransactions возвращает проводки по кошелькам пользователя, от новых к старым.
+func (s *AccountService) GetTransactions(ctx context.Context, userID, cursor string, limit int) (*ledger.Page, error) {
+	after, err := ledger.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultTransactionsPageSize
+	}
+	if limit > maxTransactionsPageSize {
+		limit = maxTransactionsPageSize
+	}
+
+	// Берём на одну запись больше, чтобы понять, есть ли следующая страница
+	entries, err := s.ledgerRepo.ListEntriesByUserID(ctx, userID, after, limit+1)
+	if err != nil {
+		log.Printf("Error listing transactions for user %s: %v", userID, err)
+		return nil, err
+	}
+
+	page := &ledger.Page{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.NextCursor = ledger.CursorAfter(page.Entries[limit-1]).Encode()
+	}
+
+	return page, nil
+}
+
+// openWallet открывает кошелёк в новой валюте только для уже существующего пользователя.
+// Кошельки пользователя блокируются, после чего валюта проверяется повторно: если
+// параллельное пополнение уже открыло кошелёк, возвращается он, а не дубликат.
+func (s *AccountService) openWallet(ctx context.Context, tx *sql.Tx, userID, currency string) (*account.Account, bool, error) {
+	wallets, err := s.accountRepo.ListByUserIDWithTx(ctx, tx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(wallets) == 0 {
+		return nil, false, fmt.Errorf("%w for user %s", account.ErrAccountNotFound, userID)
+	}
+
+	acc, err := s.accountRepo.GetByUserIDAndCurrencyWithTx(ctx, tx, userID, currency)
+	if err == nil {
+		return acc, false, nil
+	}
+	if !errors.Is(err, account.ErrAccountNotFound) {
+		return nil, false, err
+	}
+
+	acc, err = account.NewAccount(userID, currency)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return acc, true, nil
+}
+
+func (s *AccountService) storeToppedUpEvent(ctx context.Context, tx *sql.Tx, acc *account.Account, amount money.Money, ledgerTxnID string) error {
+	event := events.AccountToppedUpEvent{
+		AccountID:      acc.ID,
+		UserID:         acc.UserID,
+		AmountMoney:    amount,
+		BalanceMoney:   acc.Balance,
+		AvailableMoney: acc.Available(),
+		Currency:       acc.Currency(),
+		TransactionID:  ledgerTxnID,
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal account topped up event: %w", err)
+	}
+
+	outboxMessage, err := outbox.NewOutboxMessage("account.topped_up", acc.UserID, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox message: %w", err)
+	}
+	outboxMessage.Correlate(correlation.FromContext(ctx))
+
+	if err := s.outboxRepo.StoreWithTx(ctx, tx, outboxMessage); err != nil {
+		return fmt.Errorf("failed to store outbox message: %w", err)
+	}
+
+	return nil
 }
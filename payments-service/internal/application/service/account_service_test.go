@@ -2,18 +2,25 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"contracts/events"
+	"contracts/money"
+	"messaging/outbox"
 	"payments-service/internal/domain/account"
+	"payments-service/internal/domain/ledger"
 )
 
 func TestAccountService_CreateAccount(t *testing.T) {
 	mockAccountRepo := new(MockAccountRepository)
-	service := NewAccountService(mockAccountRepo)
+	service := NewAccountService(nil, mockAccountRepo, nil, nil)
 	ctx := context.Background()
 
 	mockAccountRepo.On("Store", ctx, mock.AnythingOfType("*account.Account")).Return(nil)
@@ -26,15 +33,32 @@ func TestAccountService_CreateAccount(t *testing.T) {
 }
 
 func TestAccountService_TopUpAccount(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
 	mockAccountRepo := new(MockAccountRepository)
-	service := NewAccountService(mockAccountRepo)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	service := NewAccountService(&safeDB{DB: db}, mockAccountRepo, mockLedgerRepo, mockOutboxRepo)
 	ctx := context.Background()
 	userID := "user-123"
-	amount := 100.50
+	amount := money.New(10050, money.USD)
+
+	existingAccount, _ := account.NewAccount(userID, money.USD)
 
-	existingAccount, _ := account.NewAccount(userID)
-	mockAccountRepo.On("GetByUserID", ctx, userID).Return(existingAccount, nil)
-	mockAccountRepo.On("Update", ctx, existingAccount).Return(nil)
+	mockSQL.ExpectBegin()
+	mockAccountRepo.On("GetByUserIDAndCurrencyWithTx", ctx, mock.Anything, userID, money.USD).Return(existingAccount, nil)
+	mockAccountRepo.On("UpdateWithTx", ctx, mock.Anything, existingAccount).Return(nil)
+	expectLedgerPosting(mockLedgerRepo, ctx, ledger.KindTopUp, existingAccount.ID, amount)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
+		var event events.AccountToppedUpEvent
+		if msg.EventType != "account.topped_up" || json.Unmarshal(msg.Payload, &event) != nil {
+			return false
+		}
+		return event.AccountID == existingAccount.ID && event.AmountMoney == amount && event.BalanceMoney == amount
+	})).Return(nil)
+	mockSQL.ExpectCommit()
 
 	acc, err := service.TopUpAccount(ctx, userID, amount)
 
@@ -42,28 +66,194 @@ func TestAccountService_TopUpAccount(t *testing.T) {
 	assert.NotNil(t, acc)
 	assert.Equal(t, amount, acc.Balance)
 	mockAccountRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestAccountService_TopUpAccount_LedgerMismatchRollsBack(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockAccountRepo := new(MockAccountRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	service := NewAccountService(&safeDB{DB: db}, mockAccountRepo, mockLedgerRepo, mockOutboxRepo)
+	ctx := context.Background()
+	userID := "user-123"
+
+	// Баланс кошелька изменили в обход журнала
+	existingAccount, _ := account.NewAccount(userID, money.USD)
+	_ = existingAccount.Credit(money.New(500, money.USD))
+
+	mockSQL.ExpectBegin()
+	mockAccountRepo.On("GetByUserIDAndCurrencyWithTx", ctx, mock.Anything, userID, money.USD).Return(existingAccount, nil)
+	mockAccountRepo.On("UpdateWithTx", ctx, mock.Anything, existingAccount).Return(nil)
+	expectLedgerPosting(mockLedgerRepo, ctx, ledger.KindTopUp, existingAccount.ID, money.New(1000, money.USD))
+	mockSQL.ExpectRollback()
+
+	_, err = service.TopUpAccount(ctx, userID, money.New(1000, money.USD))
+
+	assert.ErrorIs(t, err, ledger.ErrBalanceMismatch)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
 }
 
 func TestAccountService_GetAccountInfo(t *testing.T) {
 	mockAccountRepo := new(MockAccountRepository)
-	service := NewAccountService(mockAccountRepo)
+	service := NewAccountService(nil, mockAccountRepo, nil, nil)
 	ctx := context.Background()
 	userID := "user-123"
 
-	existingAccount, _ := account.NewAccount(userID)
-	mockAccountRepo.On("GetByUserID", ctx, userID).Return(existingAccount, nil)
+	existingAccount, _ := account.NewAccount(userID, money.USD)
+	eurWallet, _ := account.NewAccount(userID, money.EUR)
+	mockAccountRepo.On("ListByUserID", ctx, userID).Return([]*account.Account{existingAccount, eurWallet}, nil)
 
-	acc, err := service.GetAccountInfo(ctx, userID)
+	wallets, err := service.GetAccountInfo(ctx, userID)
 
 	assert.NoError(t, err)
-	assert.NotNil(t, acc)
-	assert.Equal(t, existingAccount.ID, acc.ID)
+	assert.Len(t, wallets, 2)
+	assert.Equal(t, existingAccount.ID, wallets[0].ID)
+	mockAccountRepo.AssertExpectations(t)
+}
+
+func TestAccountService_TopUpAccount_OpensWalletInNewCurrency(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockAccountRepo := new(MockAccountRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	service := NewAccountService(&safeDB{DB: db}, mockAccountRepo, mockLedgerRepo, mockOutboxRepo)
+	ctx := context.Background()
+	userID := "user-123"
+	amount := money.New(5000, money.EUR)
+
+	usdWallet, _ := account.NewAccount(userID, money.USD)
+
+	mockSQL.ExpectBegin()
+	mockAccountRepo.On("GetByUserIDAndCurrencyWithTx", ctx, mock.Anything, userID, money.EUR).Return(nil, account.ErrAccountNotFound)
+	mockAccountRepo.On("ListByUserIDWithTx", ctx, mock.Anything, userID).Return([]*account.Account{usdWallet}, nil)
+	mockAccountRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(acc *account.Account) bool {
+		return acc.UserID == userID && acc.Balance == amount
+	})).Return(nil)
+	mockLedgerRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*ledger.Transaction")).Return(nil)
+	mockLedgerRepo.On("BalanceWithTx", ctx, mock.Anything, mock.AnythingOfType("string"), money.EUR).Return(amount, nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Return(nil)
+	mockSQL.ExpectCommit()
+
+	acc, err := service.TopUpAccount(ctx, userID, amount)
+
+	assert.NoError(t, err)
+	assert.Equal(t, money.EUR, acc.Currency())
+	assert.Equal(t, amount, acc.Balance)
+	mockAccountRepo.AssertNotCalled(t, "UpdateWithTx", mock.Anything, mock.Anything, mock.Anything)
 	mockAccountRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestAccountService_TopUpAccount_WalletOpenedConcurrently(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockAccountRepo := new(MockAccountRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	service := NewAccountService(&safeDB{DB: db}, mockAccountRepo, mockLedgerRepo, mockOutboxRepo)
+	ctx := context.Background()
+	userID := "user-123"
+	amount := money.New(5000, money.EUR)
+
+	usdWallet, _ := account.NewAccount(userID, money.USD)
+	// Кошелёк в EUR открыло параллельное пополнение, пока мы ждали блокировку
+	eurWallet, _ := account.NewAccount(userID, money.EUR)
+
+	mockSQL.ExpectBegin()
+	mockAccountRepo.On("GetByUserIDAndCurrencyWithTx", ctx, mock.Anything, userID, money.EUR).Return(nil, account.ErrAccountNotFound).Once()
+	mockAccountRepo.On("ListByUserIDWithTx", ctx, mock.Anything, userID).Return([]*account.Account{usdWallet, eurWallet}, nil)
+	mockAccountRepo.On("GetByUserIDAndCurrencyWithTx", ctx, mock.Anything, userID, money.EUR).Return(eurWallet, nil).Once()
+	mockAccountRepo.On("UpdateWithTx", ctx, mock.Anything, eurWallet).Return(nil)
+	expectLedgerPosting(mockLedgerRepo, ctx, ledger.KindTopUp, eurWallet.ID, amount)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Return(nil)
+	mockSQL.ExpectCommit()
+
+	acc, err := service.TopUpAccount(ctx, userID, amount)
+
+	assert.NoError(t, err)
+	assert.Equal(t, eurWallet.ID, acc.ID)
+	mockAccountRepo.AssertNotCalled(t, "StoreWithTx", mock.Anything, mock.Anything, mock.Anything)
+	mockAccountRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestAccountService_TopUpAccount_UnknownUserOrCurrency(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockAccountRepo := new(MockAccountRepository)
+	service := NewAccountService(&safeDB{DB: db}, mockAccountRepo, nil, nil)
+	ctx := context.Background()
+
+	mockSQL.ExpectBegin()
+	mockAccountRepo.On("GetByUserIDAndCurrencyWithTx", ctx, mock.Anything, "ghost", money.EUR).Return(nil, account.ErrAccountNotFound)
+	mockAccountRepo.On("ListByUserIDWithTx", ctx, mock.Anything, "ghost").Return([]*account.Account{}, nil)
+	mockSQL.ExpectRollback()
+
+	_, err = service.TopUpAccount(ctx, "ghost", money.New(5000, money.EUR))
+	assert.ErrorIs(t, err, account.ErrAccountNotFound)
+
+	_, err = service.TopUpAccount(ctx, "user-123", money.New(5000, "JPY"))
+	assert.ErrorIs(t, err, money.ErrUnsupportedCurrency)
+
+	mockAccountRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestAccountService_GetTransactions_Paginates(t *testing.T) {
+	mockLedgerRepo := new(MockLedgerRepository)
+	service := NewAccountService(nil, nil, mockLedgerRepo, nil)
+	ctx := context.Background()
+	userID := "user-123"
+
+	now := time.Now()
+	entries := []*ledger.Entry{
+		{ID: "entry-3", CreatedAt: now},
+		{ID: "entry-2", CreatedAt: now.Add(-time.Second)},
+		{ID: "entry-1", CreatedAt: now.Add(-2 * time.Second)},
+	}
+	mockLedgerRepo.On("ListEntriesByUserID", ctx, userID, (*ledger.Cursor)(nil), 3).Return(entries, nil)
+
+	page, err := service.GetTransactions(ctx, userID, "", 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 2)
+	assert.NotEmpty(t, page.NextCursor)
+
+	cursor, err := ledger.DecodeCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, "entry-2", cursor.ID)
+
+	mockLedgerRepo.On("ListEntriesByUserID", ctx, userID, cursor, 3).Return(entries[2:], nil)
+
+	page, err = service.GetTransactions(ctx, userID, page.NextCursor, 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 1)
+	assert.Empty(t, page.NextCursor)
+
+	_, err = service.GetTransactions(ctx, userID, "%%%", 2)
+	assert.ErrorIs(t, err, ledger.ErrInvalidCursor)
+
+	mockLedgerRepo.AssertExpectations(t)
 }
 
 func TestAccountService_CreateAccount_StoreError(t *testing.T) {
 	mockAccountRepo := new(MockAccountRepository)
-	service := NewAccountService(mockAccountRepo)
+	service := NewAccountService(nil, mockAccountRepo, nil, nil)
 	ctx := context.Background()
 
 	expectedErr := errors.New("store error")
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// HoldSweeper периодически снимает блокировки, которые не были списаны до истечения срока,
+// и отклоняет отложенные платежи, не дождавшиеся пополнения.
+type HoldSweeper struct {
+	paymentsService *PaymentsService
+	interval        time.Duration
+	ticker          *time.Ticker
+	done            chan bool
+}
+
+func NewHoldSweeper(paymentsService *PaymentsService, holdConfig HoldConfig) *HoldSweeper {
+	return &HoldSweeper{
+		paymentsService: paymentsService,
+		interval:        holdConfig.SweepInterval,
+		done:            make(chan bool),
+	}
+}
+
+func (s *HoldSweeper) Start(ctx context.Context) {
+	s.ticker = time.NewTicker(s.interval)
+
+	go func() {
+		for {
+			select {
+			case <-s.ticker.C:
+				released, err := s.paymentsService.ReleaseExpiredHolds(ctx)
+				if err != nil {
+					log.Printf("Error releasing expired holds: %v", err)
+				} else if released > 0 {
+					log.Printf("Released %d expired holds", released)
+				}
+
+				expired, err := s.paymentsService.ExpireAwaitingPayments(ctx)
+				if err != nil {
+					log.Printf("Error expiring payments awaiting funds: %v", err)
+				} else if expired > 0 {
+					log.Printf("Failed %d payments that were awaiting funds", expired)
+				}
+			case <-s.done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (s *HoldSweeper) Stop() {
+	if s.ticker != nil {
+		s.ticker.Stop()
+	}
+	close(s.done)
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"payments-service/internal/domain/account"
+	"payments-service/internal/domain/ledger"
+	"payments-service/internal/interfaces/repository"
+)
+
+// postLedger записывает проводки в журнал в той же транзакции, что и изменение
+// баланса, и сверяет баланс кошелька с журналом. При расхождении транзакция откатывается.
+func postLedger(ctx context.Context, tx *sql.Tx, ledgerRepo repository.LedgerRepository, txn *ledger.Transaction, wallet *account.Account) error {
+	if err := ledgerRepo.StoreWithTx(ctx, tx, txn); err != nil {
+		return fmt.Errorf("failed to store ledger transaction: %w", err)
+	}
+
+	balance, err := ledgerRepo.BalanceWithTx(ctx, tx, wallet.ID, wallet.Currency())
+	if err != nil {
+		return err
+	}
+
+	if balance != wallet.Balance {
+		return fmt.Errorf("%w: account %s has %s, ledger has %s",
+			ledger.ErrBalanceMismatch, wallet.ID, wallet.Balance, balance)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"contracts/events"
+	"contracts/money"
+	"messaging/broker"
+	"messaging/broker/memory"
+	"messaging/correlation"
+	"messaging/inbox"
+	"messaging/inbox/inboxtest"
+	"messaging/outbox"
+	"messaging/relay"
+	"payments-service/internal/domain/account"
+	"payments-service/internal/domain/payments"
+)
+
+// orderFlowTestdata — события сквозного сценария, общие с тестом orders
+var orderFlowTestdata = filepath.Join("..", "..", "..", "..", "contracts", "events", "testdata", "order_flow")
+
+func readOrderFlowEvent(t *testing.T, file string, event any) []byte {
+	payload, err := os.ReadFile(filepath.Join(orderFlowTestdata, file))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(payload, event))
+	return payload
+}
+
+// order.created проходит через шину и inbox processor в настоящий ProcessOrderCreated,
+// а ответ payments совпадает с payment_authorized.json, который подаёт в orders его тест
+func TestPaymentsService_OrderCreatedFlow(t *testing.T) {
+	var created events.OrderCreatedEvent
+	payload := readOrderFlowEvent(t, "order_created.json", &created)
+
+	db, mockSQL, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	service := NewPaymentsService(&safeDB{DB: db}, mockPaymentsRepo, mockAccountRepo, new(MockLedgerRepository), nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
+
+	bus := memory.NewBus()
+	cfg := &relay.Config{
+		Transport: relay.TransportMemory,
+		Source:    "payments-service",
+		Publisher: relay.Publisher{Interval: time.Hour, BatchSize: 10, Lease: time.Minute},
+		Retry:     relay.NewRetryPolicy(relay.Backoff{MaxAttempts: 1}, nil),
+		Bus:       bus,
+	}
+	inboxRepo := inboxtest.NewRepository()
+	processor, err := relay.NewInboxProcessor(db, inboxRepo, nil, cfg, "orders-events")
+	require.NoError(t, err)
+	processor.RegisterHandler(events.OrderCreated, service.ProcessOrderCreated)
+
+	wallet, _ := account.NewAccount(created.UserID, money.USD)
+	_ = wallet.Credit(money.New(20000, money.USD))
+
+	var authorized *outbox.OutboxMessage
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("GetByOrderIDWithTx", mock.Anything, mock.Anything, created.OrderID).Return(nil, sql.ErrNoRows)
+	mockPaymentsRepo.On("StoreWithTx", mock.Anything, mock.Anything, mock.AnythingOfType("*payments.Payment")).Return(nil)
+	mockAccountRepo.On("ListByUserIDWithTx", mock.Anything, mock.Anything, created.UserID).Return([]*account.Account{wallet}, nil)
+	mockAccountRepo.On("UpdateWithTx", mock.Anything, mock.Anything, wallet).Return(nil)
+	mockPaymentsRepo.On("UpdateWithTx", mock.Anything, mock.Anything, mock.MatchedBy(func(p *payments.Payment) bool {
+		return p.IsAuthorized()
+	})).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", mock.Anything, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Run(func(args mock.Arguments) {
+		authorized = args.Get(2).(*outbox.OutboxMessage)
+	}).Return(nil)
+	mockSQL.ExpectCommit()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	processor.Start(ctx)
+	defer processor.Stop()
+
+	var data map[string]any
+	require.NoError(t, json.Unmarshal(payload, &data))
+
+	require.NoError(t, bus.Publisher().PublishEvent(ctx, "orders-events", broker.Event{
+		EventType:    events.OrderCreated,
+		EventID:      "order-event-1",
+		PartitionKey: created.OrderID,
+		Data:         data,
+		Metadata: correlation.Metadata{
+			CorrelationID: "corr-1",
+			Source:        "orders-service",
+			SchemaVersion: events.LatestVersion(events.OrderCreated),
+		},
+	}))
+
+	require.Eventually(t, func() bool {
+		status, _ := inboxRepo.Status("order-event-1")
+		return status == inbox.InboxMessageStatusProcessed
+	}, time.Second, 10*time.Millisecond)
+
+	require.NotNil(t, authorized)
+	assert.Equal(t, events.PaymentAuthorized, authorized.EventType)
+	assert.Equal(t, created.OrderID, authorized.PartitionKey)
+	assert.Equal(t, "corr-1", authorized.CorrelationID)
+	assert.NoError(t, events.Validate(events.PaymentAuthorized, events.LatestVersion(events.PaymentAuthorized), authorized.Payload))
+
+	var got events.PaymentAuthorizedEvent
+	require.NoError(t, json.Unmarshal(authorized.Payload, &got))
+	assert.NotEmpty(t, got.PaymentID)
+	var expected events.PaymentAuthorizedEvent
+	readOrderFlowEvent(t, "payment_authorized.json", &expected)
+	// id платежа и срок блокировки выбирает payments
+	expected.PaymentID, expected.HoldExpiresAt = got.PaymentID, got.HoldExpiresAt
+	expectedPayload, err := json.Marshal(expected)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expectedPayload), string(authorized.Payload))
+
+	assert.Equal(t, money.New(18500, money.USD), wallet.Available())
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
@@ -4,16 +4,23 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
 
+	"contracts/events"
+	"contracts/money"
+	"messaging/correlation"
+	"messaging/inbox"
+	"messaging/outbox"
 	"payments-service/internal/domain/account"
-	"payments-service/internal/domain/inbox"
-	"payments-service/internal/domain/outbox"
+	"payments-service/internal/domain/ledger"
 	"payments-service/internal/domain/payments"
+	"payments-service/internal/interfaces/fx"
 	"payments-service/internal/interfaces/repository"
 	"payments-service/pkg/random"
 )
@@ -22,13 +29,34 @@ type DBTX interface {
 	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
 }
 
+const fundsWaitExpiredMessage = "Payment timed out waiting for funds"
+
+// FundsWaitConfig задаёт, сколько платёж ждёт пополнения кошелька,
+// и какими пачками отклоняются платежи, не дождавшиеся его.
+type FundsWaitConfig struct {
+	Wait      time.Duration
+	BatchSize int
+}
+
+// HoldConfig задаёт срок жизни блокировки под авторизованный платёж
+// и то, как часто и какими пачками снимаются просроченные блокировки.
+type HoldConfig struct {
+	Expiry        time.Duration
+	SweepInterval time.Duration
+	BatchSize     int
+}
+
 type PaymentsService struct {
 	db                  DBTX
 	paymentsRepo        repository.PaymentsRepository
 	accountRepo         repository.AccountRepository
+	ledgerRepo          repository.LedgerRepository
 	inboxRepo           repository.InboxRepository
 	outboxRepo          repository.OutboxRepository
 	randomGenerator     random.Generator
+	rateProvider        fx.RateProvider
+	holdConfig          HoldConfig
+	fundsWaitConfig     FundsWaitConfig
 	maxRetries          int
 	retryDelay          time.Duration
 	outboxProcessorStop chan bool
@@ -38,43 +66,49 @@ func NewPaymentsService(
 	db DBTX,
 	paymentsRepo repository.PaymentsRepository,
 	accountRepo repository.AccountRepository,
+	ledgerRepo repository.LedgerRepository,
 	inboxRepo repository.InboxRepository,
 	outboxRepo repository.OutboxRepository,
 	randomGenerator random.Generator,
+	rateProvider fx.RateProvider,
+	holdConfig HoldConfig,
+	fundsWaitConfig FundsWaitConfig,
 ) *PaymentsService {
 	return &PaymentsService{
 		db:                  db,
 		paymentsRepo:        paymentsRepo,
 		accountRepo:         accountRepo,
+		ledgerRepo:          ledgerRepo,
 		inboxRepo:           inboxRepo,
 		outboxRepo:          outboxRepo,
 		randomGenerator:     randomGenerator,
+		rateProvider:        rateProvider,
+		holdConfig:          holdConfig,
+		fundsWaitConfig:     fundsWaitConfig,
 		maxRetries:          3,
 		retryDelay:          5 * time.Second,
 		outboxProcessorStop: make(chan bool),
 	}
 }
 
-func (s *PaymentsService) ProcessOrderCreated(ctx context.Context, inboxMessage *inbox.InboxMessage) error {
-	var orderEvent inbox.OrderCreatedEvent
+func (s *PaymentsService) ProcessOrderCreated(ctx context.Context, tx *sql.Tx, inboxMessage *inbox.InboxMessage) error {
+	var orderEvent events.OrderCreatedEvent
 	if err := json.Unmarshal(inboxMessage.Payload, &orderEvent); err != nil {
 		return fmt.Errorf("failed to unmarshal order created event: %w", err)
 	}
 
-	log.Printf("Processing order created event: OrderID=%s, UserID=%s, Amount=%.2f",
-		orderEvent.OrderID, orderEvent.UserID, orderEvent.Amount)
+	amount := orderEvent.AmountMoney.OrLegacy(orderEvent.Amount, orderEvent.Currency)
 
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
+	log.Printf("Processing order created event: OrderID=%s, UserID=%s, Amount=%s, Items=%d",
+		orderEvent.OrderID, orderEvent.UserID, amount, len(orderEvent.Items))
 
+	// Проверка и вставка идут в транзакции inbox: существующий платёж блокируется, а параллельную
+	// вставку по тому же заказу отсекает уникальный индекс — транзакция откатится, событие повторится
 	var payment *payments.Payment
-	existingPayment, err := s.paymentsRepo.GetByOrderID(ctx, orderEvent.OrderID)
+	existingPayment, err := s.paymentsRepo.GetByOrderIDWithTx(ctx, tx, orderEvent.OrderID)
 	if err != nil {
-		if err == sql.ErrNoRows || fmt.Sprintf("%s", err) == fmt.Sprintf("payment not found for order: %s", orderEvent.OrderID) {
-			payment, err = payments.NewPayment(orderEvent.OrderID, orderEvent.UserID, orderEvent.Amount, orderEvent.Currency)
+		if err == sql.ErrNoRows || errors.Is(err, payments.ErrPaymentNotFound) {
+			payment, err = payments.NewPayment(orderEvent.OrderID, orderEvent.UserID, amount)
 			if err != nil {
 				return fmt.Errorf("failed to create payment: %w", err)
 			}
@@ -89,124 +123,605 @@ func (s *PaymentsService) ProcessOrderCreated(ctx context.Context, inboxMessage
 	} else {
 		payment = existingPayment
 		log.Printf("Found existing payment: PaymentID=%s, Status=%s", payment.ID, payment.Status)
+
+		if !payment.IsPending() {
+			log.Printf("Payment %s is already %s, skipping", payment.ID, payment.Status)
+			return nil
+		}
 	}
 
-	success, shouldRetry, errorMessage, err := s.processPayment(ctx, tx, payment)
+	success, shouldRetry, errorMessage, err := s.authorizePayment(ctx, tx, payment)
 	if err != nil {
 		return err
 	}
 
 	if shouldRetry {
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("failed to commit transaction: %w", err)
-		}
-		log.Printf("Payment retry scheduled: PaymentID=%s, Reason=%s", payment.ID, errorMessage)
-		return fmt.Errorf("insufficient funds, will retry later: %s", errorMessage)
+		return s.parkPayment(ctx, tx, payment, errorMessage)
 	}
 
-	var outboxMessage *outbox.OutboxMessage
 	if success {
-		transactionID := uuid.Must(uuid.NewV7()).String()
-		payment.Complete(transactionID)
+		err = s.storeAuthorizedPayment(ctx, tx, payment)
+	} else {
+		err = s.failPayment(ctx, tx, payment, errorMessage)
+	}
+	if err != nil {
+		return err
+	}
 
-		if err := s.paymentsRepo.UpdateWithTx(ctx, tx, payment); err != nil {
-			return fmt.Errorf("failed to update payment: %w", err)
+	log.Printf("Successfully processed order created event and stored outbox message")
+	return nil
+}
+
+// ProcessAccountToppedUp повторяет отложенные платежи пользователя после пополнения.
+func (s *PaymentsService) ProcessAccountToppedUp(ctx context.Context, tx *sql.Tx, inboxMessage *inbox.InboxMessage) error {
+	var toppedUpEvent events.AccountToppedUpEvent
+	if err := json.Unmarshal(inboxMessage.Payload, &toppedUpEvent); err != nil {
+		return fmt.Errorf("failed to unmarshal account topped up event: %w", err)
+	}
+
+	authorized, err := s.RetryAwaitingPayments(ctx, tx, toppedUpEvent.UserID)
+	if err != nil {
+		return err
+	}
+
+	if authorized > 0 {
+		log.Printf("Authorized %d payments awaiting funds after top-up: UserID=%s", authorized, toppedUpEvent.UserID)
+	}
+	return nil
+}
+
+// RetryAwaitingPayments заново пытается провести отложенные платежи пользователя
+// в порядке поступления. Если очередному платежу по-прежнему не хватает средств,
+// обработка останавливается, чтобы более поздние платежи его не обгоняли.
+func (s *PaymentsService) RetryAwaitingPayments(ctx context.Context, tx *sql.Tx, userID string) (int, error) {
+	parked, err := s.paymentsRepo.ListAwaitingFundsByUserIDWithTx(ctx, tx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	authorized := 0
+	for _, payment := range parked {
+		if payment.IsFundsWaitExpired(now) {
+			if err := s.failPayment(ctx, tx, payment, fundsWaitExpiredMessage); err != nil {
+				return 0, err
+			}
+			continue
 		}
 
-		paymentEvent := outbox.PaymentCompletedEvent{
-			PaymentID:     payment.ID,
-			OrderID:       payment.OrderID,
-			UserID:        payment.UserID,
-			Amount:        payment.Amount,
-			Currency:      payment.Currency,
-			TransactionID: payment.TransactionID,
+		success, shouldRetry, errorMessage, err := s.authorizePayment(ctx, tx, payment)
+		if err != nil {
+			return 0, err
 		}
 
-		payload, err := json.Marshal(paymentEvent)
+		if shouldRetry {
+			if err := s.parkPayment(ctx, tx, payment, errorMessage); err != nil {
+				return 0, err
+			}
+			break
+		}
+
+		if success {
+			err = s.storeAuthorizedPayment(ctx, tx, payment)
+			authorized++
+		} else {
+			err = s.failPayment(ctx, tx, payment, errorMessage)
+		}
 		if err != nil {
-			return fmt.Errorf("failed to marshal payment completed event: %w", err)
+			return 0, err
+		}
+	}
+
+	return authorized, nil
+}
+
+// ExpireAwaitingPayments отклоняет отложенные платежи, не дождавшиеся пополнения.
+func (s *PaymentsService) ExpireAwaitingPayments(ctx context.Context) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	expired, err := s.paymentsRepo.ListExpiredFundsWaitWithTx(ctx, tx, time.Now(), s.fundsWaitConfig.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, payment := range expired {
+		if err := s.failPayment(ctx, tx, payment, fundsWaitExpiredMessage); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(expired), nil
+}
+
+// parkPayment откладывает платёж до пополнения кошелька. Транзакцию подтверждает вызывающий.
+func (s *PaymentsService) parkPayment(ctx context.Context, tx *sql.Tx, payment *payments.Payment, reason string) error {
+	payment.AwaitFunds(reason, time.Now().Add(s.fundsWaitConfig.Wait))
+
+	if err := s.paymentsRepo.UpdateWithTx(ctx, tx, payment); err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	log.Printf("Payment awaiting funds: PaymentID=%s, Until=%s, Reason=%s",
+		payment.ID, payment.FundsWaitUntil.Format(time.RFC3339), reason)
+	return nil
+}
+
+// storeAuthorizedPayment сохраняет авторизованный платёж и публикует payment.authorized.
+// Транзакцию подтверждает вызывающий.
+func (s *PaymentsService) storeAuthorizedPayment(ctx context.Context, tx *sql.Tx, payment *payments.Payment) error {
+	if err := s.paymentsRepo.UpdateWithTx(ctx, tx, payment); err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	paymentEvent := events.PaymentAuthorizedEvent{
+		PaymentID:     payment.ID,
+		OrderID:       payment.OrderID,
+		UserID:        payment.UserID,
+		AmountMoney:   payment.Amount,
+		Currency:      payment.Amount.Currency(),
+		HeldMoney:     payment.Charged(),
+		HoldExpiresAt: *payment.HoldExpiresAt,
+		Amount:        payment.Amount.Float64(),
+	}
+
+	payload, err := json.Marshal(paymentEvent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment authorized event: %w", err)
+	}
+
+	outboxMessage, err := outbox.NewOutboxMessage("payment.authorized", payment.OrderID, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox message: %w", err)
+	}
+	outboxMessage.Correlate(correlation.FromContext(ctx))
+
+	if err := s.outboxRepo.StoreWithTx(ctx, tx, outboxMessage); err != nil {
+		return fmt.Errorf("failed to store outbox message: %w", err)
+	}
+
+	log.Printf("Payment authorized: PaymentID=%s, Held=%s, ExpiresAt=%s",
+		payment.ID, payment.Charged(), payment.HoldExpiresAt.Format(time.RFC3339))
+	return nil
+}
+
+// failPayment отклоняет платёж и публикует payment.failed. Транзакцию подтверждает вызывающий.
+func (s *PaymentsService) failPayment(ctx context.Context, tx *sql.Tx, payment *payments.Payment, errorMessage string) error {
+	payment.Fail(errorMessage)
+
+	if err := s.paymentsRepo.UpdateWithTx(ctx, tx, payment); err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	paymentEvent := events.PaymentFailedEvent{
+		PaymentID:    payment.ID,
+		OrderID:      payment.OrderID,
+		UserID:       payment.UserID,
+		AmountMoney:  payment.Amount,
+		Currency:     payment.Amount.Currency(),
+		ErrorMessage: errorMessage,
+		Amount:       payment.Amount.Float64(),
+	}
+
+	payload, err := json.Marshal(paymentEvent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment failed event: %w", err)
+	}
+
+	outboxMessage, err := outbox.NewOutboxMessage("payment.failed", payment.OrderID, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox message: %w", err)
+	}
+	outboxMessage.Correlate(correlation.FromContext(ctx))
+
+	if err := s.outboxRepo.StoreWithTx(ctx, tx, outboxMessage); err != nil {
+		return fmt.Errorf("failed to store outbox message: %w", err)
+	}
+
+	log.Printf("Payment failed: PaymentID=%s, Error=%s", payment.ID, errorMessage)
+	return nil
+}
+
+// ProcessOrderCancelled возвращает средства за отменённый заказ.
+// Если платёж ещё не проведён, он отменяется, чтобы повторная обработка order.created его не списала,
+// а блокировка авторизованного платежа снимается без проводок по журналу.
+func (s *PaymentsService) ProcessOrderCancelled(ctx context.Context, tx *sql.Tx, inboxMessage *inbox.InboxMessage) error {
+	var cancelEvent events.OrderCancelledEvent
+	if err := json.Unmarshal(inboxMessage.Payload, &cancelEvent); err != nil {
+		return fmt.Errorf("failed to unmarshal order cancelled event: %w", err)
+	}
+
+	log.Printf("Processing order cancelled event: OrderID=%s, UserID=%s, Reason=%s",
+		cancelEvent.OrderID, cancelEvent.UserID, cancelEvent.Reason)
+
+	payment, err := s.paymentsRepo.GetByOrderIDWithTx(ctx, tx, cancelEvent.OrderID)
+	if err != nil {
+		if err != sql.ErrNoRows && !errors.Is(err, payments.ErrPaymentNotFound) {
+			return fmt.Errorf("failed to check existing payment: %w", err)
 		}
 
-		outboxMessage, err = outbox.NewOutboxMessage("payment.completed", payload)
+		payment, err = payments.NewPayment(cancelEvent.OrderID, cancelEvent.UserID, cancelEvent.AmountMoney.OrLegacy(cancelEvent.Amount, cancelEvent.Currency))
 		if err != nil {
-			return fmt.Errorf("failed to create outbox message: %w", err)
+			return fmt.Errorf("failed to create payment: %w", err)
 		}
+		payment.Cancel(cancelEvent.Reason)
 
-		log.Printf("Payment completed successfully: PaymentID=%s, TransactionID=%s", payment.ID, transactionID)
-	} else {
-		payment.Fail(errorMessage)
+		if err := s.paymentsRepo.StoreWithTx(ctx, tx, payment); err != nil {
+			return fmt.Errorf("failed to store payment: %w", err)
+		}
+
+		log.Printf("Order cancelled before payment was attempted: OrderID=%s", cancelEvent.OrderID)
+		return nil
+	}
+
+	switch {
+	case payment.IsPending(), payment.IsAwaitingFunds():
+		payment.Cancel(cancelEvent.Reason)
 
 		if err := s.paymentsRepo.UpdateWithTx(ctx, tx, payment); err != nil {
 			return fmt.Errorf("failed to update payment: %w", err)
 		}
 
-		paymentEvent := outbox.PaymentFailedEvent{
-			PaymentID:    payment.ID,
-			OrderID:      payment.OrderID,
-			UserID:       payment.UserID,
-			Amount:       payment.Amount,
-			Currency:     payment.Currency,
-			ErrorMessage: errorMessage,
+		log.Printf("Pending payment cancelled: PaymentID=%s", payment.ID)
+		return nil
+	case payment.IsAuthorized():
+		if err := s.releaseHold(ctx, tx, payment, cancelEvent.Reason); err != nil {
+			return err
 		}
 
-		payload, err := json.Marshal(paymentEvent)
-		if err != nil {
-			return fmt.Errorf("failed to marshal payment failed event: %w", err)
+		log.Printf("Payment hold released: PaymentID=%s, Amount=%s", payment.ID, payment.Charged())
+		return nil
+	case payment.IsReleased():
+		// Блокировку уже снял ReleaseExpiredHolds: заказ остался оплаченным и ждёт
+		// payment.released, чтобы завершить отмену. Кошелёк при этом не меняется
+		if err := s.storeReleasedEvent(ctx, tx, payment, cancelEvent.Reason); err != nil {
+			return err
 		}
 
-		outboxMessage, err = outbox.NewOutboxMessage("payment.failed", payload)
+		log.Printf("Payment hold was already released: PaymentID=%s", payment.ID)
+		return nil
+	case !payment.IsCompleted():
+		log.Printf("Payment %s is %s, nothing to refund", payment.ID, payment.Status)
+		return nil
+	}
+
+	// Возврат идёт в тот кошелёк и в той сумме, что были списаны, без повторной конвертации
+	charged := payment.Charged()
+	acc, err := s.accountRepo.GetByUserIDAndCurrencyWithTx(ctx, tx, payment.UserID, charged.Currency())
+	if err != nil {
+		return fmt.Errorf("failed to get user account: %w", err)
+	}
+
+	if err := acc.Credit(charged); err != nil {
+		return fmt.Errorf("failed to credit account: %w", err)
+	}
+
+	if err := s.accountRepo.UpdateWithTx(ctx, tx, acc); err != nil {
+		return fmt.Errorf("failed to update account: %w", err)
+	}
+
+	ledgerTxn, err := ledger.NewRefund(acc, charged, payment.ID, fmt.Sprintf("refund for order %s", payment.OrderID))
+	if err != nil {
+		return fmt.Errorf("failed to create ledger transaction: %w", err)
+	}
+
+	if err := postLedger(ctx, tx, s.ledgerRepo, ledgerTxn, acc); err != nil {
+		return err
+	}
+
+	payment.Refund()
+
+	if err := s.paymentsRepo.UpdateWithTx(ctx, tx, payment); err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	refundEvent := events.PaymentRefundedEvent{
+		PaymentID:     payment.ID,
+		OrderID:       payment.OrderID,
+		UserID:        payment.UserID,
+		AmountMoney:   payment.Amount,
+		Currency:      payment.Amount.Currency(),
+		TransactionID: payment.TransactionID,
+		Reason:        cancelEvent.Reason,
+		Amount:        payment.Amount.Float64(),
+	}
+
+	payload, err := json.Marshal(refundEvent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment refunded event: %w", err)
+	}
+
+	outboxMessage, err := outbox.NewOutboxMessage("payment.refunded", payment.OrderID, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox message: %w", err)
+	}
+	outboxMessage.Correlate(correlation.FromContext(ctx))
+
+	if err := s.outboxRepo.StoreWithTx(ctx, tx, outboxMessage); err != nil {
+		return fmt.Errorf("failed to store outbox message: %w", err)
+	}
+
+	log.Printf("Payment refunded: PaymentID=%s, Amount=%s, NewBalance=%s", payment.ID, charged, acc.Balance)
+	return nil
+}
+
+// ProcessOrderCompleted списывает заблокированную сумму после выполнения заказа.
+// Пока платёж авторизован, сумма лежит в блокировке, поэтому списывается и после
+// истечения срока. Если блокировку уже снял ReleaseExpiredHolds, сумма блокируется
+// заново и сразу списывается: выполненный заказ не должен остаться неоплаченным.
+func (s *PaymentsService) ProcessOrderCompleted(ctx context.Context, tx *sql.Tx, inboxMessage *inbox.InboxMessage) error {
+	var completedEvent events.OrderCompletedEvent
+	if err := json.Unmarshal(inboxMessage.Payload, &completedEvent); err != nil {
+		return fmt.Errorf("failed to unmarshal order completed event: %w", err)
+	}
+
+	log.Printf("Processing order completed event: OrderID=%s, UserID=%s", completedEvent.OrderID, completedEvent.UserID)
+
+	payment, err := s.paymentsRepo.GetByOrderIDWithTx(ctx, tx, completedEvent.OrderID)
+	if err != nil {
+		return fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	if payment.IsReleased() {
+		authorized, _, errorMessage, err := s.authorizePayment(ctx, tx, payment)
 		if err != nil {
-			return fmt.Errorf("failed to create outbox message: %w", err)
+			return err
 		}
+		if !authorized {
+			// Ошибка оставляет событие в inbox: повтор после пополнения спишет сумму,
+			// а исчерпанные попытки попадут в dead letters для ручного разбора
+			return fmt.Errorf("failed to re-authorize released payment %s: %s", payment.ID, errorMessage)
+		}
+
+		log.Printf("Hold was released before capture, placed it again: PaymentID=%s", payment.ID)
+	}
+
+	if !payment.IsAuthorized() {
+		log.Printf("Payment %s is %s, nothing to capture", payment.ID, payment.Status)
+		return nil
+	}
+
+	charged := payment.Charged()
+	acc, err := s.accountRepo.GetByUserIDAndCurrencyWithTx(ctx, tx, payment.UserID, charged.Currency())
+	if err != nil {
+		return fmt.Errorf("failed to get user account: %w", err)
+	}
+
+	if err := acc.Capture(charged); err != nil {
+		return fmt.Errorf("failed to capture hold: %w", err)
+	}
+
+	if err := s.accountRepo.UpdateWithTx(ctx, tx, acc); err != nil {
+		return fmt.Errorf("failed to update account: %w", err)
+	}
+
+	description := fmt.Sprintf("payment for order %s", payment.OrderID)
+	if payment.ExchangeRate != nil {
+		description = fmt.Sprintf("payment for order %s: %s at %s", payment.OrderID, payment.Amount, payment.ExchangeRate)
+	}
 
-		log.Printf("Payment failed: PaymentID=%s, Error=%s", payment.ID, errorMessage)
+	ledgerTxn, err := ledger.NewPaymentCharge(acc, charged, payment.ID, description)
+	if err != nil {
+		return fmt.Errorf("failed to create ledger transaction: %w", err)
+	}
+
+	if err := postLedger(ctx, tx, s.ledgerRepo, ledgerTxn, acc); err != nil {
+		return err
 	}
 
+	payment.Capture(uuid.Must(uuid.NewV7()).String())
+
+	if err := s.paymentsRepo.UpdateWithTx(ctx, tx, payment); err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	capturedEvent := events.PaymentCapturedEvent{
+		PaymentID:     payment.ID,
+		OrderID:       payment.OrderID,
+		UserID:        payment.UserID,
+		AmountMoney:   payment.Amount,
+		Currency:      payment.Amount.Currency(),
+		ChargedMoney:  charged,
+		TransactionID: payment.TransactionID,
+		Amount:        payment.Amount.Float64(),
+	}
+
+	payload, err := json.Marshal(capturedEvent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment captured event: %w", err)
+	}
+
+	outboxMessage, err := outbox.NewOutboxMessage("payment.captured", payment.OrderID, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox message: %w", err)
+	}
+	outboxMessage.Correlate(correlation.FromContext(ctx))
+
 	if err := s.outboxRepo.StoreWithTx(ctx, tx, outboxMessage); err != nil {
 		return fmt.Errorf("failed to store outbox message: %w", err)
 	}
 
+	log.Printf("Payment captured: PaymentID=%s, Amount=%s, NewBalance=%s", payment.ID, charged, acc.Balance)
+	return nil
+}
+
+// ReleaseExpiredHolds снимает просроченные блокировки и возвращает их количество.
+func (s *PaymentsService) ReleaseExpiredHolds(ctx context.Context) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	expired, err := s.paymentsRepo.ListExpiredHoldsWithTx(ctx, tx, time.Now(), s.holdConfig.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, payment := range expired {
+		if err := s.releaseHold(ctx, tx, payment, "authorization expired"); err != nil {
+			return 0, err
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(expired), nil
+}
+
+// releaseHold снимает блокировку платежа с кошелька и публикует payment.released.
+// Транзакцию подтверждает вызывающий.
+func (s *PaymentsService) releaseHold(ctx context.Context, tx *sql.Tx, payment *payments.Payment, reason string) error {
+	held := payment.Charged()
+	acc, err := s.accountRepo.GetByUserIDAndCurrencyWithTx(ctx, tx, payment.UserID, held.Currency())
+	if err != nil {
+		return fmt.Errorf("failed to get user account: %w", err)
+	}
+
+	if err := acc.ReleaseHold(held); err != nil {
+		return fmt.Errorf("failed to release hold: %w", err)
+	}
+
+	if err := s.accountRepo.UpdateWithTx(ctx, tx, acc); err != nil {
+		return fmt.Errorf("failed to update account: %w", err)
+	}
+
+	payment.Release(reason)
+
+	if err := s.paymentsRepo.UpdateWithTx(ctx, tx, payment); err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	return s.storeReleasedEvent(ctx, tx, payment, reason)
+}
+
+// storeReleasedEvent сохраняет payment.released в outbox в транзакции вызывающего
+func (s *PaymentsService) storeReleasedEvent(ctx context.Context, tx *sql.Tx, payment *payments.Payment, reason string) error {
+	releasedEvent := events.PaymentReleasedEvent{
+		PaymentID:   payment.ID,
+		OrderID:     payment.OrderID,
+		UserID:      payment.UserID,
+		AmountMoney: payment.Amount,
+		Currency:    payment.Amount.Currency(),
+		Reason:      reason,
+		Amount:      payment.Amount.Float64(),
+	}
+
+	payload, err := json.Marshal(releasedEvent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment released event: %w", err)
+	}
+
+	outboxMessage, err := outbox.NewOutboxMessage("payment.released", payment.OrderID, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox message: %w", err)
+	}
+	outboxMessage.Correlate(correlation.FromContext(ctx))
+
+	if err := s.outboxRepo.StoreWithTx(ctx, tx, outboxMessage); err != nil {
+		return fmt.Errorf("failed to store outbox message: %w", err)
 	}
 
-	log.Printf("Successfully processed order created event and stored outbox message")
 	return nil
 }
 
-// processPayment обрабатывает платеж со счета пользователя.
+// authorizePayment блокирует сумму платежа на кошельке пользователя.
+// Текущий баланс и журнал не меняются до списания.
 // returns: success, shouldRetry, errorMessage, error
-func (s *PaymentsService) processPayment(ctx context.Context, tx *sql.Tx, payment *payments.Payment) (bool, bool, string, error) {
-	if payment.IsTimedOut() {
-		return false, false, "Payment timed out", nil
+func (s *PaymentsService) authorizePayment(ctx context.Context, tx *sql.Tx, payment *payments.Payment) (bool, bool, string, error) {
+	wallets, err := s.accountRepo.ListByUserIDWithTx(ctx, tx, payment.UserID)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to get user accounts: %w", err).Error(), nil
 	}
 
-	acc, err := s.accountRepo.GetByUserID(ctx, payment.UserID)
+	if len(wallets) == 0 {
+		return false, false, "User account not found", nil
+	}
+
+	acc, charge, rate, err := s.selectWallet(ctx, wallets, payment.Amount)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return false, false, "User account not found", nil
-		}
-		return false, false, fmt.Errorf("failed to get user account: %w", err).Error(), nil
+		return false, false, "", err
 	}
 
-	if !acc.HasSufficientFunds(payment.Amount) {
-		return false, true, fmt.Sprintf("Insufficient funds: balance %.2f, required %.2f", acc.Balance, payment.Amount), nil
+	if acc == nil {
+		return false, true, fmt.Sprintf("Insufficient funds: available %s, required %s", formatBalances(wallets), payment.Amount), nil
 	}
 
-	if err := acc.Debit(payment.Amount); err != nil {
-		return false, false, "", fmt.Errorf("failed to debit account: %w", err)
+	if err := acc.Hold(charge); err != nil {
+		return false, false, "", fmt.Errorf("failed to place hold: %w", err)
 	}
 
 	if err := s.accountRepo.UpdateWithTx(ctx, tx, acc); err != nil {
 		return false, false, "", fmt.Errorf("failed to update account: %w", err)
 	}
 
-	log.Printf("Successfully debited %.2f from account: UserID=%s, NewBalance=%.2f",
-		payment.Amount, payment.UserID, acc.Balance)
+	payment.Authorize(charge, rate, time.Now().Add(s.holdConfig.Expiry))
+
+	if rate != nil {
+		log.Printf("Placed hold of %s (%s at %s) on account: UserID=%s, Available=%s",
+			charge, payment.Amount, rate, payment.UserID, acc.Available())
+	} else {
+		log.Printf("Placed hold of %s on account: UserID=%s, Available=%s",
+			charge, payment.UserID, acc.Available())
+	}
 
 	return true, false, "", nil
 }
 
+// selectWallet выбирает кошелёк для списания: сначала кошелёк в валюте платежа,
+// затем первый кошелёк в другой валюте, которого хватает после пересчёта по курсу.
+// Если подходящего кошелька нет, возвращает nil.
+func (s *PaymentsService) selectWallet(ctx context.Context, wallets []*account.Account, amount money.Money) (*account.Account, money.Money, *money.ExchangeRate, error) {
+	for _, acc := range wallets {
+		if acc.Currency() == amount.Currency() && acc.HasSufficientFunds(amount) {
+			return acc, amount, nil, nil
+		}
+	}
+
+	for _, acc := range wallets {
+		if acc.Currency() == amount.Currency() {
+			continue
+		}
+
+		rate, err := s.rateProvider.Rate(ctx, amount.Currency(), acc.Currency())
+		if err != nil {
+			if errors.Is(err, fx.ErrRateNotFound) {
+				log.Printf("Skipping %s wallet %s: %v", acc.Currency(), acc.ID, err)
+				continue
+			}
+			return nil, money.Money{}, nil, fmt.Errorf("failed to get exchange rate: %w", err)
+		}
+
+		charge, err := amount.Convert(rate)
+		if err != nil {
+			return nil, money.Money{}, nil, fmt.Errorf("failed to convert amount: %w", err)
+		}
+
+		if acc.HasSufficientFunds(charge) {
+			return acc, charge, &rate, nil
+		}
+	}
+
+	return nil, money.Money{}, nil, nil
+}
+
+func formatBalances(wallets []*account.Account) string {
+	balances := make([]string, 0, len(wallets))
+	for _, acc := range wallets {
+		balances = append(balances, acc.Available().String())
+	}
+	return strings.Join(balances, ", ")
+}
+
 func (s *PaymentsService) GetPaymentByOrderID(ctx context.Context, orderID string) (*payments.Payment, error) {
 	return s.paymentsRepo.GetByOrderID(ctx, orderID)
 }
@@ -215,25 +730,55 @@ func (s *PaymentsService) GetPaymentByID(ctx context.Context, paymentID string)
 	return s.paymentsRepo.GetByID(ctx, paymentID)
 }
 
-func (s *PaymentsService) CreateAccount(ctx context.Context, userID string, initialBalance float64) (*account.Account, error) {
-	acc, err := account.NewAccount(userID)
+func (s *PaymentsService) CreateAccount(ctx context.Context, userID string, initialBalance money.Money) (*account.Account, error) {
+	currency := initialBalance.Currency()
+	if currency == "" {
+		currency = money.USD
+	}
+
+	acc, err := account.NewAccount(userID, currency)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create account: %w", err)
 	}
 
-	if initialBalance > 0 {
-		if err := acc.Credit(initialBalance); err != nil {
-			return nil, fmt.Errorf("failed to credit initial balance: %w", err)
+	if !initialBalance.IsPositive() {
+		if err := s.accountRepo.Store(ctx, acc); err != nil {
+			return nil, fmt.Errorf("failed to store account: %w", err)
 		}
+		return acc, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := acc.Credit(initialBalance); err != nil {
+		return nil, fmt.Errorf("failed to credit initial balance: %w", err)
 	}
 
-	if err := s.accountRepo.Store(ctx, acc); err != nil {
+	if err := s.accountRepo.StoreWithTx(ctx, tx, acc); err != nil {
 		return nil, fmt.Errorf("failed to store account: %w", err)
 	}
 
+	// Начальный баланс попадает в журнал корректировкой, чтобы баланс сходился с проводками
+	ledgerTxn, err := ledger.NewAdjustment(acc, initialBalance, "opening balance")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ledger transaction: %w", err)
+	}
+
+	if err := postLedger(ctx, tx, s.ledgerRepo, ledgerTxn, acc); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return acc, nil
 }
 
-func (s *PaymentsService) GetAccountByUserID(ctx context.Context, userID string) (*account.Account, error) {
-	return s.accountRepo.GetByUserID(ctx, userID)
+func (s *PaymentsService) GetAccountsByUserID(ctx context.Context, userID string) ([]*account.Account, error) {
+	return s.accountRepo.ListByUserID(ctx, userID)
 }
@@ -4,16 +4,22 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"contracts/events"
+	"contracts/money"
+	"messaging/inbox"
+	"messaging/outbox"
 	"payments-service/internal/domain/account"
-	"payments-service/internal/domain/inbox"
-	"payments-service/internal/domain/outbox"
+	"payments-service/internal/domain/ledger"
 	"payments-service/internal/domain/payments"
 )
 
@@ -29,6 +35,10 @@ func (db *safeDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, er
 	return db.DB.BeginTx(ctx, opts)
 }
 
+var testHoldConfig = HoldConfig{Expiry: 30 * time.Minute, SweepInterval: time.Second, BatchSize: 10}
+
+var testFundsWaitConfig = FundsWaitConfig{Wait: 10 * time.Minute, BatchSize: 10}
+
 // Mocks
 type MockPaymentsRepository struct {
 	mock.Mock
@@ -60,6 +70,38 @@ func (m *MockPaymentsRepository) GetByOrderID(ctx context.Context, orderID strin
 	return args.Get(0).(*payments.Payment), args.Error(1)
 }
 
+func (m *MockPaymentsRepository) GetByOrderIDWithTx(ctx context.Context, tx *sql.Tx, orderID string) (*payments.Payment, error) {
+	args := m.Called(ctx, tx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*payments.Payment), args.Error(1)
+}
+
+func (m *MockPaymentsRepository) ListExpiredHoldsWithTx(ctx context.Context, tx *sql.Tx, now time.Time, limit int) ([]*payments.Payment, error) {
+	args := m.Called(ctx, tx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*payments.Payment), args.Error(1)
+}
+
+func (m *MockPaymentsRepository) ListAwaitingFundsByUserIDWithTx(ctx context.Context, tx *sql.Tx, userID string) ([]*payments.Payment, error) {
+	args := m.Called(ctx, tx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*payments.Payment), args.Error(1)
+}
+
+func (m *MockPaymentsRepository) ListExpiredFundsWaitWithTx(ctx context.Context, tx *sql.Tx, now time.Time, limit int) ([]*payments.Payment, error) {
+	args := m.Called(ctx, tx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*payments.Payment), args.Error(1)
+}
+
 func (m *MockPaymentsRepository) Update(ctx context.Context, p *payments.Payment) error {
 	args := m.Called(ctx, p)
 	return args.Error(0)
@@ -84,25 +126,36 @@ func (m *MockAccountRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, a *
 	return args.Error(0)
 }
 
-func (m *MockAccountRepository) GetByUserID(ctx context.Context, userID string) (*account.Account, error) {
-	args := m.Called(ctx, userID)
+func (m *MockAccountRepository) GetByUserIDAndCurrency(ctx context.Context, userID, currency string) (*account.Account, error) {
+	args := m.Called(ctx, userID, currency)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*account.Account), args.Error(1)
 }
 
-func (m *MockAccountRepository) GetByUserIDWithTx(ctx context.Context, tx *sql.Tx, userID string) (*account.Account, error) {
-	args := m.Called(ctx, tx, userID)
+func (m *MockAccountRepository) GetByUserIDAndCurrencyWithTx(ctx context.Context, tx *sql.Tx, userID, currency string) (*account.Account, error) {
+	args := m.Called(ctx, tx, userID, currency)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*account.Account), args.Error(1)
 }
 
-func (m *MockAccountRepository) Update(ctx context.Context, a *account.Account) error {
-	args := m.Called(ctx, a)
-	return args.Error(0)
+func (m *MockAccountRepository) ListByUserID(ctx context.Context, userID string) ([]*account.Account, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*account.Account), args.Error(1)
+}
+
+func (m *MockAccountRepository) ListByUserIDWithTx(ctx context.Context, tx *sql.Tx, userID string) ([]*account.Account, error) {
+	args := m.Called(ctx, tx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*account.Account), args.Error(1)
 }
 
 func (m *MockAccountRepository) UpdateWithTx(ctx context.Context, tx *sql.Tx, a *account.Account) error {
@@ -110,6 +163,36 @@ func (m *MockAccountRepository) UpdateWithTx(ctx context.Context, tx *sql.Tx, a
 	return args.Error(0)
 }
 
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+func (m *MockLedgerRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, txn *ledger.Transaction) error {
+	args := m.Called(ctx, tx, txn)
+	return args.Error(0)
+}
+
+func (m *MockLedgerRepository) BalanceWithTx(ctx context.Context, tx *sql.Tx, ledgerAccount, currency string) (money.Money, error) {
+	args := m.Called(ctx, tx, ledgerAccount, currency)
+	return args.Get(0).(money.Money), args.Error(1)
+}
+
+func (m *MockLedgerRepository) ListEntriesByUserID(ctx context.Context, userID string, after *ledger.Cursor, limit int) ([]*ledger.Entry, error) {
+	args := m.Called(ctx, userID, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*ledger.Entry), args.Error(1)
+}
+
+// expectLedgerPosting ожидает проводку заданного вида; журнал отдаёт баланс balance для кошелька.
+func expectLedgerPosting(m *MockLedgerRepository, ctx context.Context, kind ledger.Kind, walletID string, balance money.Money) {
+	m.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(txn *ledger.Transaction) bool {
+		return txn.Kind == kind && txn.Validate() == nil
+	})).Return(nil)
+	m.On("BalanceWithTx", ctx, mock.Anything, walletID, balance.Currency()).Return(balance, nil)
+}
+
 type MockOutboxRepository struct {
 	mock.Mock
 }
@@ -124,16 +207,16 @@ func (m *MockOutboxRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, mess
 	return args.Error(0)
 }
 
-func (m *MockOutboxRepository) GetPendingMessages(ctx context.Context, limit int) ([]*outbox.OutboxMessage, error) {
-	args := m.Called(ctx, limit)
+func (m *MockOutboxRepository) ClaimPendingMessages(ctx context.Context, limit int, lease time.Duration) ([]*outbox.OutboxMessage, error) {
+	args := m.Called(ctx, limit, lease)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*outbox.OutboxMessage), args.Error(1)
 }
 
-func (m *MockOutboxRepository) GetFailedMessages(ctx context.Context, maxRetries, limit int) ([]*outbox.OutboxMessage, error) {
-	args := m.Called(ctx, maxRetries, limit)
+func (m *MockOutboxRepository) ClaimFailedMessages(ctx context.Context, limit int, lease time.Duration) ([]*outbox.OutboxMessage, error) {
+	args := m.Called(ctx, limit, lease)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -145,11 +228,25 @@ func (m *MockOutboxRepository) MarkAsSent(ctx context.Context, id string) error
 	return args.Error(0)
 }
 
-func (m *MockOutboxRepository) MarkAsFailed(ctx context.Context, id string) error {
-	args := m.Called(ctx, id)
+func (m *MockOutboxRepository) MarkAsFailed(ctx context.Context, id string, reason string, nextAttemptAt time.Time) error {
+	args := m.Called(ctx, id, reason, nextAttemptAt)
 	return args.Error(0)
 }
 
+func (m *MockOutboxRepository) MarkAsDeadLetter(ctx context.Context, id, reason string) error {
+	args := m.Called(ctx, id, reason)
+	return args.Error(0)
+}
+
+type MockRateProvider struct {
+	mock.Mock
+}
+
+func (m *MockRateProvider) Rate(ctx context.Context, from, to string) (money.ExchangeRate, error) {
+	args := m.Called(ctx, from, to)
+	return args.Get(0).(money.ExchangeRate), args.Error(1)
+}
+
 func TestPaymentsService_ProcessOrderCreated_Success(t *testing.T) {
 	db, mockSQL, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -159,12 +256,13 @@ func TestPaymentsService_ProcessOrderCreated_Success(t *testing.T) {
 
 	mockPaymentsRepo := new(MockPaymentsRepository)
 	mockAccountRepo := new(MockAccountRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
 	mockOutboxRepo := new(MockOutboxRepository)
 
-	service := NewPaymentsService(safeDB, mockPaymentsRepo, mockAccountRepo, nil, mockOutboxRepo, nil)
+	service := NewPaymentsService(safeDB, mockPaymentsRepo, mockAccountRepo, mockLedgerRepo, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
 
 	ctx := context.Background()
-	orderEvent := inbox.OrderCreatedEvent{
+	orderEvent := events.OrderCreatedEvent{
 		OrderID:  "order-123",
 		UserID:   "user-456",
 		Amount:   100.50,
@@ -173,23 +271,126 @@ func TestPaymentsService_ProcessOrderCreated_Success(t *testing.T) {
 	payload, _ := json.Marshal(orderEvent)
 	inboxMsg := &inbox.InboxMessage{Payload: payload}
 
-	userAccount, _ := account.NewAccount(orderEvent.UserID)
-	_ = userAccount.Credit(200.0) // Sufficient funds
+	userAccount, _ := account.NewAccount(orderEvent.UserID, money.USD)
+	_ = userAccount.Credit(money.New(20000, money.USD)) // Sufficient funds
 
 	mockSQL.ExpectBegin()
-	mockPaymentsRepo.On("GetByOrderID", ctx, orderEvent.OrderID).Return(nil, sql.ErrNoRows)
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, orderEvent.OrderID).Return(nil, sql.ErrNoRows)
 	mockPaymentsRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*payments.Payment")).Return(nil)
-	mockAccountRepo.On("GetByUserID", ctx, orderEvent.UserID).Return(userAccount, nil)
+	mockAccountRepo.On("ListByUserIDWithTx", ctx, mock.Anything, orderEvent.UserID).Return([]*account.Account{userAccount}, nil)
 	mockAccountRepo.On("UpdateWithTx", ctx, mock.Anything, userAccount).Return(nil)
-	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, mock.AnythingOfType("*payments.Payment")).Return(nil)
+	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, mock.MatchedBy(func(p *payments.Payment) bool {
+		return p.IsAuthorized() && p.HoldExpiresAt != nil
+	})).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
+		return msg.EventType == "payment.authorized"
+	})).Return(nil)
+
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCreated(ctx, tx, inboxMsg)
+	assert.NoError(t, err)
+
+	// Блокировка уменьшает доступный остаток, но не текущий баланс и не журнал
+	assert.Equal(t, money.New(20000, money.USD), userAccount.Current())
+	assert.Equal(t, money.New(9950, money.USD), userAccount.Available())
+	mockLedgerRepo.AssertNotCalled(t, "StoreWithTx", mock.Anything, mock.Anything, mock.Anything)
+
+	mockPaymentsRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestPaymentsService_ProcessOrderCreated_ConcurrentPaymentConflict(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	service := NewPaymentsService(&safeDB{DB: db}, mockPaymentsRepo, mockAccountRepo, nil, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
+
+	ctx := context.Background()
+	orderEvent := events.OrderCreatedEvent{
+		OrderID:     "order-race-123",
+		UserID:      "user-456",
+		AmountMoney: money.New(5000, money.USD),
+	}
+	payload, _ := json.Marshal(orderEvent)
+
+	// Параллельная доставка успела вставить платёж: уникальный индекс отклоняет второй
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, orderEvent.OrderID).Return(nil, payments.ErrPaymentNotFound)
+	mockPaymentsRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*payments.Payment")).
+		Return(fmt.Errorf("%w: %s", payments.ErrPaymentAlreadyExists, orderEvent.OrderID))
+
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCreated(ctx, tx, &inbox.InboxMessage{Payload: payload})
+
+	// Ошибка откатывает транзакцию inbox, повтор найдёт уже созданный платёж
+	assert.ErrorIs(t, err, payments.ErrPaymentAlreadyExists)
+	mockAccountRepo.AssertNotCalled(t, "ListByUserIDWithTx", mock.Anything, mock.Anything, mock.Anything)
+	mockOutboxRepo.AssertNotCalled(t, "StoreWithTx", mock.Anything, mock.Anything, mock.Anything)
+	mockPaymentsRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestPaymentsService_ProcessOrderCreated_ConvertsFromOtherCurrencyWallet(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	safeDB := &safeDB{DB: db}
+
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+	mockRateProvider := new(MockRateProvider)
+
+	service := NewPaymentsService(safeDB, mockPaymentsRepo, mockAccountRepo, mockLedgerRepo, nil, mockOutboxRepo, nil, mockRateProvider, testHoldConfig, testFundsWaitConfig)
+
+	ctx := context.Background()
+	orderEvent := events.OrderCreatedEvent{
+		OrderID:     "order-123",
+		UserID:      "user-456",
+		AmountMoney: money.New(1000, money.EUR),
+	}
+	payload, _ := json.Marshal(orderEvent)
+	inboxMsg := &inbox.InboxMessage{Payload: payload}
+
+	// EUR кошелёк есть, но пустой — списание должно уйти в USD по курсу
+	eurWallet, _ := account.NewAccount(orderEvent.UserID, money.EUR)
+	usdWallet, _ := account.NewAccount(orderEvent.UserID, money.USD)
+	_ = usdWallet.Credit(money.New(20000, money.USD))
+	rate, _ := money.ParseExchangeRate(money.EUR, money.USD, "1.085")
+
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, orderEvent.OrderID).Return(nil, sql.ErrNoRows)
+	mockPaymentsRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*payments.Payment")).Return(nil)
+	mockAccountRepo.On("ListByUserIDWithTx", ctx, mock.Anything, orderEvent.UserID).Return([]*account.Account{eurWallet, usdWallet}, nil)
+	mockRateProvider.On("Rate", ctx, money.EUR, money.USD).Return(rate, nil)
+	mockAccountRepo.On("UpdateWithTx", ctx, mock.Anything, usdWallet).Return(nil)
+	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, mock.MatchedBy(func(p *payments.Payment) bool {
+		return p.IsAuthorized() &&
+			p.Amount == money.New(1000, money.EUR) &&
+			p.ChargedAmount == money.New(1085, money.USD) &&
+			p.ExchangeRate != nil && p.ExchangeRate.Decimal() == "1.0850000000"
+	})).Return(nil)
 	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Return(nil)
-	mockSQL.ExpectCommit()
 
-	err = service.ProcessOrderCreated(ctx, inboxMsg)
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCreated(ctx, tx, inboxMsg)
 	assert.NoError(t, err)
+	assert.Equal(t, money.New(18915, money.USD), usdWallet.Available())
+	assert.Equal(t, money.New(1085, money.USD), usdWallet.Held)
+	assert.True(t, eurWallet.Balance.IsZero())
 
 	mockPaymentsRepo.AssertExpectations(t)
 	mockAccountRepo.AssertExpectations(t)
+	mockRateProvider.AssertExpectations(t)
 	mockOutboxRepo.AssertExpectations(t)
 	assert.NoError(t, mockSQL.ExpectationsWereMet())
 }
@@ -203,12 +404,13 @@ func TestPaymentsService_ProcessOrderCreated_InsufficientFunds(t *testing.T) {
 
 	mockPaymentsRepo := new(MockPaymentsRepository)
 	mockAccountRepo := new(MockAccountRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
 	mockOutboxRepo := new(MockOutboxRepository)
 
-	service := NewPaymentsService(safeDB, mockPaymentsRepo, mockAccountRepo, nil, mockOutboxRepo, nil)
+	service := NewPaymentsService(safeDB, mockPaymentsRepo, mockAccountRepo, mockLedgerRepo, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
 
 	ctx := context.Background()
-	orderEvent := inbox.OrderCreatedEvent{
+	orderEvent := events.OrderCreatedEvent{
 		OrderID:  "order-123",
 		UserID:   "user-456",
 		Amount:   100.50,
@@ -217,21 +419,26 @@ func TestPaymentsService_ProcessOrderCreated_InsufficientFunds(t *testing.T) {
 	payload, _ := json.Marshal(orderEvent)
 	inboxMsg := &inbox.InboxMessage{Payload: payload}
 
-	userAccount, _ := account.NewAccount(orderEvent.UserID)
-	_ = userAccount.Credit(50.0) // Insufficient funds
+	userAccount, _ := account.NewAccount(orderEvent.UserID, money.USD)
+	_ = userAccount.Credit(money.New(5000, money.USD)) // Insufficient funds
 
 	mockSQL.ExpectBegin()
-	mockPaymentsRepo.On("GetByOrderID", ctx, orderEvent.OrderID).Return(nil, sql.ErrNoRows)
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, orderEvent.OrderID).Return(nil, sql.ErrNoRows)
 	mockPaymentsRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*payments.Payment")).Return(nil)
-	mockAccountRepo.On("GetByUserID", ctx, orderEvent.UserID).Return(userAccount, nil)
-	mockSQL.ExpectCommit() // The transaction is committed even on retry
-
-	err = service.ProcessOrderCreated(ctx, inboxMsg)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "insufficient funds")
+	mockAccountRepo.On("ListByUserIDWithTx", ctx, mock.Anything, orderEvent.UserID).Return([]*account.Account{userAccount}, nil)
+	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, mock.MatchedBy(func(p *payments.Payment) bool {
+		return p.IsAwaitingFunds() && p.FundsWaitUntil != nil && strings.HasPrefix(p.ErrorMessage, "Insufficient funds")
+	})).Return(nil)
+
+	// Платёж откладывается до пополнения, а не возвращается в inbox на повтор
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCreated(ctx, tx, inboxMsg)
+	assert.NoError(t, err)
+	mockOutboxRepo.AssertNotCalled(t, "StoreWithTx", mock.Anything, mock.Anything, mock.Anything)
 
 	mockPaymentsRepo.AssertExpectations(t)
 	mockAccountRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
 	mockOutboxRepo.AssertExpectations(t)
 	assert.NoError(t, mockSQL.ExpectationsWereMet())
 }
@@ -245,12 +452,13 @@ func TestPaymentsService_ProcessOrderCreated_ExistingPayment(t *testing.T) {
 
 	mockPaymentsRepo := new(MockPaymentsRepository)
 	mockAccountRepo := new(MockAccountRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
 	mockOutboxRepo := new(MockOutboxRepository)
 
-	service := NewPaymentsService(safeDB, mockPaymentsRepo, mockAccountRepo, nil, mockOutboxRepo, nil)
+	service := NewPaymentsService(safeDB, mockPaymentsRepo, mockAccountRepo, mockLedgerRepo, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
 
 	ctx := context.Background()
-	orderEvent := inbox.OrderCreatedEvent{
+	orderEvent := events.OrderCreatedEvent{
 		OrderID:  "order-123",
 		UserID:   "user-456",
 		Amount:   100.50,
@@ -259,20 +467,535 @@ func TestPaymentsService_ProcessOrderCreated_ExistingPayment(t *testing.T) {
 	payload, _ := json.Marshal(orderEvent)
 	inboxMsg := &inbox.InboxMessage{Payload: payload}
 
-	existingPayment, _ := payments.NewPayment(orderEvent.OrderID, orderEvent.UserID, orderEvent.Amount, orderEvent.Currency)
-	userAccount, _ := account.NewAccount(orderEvent.UserID)
-	_ = userAccount.Credit(200.0) // Sufficient funds
+	existingPayment, _ := payments.NewPayment(orderEvent.OrderID, orderEvent.UserID, money.FromFloat(orderEvent.Amount, orderEvent.Currency))
+	userAccount, _ := account.NewAccount(orderEvent.UserID, money.USD)
+	_ = userAccount.Credit(money.New(20000, money.USD)) // Sufficient funds
 
 	mockSQL.ExpectBegin()
-	mockPaymentsRepo.On("GetByOrderID", ctx, orderEvent.OrderID).Return(existingPayment, nil)
-	mockAccountRepo.On("GetByUserID", ctx, orderEvent.UserID).Return(userAccount, nil)
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, orderEvent.OrderID).Return(existingPayment, nil)
+	mockAccountRepo.On("ListByUserIDWithTx", ctx, mock.Anything, orderEvent.UserID).Return([]*account.Account{userAccount}, nil)
 	mockAccountRepo.On("UpdateWithTx", ctx, mock.Anything, userAccount).Return(nil)
 	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, existingPayment).Return(nil)
 	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Return(nil)
+
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCreated(ctx, tx, inboxMsg)
+	assert.NoError(t, err)
+	assert.True(t, existingPayment.IsAuthorized())
+
+	mockPaymentsRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestPaymentsService_ProcessOrderCancelled_RefundsCompletedPayment(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	safeDB := &safeDB{DB: db}
+
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	service := NewPaymentsService(safeDB, mockPaymentsRepo, mockAccountRepo, mockLedgerRepo, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
+
+	ctx := context.Background()
+	cancelEvent := events.OrderCancelledEvent{
+		OrderID:  "order-123",
+		UserID:   "user-456",
+		Amount:   100.50,
+		Currency: "USD",
+		Reason:   "changed my mind",
+	}
+	payload, _ := json.Marshal(cancelEvent)
+	inboxMsg := &inbox.InboxMessage{Payload: payload}
+
+	completedPayment, _ := payments.NewPayment(cancelEvent.OrderID, cancelEvent.UserID, money.FromFloat(cancelEvent.Amount, cancelEvent.Currency))
+	completedPayment.Complete("txn-1")
+	userAccount, _ := account.NewAccount(cancelEvent.UserID, money.USD)
+
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, cancelEvent.OrderID).Return(completedPayment, nil)
+	mockAccountRepo.On("GetByUserIDAndCurrencyWithTx", ctx, mock.Anything, cancelEvent.UserID, money.USD).Return(userAccount, nil)
+	mockAccountRepo.On("UpdateWithTx", ctx, mock.Anything, mock.MatchedBy(func(acc *account.Account) bool {
+		return acc.Balance == money.New(10050, money.USD)
+	})).Return(nil)
+	expectLedgerPosting(mockLedgerRepo, ctx, ledger.KindRefund, userAccount.ID, money.New(10050, money.USD))
+	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, mock.MatchedBy(func(p *payments.Payment) bool {
+		return p.IsRefunded()
+	})).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
+		return msg.EventType == "payment.refunded"
+	})).Return(nil)
+
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCancelled(ctx, tx, inboxMsg)
+	assert.NoError(t, err)
+
+	mockPaymentsRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestPaymentsService_ProcessOrderCancelled_RefundsChargedWallet(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	safeDB := &safeDB{DB: db}
+
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	service := NewPaymentsService(safeDB, mockPaymentsRepo, mockAccountRepo, mockLedgerRepo, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
+
+	ctx := context.Background()
+	cancelEvent := events.OrderCancelledEvent{
+		OrderID:     "order-123",
+		UserID:      "user-456",
+		AmountMoney: money.New(1000, money.EUR),
+		Reason:      "changed my mind",
+	}
+	payload, _ := json.Marshal(cancelEvent)
+	inboxMsg := &inbox.InboxMessage{Payload: payload}
+
+	completedPayment, _ := payments.NewPayment(cancelEvent.OrderID, cancelEvent.UserID, cancelEvent.AmountMoney)
+	rate, _ := money.ParseExchangeRate(money.EUR, money.USD, "1.085")
+	completedPayment.RecordCharge(money.New(1085, money.USD), &rate)
+	completedPayment.Complete("txn-1")
+	usdWallet, _ := account.NewAccount(cancelEvent.UserID, money.USD)
+
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, cancelEvent.OrderID).Return(completedPayment, nil)
+	mockAccountRepo.On("GetByUserIDAndCurrencyWithTx", ctx, mock.Anything, cancelEvent.UserID, money.USD).Return(usdWallet, nil)
+	mockAccountRepo.On("UpdateWithTx", ctx, mock.Anything, mock.MatchedBy(func(acc *account.Account) bool {
+		return acc.Balance == money.New(1085, money.USD)
+	})).Return(nil)
+	expectLedgerPosting(mockLedgerRepo, ctx, ledger.KindRefund, usdWallet.ID, money.New(1085, money.USD))
+	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, completedPayment).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*outbox.OutboxMessage")).Return(nil)
+
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCancelled(ctx, tx, inboxMsg)
+	assert.NoError(t, err)
+
+	mockPaymentsRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestPaymentsService_ProcessOrderCancelled_CancelsPendingPayment(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	safeDB := &safeDB{DB: db}
+
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	service := NewPaymentsService(safeDB, mockPaymentsRepo, mockAccountRepo, mockLedgerRepo, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
+
+	ctx := context.Background()
+	cancelEvent := events.OrderCancelledEvent{
+		OrderID:  "order-123",
+		UserID:   "user-456",
+		Amount:   100.50,
+		Currency: "USD",
+		Reason:   "changed my mind",
+	}
+	payload, _ := json.Marshal(cancelEvent)
+	inboxMsg := &inbox.InboxMessage{Payload: payload}
+
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, cancelEvent.OrderID).Return(nil, payments.ErrPaymentNotFound)
+	mockPaymentsRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(p *payments.Payment) bool {
+		return p.IsCancelled() && p.ErrorMessage == "changed my mind"
+	})).Return(nil)
+
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCancelled(ctx, tx, inboxMsg)
+	assert.NoError(t, err)
+
+	mockPaymentsRepo.AssertExpectations(t)
+	mockAccountRepo.AssertNotCalled(t, "GetByUserIDAndCurrencyWithTx")
+	mockOutboxRepo.AssertNotCalled(t, "StoreWithTx")
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestPaymentsService_ProcessOrderCreated_SkipsCancelledPayment(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	safeDB := &safeDB{DB: db}
+
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	service := NewPaymentsService(safeDB, mockPaymentsRepo, mockAccountRepo, mockLedgerRepo, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
+
+	ctx := context.Background()
+	orderEvent := events.OrderCreatedEvent{
+		OrderID:  "order-123",
+		UserID:   "user-456",
+		Amount:   100.50,
+		Currency: "USD",
+	}
+	payload, _ := json.Marshal(orderEvent)
+	inboxMsg := &inbox.InboxMessage{Payload: payload}
+
+	cancelledPayment, _ := payments.NewPayment(orderEvent.OrderID, orderEvent.UserID, money.FromFloat(orderEvent.Amount, orderEvent.Currency))
+	cancelledPayment.Cancel("changed my mind")
+
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, orderEvent.OrderID).Return(cancelledPayment, nil)
+
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCreated(ctx, tx, inboxMsg)
+	assert.NoError(t, err)
+
+	mockAccountRepo.AssertNotCalled(t, "ListByUserIDWithTx")
+	mockOutboxRepo.AssertNotCalled(t, "StoreWithTx")
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestPaymentsService_ProcessOrderCompleted_CapturesHold(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	service := NewPaymentsService(&safeDB{DB: db}, mockPaymentsRepo, mockAccountRepo, mockLedgerRepo, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
+
+	ctx := context.Background()
+	payload, _ := json.Marshal(events.OrderCompletedEvent{OrderID: "order-123", UserID: "user-456"})
+	inboxMsg := &inbox.InboxMessage{Payload: payload}
+
+	amount := money.New(10050, money.USD)
+	userAccount, _ := account.NewAccount("user-456", money.USD)
+	_ = userAccount.Credit(money.New(20000, money.USD))
+	_ = userAccount.Hold(amount)
+
+	authorizedPayment, _ := payments.NewPayment("order-123", "user-456", amount)
+	authorizedPayment.Authorize(amount, nil, time.Now().Add(time.Minute))
+
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, "order-123").Return(authorizedPayment, nil)
+	mockAccountRepo.On("GetByUserIDAndCurrencyWithTx", ctx, mock.Anything, "user-456", money.USD).Return(userAccount, nil)
+	mockAccountRepo.On("UpdateWithTx", ctx, mock.Anything, userAccount).Return(nil)
+	expectLedgerPosting(mockLedgerRepo, ctx, ledger.KindPayment, userAccount.ID, money.New(9950, money.USD))
+	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, mock.MatchedBy(func(p *payments.Payment) bool {
+		return p.IsCompleted() && p.TransactionID != "" && p.HoldExpiresAt == nil
+	})).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
+		return msg.EventType == "payment.captured"
+	})).Return(nil)
+
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCompleted(ctx, tx, inboxMsg)
+	assert.NoError(t, err)
+
+	assert.Equal(t, money.New(9950, money.USD), userAccount.Current())
+	assert.Equal(t, money.New(9950, money.USD), userAccount.Available())
+
+	mockPaymentsRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestPaymentsService_ProcessOrderCompleted_CapturesExpiredHold(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	service := NewPaymentsService(&safeDB{DB: db}, mockPaymentsRepo, mockAccountRepo, mockLedgerRepo, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
+
+	ctx := context.Background()
+	payload, _ := json.Marshal(events.OrderCompletedEvent{OrderID: "order-123", UserID: "user-456"})
+	inboxMsg := &inbox.InboxMessage{Payload: payload}
+
+	amount := money.New(10050, money.USD)
+	userAccount, _ := account.NewAccount("user-456", money.USD)
+	_ = userAccount.Credit(money.New(20000, money.USD))
+	_ = userAccount.Hold(amount)
+
+	// Срок блокировки истёк, но ReleaseExpiredHolds до платежа ещё не дошёл
+	authorizedPayment, _ := payments.NewPayment("order-123", "user-456", amount)
+	authorizedPayment.Authorize(amount, nil, time.Now().Add(-time.Minute))
+
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, "order-123").Return(authorizedPayment, nil)
+	mockAccountRepo.On("GetByUserIDAndCurrencyWithTx", ctx, mock.Anything, "user-456", money.USD).Return(userAccount, nil)
+	mockAccountRepo.On("UpdateWithTx", ctx, mock.Anything, userAccount).Return(nil)
+	expectLedgerPosting(mockLedgerRepo, ctx, ledger.KindPayment, userAccount.ID, money.New(9950, money.USD))
+	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, mock.MatchedBy(func(p *payments.Payment) bool {
+		return p.IsCompleted() && p.HoldExpiresAt == nil
+	})).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
+		return msg.EventType == "payment.captured"
+	})).Return(nil)
+
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCompleted(ctx, tx, inboxMsg)
+	assert.NoError(t, err)
+
+	assert.Equal(t, money.New(9950, money.USD), userAccount.Current())
+	assert.Equal(t, money.New(9950, money.USD), userAccount.Available())
+
+	mockPaymentsRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestPaymentsService_ProcessOrderCompleted_RecapturesReleasedHold(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	service := NewPaymentsService(&safeDB{DB: db}, mockPaymentsRepo, mockAccountRepo, mockLedgerRepo, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
+
+	ctx := context.Background()
+	payload, _ := json.Marshal(events.OrderCompletedEvent{OrderID: "order-123", UserID: "user-456"})
+	inboxMsg := &inbox.InboxMessage{Payload: payload}
+
+	amount := money.New(10050, money.USD)
+	userAccount, _ := account.NewAccount("user-456", money.USD)
+	_ = userAccount.Credit(money.New(20000, money.USD))
+
+	// Заказ выполнен уже после того, как ReleaseExpiredHolds снял блокировку
+	releasedPayment, _ := payments.NewPayment("order-123", "user-456", amount)
+	releasedPayment.Authorize(amount, nil, time.Now().Add(-time.Minute))
+	releasedPayment.Release("authorization expired")
+
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, "order-123").Return(releasedPayment, nil)
+	mockAccountRepo.On("ListByUserIDWithTx", ctx, mock.Anything, "user-456").Return([]*account.Account{userAccount}, nil)
+	mockAccountRepo.On("GetByUserIDAndCurrencyWithTx", ctx, mock.Anything, "user-456", money.USD).Return(userAccount, nil)
+	mockAccountRepo.On("UpdateWithTx", ctx, mock.Anything, userAccount).Return(nil)
+	expectLedgerPosting(mockLedgerRepo, ctx, ledger.KindPayment, userAccount.ID, money.New(9950, money.USD))
+	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, mock.MatchedBy(func(p *payments.Payment) bool {
+		return p.IsCompleted() && p.TransactionID != ""
+	})).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
+		return msg.EventType == "payment.captured"
+	})).Return(nil)
+
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCompleted(ctx, tx, inboxMsg)
+	assert.NoError(t, err)
+
+	assert.Equal(t, money.New(9950, money.USD), userAccount.Current())
+	assert.Equal(t, money.New(9950, money.USD), userAccount.Available())
+
+	mockPaymentsRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+	mockLedgerRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestPaymentsService_ProcessOrderCompleted_ReleasedHoldWithoutFunds(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	service := NewPaymentsService(&safeDB{DB: db}, mockPaymentsRepo, mockAccountRepo, nil, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
+
+	ctx := context.Background()
+	payload, _ := json.Marshal(events.OrderCompletedEvent{OrderID: "order-123", UserID: "user-456"})
+
+	amount := money.New(10050, money.USD)
+	userAccount, _ := account.NewAccount("user-456", money.USD)
+
+	releasedPayment, _ := payments.NewPayment("order-123", "user-456", amount)
+	releasedPayment.Release("authorization expired")
+
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, "order-123").Return(releasedPayment, nil)
+	mockAccountRepo.On("ListByUserIDWithTx", ctx, mock.Anything, "user-456").Return([]*account.Account{userAccount}, nil)
+
+	// Без средств событие не подтверждается и повторится, платёж остаётся снятым
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCompleted(ctx, tx, &inbox.InboxMessage{Payload: payload})
+	assert.Error(t, err)
+
+	assert.True(t, releasedPayment.IsReleased())
+	mockPaymentsRepo.AssertNotCalled(t, "UpdateWithTx", mock.Anything, mock.Anything, mock.Anything)
+	mockOutboxRepo.AssertNotCalled(t, "StoreWithTx", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestPaymentsService_ProcessOrderCancelled_ReleasesHold(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockLedgerRepo := new(MockLedgerRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	service := NewPaymentsService(&safeDB{DB: db}, mockPaymentsRepo, mockAccountRepo, mockLedgerRepo, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
+
+	ctx := context.Background()
+	cancelEvent := events.OrderCancelledEvent{
+		OrderID:     "order-123",
+		UserID:      "user-456",
+		AmountMoney: money.New(10050, money.USD),
+		Reason:      "changed my mind",
+	}
+	payload, _ := json.Marshal(cancelEvent)
+	inboxMsg := &inbox.InboxMessage{Payload: payload}
+
+	userAccount, _ := account.NewAccount("user-456", money.USD)
+	_ = userAccount.Credit(money.New(20000, money.USD))
+	_ = userAccount.Hold(cancelEvent.AmountMoney)
+
+	authorizedPayment, _ := payments.NewPayment(cancelEvent.OrderID, cancelEvent.UserID, cancelEvent.AmountMoney)
+	authorizedPayment.Authorize(cancelEvent.AmountMoney, nil, time.Now().Add(time.Minute))
+
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, cancelEvent.OrderID).Return(authorizedPayment, nil)
+	mockAccountRepo.On("GetByUserIDAndCurrencyWithTx", ctx, mock.Anything, "user-456", money.USD).Return(userAccount, nil)
+	mockAccountRepo.On("UpdateWithTx", ctx, mock.Anything, userAccount).Return(nil)
+	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, mock.MatchedBy(func(p *payments.Payment) bool {
+		return p.Status == payments.PaymentStatusReleased && p.ErrorMessage == "changed my mind"
+	})).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
+		return msg.EventType == "payment.released"
+	})).Return(nil)
+
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCancelled(ctx, tx, inboxMsg)
+	assert.NoError(t, err)
+
+	assert.Equal(t, money.New(20000, money.USD), userAccount.Current())
+	assert.Equal(t, money.New(20000, money.USD), userAccount.Available())
+	mockLedgerRepo.AssertNotCalled(t, "StoreWithTx", mock.Anything, mock.Anything, mock.Anything)
+
+	mockPaymentsRepo.AssertExpectations(t)
+	mockAccountRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+// Блокировку уже снял ReleaseExpiredHolds: отмена только повторяет payment.released для заказа
+func TestPaymentsService_ProcessOrderCancelled_AfterHoldExpired(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	service := NewPaymentsService(&safeDB{DB: db}, mockPaymentsRepo, mockAccountRepo, nil, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
+
+	ctx := context.Background()
+	cancelEvent := events.OrderCancelledEvent{
+		OrderID:     "order-123",
+		UserID:      "user-456",
+		AmountMoney: money.New(10050, money.USD),
+		Reason:      "changed my mind",
+	}
+	payload, _ := json.Marshal(cancelEvent)
+	inboxMsg := &inbox.InboxMessage{Payload: payload}
+
+	releasedPayment, _ := payments.NewPayment(cancelEvent.OrderID, cancelEvent.UserID, cancelEvent.AmountMoney)
+	releasedPayment.Authorize(cancelEvent.AmountMoney, nil, time.Now().Add(-time.Minute))
+	releasedPayment.Release("authorization expired")
+
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, cancelEvent.OrderID).Return(releasedPayment, nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
+		var released events.PaymentReleasedEvent
+		return msg.EventType == "payment.released" &&
+			json.Unmarshal(msg.Payload, &released) == nil && released.Reason == "changed my mind"
+	})).Return(nil)
+
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCancelled(ctx, tx, inboxMsg)
+	assert.NoError(t, err)
+
+	mockAccountRepo.AssertNotCalled(t, "UpdateWithTx", mock.Anything, mock.Anything, mock.Anything)
+	mockPaymentsRepo.AssertNotCalled(t, "UpdateWithTx", mock.Anything, mock.Anything, mock.Anything)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestPaymentsService_ReleaseExpiredHolds(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	service := NewPaymentsService(&safeDB{DB: db}, mockPaymentsRepo, mockAccountRepo, nil, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
+
+	ctx := context.Background()
+	eurWallet, _ := account.NewAccount("user-456", money.EUR)
+	_ = eurWallet.Credit(money.New(5000, money.EUR))
+	_ = eurWallet.Hold(money.New(1085, money.EUR))
+
+	expiredPayment, _ := payments.NewPayment("order-123", "user-456", money.New(1000, money.GBP))
+	expiredPayment.Authorize(money.New(1085, money.EUR), nil, time.Now().Add(-time.Minute))
+
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("ListExpiredHoldsWithTx", ctx, mock.Anything, mock.AnythingOfType("time.Time"), testHoldConfig.BatchSize).
+		Return([]*payments.Payment{expiredPayment}, nil)
+	mockAccountRepo.On("GetByUserIDAndCurrencyWithTx", ctx, mock.Anything, "user-456", money.EUR).Return(eurWallet, nil)
+	mockAccountRepo.On("UpdateWithTx", ctx, mock.Anything, eurWallet).Return(nil)
+	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, expiredPayment).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
+		return msg.EventType == "payment.released"
+	})).Return(nil)
 	mockSQL.ExpectCommit()
 
-	err = service.ProcessOrderCreated(ctx, inboxMsg)
+	released, err := service.ReleaseExpiredHolds(ctx)
+
 	assert.NoError(t, err)
+	assert.Equal(t, 1, released)
+	assert.Equal(t, payments.PaymentStatusReleased, expiredPayment.Status)
+	assert.Equal(t, money.New(5000, money.EUR), eurWallet.Available())
 
 	mockPaymentsRepo.AssertExpectations(t)
 	mockAccountRepo.AssertExpectations(t)
@@ -11,183 +11,251 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"contracts/events"
+	"contracts/money"
+	"messaging/inbox"
+	"messaging/outbox"
 	"payments-service/internal/domain/account"
-	"payments-service/internal/domain/inbox"
-	"payments-service/internal/domain/outbox"
 	"payments-service/internal/domain/payments"
 )
 
-func TestPaymentsService_ProcessOrderCreated_PaymentTimeout(t *testing.T) {
+func parkedPayment(t *testing.T, orderID, userID string, amount money.Money, waitUntil time.Time) *payments.Payment {
+	payment, err := payments.NewPayment(orderID, userID, amount)
+	require.NoError(t, err)
+	payment.AwaitFunds("Insufficient funds", waitUntil)
+	return payment
+}
+
+func TestPaymentsService_ProcessOrderCreated_ParksUntilConfiguredWindow(t *testing.T) {
 	db, mockSQL, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
 
-	safeDB := &safeDB{DB: db}
-
 	mockPaymentsRepo := new(MockPaymentsRepository)
 	mockAccountRepo := new(MockAccountRepository)
 	mockOutboxRepo := new(MockOutboxRepository)
 
-	service := NewPaymentsService(safeDB, mockPaymentsRepo, mockAccountRepo, nil, mockOutboxRepo, nil)
+	fundsWait := FundsWaitConfig{Wait: 2 * time.Hour, BatchSize: 10}
+	service := NewPaymentsService(&safeDB{DB: db}, mockPaymentsRepo, mockAccountRepo, nil, nil, mockOutboxRepo, nil, nil, testHoldConfig, fundsWait)
 
 	ctx := context.Background()
-
-	orderEvent := inbox.OrderCreatedEvent{
-		OrderID:  "order-timeout-123",
-		UserID:   "user-789",
-		Amount:   75.25,
-		Currency: "USD",
+	orderEvent := events.OrderCreatedEvent{
+		OrderID:     "order-timeout-123",
+		UserID:      "user-789",
+		AmountMoney: money.New(7525, money.USD),
 	}
 	payload, err := json.Marshal(orderEvent)
 	require.NoError(t, err)
 
-	inboxMsg := &inbox.InboxMessage{Payload: payload}
-
-	oldPayment, err := payments.NewPayment(orderEvent.OrderID, orderEvent.UserID, orderEvent.Amount, orderEvent.Currency)
-	require.NoError(t, err)
-
-	oldPayment.CreatedAt = time.Now().Add(-20 * time.Second)
-
-	userAccount, err := account.NewAccount(orderEvent.UserID)
-	require.NoError(t, err)
-	err = userAccount.Credit(100.0)
+	userAccount, err := account.NewAccount(orderEvent.UserID, money.USD)
 	require.NoError(t, err)
 
 	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, orderEvent.OrderID).Return(nil, payments.ErrPaymentNotFound)
+	mockPaymentsRepo.On("StoreWithTx", ctx, mock.Anything, mock.AnythingOfType("*payments.Payment")).Return(nil)
+	mockAccountRepo.On("ListByUserIDWithTx", ctx, mock.Anything, orderEvent.UserID).Return([]*account.Account{userAccount}, nil)
 
-	mockPaymentsRepo.On("GetByOrderID", ctx, orderEvent.OrderID).Return(oldPayment, nil)
-
+	var parked *payments.Payment
 	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, mock.MatchedBy(func(p *payments.Payment) bool {
-		return p.IsFailed() && p.ErrorMessage == "Payment timed out"
+		parked = p
+		return p.IsAwaitingFunds()
 	})).Return(nil)
 
-	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
-		return msg.EventType == "payment.failed"
-	})).Return(nil)
+	before := time.Now()
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCreated(ctx, tx, &inbox.InboxMessage{Payload: payload})
 
-	mockSQL.ExpectCommit()
+	assert.NoError(t, err)
+	require.NotNil(t, parked)
+	assert.WithinDuration(t, before.Add(fundsWait.Wait), *parked.FundsWaitUntil, time.Minute)
+	mockOutboxRepo.AssertNotCalled(t, "StoreWithTx", mock.Anything, mock.Anything, mock.Anything)
+	mockPaymentsRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
 
-	err = service.ProcessOrderCreated(ctx, inboxMsg)
+func TestPaymentsService_ProcessOrderCreated_SkipsPaymentAwaitingFunds(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
 
-	assert.NoError(t, err)
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
 
-	mockPaymentsRepo.AssertExpectations(t)
-	mockOutboxRepo.AssertExpectations(t)
+	service := NewPaymentsService(&safeDB{DB: db}, mockPaymentsRepo, mockAccountRepo, nil, nil, nil, nil, nil, testHoldConfig, testFundsWaitConfig)
+
+	ctx := context.Background()
+	orderEvent := events.OrderCreatedEvent{
+		OrderID:     "order-fresh-456",
+		UserID:      "user-101",
+		AmountMoney: money.New(5000, money.USD),
+	}
+	payload, err := json.Marshal(orderEvent)
+	require.NoError(t, err)
+
+	payment := parkedPayment(t, orderEvent.OrderID, orderEvent.UserID, orderEvent.AmountMoney, time.Now().Add(time.Minute))
+
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("GetByOrderIDWithTx", ctx, mock.Anything, orderEvent.OrderID).Return(payment, nil)
 
-	mockAccountRepo.AssertNotCalled(t, "GetByUserID")
+	// Повторная доставка order.created не опрашивает кошелёк: платёж ждёт пополнения
+	tx, _ := db.Begin()
+	err = service.ProcessOrderCreated(ctx, tx, &inbox.InboxMessage{Payload: payload})
 
+	assert.NoError(t, err)
+	assert.True(t, payment.IsAwaitingFunds())
+	mockAccountRepo.AssertNotCalled(t, "ListByUserIDWithTx", mock.Anything, mock.Anything, mock.Anything)
 	assert.NoError(t, mockSQL.ExpectationsWereMet())
 }
 
-func TestPaymentsService_ProcessOrderCreated_PaymentNotTimedOut(t *testing.T) {
+func TestPaymentsService_ProcessAccountToppedUp_RetriesInFIFOOrder(t *testing.T) {
 	db, mockSQL, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
 
-	safeDB := &safeDB{DB: db}
-
 	mockPaymentsRepo := new(MockPaymentsRepository)
 	mockAccountRepo := new(MockAccountRepository)
 	mockOutboxRepo := new(MockOutboxRepository)
 
-	service := NewPaymentsService(safeDB, mockPaymentsRepo, mockAccountRepo, nil, mockOutboxRepo, nil)
+	service := NewPaymentsService(&safeDB{DB: db}, mockPaymentsRepo, mockAccountRepo, nil, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
 
 	ctx := context.Background()
+	userID := "user-456"
+	waitUntil := time.Now().Add(5 * time.Minute)
 
-	orderEvent := inbox.OrderCreatedEvent{
-		OrderID:  "order-fresh-456",
-		UserID:   "user-101",
-		Amount:   50.00,
-		Currency: "EUR",
-	}
-	payload, err := json.Marshal(orderEvent)
-	require.NoError(t, err)
+	userAccount, _ := account.NewAccount(userID, money.USD)
+	_ = userAccount.Credit(money.New(3000, money.USD))
 
-	inboxMsg := &inbox.InboxMessage{Payload: payload}
+	first := parkedPayment(t, "order-1", userID, money.New(2000, money.USD), waitUntil)
+	second := parkedPayment(t, "order-2", userID, money.New(2000, money.USD), waitUntil)
+	third := parkedPayment(t, "order-3", userID, money.New(500, money.USD), waitUntil)
 
-	freshPayment, err := payments.NewPayment(orderEvent.OrderID, orderEvent.UserID, orderEvent.Amount, orderEvent.Currency)
-	require.NoError(t, err)
+	payload, _ := json.Marshal(events.AccountToppedUpEvent{UserID: userID, AmountMoney: money.New(3000, money.USD)})
 
-	freshPayment.CreatedAt = time.Now().Add(-5 * time.Second)
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("ListAwaitingFundsByUserIDWithTx", ctx, mock.Anything, userID).
+		Return([]*payments.Payment{first, second, third}, nil)
+	mockAccountRepo.On("ListByUserIDWithTx", ctx, mock.Anything, userID).Return([]*account.Account{userAccount}, nil)
+	mockAccountRepo.On("UpdateWithTx", ctx, mock.Anything, userAccount).Return(nil)
+	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, first).Return(nil)
+	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, second).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
+		return msg.EventType == "payment.authorized"
+	})).Return(nil).Once()
 
-	userAccount, err := account.NewAccount(orderEvent.UserID)
-	require.NoError(t, err)
-	err = userAccount.Credit(100.0)
+	tx, _ := db.Begin()
+	err = service.ProcessAccountToppedUp(ctx, tx, &inbox.InboxMessage{Payload: payload})
+
+	assert.NoError(t, err)
+	assert.True(t, first.IsAuthorized())
+	// Второму платежу средств не хватает, и третий его не обгоняет, хотя на него хватило бы
+	assert.True(t, second.IsAwaitingFunds())
+	assert.Equal(t, waitUntil, *second.FundsWaitUntil)
+	assert.True(t, third.IsAwaitingFunds())
+	assert.Equal(t, money.New(1000, money.USD), userAccount.Available())
+
+	mockPaymentsRepo.AssertNotCalled(t, "UpdateWithTx", mock.Anything, mock.Anything, third)
+	mockPaymentsRepo.AssertExpectations(t)
+	mockOutboxRepo.AssertExpectations(t)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestPaymentsService_RetryAwaitingPayments_FailsExpiredWait(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
 	require.NoError(t, err)
+	defer db.Close()
 
-	mockSQL.ExpectBegin()
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockAccountRepo := new(MockAccountRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
 
-	mockPaymentsRepo.On("GetByOrderID", ctx, orderEvent.OrderID).Return(freshPayment, nil)
+	service := NewPaymentsService(&safeDB{DB: db}, mockPaymentsRepo, mockAccountRepo, nil, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
 
-	mockAccountRepo.On("GetByUserID", ctx, orderEvent.UserID).Return(userAccount, nil)
+	ctx := context.Background()
+	expired := parkedPayment(t, "order-1", "user-456", money.New(2000, money.USD), time.Now().Add(-time.Second))
 
-	mockAccountRepo.On("UpdateWithTx", ctx, mock.Anything, mock.MatchedBy(func(acc *account.Account) bool {
-		return acc.Balance == 50.0 // 100 - 50 = 50
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("ListAwaitingFundsByUserIDWithTx", ctx, mock.Anything, "user-456").Return([]*payments.Payment{expired}, nil)
+	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, expired).Return(nil)
+	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
+		return msg.EventType == "payment.failed"
 	})).Return(nil)
 
-	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, mock.MatchedBy(func(p *payments.Payment) bool {
-		return p.IsCompleted() && p.TransactionID != ""
-	})).Return(nil)
+	tx, _ := db.Begin()
+	authorized, err := service.RetryAwaitingPayments(ctx, tx, "user-456")
 
+	assert.NoError(t, err)
+	assert.Zero(t, authorized)
+	assert.True(t, expired.IsFailed())
+	assert.Equal(t, fundsWaitExpiredMessage, expired.ErrorMessage)
+	mockAccountRepo.AssertNotCalled(t, "ListByUserIDWithTx", mock.Anything, mock.Anything, mock.Anything)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
+
+func TestPaymentsService_ExpireAwaitingPayments(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mockPaymentsRepo := new(MockPaymentsRepository)
+	mockOutboxRepo := new(MockOutboxRepository)
+
+	service := NewPaymentsService(&safeDB{DB: db}, mockPaymentsRepo, nil, nil, nil, mockOutboxRepo, nil, nil, testHoldConfig, testFundsWaitConfig)
+
+	ctx := context.Background()
+	expired := parkedPayment(t, "order-1", "user-456", money.New(2000, money.USD), time.Now().Add(-time.Minute))
+
+	mockSQL.ExpectBegin()
+	mockPaymentsRepo.On("ListExpiredFundsWaitWithTx", ctx, mock.Anything, mock.AnythingOfType("time.Time"), testFundsWaitConfig.BatchSize).
+		Return([]*payments.Payment{expired}, nil)
+	mockPaymentsRepo.On("UpdateWithTx", ctx, mock.Anything, expired).Return(nil)
 	mockOutboxRepo.On("StoreWithTx", ctx, mock.Anything, mock.MatchedBy(func(msg *outbox.OutboxMessage) bool {
-		return msg.EventType == "payment.completed"
+		return msg.EventType == "payment.failed"
 	})).Return(nil)
-
 	mockSQL.ExpectCommit()
 
-	err = service.ProcessOrderCreated(ctx, inboxMsg)
+	count, err := service.ExpireAwaitingPayments(ctx)
 
 	assert.NoError(t, err)
-
+	assert.Equal(t, 1, count)
+	assert.True(t, expired.IsFailed())
 	mockPaymentsRepo.AssertExpectations(t)
-	mockAccountRepo.AssertExpectations(t)
 	mockOutboxRepo.AssertExpectations(t)
-
 	assert.NoError(t, mockSQL.ExpectationsWereMet())
 }
 
-func TestPayment_IsTimedOut_EdgeCases(t *testing.T) {
-	t.Run("14.9 seconds old - should not be timed out", func(t *testing.T) {
-		payment, err := payments.NewPayment("order-1", "user-1", 100.0, "USD")
-		require.NoError(t, err)
-
-		payment.CreatedAt = time.Now().Add(-14*time.Second - 900*time.Millisecond)
+func TestPayment_IsFundsWaitExpired_EdgeCases(t *testing.T) {
+	now := time.Now()
 
-		assert.False(t, payment.IsTimedOut())
+	t.Run("Deadline in the future - still waiting", func(t *testing.T) {
+		payment := parkedPayment(t, "order-1", "user-1", money.New(10000, money.USD), now.Add(100*time.Millisecond))
+		assert.False(t, payment.IsFundsWaitExpired(now))
 	})
 
-	t.Run("15.1 seconds old - should be timed out", func(t *testing.T) {
-		payment, err := payments.NewPayment("order-2", "user-2", 100.0, "USD")
-		require.NoError(t, err)
-
-		payment.CreatedAt = time.Now().Add(-15*time.Second - 100*time.Millisecond)
-
-		assert.True(t, payment.IsTimedOut())
+	t.Run("Deadline reached - expired", func(t *testing.T) {
+		payment := parkedPayment(t, "order-2", "user-2", money.New(10000, money.USD), now)
+		assert.True(t, payment.IsFundsWaitExpired(now))
 	})
 
-	t.Run("Just created - should not be timed out", func(t *testing.T) {
-		payment, err := payments.NewPayment("order-3", "user-3", 100.0, "USD")
+	t.Run("Pending payment never expires", func(t *testing.T) {
+		payment, err := payments.NewPayment("order-3", "user-3", money.New(10000, money.USD))
 		require.NoError(t, err)
-
-		assert.False(t, payment.IsTimedOut())
+		assert.False(t, payment.IsFundsWaitExpired(now.Add(time.Hour)))
 	})
 
-	t.Run("Very old payment - should be timed out", func(t *testing.T) {
-		payment, err := payments.NewPayment("order-4", "user-4", 100.0, "USD")
-		require.NoError(t, err)
-
-		payment.CreatedAt = time.Now().Add(-1 * time.Hour)
-
-		assert.True(t, payment.IsTimedOut())
+	t.Run("Authorized payment is no longer waiting", func(t *testing.T) {
+		payment := parkedPayment(t, "order-4", "user-4", money.New(10000, money.USD), now.Add(-time.Hour))
+		payment.Authorize(payment.Amount, nil, now.Add(time.Hour))
+		assert.False(t, payment.IsFundsWaitExpired(now))
 	})
 }
 
-func BenchmarkPayment_IsTimedOut(b *testing.B) {
-	payment, _ := payments.NewPayment("order-bench", "user-bench", 100.0, "USD")
-	payment.CreatedAt = time.Now().Add(-20 * time.Second)
+func BenchmarkPayment_IsFundsWaitExpired(b *testing.B) {
+	payment, _ := payments.NewPayment("order-bench", "user-bench", money.New(10000, money.USD))
+	payment.AwaitFunds("Insufficient funds", time.Now().Add(-20*time.Second))
+	now := time.Now()
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = payment.IsTimedOut()
+		_ = payment.IsFundsWaitExpired(now)
 	}
 }
@@ -1,21 +1,39 @@
 package account
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/gofrs/uuid"
+
+	"contracts/money"
+)
+
+var (
+	ErrAccountNotFound   = errors.New("account not found")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrInvalidHold       = errors.New("invalid hold")
 )
 
+// Account — кошелёк пользователя в одной валюте. У пользователя может быть
+// по одному кошельку на каждую поддерживаемую валюту.
+// Balance — текущий (проведённый по журналу) баланс, Held — сумма, заблокированная
+// под авторизованные, но ещё не списанные платежи.
 type Account struct {
 	ID        string
 	UserID    string
-	Balance   float64
+	Balance   money.Money
+	Held      money.Money
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
-func NewAccount(userID string) (*Account, error) {
+func NewAccount(userID, currency string) (*Account, error) {
+	if err := money.ValidateCurrency(currency); err != nil {
+		return nil, err
+	}
+
 	v7, err := uuid.NewV7()
 	if err != nil {
 		return nil, err
@@ -24,36 +42,142 @@ func NewAccount(userID string) (*Account, error) {
 	return &Account{
 		ID:        v7.String(),
 		UserID:    userID,
-		Balance:   0.0,
+		Balance:   money.Zero(currency),
+		Held:      money.Zero(currency),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}, nil
 }
 
-func (a *Account) Credit(amount float64) error {
-	if amount <= 0 {
+func (a *Account) Currency() string {
+	return a.Balance.Currency()
+}
+
+// Current возвращает текущий баланс, включая заблокированные средства.
+func (a *Account) Current() money.Money {
+	return a.Balance
+}
+
+// Available возвращает сумму, которую можно заблокировать или списать.
+func (a *Account) Available() money.Money {
+	available, err := a.Balance.Sub(a.held())
+	if err != nil {
+		return a.Balance
+	}
+	return available
+}
+
+// held нужен для кошельков, прочитанных без колонки held.
+func (a *Account) held() money.Money {
+	if a.Held.Currency() == "" {
+		return money.Zero(a.Currency())
+	}
+	return a.Held
+}
+
+func (a *Account) Credit(amount money.Money) error {
+	if !amount.IsPositive() {
 		return fmt.Errorf("credit amount must be positive")
 	}
 
-	a.Balance += amount
+	balance, err := a.Balance.Add(amount)
+	if err != nil {
+		return err
+	}
+
+	a.Balance = balance
 	a.UpdatedAt = time.Now()
 	return nil
 }
 
-func (a *Account) Debit(amount float64) error {
-	if amount <= 0 {
+func (a *Account) Debit(amount money.Money) error {
+	if !amount.IsPositive() {
 		return fmt.Errorf("debit amount must be positive")
 	}
 
-	if a.Balance < amount {
-		return fmt.Errorf("insufficient funds: balance %.2f, required %.2f", a.Balance, amount)
+	balance, err := a.Balance.Sub(amount)
+	if err != nil {
+		return err
+	}
+
+	if !a.HasSufficientFunds(amount) {
+		return fmt.Errorf("%w: available %s, required %s", ErrInsufficientFunds, a.Available(), amount)
+	}
+
+	a.Balance = balance
+	a.UpdatedAt = time.Now()
+	return nil
+}
+
+// Hold блокирует сумму под платёж: доступный остаток уменьшается, текущий баланс — нет.
+func (a *Account) Hold(amount money.Money) error {
+	if !amount.IsPositive() {
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidHold)
+	}
+
+	held, err := a.held().Add(amount)
+	if err != nil {
+		return err
+	}
+
+	if !a.HasSufficientFunds(amount) {
+		return fmt.Errorf("%w: available %s, required %s", ErrInsufficientFunds, a.Available(), amount)
+	}
+
+	a.Held = held
+	a.UpdatedAt = time.Now()
+	return nil
+}
+
+// ReleaseHold снимает блокировку, не меняя текущий баланс.
+func (a *Account) ReleaseHold(amount money.Money) error {
+	held, err := a.reduceHeld(amount)
+	if err != nil {
+		return err
+	}
+
+	a.Held = held
+	a.UpdatedAt = time.Now()
+	return nil
+}
+
+// Capture списывает ранее заблокированную сумму.
+func (a *Account) Capture(amount money.Money) error {
+	held, err := a.reduceHeld(amount)
+	if err != nil {
+		return err
+	}
+
+	balance, err := a.Balance.Sub(amount)
+	if err != nil {
+		return err
 	}
 
-	a.Balance -= amount
+	a.Held = held
+	a.Balance = balance
 	a.UpdatedAt = time.Now()
 	return nil
 }
 
-func (a *Account) HasSufficientFunds(amount float64) bool {
-	return a.Balance >= amount
+func (a *Account) reduceHeld(amount money.Money) (money.Money, error) {
+	if !amount.IsPositive() {
+		return money.Money{}, fmt.Errorf("%w: amount must be positive", ErrInvalidHold)
+	}
+
+	held, err := a.held().Sub(amount)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	if held.IsNegative() {
+		return money.Money{}, fmt.Errorf("%w: held %s, requested %s", ErrInvalidHold, a.held(), amount)
+	}
+
+	return held, nil
+}
+
+// HasSufficientFunds проверяет доступный остаток, а не текущий баланс.
+func (a *Account) HasSufficientFunds(amount money.Money) bool {
+	cmp, err := a.Available().Cmp(amount)
+	return err == nil && cmp >= 0
 }
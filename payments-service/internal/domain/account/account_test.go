@@ -5,60 +5,118 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"contracts/money"
 )
 
 func TestNewAccount(t *testing.T) {
 	userID := "user-123"
-	acc, err := NewAccount(userID)
+	acc, err := NewAccount(userID, money.USD)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, acc)
 	assert.NotEmpty(t, acc.ID)
 	assert.Equal(t, userID, acc.UserID)
-	assert.Equal(t, 0.0, acc.Balance)
+	assert.Equal(t, money.Zero(money.USD), acc.Balance)
 	assert.WithinDuration(t, time.Now(), acc.CreatedAt, time.Second)
 	assert.WithinDuration(t, time.Now(), acc.UpdatedAt, time.Second)
 }
 
+func TestNewAccount_Currency(t *testing.T) {
+	acc, err := NewAccount("user-123", money.EUR)
+	assert.NoError(t, err)
+	assert.Equal(t, money.EUR, acc.Currency())
+	assert.Equal(t, money.Zero(money.EUR), acc.Balance)
+
+	_, err = NewAccount("user-123", "JPY")
+	assert.ErrorIs(t, err, money.ErrUnsupportedCurrency)
+}
+
 func TestAccount_Credit(t *testing.T) {
-	acc, _ := NewAccount("user-123")
+	acc, _ := NewAccount("user-123", money.USD)
 
-	err := acc.Credit(100.50)
+	err := acc.Credit(money.New(10050, money.USD))
 	assert.NoError(t, err)
-	assert.Equal(t, 100.50, acc.Balance)
+	assert.Equal(t, money.New(10050, money.USD), acc.Balance)
 
-	err = acc.Credit(50.25)
+	err = acc.Credit(money.New(5025, money.USD))
 	assert.NoError(t, err)
-	assert.Equal(t, 150.75, acc.Balance)
+	assert.Equal(t, money.New(15075, money.USD), acc.Balance)
 
-	err = acc.Credit(-10)
+	err = acc.Credit(money.New(-1000, money.USD))
 	assert.Error(t, err)
 }
 
 func TestAccount_Debit(t *testing.T) {
-	acc, _ := NewAccount("user-123")
-	_ = acc.Credit(200.0)
+	acc, _ := NewAccount("user-123", money.USD)
+	_ = acc.Credit(money.New(20000, money.USD))
 
-	err := acc.Debit(50.0)
+	err := acc.Debit(money.New(5000, money.USD))
 	assert.NoError(t, err)
-	assert.Equal(t, 150.0, acc.Balance)
+	assert.Equal(t, money.New(15000, money.USD), acc.Balance)
 
-	err = acc.Debit(150.0)
+	err = acc.Debit(money.New(15000, money.USD))
 	assert.NoError(t, err)
-	assert.Equal(t, 0.0, acc.Balance)
+	assert.Equal(t, money.Zero(money.USD), acc.Balance)
 
-	err = acc.Debit(10.0)
+	err = acc.Debit(money.New(1000, money.USD))
 	assert.Error(t, err)
 
-	err = acc.Debit(-10.0)
+	err = acc.Debit(money.New(-1000, money.USD))
 	assert.Error(t, err)
 }
 
+func TestAccount_NoFloatDrift(t *testing.T) {
+	acc, _ := NewAccount("user-123", money.USD)
+	for i := 0; i < 1000; i++ {
+		_ = acc.Credit(money.FromFloat(0.1, money.USD))
+	}
+	for i := 0; i < 999; i++ {
+		_ = acc.Debit(money.FromFloat(0.1, money.USD))
+	}
+	assert.Equal(t, money.New(10, money.USD), acc.Balance)
+
+	err := acc.Credit(money.New(100, "EUR"))
+	assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+}
+
 func TestAccount_HasSufficientFunds(t *testing.T) {
-	acc, _ := NewAccount("user-123")
-	_ = acc.Credit(100.0)
+	acc, _ := NewAccount("user-123", money.USD)
+	_ = acc.Credit(money.New(10000, money.USD))
+
+	assert.True(t, acc.HasSufficientFunds(money.New(5000, money.USD)))
+	assert.True(t, acc.HasSufficientFunds(money.New(10000, money.USD)))
+	assert.False(t, acc.HasSufficientFunds(money.New(10001, money.USD)))
+}
+
+func TestAccount_HoldCaptureRelease(t *testing.T) {
+	acc, _ := NewAccount("user-123", money.USD)
+	_ = acc.Credit(money.New(10000, money.USD))
+
+	err := acc.Hold(money.New(6000, money.USD))
+	assert.NoError(t, err)
+	assert.Equal(t, money.New(10000, money.USD), acc.Current())
+	assert.Equal(t, money.New(4000, money.USD), acc.Available())
+
+	// Заблокированные средства нельзя заблокировать или списать повторно
+	err = acc.Hold(money.New(5000, money.USD))
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	err = acc.Debit(money.New(5000, money.USD))
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+
+	err = acc.Capture(money.New(6000, money.USD))
+	assert.NoError(t, err)
+	assert.Equal(t, money.New(4000, money.USD), acc.Current())
+	assert.Equal(t, money.New(4000, money.USD), acc.Available())
+	assert.Equal(t, money.Zero(money.USD), acc.Held)
+
+	_ = acc.Hold(money.New(1500, money.USD))
+	err = acc.ReleaseHold(money.New(1500, money.USD))
+	assert.NoError(t, err)
+	assert.Equal(t, money.New(4000, money.USD), acc.Available())
 
-	assert.True(t, acc.HasSufficientFunds(50.0))
-	assert.True(t, acc.HasSufficientFunds(100.0))
-	assert.False(t, acc.HasSufficientFunds(100.01))
+	err = acc.ReleaseHold(money.New(100, money.USD))
+	assert.ErrorIs(t, err, ErrInvalidHold)
+	err = acc.Capture(money.New(100, money.USD))
+	assert.ErrorIs(t, err, ErrInvalidHold)
 }
@@ -0,0 +1,56 @@
+package ledger
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor указывает на последнюю отданную проводку. Выдача идёт от новых
+// к старым по (created_at, id), поэтому новые проводки не сдвигают страницы.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Page — страница проводок; NextCursor пуст, если это последняя страница.
+type Page struct {
+	Entries    []*Entry
+	NextCursor string
+}
+
+func CursorAfter(entry *Entry) Cursor {
+	return Cursor{CreatedAt: entry.CreatedAt, ID: entry.ID}
+}
+
+func (c Cursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(value string) (*Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	return &Cursor{CreatedAt: ts, ID: id}, nil
+}
@@ -0,0 +1,199 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"contracts/money"
+	"payments-service/internal/domain/account"
+)
+
+type Direction string
+
+const (
+	Debit  Direction = "debit"
+	Credit Direction = "credit"
+)
+
+type Kind string
+
+const (
+	KindTopUp      Kind = "topup"
+	KindPayment    Kind = "payment"
+	KindRefund     Kind = "refund"
+	KindAdjustment Kind = "adjustment"
+)
+
+// Системные счета — вторая сторона проводок по кошелькам пользователей.
+// Кошелёк — обязательство перед пользователем: кредит увеличивает баланс, дебет уменьшает.
+const (
+	FundingAccount     = "system:funding"
+	SettlementAccount  = "system:settlement"
+	AdjustmentsAccount = "system:adjustments"
+)
+
+var (
+	ErrInvalidEntry          = errors.New("invalid ledger entry")
+	ErrUnbalancedTransaction = errors.New("unbalanced ledger transaction")
+	ErrBalanceMismatch       = errors.New("account balance does not match ledger")
+)
+
+// Entry — одна проводка по счёту. Kind, Reference и Description
+// берутся из транзакции, к которой относится проводка.
+type Entry struct {
+	ID            string
+	TransactionID string
+	LedgerAccount string
+	UserID        string
+	Direction     Direction
+	Amount        money.Money
+	Kind          Kind
+	Reference     string
+	Description   string
+	CreatedAt     time.Time
+}
+
+// Transaction — набор проводок, которые записываются атомарно.
+// В каждой валюте сумма дебетов равна сумме кредитов.
+type Transaction struct {
+	ID          string
+	Kind        Kind
+	Reference   string
+	Description string
+	Entries     []*Entry
+	CreatedAt   time.Time
+}
+
+type Posting struct {
+	LedgerAccount string
+	UserID        string
+	Direction     Direction
+	Amount        money.Money
+}
+
+func NewTransaction(kind Kind, reference, description string, postings ...Posting) (*Transaction, error) {
+	v7, err := uuid.NewV7()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	txn := &Transaction{
+		ID:          v7.String(),
+		Kind:        kind,
+		Reference:   reference,
+		Description: description,
+		CreatedAt:   now,
+	}
+
+	for _, posting := range postings {
+		entryID, err := uuid.NewV7()
+		if err != nil {
+			return nil, err
+		}
+
+		txn.Entries = append(txn.Entries, &Entry{
+			ID:            entryID.String(),
+			TransactionID: txn.ID,
+			LedgerAccount: posting.LedgerAccount,
+			UserID:        posting.UserID,
+			Direction:     posting.Direction,
+			Amount:        posting.Amount,
+			Kind:          kind,
+			Reference:     reference,
+			Description:   description,
+			CreatedAt:     now,
+		})
+	}
+
+	if err := txn.Validate(); err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
+
+// NewTopUp — пополнение: деньги приходят извне на кошелёк.
+func NewTopUp(wallet *account.Account, amount money.Money) (*Transaction, error) {
+	return NewTransaction(KindTopUp, wallet.ID, "account top-up",
+		Posting{LedgerAccount: FundingAccount, Direction: Debit, Amount: amount},
+		Posting{LedgerAccount: wallet.ID, UserID: wallet.UserID, Direction: Credit, Amount: amount},
+	)
+}
+
+// NewPaymentCharge — списание с кошелька в оплату заказа. amount — сумма в валюте кошелька.
+func NewPaymentCharge(wallet *account.Account, amount money.Money, paymentID, description string) (*Transaction, error) {
+	return NewTransaction(KindPayment, paymentID, description,
+		Posting{LedgerAccount: wallet.ID, UserID: wallet.UserID, Direction: Debit, Amount: amount},
+		Posting{LedgerAccount: SettlementAccount, Direction: Credit, Amount: amount},
+	)
+}
+
+// NewRefund — возврат ранее списанной суммы на кошелёк.
+func NewRefund(wallet *account.Account, amount money.Money, paymentID, description string) (*Transaction, error) {
+	return NewTransaction(KindRefund, paymentID, description,
+		Posting{LedgerAccount: SettlementAccount, Direction: Debit, Amount: amount},
+		Posting{LedgerAccount: wallet.ID, UserID: wallet.UserID, Direction: Credit, Amount: amount},
+	)
+}
+
+// NewAdjustment — ручная корректировка баланса; отрицательная сумма уменьшает баланс кошелька.
+func NewAdjustment(wallet *account.Account, amount money.Money, reason string) (*Transaction, error) {
+	if amount.IsNegative() {
+		positive := money.New(-amount.MinorUnits(), amount.Currency())
+		return NewTransaction(KindAdjustment, wallet.ID, reason,
+			Posting{LedgerAccount: wallet.ID, UserID: wallet.UserID, Direction: Debit, Amount: positive},
+			Posting{LedgerAccount: AdjustmentsAccount, Direction: Credit, Amount: positive},
+		)
+	}
+
+	return NewTransaction(KindAdjustment, wallet.ID, reason,
+		Posting{LedgerAccount: AdjustmentsAccount, Direction: Debit, Amount: amount},
+		Posting{LedgerAccount: wallet.ID, UserID: wallet.UserID, Direction: Credit, Amount: amount},
+	)
+}
+
+// Validate проверяет, что транзакция сбалансирована в каждой валюте.
+func (t *Transaction) Validate() error {
+	if len(t.Entries) < 2 {
+		return fmt.Errorf("%w: transaction needs at least two entries", ErrUnbalancedTransaction)
+	}
+
+	totals := make(map[string]int64)
+	for _, entry := range t.Entries {
+		if entry.LedgerAccount == "" {
+			return fmt.Errorf("%w: ledger account is required", ErrInvalidEntry)
+		}
+		if !entry.Amount.IsPositive() {
+			return fmt.Errorf("%w: amount must be positive, got %s", ErrInvalidEntry, entry.Amount)
+		}
+
+		switch entry.Direction {
+		case Debit:
+			totals[entry.Amount.Currency()] += entry.Amount.MinorUnits()
+		case Credit:
+			totals[entry.Amount.Currency()] -= entry.Amount.MinorUnits()
+		default:
+			return fmt.Errorf("%w: unknown direction %q", ErrInvalidEntry, entry.Direction)
+		}
+	}
+
+	for currency, total := range totals {
+		if total != 0 {
+			return fmt.Errorf("%w: debits and credits differ by %s", ErrUnbalancedTransaction, money.New(total, currency))
+		}
+	}
+
+	return nil
+}
+
+// Signed возвращает сумму проводки с точки зрения кошелька: кредит увеличивает баланс.
+func (e *Entry) Signed() money.Money {
+	if e.Direction == Debit {
+		return money.New(-e.Amount.MinorUnits(), e.Amount.Currency())
+	}
+	return e.Amount
+}
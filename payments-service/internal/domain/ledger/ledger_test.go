@@ -0,0 +1,89 @@
+package ledger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"contracts/money"
+	"payments-service/internal/domain/account"
+)
+
+func TestNewTopUp(t *testing.T) {
+	wallet, _ := account.NewAccount("user-123", money.USD)
+	amount := money.New(10000, money.USD)
+
+	txn, err := NewTopUp(wallet, amount)
+
+	assert.NoError(t, err)
+	assert.Equal(t, KindTopUp, txn.Kind)
+	assert.Len(t, txn.Entries, 2)
+	assert.Equal(t, FundingAccount, txn.Entries[0].LedgerAccount)
+	assert.Equal(t, Debit, txn.Entries[0].Direction)
+	assert.Equal(t, wallet.ID, txn.Entries[1].LedgerAccount)
+	assert.Equal(t, "user-123", txn.Entries[1].UserID)
+	assert.Equal(t, amount, txn.Entries[1].Signed())
+	for _, entry := range txn.Entries {
+		assert.Equal(t, txn.ID, entry.TransactionID)
+	}
+}
+
+func TestNewPaymentChargeAndRefund(t *testing.T) {
+	wallet, _ := account.NewAccount("user-123", money.EUR)
+	amount := money.New(1999, money.EUR)
+
+	charge, err := NewPaymentCharge(wallet, amount, "payment-1", "order order-1")
+	assert.NoError(t, err)
+	assert.Equal(t, money.New(-1999, money.EUR), charge.Entries[0].Signed())
+	assert.Equal(t, "payment-1", charge.Reference)
+
+	refund, err := NewRefund(wallet, amount, "payment-1", "refund order-1")
+	assert.NoError(t, err)
+	assert.Equal(t, amount, refund.Entries[1].Signed())
+}
+
+func TestNewAdjustment_Negative(t *testing.T) {
+	wallet, _ := account.NewAccount("user-123", money.USD)
+
+	txn, err := NewAdjustment(wallet, money.New(-500, money.USD), "chargeback")
+
+	assert.NoError(t, err)
+	assert.Equal(t, wallet.ID, txn.Entries[0].LedgerAccount)
+	assert.Equal(t, money.New(-500, money.USD), txn.Entries[0].Signed())
+}
+
+func TestNewTransaction_Unbalanced(t *testing.T) {
+	_, err := NewTransaction(KindAdjustment, "ref", "broken",
+		Posting{LedgerAccount: AdjustmentsAccount, Direction: Debit, Amount: money.New(100, money.USD)},
+		Posting{LedgerAccount: "wallet", Direction: Credit, Amount: money.New(90, money.USD)},
+	)
+	assert.ErrorIs(t, err, ErrUnbalancedTransaction)
+
+	// Балансируется каждая валюта отдельно
+	_, err = NewTransaction(KindAdjustment, "ref", "cross currency",
+		Posting{LedgerAccount: AdjustmentsAccount, Direction: Debit, Amount: money.New(100, money.USD)},
+		Posting{LedgerAccount: "wallet", Direction: Credit, Amount: money.New(100, money.EUR)},
+	)
+	assert.ErrorIs(t, err, ErrUnbalancedTransaction)
+
+	_, err = NewTopUp(&account.Account{ID: "wallet"}, money.Zero(money.USD))
+	assert.ErrorIs(t, err, ErrInvalidEntry)
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC), ID: "entry-1"}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+
+	assert.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, cursor.ID, decoded.ID)
+
+	empty, err := DecodeCursor("")
+	assert.NoError(t, err)
+	assert.Nil(t, empty)
+
+	_, err = DecodeCursor("not-a-cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
@@ -1,33 +1,53 @@
 package payments
 
 import (
+	"errors"
 	"time"
 
 	"github.com/gofrs/uuid"
+
+	"contracts/money"
 )
 
 type PaymentStatus string
 
 const (
-	PaymentStatusPending   PaymentStatus = "pending"
-	PaymentStatusCompleted PaymentStatus = "completed"
-	PaymentStatusFailed    PaymentStatus = "failed"
+	PaymentStatusPending       PaymentStatus = "pending"
+	PaymentStatusAwaitingFunds PaymentStatus = "awaiting_funds"
+	PaymentStatusAuthorized    PaymentStatus = "authorized"
+	PaymentStatusCompleted     PaymentStatus = "completed"
+	PaymentStatusFailed        PaymentStatus = "failed"
+	PaymentStatusRefunded      PaymentStatus = "refunded"
+	PaymentStatusCancelled     PaymentStatus = "cancelled"
+	PaymentStatusReleased      PaymentStatus = "released"
+)
+
+var (
+	ErrPaymentNotFound      = errors.New("payment not found")
+	ErrPaymentAlreadyExists = errors.New("payment already exists for order")
 )
 
+// Payment — оплата заказа. Amount выставлен в валюте заказа, ChargedAmount — сумма,
+// фактически списанная с кошелька. Если кошелёк в другой валюте, ExchangeRate хранит курс пересчёта.
+// Авторизованный платёж держит блокировку на кошельке до HoldExpiresAt.
+// Платёж, которому не хватило средств, ждёт пополнения до FundsWaitUntil.
 type Payment struct {
-	ID            string
-	OrderID       string
-	UserID        string
-	Amount        float64
-	Currency      string
-	Status        PaymentStatus
-	ErrorMessage  string
-	TransactionID string
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-}
-
-func NewPayment(orderID, userID string, amount float64, currency string) (*Payment, error) {
+	ID             string
+	OrderID        string
+	UserID         string
+	Amount         money.Money
+	ChargedAmount  money.Money
+	ExchangeRate   *money.ExchangeRate
+	Status         PaymentStatus
+	ErrorMessage   string
+	TransactionID  string
+	HoldExpiresAt  *time.Time
+	FundsWaitUntil *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func NewPayment(orderID, userID string, amount money.Money) (*Payment, error) {
 	v7, err := uuid.NewV7()
 	if err != nil {
 		return nil, err
@@ -38,13 +58,63 @@ func NewPayment(orderID, userID string, amount float64, currency string) (*Payme
 		OrderID:   orderID,
 		UserID:    userID,
 		Amount:    amount,
-		Currency:  currency,
 		Status:    PaymentStatusPending,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}, nil
 }
 
+// RecordCharge запоминает, сколько и по какому курсу списано с кошелька.
+// Для списания в валюте платежа rate равен nil.
+func (p *Payment) RecordCharge(charged money.Money, rate *money.ExchangeRate) {
+	p.ChargedAmount = charged
+	p.ExchangeRate = rate
+	p.UpdatedAt = time.Now()
+}
+
+// Charged возвращает фактически списанную сумму. У платежей, проведённых до
+// появления мультивалютных кошельков, она совпадает с Amount.
+func (p *Payment) Charged() money.Money {
+	if p.ChargedAmount.Currency() == "" {
+		return p.Amount
+	}
+	return p.ChargedAmount
+}
+
+// Authorize фиксирует блокировку суммы charged на кошельке до expiresAt.
+func (p *Payment) Authorize(charged money.Money, rate *money.ExchangeRate, expiresAt time.Time) {
+	p.RecordCharge(charged, rate)
+	p.Status = PaymentStatusAuthorized
+	p.ErrorMessage = ""
+	p.HoldExpiresAt = &expiresAt
+	p.FundsWaitUntil = nil
+}
+
+// AwaitFunds откладывает платёж до пополнения кошелька. Срок ожидания задаётся
+// при первой постановке в очередь и при повторных попытках не продлевается.
+func (p *Payment) AwaitFunds(reason string, until time.Time) {
+	p.Status = PaymentStatusAwaitingFunds
+	p.ErrorMessage = reason
+	if p.FundsWaitUntil == nil {
+		p.FundsWaitUntil = &until
+	}
+	p.UpdatedAt = time.Now()
+}
+
+// Capture списывает авторизованную сумму.
+func (p *Payment) Capture(transactionID string) {
+	p.Complete(transactionID)
+	p.HoldExpiresAt = nil
+}
+
+// Release снимает блокировку без списания.
+func (p *Payment) Release(reason string) {
+	p.Status = PaymentStatusReleased
+	p.ErrorMessage = reason
+	p.HoldExpiresAt = nil
+	p.UpdatedAt = time.Now()
+}
+
 func (p *Payment) Complete(transactionID string) {
 	p.Status = PaymentStatusCompleted
 	p.TransactionID = transactionID
@@ -57,6 +127,39 @@ func (p *Payment) Fail(errorMessage string) {
 	p.UpdatedAt = time.Now()
 }
 
+func (p *Payment) Refund() {
+	p.Status = PaymentStatusRefunded
+	p.UpdatedAt = time.Now()
+}
+
+func (p *Payment) Cancel(reason string) {
+	p.Status = PaymentStatusCancelled
+	p.ErrorMessage = reason
+	p.UpdatedAt = time.Now()
+}
+
+func (p *Payment) IsAwaitingFunds() bool {
+	return p.Status == PaymentStatusAwaitingFunds
+}
+
+// IsFundsWaitExpired сообщает, что отложенный платёж так и не дождался пополнения.
+func (p *Payment) IsFundsWaitExpired(now time.Time) bool {
+	return p.IsAwaitingFunds() && p.FundsWaitUntil != nil && !now.Before(*p.FundsWaitUntil)
+}
+
+func (p *Payment) IsAuthorized() bool {
+	return p.Status == PaymentStatusAuthorized
+}
+
+// IsHoldExpired сообщает, что блокировка авторизованного платежа просрочена.
+func (p *Payment) IsHoldExpired(now time.Time) bool {
+	return p.IsAuthorized() && p.HoldExpiresAt != nil && !now.Before(*p.HoldExpiresAt)
+}
+
+func (p *Payment) IsReleased() bool {
+	return p.Status == PaymentStatusReleased
+}
+
 func (p *Payment) IsCompleted() bool {
 	return p.Status == PaymentStatusCompleted
 }
@@ -69,6 +172,10 @@ func (p *Payment) IsPending() bool {
 	return p.Status == PaymentStatusPending
 }
 
-func (p *Payment) IsTimedOut() bool {
-	return time.Since(p.CreatedAt) > 15*time.Second
+func (p *Payment) IsRefunded() bool {
+	return p.Status == PaymentStatusRefunded
+}
+
+func (p *Payment) IsCancelled() bool {
+	return p.Status == PaymentStatusCancelled
 }
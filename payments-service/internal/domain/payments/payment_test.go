@@ -5,15 +5,16 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"contracts/money"
 )
 
 func TestNewPayment(t *testing.T) {
 	orderID := "order-123"
 	userID := "user-456"
-	amount := 100.50
-	currency := "USD"
+	amount := money.New(10050, money.USD)
 
-	p, err := NewPayment(orderID, userID, amount, currency)
+	p, err := NewPayment(orderID, userID, amount)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, p)
@@ -21,7 +22,7 @@ func TestNewPayment(t *testing.T) {
 	assert.Equal(t, orderID, p.OrderID)
 	assert.Equal(t, userID, p.UserID)
 	assert.Equal(t, amount, p.Amount)
-	assert.Equal(t, currency, p.Currency)
+	assert.Equal(t, "USD", p.Amount.Currency())
 	assert.Equal(t, PaymentStatusPending, p.Status)
 	assert.Empty(t, p.TransactionID)
 	assert.Empty(t, p.ErrorMessage)
@@ -30,7 +31,7 @@ func TestNewPayment(t *testing.T) {
 }
 
 func TestPayment_Complete(t *testing.T) {
-	p, _ := NewPayment("order-123", "user-456", 100.50, "USD")
+	p, _ := NewPayment("order-123", "user-456", money.New(10050, money.USD))
 	transactionID := "txn-789"
 
 	p.Complete(transactionID)
@@ -40,8 +41,23 @@ func TestPayment_Complete(t *testing.T) {
 	assert.True(t, p.UpdatedAt.After(p.CreatedAt))
 }
 
+func TestPayment_RecordCharge(t *testing.T) {
+	p, _ := NewPayment("order-123", "user-456", money.New(1999, money.EUR))
+	assert.Equal(t, p.Amount, p.Charged())
+
+	rate, err := money.ParseExchangeRate(money.EUR, money.USD, "1.08")
+	assert.NoError(t, err)
+	charged, err := p.Amount.Convert(rate)
+	assert.NoError(t, err)
+
+	p.RecordCharge(charged, &rate)
+
+	assert.Equal(t, money.New(2159, money.USD), p.Charged())
+	assert.Equal(t, "1.0800000000", p.ExchangeRate.Decimal())
+}
+
 func TestPayment_Fail(t *testing.T) {
-	p, _ := NewPayment("order-123", "user-456", 100.50, "USD")
+	p, _ := NewPayment("order-123", "user-456", money.New(10050, money.USD))
 	failureReason := "Insufficient funds"
 
 	p.Fail(failureReason)
@@ -52,7 +68,7 @@ func TestPayment_Fail(t *testing.T) {
 }
 
 func TestPayment_IsStatus(t *testing.T) {
-	p, _ := NewPayment("order-123", "user-456", 100.50, "USD")
+	p, _ := NewPayment("order-123", "user-456", money.New(10050, money.USD))
 	assert.True(t, p.IsPending())
 	assert.False(t, p.IsCompleted())
 	assert.False(t, p.IsFailed())
@@ -62,17 +78,72 @@ func TestPayment_IsStatus(t *testing.T) {
 	assert.True(t, p.IsCompleted())
 	assert.False(t, p.IsFailed())
 
-	p, _ = NewPayment("order-123", "user-456", 100.50, "USD")
+	p, _ = NewPayment("order-123", "user-456", money.New(10050, money.USD))
 	p.Fail("failed")
 	assert.False(t, p.IsPending())
 	assert.False(t, p.IsCompleted())
 	assert.True(t, p.IsFailed())
 }
 
-func TestPayment_IsTimedOut(t *testing.T) {
-	p, _ := NewPayment("order-123", "user-456", 100.50, "USD")
-	assert.False(t, p.IsTimedOut())
+func TestPayment_RefundAndCancel(t *testing.T) {
+	p, _ := NewPayment("order-123", "user-456", money.New(10050, money.USD))
+	p.Complete("txn-1")
+	p.Refund()
+	assert.True(t, p.IsRefunded())
+	assert.False(t, p.IsCompleted())
+	assert.Equal(t, "txn-1", p.TransactionID)
+
+	p, _ = NewPayment("order-123", "user-456", money.New(10050, money.USD))
+	p.Cancel("order cancelled")
+	assert.True(t, p.IsCancelled())
+	assert.False(t, p.IsPending())
+	assert.Equal(t, "order cancelled", p.ErrorMessage)
+}
+
+func TestPayment_AwaitFunds(t *testing.T) {
+	p, _ := NewPayment("order-123", "user-456", money.New(10050, money.USD))
+	until := time.Now().Add(10 * time.Minute)
+
+	p.AwaitFunds("Insufficient funds", until)
+
+	assert.True(t, p.IsAwaitingFunds())
+	assert.False(t, p.IsPending())
+	assert.Equal(t, "Insufficient funds", p.ErrorMessage)
+	assert.False(t, p.IsFundsWaitExpired(time.Now()))
+	assert.True(t, p.IsFundsWaitExpired(until))
+
+	// Повторная постановка в очередь не продлевает срок
+	p.AwaitFunds("Still insufficient", until.Add(time.Hour))
+	assert.Equal(t, until, *p.FundsWaitUntil)
+
+	p.Authorize(p.Amount, nil, time.Now().Add(30*time.Minute))
+	assert.True(t, p.IsAuthorized())
+	assert.Empty(t, p.ErrorMessage)
+	assert.Nil(t, p.FundsWaitUntil)
+	assert.False(t, p.IsFundsWaitExpired(until))
+}
+
+func TestPayment_AuthorizeCaptureRelease(t *testing.T) {
+	p, _ := NewPayment("order-123", "user-456", money.New(10050, money.USD))
+	expiresAt := time.Now().Add(30 * time.Minute)
+
+	p.Authorize(p.Amount, nil, expiresAt)
+
+	assert.True(t, p.IsAuthorized())
+	assert.Equal(t, p.Amount, p.Charged())
+	assert.False(t, p.IsHoldExpired(time.Now()))
+	assert.True(t, p.IsHoldExpired(expiresAt))
+
+	p.Capture("txn-789")
+	assert.True(t, p.IsCompleted())
+	assert.Equal(t, "txn-789", p.TransactionID)
+	assert.Nil(t, p.HoldExpiresAt)
+
+	released, _ := NewPayment("order-124", "user-456", money.New(500, money.USD))
+	released.Authorize(released.Amount, nil, expiresAt)
+	released.Release("hold expired")
 
-	p.CreatedAt = time.Now().Add(-16 * time.Minute)
-	assert.True(t, p.IsTimedOut())
+	assert.Equal(t, PaymentStatusReleased, released.Status)
+	assert.Equal(t, "hold expired", released.ErrorMessage)
+	assert.False(t, released.IsHoldExpired(expiresAt))
 }
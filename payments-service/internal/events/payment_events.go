@@ -4,8 +4,8 @@ import (
 	"context"
 	"time"
 
+	"messaging/kafka"
 	"payments-service/config"
-	"payments-service/pkg/kafka"
 
 	"github.com/gofrs/uuid"
 )
@@ -8,9 +8,28 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// KafkaRoute связывает тип события с топиком. В event допускается шаблон вида "payment.*"
+type KafkaRoute struct {
+	Event string `yaml:"event"`
+	Topic string `yaml:"topic"`
+}
+
+// RetryBackoff — экспоненциальная задержка между повторами: initial_delay_ms * multiplier^(n-1),
+// не больше max_delay_ms, с разбросом ±jitter. Нулевые поля в retry.events берутся из retry.default;
+// jitter наследуется, только если не задан, поэтому jitter: 0 отключает разброс для события
+type RetryBackoff struct {
+	MaxAttempts    int      `yaml:"max_attempts"`
+	InitialDelayMs int      `yaml:"initial_delay_ms"`
+	MaxDelayMs     int      `yaml:"max_delay_ms"`
+	Multiplier     float64  `yaml:"multiplier"`
+	Jitter         *float64 `yaml:"jitter"`
+}
+
 type Config struct {
 	Server struct {
 		Port int `yaml:"port"`
+		// AdminPort — внутренний порт служебных эндпоинтов (dead letters), gateway его не проксирует
+		AdminPort int `yaml:"admin_port"`
 	} `yaml:"server"`
 	Db struct {
 		Host string `yaml:"host"`
@@ -20,16 +39,57 @@ type Config struct {
 		Name string `yaml:"name"`
 	} `yaml:"db"`
 	Kafka struct {
-		Publisher struct {
-			IntervalMs int `yaml:"interval_ms"`
-			BatchSize  int `yaml:"batch_size"`
-			MaxRetries int `yaml:"max_retries"`
+		// Mode — outbox или transactional
+		Mode            string `yaml:"mode"`
+		TransactionalID string `yaml:"transactional_id"`
+		Publisher       struct {
+			IntervalMs         int `yaml:"interval_ms"`
+			FallbackIntervalMs int `yaml:"fallback_interval_ms"`
+			BatchSize          int `yaml:"batch_size"`
+			LeaseMs            int `yaml:"lease_ms"`
 		} `yaml:"publisher"`
 		Consumer struct {
 			GroupID string `yaml:"group_id"`
+			// Retry — повторы обработки сообщения до фиксации offset, PauseMs — пауза партиции после них
+			Retry   RetryBackoff `yaml:"retry"`
+			PauseMs int          `yaml:"pause_ms"`
+			// Workers — воркеры на партицию, MaxInFlight — сколько сообщений партиции читается до фиксации offset
+			Workers     int `yaml:"workers"`
+			MaxInFlight int `yaml:"max_in_flight"`
 		} `yaml:"consumer"`
-		Brokers []string `yaml:"brokers"`
+		Brokers []string     `yaml:"brokers"`
+		Routes  []KafkaRoute `yaml:"routes"`
+		// Formats — формат событий по топикам: legacy, cloudevents-structured или cloudevents-binary
+		Formats map[string]string `yaml:"formats"`
 	} `yaml:"kafka"`
+	// Broker — транспорт событий outbox и inbox: kafka или rabbitmq. Шина memory работает только
+	// внутри одного процесса, поэтому сервис с ней не запускается.
+	// Маршруты, форматы и настройки publisher/consumer берутся из секции kafka
+	Broker struct {
+		Transport string `yaml:"transport"`
+		RabbitMQ  struct {
+			URL string `yaml:"url"`
+		} `yaml:"rabbitmq"`
+	} `yaml:"broker"`
+	FX struct {
+		RatesPath string `yaml:"rates_path"`
+	} `yaml:"fx"`
+	Holds struct {
+		ExpiryMinutes   int `yaml:"expiry_minutes"`
+		SweepIntervalMs int `yaml:"sweep_interval_ms"`
+		BatchSize       int `yaml:"batch_size"`
+	} `yaml:"holds"`
+	AwaitingFunds struct {
+		WaitMinutes int `yaml:"wait_minutes"`
+		BatchSize   int `yaml:"batch_size"`
+	} `yaml:"awaiting_funds"`
+	Idempotency struct {
+		TTLHours int `yaml:"ttl_hours"`
+	} `yaml:"idempotency"`
+	Retry struct {
+		Default RetryBackoff            `yaml:"default"`
+		Events  map[string]RetryBackoff `yaml:"events"`
+	} `yaml:"retry"`
 }
 
 type App struct {
@@ -58,6 +118,42 @@ func MustLoad(app *App) *Config {
 	return &config
 }
 
+// GetBrokerTransport — транспорт из broker.transport; переменная BROKER_TRANSPORT его переопределяет,
+// чтобы docker-compose.rabbitmq.yml переключал сервисы на RabbitMQ без правки config.yaml
+func (c *Config) GetBrokerTransport() string {
+	if transport := os.Getenv("BROKER_TRANSPORT"); transport != "" {
+		return transport
+	}
+	if c.Broker.Transport == "" {
+		return "kafka"
+	}
+	return c.Broker.Transport
+}
+
+func (c *Config) GetKafkaMode() string {
+	if c.Kafka.Mode == "" {
+		return "outbox"
+	}
+	return c.Kafka.Mode
+}
+
+// GetTransactionalID — префикс transactional.id, уникальный для реплики: к kafka.transactional_id
+// (по умолчанию имя сервиса) добавляется имя хоста. Имя должно переживать перезапуск реплики,
+// иначе брокер не отсечёт её зависший экземпляр: в Kubernetes сервис разворачивается StatefulSet'ом
+// (имя пода с порядковым номером), в docker-compose имя хоста задано явно
+func (c *Config) GetTransactionalID() string {
+	prefix := c.Kafka.TransactionalID
+	if prefix == "" {
+		prefix = "payments-service"
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return prefix
+	}
+	return prefix + "-" + hostname
+}
+
 func (c *Config) GetPublisherInterval() time.Duration {
 	if c.Kafka.Publisher.IntervalMs <= 0 {
 		return time.Second
@@ -65,6 +161,14 @@ func (c *Config) GetPublisherInterval() time.Duration {
 	return time.Duration(c.Kafka.Publisher.IntervalMs) * time.Millisecond
 }
 
+// GetPublisherFallbackInterval — период страховочного опроса outbox на случай пропущенных NOTIFY
+func (c *Config) GetPublisherFallbackInterval() time.Duration {
+	if c.Kafka.Publisher.FallbackIntervalMs <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.Kafka.Publisher.FallbackIntervalMs) * time.Millisecond
+}
+
 func (c *Config) GetPublisherBatchSize() int {
 	if c.Kafka.Publisher.BatchSize <= 0 {
 		return 50
@@ -72,9 +176,155 @@ func (c *Config) GetPublisherBatchSize() int {
 	return c.Kafka.Publisher.BatchSize
 }
 
-func (c *Config) GetPublisherMaxRetries() int {
-	if c.Kafka.Publisher.MaxRetries <= 0 {
-		return 3
+// GetPublisherLease — на сколько реплика захватывает сообщения outbox/inbox.
+// Должно быть больше времени обработки одной пачки, иначе пачку подхватит другая реплика
+func (c *Config) GetPublisherLease() time.Duration {
+	if c.Kafka.Publisher.LeaseMs <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.Kafka.Publisher.LeaseMs) * time.Millisecond
+}
+
+func (c *Config) GetKafkaRoutes() []KafkaRoute {
+	if len(c.Kafka.Routes) == 0 {
+		return []KafkaRoute{
+			{Event: "payment.*", Topic: "payments-events"},
+			{Event: "account.*", Topic: "payments-events"},
+		}
+	}
+	return c.Kafka.Routes
+}
+
+// GetKafkaFormats — форматы публикуемых событий по топикам; топики без записи остаются в legacy
+func (c *Config) GetKafkaFormats() map[string]string {
+	return c.Kafka.Formats
+}
+
+func (c *Config) GetFXRatesPath() string {
+	if c.FX.RatesPath == "" {
+		return "config/fx_rates.yaml"
+	}
+	return c.FX.RatesPath
+}
+
+func (c *Config) GetHoldExpiry() time.Duration {
+	if c.Holds.ExpiryMinutes <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(c.Holds.ExpiryMinutes) * time.Minute
+}
+
+func (c *Config) GetHoldSweepInterval() time.Duration {
+	if c.Holds.SweepIntervalMs <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.Holds.SweepIntervalMs) * time.Millisecond
+}
+
+func (c *Config) GetHoldBatchSize() int {
+	if c.Holds.BatchSize <= 0 {
+		return 50
+	}
+	return c.Holds.BatchSize
+}
+
+func (c *Config) GetFundsWait() time.Duration {
+	if c.AwaitingFunds.WaitMinutes <= 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(c.AwaitingFunds.WaitMinutes) * time.Minute
+}
+
+func (c *Config) GetFundsWaitBatchSize() int {
+	if c.AwaitingFunds.BatchSize <= 0 {
+		return 50
+	}
+	return c.AwaitingFunds.BatchSize
+}
+
+// GetConsumerGroupID — группа консьюмеров Kafka, общая для всех реплик сервиса
+func (c *Config) GetConsumerGroupID() string {
+	if c.Kafka.Consumer.GroupID == "" {
+		return "payments-service-group"
+	}
+	return c.Kafka.Consumer.GroupID
+}
+
+// GetConsumerRetry — повторы обработки сообщения в консьюмере Kafka до фиксации offset
+func (c *Config) GetConsumerRetry() RetryBackoff {
+	return mergeRetryBackoff(c.Kafka.Consumer.Retry, RetryBackoff{
+		MaxAttempts:    3,
+		InitialDelayMs: 200,
+		MaxDelayMs:     5000,
+		Multiplier:     2,
+	})
+}
+
+// GetConsumerPause — на сколько приостановить партицию, когда повторы в консьюмере исчерпаны
+func (c *Config) GetConsumerPause() time.Duration {
+	if c.Kafka.Consumer.PauseMs <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.Kafka.Consumer.PauseMs) * time.Millisecond
+}
+
+// GetConsumerWorkers — сколько ключей одной партиции обрабатывается параллельно
+func (c *Config) GetConsumerWorkers() int {
+	if c.Kafka.Consumer.Workers <= 0 {
+		return 8
+	}
+	return c.Kafka.Consumer.Workers
+}
+
+// GetConsumerMaxInFlight — сколько сообщений партиции можно прочитать, пока не зафиксирован offset первого из них
+func (c *Config) GetConsumerMaxInFlight() int {
+	if c.Kafka.Consumer.MaxInFlight <= 0 {
+		return 256
+	}
+	return c.Kafka.Consumer.MaxInFlight
+}
+
+func (c *Config) GetRetryDefault() RetryBackoff {
+	return mergeRetryBackoff(c.Retry.Default, RetryBackoff{
+		MaxAttempts:    5,
+		InitialDelayMs: 1000,
+		MaxDelayMs:     300000,
+		Multiplier:     2,
+	})
+}
+
+// GetRetryEvents возвращает переопределения по типам событий, дополненные значениями по умолчанию
+func (c *Config) GetRetryEvents() map[string]RetryBackoff {
+	defaults := c.GetRetryDefault()
+	events := make(map[string]RetryBackoff, len(c.Retry.Events))
+	for eventType, backoff := range c.Retry.Events {
+		events[eventType] = mergeRetryBackoff(backoff, defaults)
+	}
+	return events
+}
+
+func mergeRetryBackoff(backoff, defaults RetryBackoff) RetryBackoff {
+	if backoff.MaxAttempts <= 0 {
+		backoff.MaxAttempts = defaults.MaxAttempts
+	}
+	if backoff.InitialDelayMs <= 0 {
+		backoff.InitialDelayMs = defaults.InitialDelayMs
+	}
+	if backoff.MaxDelayMs <= 0 {
+		backoff.MaxDelayMs = defaults.MaxDelayMs
+	}
+	if backoff.Multiplier < 1 {
+		backoff.Multiplier = defaults.Multiplier
+	}
+	if backoff.Jitter == nil {
+		backoff.Jitter = defaults.Jitter
+	}
+	return backoff
+}
+
+func (c *Config) GetIdempotencyTTL() time.Duration {
+	if c.Idempotency.TTLHours <= 0 {
+		return 24 * time.Hour
 	}
-	return c.Kafka.Publisher.MaxRetries
+	return time.Duration(c.Idempotency.TTLHours) * time.Hour
 }
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGetRetryEvents_JitterOverride(t *testing.T) {
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+retry:
+  default:
+    jitter: 0.2
+  events:
+    payment.completed:
+      jitter: 0
+    payment.failed:
+      max_attempts: 2
+`), &cfg))
+
+	events := cfg.GetRetryEvents()
+
+	// Явный ноль отключает разброс, незаданный jitter берётся из retry.default
+	require.NotNil(t, events["payment.completed"].Jitter)
+	assert.Equal(t, 0.0, *events["payment.completed"].Jitter)
+	require.NotNil(t, events["payment.failed"].Jitter)
+	assert.Equal(t, 0.2, *events["payment.failed"].Jitter)
+	assert.Equal(t, 2, events["payment.failed"].MaxAttempts)
+}
+
+func TestGetBrokerTransport_EnvOverride(t *testing.T) {
+	var cfg Config
+	cfg.Broker.Transport = "kafka"
+	assert.Equal(t, "kafka", cfg.GetBrokerTransport())
+
+	t.Setenv("BROKER_TRANSPORT", "rabbitmq")
+	assert.Equal(t, "rabbitmq", cfg.GetBrokerTransport())
+}
@@ -0,0 +1,79 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"contracts/money"
+	"payments-service/internal/interfaces/fx"
+)
+
+// StaticRateProvider считает курсы по таблице котировок к базовой валюте:
+// rates[X] — сколько единиц X стоит одна единица base. Кросс-курс from → to
+// равен rates[to] / rates[from].
+type StaticRateProvider struct {
+	base  string
+	rates map[string]*big.Rat
+}
+
+type ratesFile struct {
+	Base  string            `yaml:"base"`
+	Rates map[string]string `yaml:"rates"`
+}
+
+func NewStaticRateProvider(base string, rates map[string]string) (*StaticRateProvider, error) {
+	base = strings.ToUpper(base)
+	if base == "" {
+		return nil, fmt.Errorf("fx base currency is required")
+	}
+
+	provider := &StaticRateProvider{
+		base:  base,
+		rates: map[string]*big.Rat{base: big.NewRat(1, 1)},
+	}
+
+	for currency, value := range rates {
+		rate, err := money.ParseExchangeRate(base, currency, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fx rate for %s: %w", currency, err)
+		}
+		provider.rates[rate.To()] = rate.Rat()
+	}
+
+	return provider, nil
+}
+
+// LoadStaticRateProvider читает таблицу курсов из yaml файла.
+func LoadStaticRateProvider(path string) (*StaticRateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fx rates file %s: %w", path, err)
+	}
+
+	var file ratesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode fx rates file %s: %w", path, err)
+	}
+
+	return NewStaticRateProvider(file.Base, file.Rates)
+}
+
+func (p *StaticRateProvider) Rate(_ context.Context, from, to string) (money.ExchangeRate, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+
+	fromRate, ok := p.rates[from]
+	if !ok {
+		return money.ExchangeRate{}, fmt.Errorf("%w: %s/%s", fx.ErrRateNotFound, from, to)
+	}
+	toRate, ok := p.rates[to]
+	if !ok {
+		return money.ExchangeRate{}, fmt.Errorf("%w: %s/%s", fx.ErrRateNotFound, from, to)
+	}
+
+	return money.NewExchangeRate(from, to, new(big.Rat).Quo(toRate, fromRate))
+}
@@ -0,0 +1,51 @@
+package fx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"contracts/money"
+	"payments-service/internal/interfaces/fx"
+)
+
+func TestStaticRateProvider_Rate(t *testing.T) {
+	provider, err := NewStaticRateProvider(money.USD, map[string]string{
+		money.EUR: "0.92",
+		money.RUB: "92",
+	})
+	assert.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), money.USD, money.EUR)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.9200000000", rate.Decimal())
+
+	rate, err = provider.Rate(context.Background(), money.EUR, money.RUB)
+	assert.NoError(t, err)
+	assert.Equal(t, "100.0000000000", rate.Decimal())
+
+	converted, err := money.New(1000, money.EUR).Convert(rate)
+	assert.NoError(t, err)
+	assert.Equal(t, money.New(100000, money.RUB), converted)
+
+	_, err = provider.Rate(context.Background(), money.USD, money.GBP)
+	assert.ErrorIs(t, err, fx.ErrRateNotFound)
+}
+
+func TestLoadStaticRateProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fx_rates.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("base: USD\nrates:\n  GBP: \"0.8\"\n"), 0o600))
+
+	provider, err := LoadStaticRateProvider(path)
+	assert.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), money.GBP, money.USD)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2500000000", rate.Decimal())
+
+	_, err = NewStaticRateProvider(money.USD, map[string]string{money.EUR: "-1"})
+	assert.ErrorIs(t, err, money.ErrInvalidRate)
+}
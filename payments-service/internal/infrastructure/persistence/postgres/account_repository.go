@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"contracts/money"
 	"payments-service/internal/domain/account"
 	"payments-service/internal/interfaces/repository"
 )
@@ -19,11 +20,11 @@ func NewAccountRepository(db *sql.DB) repository.AccountRepository {
 
 func (r *AccountRepository) Store(ctx context.Context, acc *account.Account) error {
 	query := `
-		INSERT INTO accounts (id, user_id, balance, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)`
+		INSERT INTO accounts (id, user_id, balance, held, currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
 	_, err := r.db.ExecContext(ctx, query,
-		acc.ID, acc.UserID, acc.Balance, acc.CreatedAt, acc.UpdatedAt)
+		acc.ID, acc.UserID, acc.Balance.Decimal(), acc.Held.Decimal(), acc.Currency(), acc.CreatedAt, acc.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to store account: %w", err)
 	}
@@ -33,11 +34,11 @@ func (r *AccountRepository) Store(ctx context.Context, acc *account.Account) err
 
 func (r *AccountRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, acc *account.Account) error {
 	query := `
-		INSERT INTO accounts (id, user_id, balance, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)`
+		INSERT INTO accounts (id, user_id, balance, held, currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
 	_, err := tx.ExecContext(ctx, query,
-		acc.ID, acc.UserID, acc.Balance, acc.CreatedAt, acc.UpdatedAt)
+		acc.ID, acc.UserID, acc.Balance.Decimal(), acc.Held.Decimal(), acc.Currency(), acc.CreatedAt, acc.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to store account with tx: %w", err)
 	}
@@ -45,77 +46,83 @@ func (r *AccountRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, acc *ac
 	return nil
 }
 
-func (r *AccountRepository) GetByUserID(ctx context.Context, userID string) (*account.Account, error) {
+func (r *AccountRepository) GetByUserIDAndCurrency(ctx context.Context, userID, currency string) (*account.Account, error) {
 	query := `
-		SELECT id, user_id, balance, created_at, updated_at
+		SELECT id, user_id, balance, held, currency, created_at, updated_at
 		FROM accounts
-		WHERE user_id = $1`
+		WHERE user_id = $1 AND currency = $2`
 
-	row := r.db.QueryRowContext(ctx, query, userID)
-
-	acc := &account.Account{}
-	err := row.Scan(&acc.ID, &acc.UserID, &acc.Balance, &acc.CreatedAt, &acc.UpdatedAt)
+	acc, err := scanAccount(r.db.QueryRowContext(ctx, query, userID, currency))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("account not found for user %s", userID)
+			return nil, fmt.Errorf("%w for user %s in %s", account.ErrAccountNotFound, userID, currency)
 		}
-		return nil, fmt.Errorf("failed to get account by user ID: %w", err)
+		return nil, fmt.Errorf("failed to get account by user ID and currency: %w", err)
 	}
 
 	return acc, nil
 }
 
-func (r *AccountRepository) GetByUserIDWithTx(ctx context.Context, tx *sql.Tx, userID string) (*account.Account, error) {
+func (r *AccountRepository) GetByUserIDAndCurrencyWithTx(ctx context.Context, tx *sql.Tx, userID, currency string) (*account.Account, error) {
 	query := `
-		SELECT id, user_id, balance, created_at, updated_at
+		SELECT id, user_id, balance, held, currency, created_at, updated_at
 		FROM accounts
-		WHERE user_id = $1
+		WHERE user_id = $1 AND currency = $2
 		FOR UPDATE`
 
-	row := tx.QueryRowContext(ctx, query, userID)
-
-	acc := &account.Account{}
-	err := row.Scan(&acc.ID, &acc.UserID, &acc.Balance, &acc.CreatedAt, &acc.UpdatedAt)
+	acc, err := scanAccount(tx.QueryRowContext(ctx, query, userID, currency))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("account not found for user %s", userID)
+			return nil, fmt.Errorf("%w for user %s in %s", account.ErrAccountNotFound, userID, currency)
 		}
-		return nil, fmt.Errorf("failed to get account by user ID with tx: %w", err)
+		return nil, fmt.Errorf("failed to get account by user ID and currency with tx: %w", err)
 	}
 
 	return acc, nil
 }
 
-func (r *AccountRepository) Update(ctx context.Context, acc *account.Account) error {
+// ListByUserID возвращает все кошельки пользователя, первым идёт самый старый.
+func (r *AccountRepository) ListByUserID(ctx context.Context, userID string) ([]*account.Account, error) {
 	query := `
-		UPDATE accounts
-		SET balance = $2, updated_at = $3
-		WHERE id = $1`
+		SELECT id, user_id, balance, held, currency, created_at, updated_at
+		FROM accounts
+		WHERE user_id = $1
+		ORDER BY created_at ASC, currency ASC`
 
-	result, err := r.db.ExecContext(ctx, query, acc.ID, acc.Balance, acc.UpdatedAt)
+	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
-		return fmt.Errorf("failed to update account: %w", err)
+		return nil, fmt.Errorf("failed to list accounts by user ID: %w", err)
 	}
+	defer rows.Close()
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
+	return scanAccounts(rows)
+}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("account not found: %s", acc.ID)
+// ListByUserIDWithTx блокирует все кошельки пользователя до конца транзакции.
+func (r *AccountRepository) ListByUserIDWithTx(ctx context.Context, tx *sql.Tx, userID string) ([]*account.Account, error) {
+	query := `
+		SELECT id, user_id, balance, held, currency, created_at, updated_at
+		FROM accounts
+		WHERE user_id = $1
+		ORDER BY created_at ASC, currency ASC
+		FOR UPDATE`
+
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts by user ID with tx: %w", err)
 	}
+	defer rows.Close()
 
-	return nil
+	return scanAccounts(rows)
 }
 
 func (r *AccountRepository) UpdateWithTx(ctx context.Context, tx *sql.Tx, acc *account.Account) error {
 	query := `
 		UPDATE accounts
-		SET balance = $2, updated_at = $3
+		SET balance = $2, held = $3, updated_at = $4
 		WHERE id = $1`
 
-	result, err := tx.ExecContext(ctx, query, acc.ID, acc.Balance, acc.UpdatedAt)
+	result, err := tx.ExecContext(ctx, query, acc.ID, acc.Balance.Decimal(), acc.Held.Decimal(), acc.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to update account with tx: %w", err)
 	}
@@ -131,3 +138,45 @@ func (r *AccountRepository) UpdateWithTx(ctx context.Context, tx *sql.Tx, acc *a
 
 	return nil
 }
+
+type accountScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAccount(row accountScanner) (*account.Account, error) {
+	acc := &account.Account{}
+	var balance, held, currency string
+	if err := row.Scan(&acc.ID, &acc.UserID, &balance, &held, &currency, &acc.CreatedAt, &acc.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	acc.Balance, err = money.Parse(balance, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse account balance: %w", err)
+	}
+
+	acc.Held, err = money.Parse(held, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse account held amount: %w", err)
+	}
+
+	return acc, nil
+}
+
+func scanAccounts(rows *sql.Rows) ([]*account.Account, error) {
+	var accounts []*account.Account
+	for rows.Next() {
+		acc, err := scanAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account: %w", err)
+		}
+		accounts = append(accounts, acc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate accounts: %w", err)
+	}
+
+	return accounts, nil
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"contracts/money"
+	"payments-service/internal/domain/ledger"
+	"payments-service/internal/interfaces/repository"
+)
+
+type LedgerRepository struct {
+	db *sql.DB
+}
+
+func NewLedgerRepository(db *sql.DB) repository.LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+func (r *LedgerRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, txn *ledger.Transaction) error {
+	if err := txn.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO ledger_transactions (id, kind, reference, description, created_at)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := tx.ExecContext(ctx, query, txn.ID, txn.Kind, txn.Reference, txn.Description, txn.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to store ledger transaction: %w", err)
+	}
+
+	entryQuery := `
+		INSERT INTO ledger_entries (id, transaction_id, ledger_account, user_id, direction, amount, currency, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	for _, entry := range txn.Entries {
+		userID := sql.NullString{String: entry.UserID, Valid: entry.UserID != ""}
+		_, err := tx.ExecContext(ctx, entryQuery,
+			entry.ID, entry.TransactionID, entry.LedgerAccount, userID, entry.Direction,
+			entry.Amount.Decimal(), entry.Amount.Currency(), entry.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to store ledger entry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *LedgerRepository) BalanceWithTx(ctx context.Context, tx *sql.Tx, ledgerAccount, currency string) (money.Money, error) {
+	query := `
+		SELECT COALESCE(SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END), 0)
+		FROM ledger_entries
+		WHERE ledger_account = $1 AND currency = $2`
+
+	var balance string
+	if err := tx.QueryRowContext(ctx, query, ledgerAccount, currency).Scan(&balance); err != nil {
+		return money.Money{}, fmt.Errorf("failed to get ledger balance: %w", err)
+	}
+
+	result, err := money.Parse(balance, currency)
+	if err != nil {
+		return money.Money{}, fmt.Errorf("failed to parse ledger balance: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *LedgerRepository) ListEntriesByUserID(ctx context.Context, userID string, after *ledger.Cursor, limit int) ([]*ledger.Entry, error) {
+	query := `
+		SELECT e.id, e.transaction_id, e.ledger_account, e.user_id, e.direction, e.amount, e.currency,
+		       t.kind, t.reference, t.description, e.created_at
+		FROM ledger_entries e
+		JOIN ledger_transactions t ON t.id = e.transaction_id
+		WHERE e.user_id = $1
+		  AND ($2::timestamptz IS NULL OR (e.created_at, e.id) < ($2::timestamptz, $3))
+		ORDER BY e.created_at DESC, e.id DESC
+		LIMIT $4`
+
+	var afterCreatedAt sql.NullTime
+	var afterID string
+	if after != nil {
+		afterCreatedAt = sql.NullTime{Time: after.CreatedAt, Valid: true}
+		afterID = after.ID
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, userID, afterCreatedAt, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*ledger.Entry
+	for rows.Next() {
+		entry := &ledger.Entry{}
+		var entryUserID sql.NullString
+		var amount, currency string
+		err := rows.Scan(&entry.ID, &entry.TransactionID, &entry.LedgerAccount, &entryUserID, &entry.Direction,
+			&amount, &currency, &entry.Kind, &entry.Reference, &entry.Description, &entry.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ledger entry: %w", err)
+		}
+
+		entry.UserID = entryUserID.String
+		entry.Amount, err = money.Parse(amount, currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ledger entry amount: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ledger entries: %w", err)
+	}
+
+	return entries, nil
+}
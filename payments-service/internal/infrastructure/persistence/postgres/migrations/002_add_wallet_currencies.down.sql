@@ -0,0 +1,16 @@
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS fx_rate,
+    DROP COLUMN IF EXISTS charged_currency,
+    DROP COLUMN IF EXISTS charged_amount;
+
+DELETE FROM accounts
+WHERE currency <> 'USD';
+
+ALTER TABLE accounts
+    DROP CONSTRAINT IF EXISTS accounts_user_id_currency_key;
+
+ALTER TABLE accounts
+    ADD CONSTRAINT accounts_user_id_key UNIQUE (user_id);
+
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS currency;
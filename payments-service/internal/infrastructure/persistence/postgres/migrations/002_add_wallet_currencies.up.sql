@@ -0,0 +1,15 @@
+-- Один кошелёк на пару (user_id, currency)
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE accounts
+    DROP CONSTRAINT IF EXISTS accounts_user_id_key;
+
+ALTER TABLE accounts
+    ADD CONSTRAINT accounts_user_id_currency_key UNIQUE (user_id, currency);
+
+-- Сумма, фактически списанная с кошелька, и курс, по которому она пересчитана
+ALTER TABLE payments
+    ADD COLUMN IF NOT EXISTS charged_amount DECIMAL(15,2),
+    ADD COLUMN IF NOT EXISTS charged_currency VARCHAR(3),
+    ADD COLUMN IF NOT EXISTS fx_rate DECIMAL(20,10);
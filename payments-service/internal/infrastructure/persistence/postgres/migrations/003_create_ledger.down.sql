@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS ledger_transaction_balanced ON ledger_entries;
+DROP FUNCTION IF EXISTS check_ledger_transaction_balanced();
+
+DROP INDEX IF EXISTS idx_ledger_entries_user_id_created_at;
+DROP INDEX IF EXISTS idx_ledger_entries_ledger_account;
+DROP INDEX IF EXISTS idx_ledger_entries_transaction_id;
+
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_transactions;
@@ -0,0 +1,66 @@
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+    id VARCHAR(36) PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('topup', 'payment', 'refund', 'adjustment')),
+    reference VARCHAR(64) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- ledger_account — id кошелька из accounts или системный счёт (system:funding, system:settlement, ...)
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id VARCHAR(36) PRIMARY KEY,
+    transaction_id VARCHAR(36) NOT NULL REFERENCES ledger_transactions (id),
+    ledger_account VARCHAR(64) NOT NULL,
+    user_id VARCHAR(36),
+    direction VARCHAR(6) NOT NULL CHECK (direction IN ('debit', 'credit')),
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_transaction_id ON ledger_entries (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_ledger_account ON ledger_entries (ledger_account, currency);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_user_id_created_at ON ledger_entries (user_id, created_at DESC, id DESC);
+
+-- Проверка двойной записи на стороне базы: к коммиту дебеты и кредиты транзакции
+-- должны совпадать в каждой валюте
+CREATE OR REPLACE FUNCTION check_ledger_transaction_balanced()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM ledger_entries
+        WHERE transaction_id = NEW.transaction_id
+        GROUP BY currency
+        HAVING SUM(CASE WHEN direction = 'debit' THEN amount ELSE -amount END) <> 0
+    ) THEN
+        RAISE EXCEPTION 'ledger transaction % is not balanced', NEW.transaction_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_transaction_balanced
+    AFTER INSERT OR UPDATE ON ledger_entries
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW
+    EXECUTE FUNCTION check_ledger_transaction_balanced();
+
+-- Входящие остатки: уже существующие балансы переносятся в журнал корректировкой
+WITH opening AS (
+    SELECT id AS account_id, user_id, balance, currency, gen_random_uuid()::text AS transaction_id
+    FROM accounts
+    WHERE balance <> 0
+), transactions AS (
+    INSERT INTO ledger_transactions (id, kind, reference, description)
+    SELECT transaction_id, 'adjustment', account_id, 'opening balance'
+    FROM opening
+)
+INSERT INTO ledger_entries (id, transaction_id, ledger_account, user_id, direction, amount, currency)
+SELECT gen_random_uuid()::text, transaction_id, 'system:adjustments', NULL,
+       CASE WHEN balance > 0 THEN 'debit' ELSE 'credit' END, ABS(balance), currency
+FROM opening
+UNION ALL
+SELECT gen_random_uuid()::text, transaction_id, account_id, user_id,
+       CASE WHEN balance > 0 THEN 'credit' ELSE 'debit' END, ABS(balance), currency
+FROM opening;
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_payments_hold_expires_at;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS hold_expires_at;
+
+ALTER TABLE accounts
+    DROP CONSTRAINT IF EXISTS accounts_held_within_balance;
+
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS held;
@@ -0,0 +1,13 @@
+-- Сумма, заблокированная под авторизованные платежи. Доступный остаток = balance - held
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS held DECIMAL(15,2) NOT NULL DEFAULT 0.00;
+
+ALTER TABLE accounts
+    ADD CONSTRAINT accounts_held_within_balance CHECK (held >= 0 AND held <= balance);
+
+-- Срок, после которого неподтверждённая блокировка снимается автоматически
+ALTER TABLE payments
+    ADD COLUMN IF NOT EXISTS hold_expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_payments_hold_expires_at ON payments(hold_expires_at)
+    WHERE status = 'authorized';
@@ -0,0 +1,15 @@
+-- Responses of requests sent with an Idempotency-Key header
+CREATE TABLE idempotency_keys
+(
+    scope           VARCHAR(255) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash    VARCHAR(64)  NOT NULL,
+    status_code     INTEGER,
+    content_type    VARCHAR(255),
+    response_body   BYTEA,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at    TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (scope, idempotency_key)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys (created_at);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_payments_funds_wait_until;
+
+DROP INDEX IF EXISTS idx_payments_awaiting_funds_user_id;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS funds_wait_until;
@@ -0,0 +1,9 @@
+-- Платёж, которому не хватило средств, ждёт пополнения кошелька до этого момента
+ALTER TABLE payments
+    ADD COLUMN IF NOT EXISTS funds_wait_until TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_payments_awaiting_funds_user_id ON payments(user_id, created_at)
+    WHERE status = 'awaiting_funds';
+
+CREATE INDEX IF NOT EXISTS idx_payments_funds_wait_until ON payments(funds_wait_until)
+    WHERE status = 'awaiting_funds';
@@ -0,0 +1,3 @@
+UPDATE outbox_messages SET status = 'failed' WHERE status = 'dead_letter';
+
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS last_error;
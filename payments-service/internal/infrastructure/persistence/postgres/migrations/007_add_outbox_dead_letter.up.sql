@@ -0,0 +1,2 @@
+-- Reason why an outbox message was parked as dead_letter (e.g. no topic route)
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS last_error TEXT;
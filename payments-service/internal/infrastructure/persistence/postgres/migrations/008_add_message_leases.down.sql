@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_inbox_messages_locked_until;
+DROP INDEX IF EXISTS idx_outbox_messages_locked_until;
+
+UPDATE inbox_messages SET status = 'pending' WHERE status = 'processing';
+UPDATE outbox_messages SET status = 'pending' WHERE status = 'processing';
+
+ALTER TABLE inbox_messages DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS locked_until;
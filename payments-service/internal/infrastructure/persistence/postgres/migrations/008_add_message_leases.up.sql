@@ -0,0 +1,6 @@
+-- Replicas claim outbox/inbox rows by switching them to 'processing' until locked_until
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE inbox_messages ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_locked_until ON outbox_messages(locked_until) WHERE status = 'processing';
+CREATE INDEX IF NOT EXISTS idx_inbox_messages_locked_until ON inbox_messages(locked_until) WHERE status = 'processing';
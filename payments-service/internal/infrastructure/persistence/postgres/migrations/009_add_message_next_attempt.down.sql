@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_inbox_next_attempt_at;
+DROP INDEX IF EXISTS idx_outbox_next_attempt_at;
+
+ALTER TABLE inbox_messages DROP COLUMN IF EXISTS next_attempt_at;
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS next_attempt_at;
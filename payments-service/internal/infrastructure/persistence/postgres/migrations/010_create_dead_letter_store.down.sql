@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_inbox_messages_dead_lettered_at;
+DROP INDEX IF EXISTS idx_outbox_messages_dead_lettered_at;
+
+UPDATE inbox_messages SET status = 'failed' WHERE status = 'dead_letter';
+ALTER TABLE inbox_messages DROP COLUMN IF EXISTS dead_lettered_at;
+ALTER TABLE inbox_messages DROP COLUMN IF EXISTS attempts;
+ALTER TABLE inbox_messages DROP COLUMN IF EXISTS last_error;
+
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS dead_lettered_at;
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS attempts;
@@ -0,0 +1,16 @@
+-- Dead letters keep the last error, every failed attempt and the moment they were parked
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS attempts JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS dead_lettered_at TIMESTAMP WITH TIME ZONE;
+UPDATE outbox_messages SET dead_lettered_at = updated_at WHERE status = 'dead_letter';
+
+ALTER TABLE inbox_messages ADD COLUMN IF NOT EXISTS last_error TEXT;
+ALTER TABLE inbox_messages ADD COLUMN IF NOT EXISTS attempts JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE inbox_messages ADD COLUMN IF NOT EXISTS dead_lettered_at TIMESTAMP WITH TIME ZONE;
+
+-- Inbox messages that already ran out of attempts become dead letters
+UPDATE inbox_messages
+SET status = 'dead_letter', dead_lettered_at = updated_at
+WHERE status = 'failed' AND next_attempt_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_dead_lettered_at ON outbox_messages(dead_lettered_at) WHERE status = 'dead_letter';
+CREATE INDEX IF NOT EXISTS idx_inbox_messages_dead_lettered_at ON inbox_messages(dead_lettered_at) WHERE status = 'dead_letter';
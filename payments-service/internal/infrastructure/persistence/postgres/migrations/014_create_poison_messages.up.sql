@@ -0,0 +1,17 @@
+-- Kafka messages the consumer could not decode, kept byte for byte with their position in the topic
+CREATE TABLE poison_messages
+(
+    id              UUID PRIMARY KEY,
+    topic           VARCHAR(255) NOT NULL,
+    kafka_partition INTEGER      NOT NULL,
+    kafka_offset    BIGINT       NOT NULL,
+    message_key     BYTEA,
+    message_value   BYTEA,
+    headers         JSONB        NOT NULL DEFAULT '{}',
+    error           TEXT         NOT NULL,
+    published_at    TIMESTAMP WITH TIME ZONE,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (topic, kafka_partition, kafka_offset)
+);
+
+CREATE INDEX idx_poison_messages_created_at ON poison_messages (created_at);
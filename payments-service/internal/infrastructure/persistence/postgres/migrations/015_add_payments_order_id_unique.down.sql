@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_payments_order_id_unique;
+
+CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments(order_id);
@@ -0,0 +1,4 @@
+-- На заказ приходится ровно один платёж: повторное или параллельное событие order.created не создаст второй
+DROP INDEX IF EXISTS idx_payments_order_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_order_id_unique ON payments(order_id);
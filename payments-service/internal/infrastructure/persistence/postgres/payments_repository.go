@@ -3,10 +3,15 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
+	"contracts/money"
 	"payments-service/internal/domain/payments"
 	"payments-service/internal/interfaces/repository"
+
+	"github.com/lib/pq"
 )
 
 type PaymentsRepository struct {
@@ -19,12 +24,16 @@ func NewPaymentsRepository(db *sql.DB) repository.PaymentsRepository {
 
 func (r *PaymentsRepository) Store(ctx context.Context, payment *payments.Payment) error {
 	query := `
-		INSERT INTO payments (id, order_id, user_id, amount, currency, status, error_message, transaction_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+		INSERT INTO payments (id, order_id, user_id, amount, currency, charged_amount, charged_currency, fx_rate,
+		                      status, error_message, transaction_id, hold_expires_at, funds_wait_until, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
 
+	chargedAmount, chargedCurrency, fxRate := chargeColumns(payment)
 	_, err := r.db.ExecContext(ctx, query,
-		payment.ID, payment.OrderID, payment.UserID, payment.Amount, payment.Currency,
-		payment.Status, payment.ErrorMessage, payment.TransactionID, payment.CreatedAt, payment.UpdatedAt)
+		payment.ID, payment.OrderID, payment.UserID, payment.Amount.Decimal(), payment.Amount.Currency(),
+		chargedAmount, chargedCurrency, fxRate,
+		payment.Status, payment.ErrorMessage, payment.TransactionID, payment.HoldExpiresAt, payment.FundsWaitUntil,
+		payment.CreatedAt, payment.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to store payment: %w", err)
 	}
@@ -34,12 +43,19 @@ func (r *PaymentsRepository) Store(ctx context.Context, payment *payments.Paymen
 
 func (r *PaymentsRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, payment *payments.Payment) error {
 	query := `
-		INSERT INTO payments (id, order_id, user_id, amount, currency, status, error_message, transaction_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+		INSERT INTO payments (id, order_id, user_id, amount, currency, charged_amount, charged_currency, fx_rate,
+		                      status, error_message, transaction_id, hold_expires_at, funds_wait_until, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
 
+	chargedAmount, chargedCurrency, fxRate := chargeColumns(payment)
 	_, err := tx.ExecContext(ctx, query,
-		payment.ID, payment.OrderID, payment.UserID, payment.Amount, payment.Currency,
-		payment.Status, payment.ErrorMessage, payment.TransactionID, payment.CreatedAt, payment.UpdatedAt)
+		payment.ID, payment.OrderID, payment.UserID, payment.Amount.Decimal(), payment.Amount.Currency(),
+		chargedAmount, chargedCurrency, fxRate,
+		payment.Status, payment.ErrorMessage, payment.TransactionID, payment.HoldExpiresAt, payment.FundsWaitUntil,
+		payment.CreatedAt, payment.UpdatedAt)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: %s", payments.ErrPaymentAlreadyExists, payment.OrderID)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to store payment with tx: %w", err)
 	}
@@ -49,18 +65,14 @@ func (r *PaymentsRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, paymen
 
 func (r *PaymentsRepository) GetByID(ctx context.Context, id string) (*payments.Payment, error) {
 	query := `
-		SELECT id, order_id, user_id, amount, currency, status, error_message, transaction_id, created_at, updated_at
+		SELECT ` + paymentColumns + `
 		FROM payments
 		WHERE id = $1`
 
-	row := r.db.QueryRowContext(ctx, query, id)
-
-	payment := &payments.Payment{}
-	err := row.Scan(&payment.ID, &payment.OrderID, &payment.UserID, &payment.Amount, &payment.Currency,
-		&payment.Status, &payment.ErrorMessage, &payment.TransactionID, &payment.CreatedAt, &payment.UpdatedAt)
+	payment, err := scanPayment(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("payment not found: %s", id)
+			return nil, fmt.Errorf("%w: %s", payments.ErrPaymentNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to get payment by ID: %w", err)
 	}
@@ -70,18 +82,14 @@ func (r *PaymentsRepository) GetByID(ctx context.Context, id string) (*payments.
 
 func (r *PaymentsRepository) GetByOrderID(ctx context.Context, orderID string) (*payments.Payment, error) {
 	query := `
-		SELECT id, order_id, user_id, amount, currency, status, error_message, transaction_id, created_at, updated_at
+		SELECT ` + paymentColumns + `
 		FROM payments
 		WHERE order_id = $1`
 
-	row := r.db.QueryRowContext(ctx, query, orderID)
-
-	payment := &payments.Payment{}
-	err := row.Scan(&payment.ID, &payment.OrderID, &payment.UserID, &payment.Amount, &payment.Currency,
-		&payment.Status, &payment.ErrorMessage, &payment.TransactionID, &payment.CreatedAt, &payment.UpdatedAt)
+	payment, err := scanPayment(r.db.QueryRowContext(ctx, query, orderID))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("payment not found for order: %s", orderID)
+			return nil, fmt.Errorf("%w for order: %s", payments.ErrPaymentNotFound, orderID)
 		}
 		return nil, fmt.Errorf("failed to get payment by order ID: %w", err)
 	}
@@ -89,14 +97,93 @@ func (r *PaymentsRepository) GetByOrderID(ctx context.Context, orderID string) (
 	return payment, nil
 }
 
+// GetByOrderIDWithTx блокирует платёж до конца транзакции, чтобы списание
+// и снятие блокировки не выполнялись одновременно.
+func (r *PaymentsRepository) GetByOrderIDWithTx(ctx context.Context, tx *sql.Tx, orderID string) (*payments.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE order_id = $1
+		FOR UPDATE`
+
+	payment, err := scanPayment(tx.QueryRowContext(ctx, query, orderID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w for order: %s", payments.ErrPaymentNotFound, orderID)
+		}
+		return nil, fmt.Errorf("failed to get payment by order ID with tx: %w", err)
+	}
+
+	return payment, nil
+}
+
+// ListExpiredHoldsWithTx возвращает авторизованные платежи с истёкшей блокировкой.
+// Строки, которые уже обрабатывает другой экземпляр сервиса, пропускаются.
+func (r *PaymentsRepository) ListExpiredHoldsWithTx(ctx context.Context, tx *sql.Tx, now time.Time, limit int) ([]*payments.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE status = $1 AND hold_expires_at <= $2
+		ORDER BY hold_expires_at ASC
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.QueryContext(ctx, query, payments.PaymentStatusAuthorized, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired holds: %w", err)
+	}
+
+	return scanPayments(rows)
+}
+
+// ListAwaitingFundsByUserIDWithTx блокирует отложенные платежи пользователя
+// и возвращает их в порядке поступления.
+func (r *PaymentsRepository) ListAwaitingFundsByUserIDWithTx(ctx context.Context, tx *sql.Tx, userID string) ([]*payments.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE status = $1 AND user_id = $2
+		ORDER BY created_at ASC, id ASC
+		FOR UPDATE`
+
+	rows, err := tx.QueryContext(ctx, query, payments.PaymentStatusAwaitingFunds, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments awaiting funds: %w", err)
+	}
+
+	return scanPayments(rows)
+}
+
+// ListExpiredFundsWaitWithTx возвращает отложенные платежи, срок ожидания которых истёк.
+// Строки, которые уже обрабатывает другой экземпляр сервиса, пропускаются.
+func (r *PaymentsRepository) ListExpiredFundsWaitWithTx(ctx context.Context, tx *sql.Tx, now time.Time, limit int) ([]*payments.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE status = $1 AND funds_wait_until <= $2
+		ORDER BY funds_wait_until ASC
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.QueryContext(ctx, query, payments.PaymentStatusAwaitingFunds, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired funds wait: %w", err)
+	}
+
+	return scanPayments(rows)
+}
+
 func (r *PaymentsRepository) Update(ctx context.Context, payment *payments.Payment) error {
 	query := `
 		UPDATE payments
-		SET status = $2, error_message = $3, transaction_id = $4, updated_at = $5
+		SET status = $2, error_message = $3, transaction_id = $4, updated_at = $5,
+		    charged_amount = $6, charged_currency = $7, fx_rate = $8, hold_expires_at = $9, funds_wait_until = $10
 		WHERE id = $1`
 
+	chargedAmount, chargedCurrency, fxRate := chargeColumns(payment)
 	result, err := r.db.ExecContext(ctx, query,
-		payment.ID, payment.Status, payment.ErrorMessage, payment.TransactionID, payment.UpdatedAt)
+		payment.ID, payment.Status, payment.ErrorMessage, payment.TransactionID, payment.UpdatedAt,
+		chargedAmount, chargedCurrency, fxRate, payment.HoldExpiresAt, payment.FundsWaitUntil)
 	if err != nil {
 		return fmt.Errorf("failed to update payment: %w", err)
 	}
@@ -116,11 +203,14 @@ func (r *PaymentsRepository) Update(ctx context.Context, payment *payments.Payme
 func (r *PaymentsRepository) UpdateWithTx(ctx context.Context, tx *sql.Tx, payment *payments.Payment) error {
 	query := `
 		UPDATE payments
-		SET status = $2, error_message = $3, transaction_id = $4, updated_at = $5
+		SET status = $2, error_message = $3, transaction_id = $4, updated_at = $5,
+		    charged_amount = $6, charged_currency = $7, fx_rate = $8, hold_expires_at = $9, funds_wait_until = $10
 		WHERE id = $1`
 
+	chargedAmount, chargedCurrency, fxRate := chargeColumns(payment)
 	result, err := tx.ExecContext(ctx, query,
-		payment.ID, payment.Status, payment.ErrorMessage, payment.TransactionID, payment.UpdatedAt)
+		payment.ID, payment.Status, payment.ErrorMessage, payment.TransactionID, payment.UpdatedAt,
+		chargedAmount, chargedCurrency, fxRate, payment.HoldExpiresAt, payment.FundsWaitUntil)
 	if err != nil {
 		return fmt.Errorf("failed to update payment with tx: %w", err)
 	}
@@ -136,3 +226,102 @@ func (r *PaymentsRepository) UpdateWithTx(ctx context.Context, tx *sql.Tx, payme
 
 	return nil
 }
+
+const paymentColumns = `id, order_id, user_id, amount, currency, charged_amount, charged_currency, fx_rate,
+		       status, error_message, transaction_id, hold_expires_at, funds_wait_until, created_at, updated_at`
+
+type paymentScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPayment(row paymentScanner) (*payments.Payment, error) {
+	payment := &payments.Payment{}
+	var amount, currency string
+	var chargedAmount, chargedCurrency, fxRate sql.NullString
+	var holdExpiresAt, fundsWaitUntil sql.NullTime
+	err := row.Scan(&payment.ID, &payment.OrderID, &payment.UserID, &amount, &currency,
+		&chargedAmount, &chargedCurrency, &fxRate,
+		&payment.Status, &payment.ErrorMessage, &payment.TransactionID, &holdExpiresAt, &fundsWaitUntil,
+		&payment.CreatedAt, &payment.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	payment.Amount, err = money.Parse(amount, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse payment amount: %w", err)
+	}
+
+	if err := scanCharge(payment, chargedAmount, chargedCurrency, fxRate); err != nil {
+		return nil, err
+	}
+
+	if holdExpiresAt.Valid {
+		payment.HoldExpiresAt = &holdExpiresAt.Time
+	}
+	if fundsWaitUntil.Valid {
+		payment.FundsWaitUntil = &fundsWaitUntil.Time
+	}
+
+	return payment, nil
+}
+
+func scanPayments(rows *sql.Rows) ([]*payments.Payment, error) {
+	defer rows.Close()
+
+	var result []*payments.Payment
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		result = append(result, payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate payments: %w", err)
+	}
+
+	return result, nil
+}
+
+// chargeColumns раскладывает списание по колонкам; пока платёж не проведён, они NULL.
+func chargeColumns(payment *payments.Payment) (sql.NullString, sql.NullString, sql.NullString) {
+	var chargedAmount, chargedCurrency, fxRate sql.NullString
+	if payment.ChargedAmount.Currency() != "" {
+		chargedAmount = sql.NullString{String: payment.ChargedAmount.Decimal(), Valid: true}
+		chargedCurrency = sql.NullString{String: payment.ChargedAmount.Currency(), Valid: true}
+	}
+	if payment.ExchangeRate != nil {
+		fxRate = sql.NullString{String: payment.ExchangeRate.Decimal(), Valid: true}
+	}
+	return chargedAmount, chargedCurrency, fxRate
+}
+
+func scanCharge(payment *payments.Payment, chargedAmount, chargedCurrency, fxRate sql.NullString) error {
+	if !chargedAmount.Valid || !chargedCurrency.Valid {
+		return nil
+	}
+
+	charged, err := money.Parse(chargedAmount.String, chargedCurrency.String)
+	if err != nil {
+		return fmt.Errorf("failed to parse charged amount: %w", err)
+	}
+	payment.ChargedAmount = charged
+
+	if fxRate.Valid {
+		rate, err := money.ParseExchangeRate(payment.Amount.Currency(), charged.Currency(), fxRate.String)
+		if err != nil {
+			return fmt.Errorf("failed to parse fx rate: %w", err)
+		}
+		payment.ExchangeRate = &rate
+	}
+
+	return nil
+}
+
+// isUniqueViolation — конфликт уникального индекса, например второй платёж по тому же заказу
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -2,10 +2,15 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"contracts/money"
 	"payments-service/internal/application/service"
+	"payments-service/internal/domain/account"
+	"payments-service/internal/domain/ledger"
 )
 
 type AccountsHandler struct {
@@ -19,29 +24,74 @@ func NewAccountsHandler(accountService *service.AccountService) *AccountsHandler
 }
 
 type CreateAccountResponse struct {
-	ID        string  `json:"id"`
-	UserID    string  `json:"user_id"`
-	Balance   float64 `json:"balance"`
-	CreatedAt string  `json:"created_at"`
+	ID           string      `json:"id"`
+	UserID       string      `json:"user_id"`
+	BalanceMoney money.Money `json:"balance_money"`
+	CreatedAt    string      `json:"created_at"`
+
+	// Deprecated: use BalanceMoney.
+	Balance float64 `json:"balance"`
 }
 
 type TopUpAccountRequest struct {
+	AmountMoney *money.Money `json:"amount_money"`
+
+	// Deprecated: use AmountMoney.
 	Amount float64 `json:"amount"`
 }
 
 type TopUpAccountResponse struct {
-	ID        string  `json:"id"`
-	UserID    string  `json:"user_id"`
-	Balance   float64 `json:"balance"`
-	UpdatedAt string  `json:"updated_at"`
+	ID             string      `json:"id"`
+	UserID         string      `json:"user_id"`
+	BalanceMoney   money.Money `json:"balance_money"`
+	AvailableMoney money.Money `json:"available_money"`
+	CurrentMoney   money.Money `json:"current_money"`
+	UpdatedAt      string      `json:"updated_at"`
+
+	// Deprecated: use BalanceMoney.
+	Balance float64 `json:"balance"`
 }
 
+// AccountInfoResponse описывает основной кошелёк пользователя и список всех его кошельков.
+// current — текущий баланс, available — он же за вычетом заблокированных под платежи сумм.
 type AccountInfoResponse struct {
-	ID        string  `json:"id"`
-	UserID    string  `json:"user_id"`
-	Balance   float64 `json:"balance"`
-	CreatedAt string  `json:"created_at"`
-	UpdatedAt string  `json:"updated_at"`
+	ID             string           `json:"id"`
+	UserID         string           `json:"user_id"`
+	BalanceMoney   money.Money      `json:"balance_money"`
+	AvailableMoney money.Money      `json:"available_money"`
+	CurrentMoney   money.Money      `json:"current_money"`
+	Wallets        []WalletResponse `json:"wallets"`
+	CreatedAt      string           `json:"created_at"`
+	UpdatedAt      string           `json:"updated_at"`
+
+	// Deprecated: use BalanceMoney.
+	Balance float64 `json:"balance"`
+}
+
+type WalletResponse struct {
+	ID             string      `json:"id"`
+	Currency       string      `json:"currency"`
+	BalanceMoney   money.Money `json:"balance_money"`
+	AvailableMoney money.Money `json:"available_money"`
+	CurrentMoney   money.Money `json:"current_money"`
+	UpdatedAt      string      `json:"updated_at"`
+}
+
+type TransactionResponse struct {
+	ID            string      `json:"id"`
+	TransactionID string      `json:"transaction_id"`
+	WalletID      string      `json:"wallet_id"`
+	Kind          string      `json:"kind"`
+	Direction     string      `json:"direction"`
+	AmountMoney   money.Money `json:"amount_money"`
+	Reference     string      `json:"reference"`
+	Description   string      `json:"description"`
+	CreatedAt     string      `json:"created_at"`
+}
+
+type TransactionsResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+	NextCursor   string                `json:"next_cursor,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -64,7 +114,7 @@ func (h *AccountsHandler) CreateAccount(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	account, err := h.accountService.CreateAccount(r.Context())
+	acc, err := h.accountService.CreateAccount(r.Context())
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to create account"})
@@ -72,10 +122,11 @@ func (h *AccountsHandler) CreateAccount(w http.ResponseWriter, r *http.Request)
 	}
 
 	response := CreateAccountResponse{
-		ID:        account.ID,
-		UserID:    account.UserID,
-		Balance:   account.Balance,
-		CreatedAt: account.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:           acc.ID,
+		UserID:       acc.UserID,
+		BalanceMoney: acc.Balance,
+		CreatedAt:    acc.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Balance:      acc.Balance.Float64(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -85,14 +136,18 @@ func (h *AccountsHandler) CreateAccount(w http.ResponseWriter, r *http.Request)
 
 // TopUpAccount пополняет баланс счета
 // @Summary Top up account balance
-// @Description Add funds to an existing account
+// @Description Add funds to the user's wallet in the amount currency; the wallet is opened on first top-up
 // @Tags Accounts
 // @Accept json
 // @Produce json
 // @Param user_id path string true "User ID"
+// @Param Idempotency-Key header string false "Key to safely retry the request; a replay returns the original response"
 // @Param request body TopUpAccountRequest true "Top up request"
 // @Success 200 {object} TopUpAccountResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "Request with the same Idempotency-Key is in progress"
+// @Failure 422 {object} ErrorResponse "Idempotency-Key was used with a different body"
 // @Failure 500 {object} ErrorResponse
 // @Router /accounts/{user_id}/topup [post]
 func (h *AccountsHandler) TopUpAccount(w http.ResponseWriter, r *http.Request) {
@@ -118,24 +173,47 @@ func (h *AccountsHandler) TopUpAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Amount <= 0 {
+	amount := money.FromFloat(req.Amount, money.USD)
+	if req.AmountMoney != nil {
+		amount = *req.AmountMoney
+	}
+
+	if !amount.IsPositive() {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(ErrorResponse{Error: "Amount must be positive"})
 		return
 	}
 
-	account, err := h.accountService.TopUpAccount(r.Context(), userID, req.Amount)
+	if err := money.ValidateCurrency(amount.Currency()); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	acc, err := h.accountService.TopUpAccount(r.Context(), userID, amount)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to top up account"})
+		switch {
+		case errors.Is(err, money.ErrUnsupportedCurrency):
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		case errors.Is(err, account.ErrAccountNotFound):
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Account not found"})
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to top up account"})
+		}
 		return
 	}
 
 	response := TopUpAccountResponse{
-		ID:        account.ID,
-		UserID:    account.UserID,
-		Balance:   account.Balance,
-		UpdatedAt: account.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:             acc.ID,
+		UserID:         acc.UserID,
+		BalanceMoney:   acc.Balance,
+		AvailableMoney: acc.Available(),
+		CurrentMoney:   acc.Current(),
+		UpdatedAt:      acc.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Balance:        acc.Balance.Float64(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -145,7 +223,7 @@ func (h *AccountsHandler) TopUpAccount(w http.ResponseWriter, r *http.Request) {
 
 // GetAccountInfo получает информацию о счете
 // @Summary Get account information
-// @Description Get account details by user ID
+// @Description Get the primary wallet and all currency wallets by user ID
 // @Tags Accounts
 // @Produce json
 // @Param user_id path string true "User ID"
@@ -169,19 +247,100 @@ func (h *AccountsHandler) GetAccountInfo(w http.ResponseWriter, r *http.Request)
 	}
 	userID := parts[3]
 
-	account, err := h.accountService.GetAccountInfo(r.Context(), userID)
+	wallets, err := h.accountService.GetAccountInfo(r.Context(), userID)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(ErrorResponse{Error: "Account not found"})
 		return
 	}
 
+	primary := wallets[0]
 	response := AccountInfoResponse{
-		ID:        account.ID,
-		UserID:    account.UserID,
-		Balance:   account.Balance,
-		CreatedAt: account.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: account.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:             primary.ID,
+		UserID:         primary.UserID,
+		BalanceMoney:   primary.Balance,
+		AvailableMoney: primary.Available(),
+		CurrentMoney:   primary.Current(),
+		Wallets:        make([]WalletResponse, 0, len(wallets)),
+		CreatedAt:      primary.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:      primary.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Balance:        primary.Balance.Float64(),
+	}
+	for _, wallet := range wallets {
+		response.Wallets = append(response.Wallets, WalletResponse{
+			ID:             wallet.ID,
+			Currency:       wallet.Currency(),
+			BalanceMoney:   wallet.Balance,
+			AvailableMoney: wallet.Available(),
+			CurrentMoney:   wallet.Current(),
+			UpdatedAt:      wallet.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetTransactions возвращает историю операций по кошелькам пользователя
+// @Summary List account transactions
+// @Description Ledger entries of all user wallets, newest first, with cursor pagination
+// @Tags Accounts
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Success 200 {object} TransactionsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /accounts/{user_id}/transactions [get]
+func (h *AccountsHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("user_id")
+	if userID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "User ID is required"})
+		return
+	}
+
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	page, err := h.accountService.GetTransactions(r.Context(), userID, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, ledger.ErrInvalidCursor) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid cursor"})
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to get transactions"})
+		return
+	}
+
+	response := TransactionsResponse{
+		Transactions: make([]TransactionResponse, 0, len(page.Entries)),
+		NextCursor:   page.NextCursor,
+	}
+	for _, entry := range page.Entries {
+		response.Transactions = append(response.Transactions, TransactionResponse{
+			ID:            entry.ID,
+			TransactionID: entry.TransactionID,
+			WalletID:      entry.LedgerAccount,
+			Kind:          string(entry.Kind),
+			Direction:     string(entry.Direction),
+			AmountMoney:   entry.Amount,
+			Reference:     entry.Reference,
+			Description:   entry.Description,
+			CreatedAt:     entry.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
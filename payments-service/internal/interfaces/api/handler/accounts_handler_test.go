@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"payments-service/internal/application/service"
+)
+
+func TestAccountsHandler_TopUpAccount_InvalidCurrency(t *testing.T) {
+	// Сервис без зависимостей: запрос с неверной валютой не должен до него дойти
+	handler := NewAccountsHandler(&service.AccountService{})
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "empty currency", body: `{"amount_money": {"minor_units": 1000, "currency": ""}}`},
+		{name: "unsupported currency", body: `{"amount_money": {"minor_units": 1000, "currency": "XYZ"}}`},
+		{name: "malformed currency", body: `{"amount_money": {"minor_units": 1000, "currency": "DOLLARS"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/accounts/user-1/topup", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler.TopUpAccount(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
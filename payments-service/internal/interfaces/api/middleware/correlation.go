@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"messaging/correlation"
+)
+
+const (
+	CorrelationIDHeader = "X-Correlation-ID"
+	TraceparentHeader   = "traceparent"
+)
+
+// Correlation кладёт X-Correlation-ID и traceparent запроса в контекст: события,
+// записанные в outbox при обработке запроса, унаследуют их
+func Correlation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationID := r.Header.Get(CorrelationIDHeader)
+		traceparent := r.Header.Get(TraceparentHeader)
+		if correlationID == "" && traceparent == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if correlationID != "" {
+			w.Header().Set(CorrelationIDHeader, correlationID)
+		}
+
+		ctx := correlation.WithMetadata(r.Context(), correlation.Metadata{
+			CorrelationID: correlationID,
+			Traceparent:   traceparent,
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -1,21 +1,28 @@
 package router
 
 import (
+	"messaging/idempotency"
 	"net/http"
 	"payments-service/internal/interfaces/api/handler"
+	"payments-service/internal/interfaces/api/middleware"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type Router struct {
 	infoHandler     *handler.InfoHandler
 	docsHandler     *handler.DocsHandler
 	accountsHandler *handler.AccountsHandler
+	idempotency     *idempotency.Middleware
 }
 
-func NewRouter(accountsHandler *handler.AccountsHandler) *Router {
+func NewRouter(accountsHandler *handler.AccountsHandler, idempotencyMiddleware *idempotency.Middleware) *Router {
 	return &Router{
 		infoHandler:     handler.NewInfoHandler(),
 		docsHandler:     handler.NewDocsHandler(),
 		accountsHandler: accountsHandler,
+		idempotency:     idempotencyMiddleware,
 	}
 }
 
@@ -28,8 +35,21 @@ func (r *Router) SetupRoutes() http.Handler {
 	mux.HandleFunc("GET /payments-api/docs/swagger.json", r.docsHandler.Swagger)
 
 	mux.HandleFunc("POST /payments-api/accounts", r.accountsHandler.CreateAccount)
-	mux.HandleFunc("GET /payments-api/accounts/", r.accountsHandler.GetAccountInfo) // /accounts/{user_id}
-	mux.HandleFunc("POST /payments-api/accounts/", r.accountsHandler.TopUpAccount)  // /accounts/{user_id}/topup
+	mux.HandleFunc("GET /payments-api/accounts/", r.accountsHandler.GetAccountInfo)                                  // /accounts/{user_id}
+	mux.HandleFunc("POST /payments-api/accounts/", r.idempotency.Wrap(r.accountsHandler.TopUpAccount, accountOwner)) // /accounts/{user_id}/topup
+	mux.HandleFunc("GET /payments-api/accounts/{user_id}/transactions", r.accountsHandler.GetTransactions)
+
+	// Метрики очередей консьюмера Kafka для Prometheus
+	mux.Handle("GET /metrics", promhttp.Handler())
 
-	return mux
+	return middleware.Correlation(mux)
+}
+
+// accountOwner берёт пользователя из пути /payments-api/accounts/{user_id}/...
+func accountOwner(r *http.Request, body []byte) string {
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) < 4 {
+		return ""
+	}
+	return parts[3]
 }
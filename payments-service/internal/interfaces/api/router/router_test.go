@@ -0,0 +1,157 @@
+package router
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"contracts/money"
+	"messaging/idempotency"
+	"messaging/outbox"
+	"payments-service/internal/application/service"
+	"payments-service/internal/domain/account"
+	"payments-service/internal/domain/ledger"
+	"payments-service/internal/interfaces/api/handler"
+	"payments-service/internal/interfaces/repository"
+)
+
+type memoryIdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[string]idempotency.Record
+}
+
+func (m *memoryIdempotencyRepository) Reserve(ctx context.Context, record *idempotency.Record, expiredBefore time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.records[record.Scope+record.Key]; ok && !existing.CreatedAt.Before(expiredBefore) {
+		return false, nil
+	}
+	m.records[record.Scope+record.Key] = *record
+	return true, nil
+}
+
+func (m *memoryIdempotencyRepository) Get(ctx context.Context, scope, key string) (*idempotency.Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, ok := m.records[scope+key]
+	if !ok {
+		return nil, idempotency.ErrRecordNotFound
+	}
+	return &record, nil
+}
+
+func (m *memoryIdempotencyRepository) Complete(ctx context.Context, record *idempotency.Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.records[record.Scope+record.Key] = *record
+	return nil
+}
+
+func (m *memoryIdempotencyRepository) Delete(ctx context.Context, scope, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.records, scope+key)
+	return nil
+}
+
+// walletRepository хранит один кошелёк и считает его сохранения
+type walletRepository struct {
+	repository.AccountRepository
+	wallet  *account.Account
+	updates int
+}
+
+func (r *walletRepository) GetByUserIDAndCurrencyWithTx(ctx context.Context, tx *sql.Tx, userID, currency string) (*account.Account, error) {
+	return r.wallet, nil
+}
+
+func (r *walletRepository) UpdateWithTx(ctx context.Context, tx *sql.Tx, acc *account.Account) error {
+	r.updates++
+	return nil
+}
+
+// journalRepository ведёт баланс по проводкам, как ledger_entries
+type journalRepository struct {
+	repository.LedgerRepository
+	posted []*ledger.Transaction
+	wallet *account.Account
+}
+
+func (r *journalRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, txn *ledger.Transaction) error {
+	r.posted = append(r.posted, txn)
+	return nil
+}
+
+func (r *journalRepository) BalanceWithTx(ctx context.Context, tx *sql.Tx, ledgerAccount, currency string) (money.Money, error) {
+	return r.wallet.Balance, nil
+}
+
+type outboxRepository struct {
+	outbox.Repository
+	stored []*outbox.OutboxMessage
+}
+
+func (r *outboxRepository) StoreWithTx(ctx context.Context, tx *sql.Tx, message *outbox.OutboxMessage) error {
+	r.stored = append(r.stored, message)
+	return nil
+}
+
+func TestRouter_TopUpReplayCreditsOnce(t *testing.T) {
+	db, mockSQL, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	wallet, err := account.NewAccount("user-1", money.USD)
+	require.NoError(t, err)
+
+	accounts := &walletRepository{wallet: wallet}
+	journal := &journalRepository{wallet: wallet}
+	events := &outboxRepository{}
+
+	accountService := service.NewAccountService(db, accounts, journal, events)
+	r := NewRouter(
+		handler.NewAccountsHandler(accountService),
+		idempotency.NewMiddleware(&memoryIdempotencyRepository{records: make(map[string]idempotency.Record)}, time.Hour),
+	)
+	routes := r.SetupRoutes()
+
+	// Транзакция пополнения открывается только один раз
+	mockSQL.ExpectBegin()
+	mockSQL.ExpectCommit()
+
+	topUp := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/payments-api/accounts/user-1/topup",
+			strings.NewReader(`{"amount_money": {"minor_units": 5000, "currency": "USD"}}`))
+		req.Header.Set(idempotency.KeyHeader, "topup-1")
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := topUp()
+	second := topUp()
+
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get(idempotency.ReplayedHeader))
+
+	assert.Equal(t, money.New(5000, money.USD), wallet.Balance)
+	assert.Equal(t, 1, accounts.updates)
+	assert.Len(t, journal.posted, 1)
+	assert.Len(t, events.stored, 1)
+	assert.NoError(t, mockSQL.ExpectationsWereMet())
+}
@@ -0,0 +1,16 @@
+package fx
+
+import (
+	"context"
+	"errors"
+
+	"contracts/money"
+)
+
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// RateProvider отдаёт курс пересчёта from → to. Реализация по умолчанию —
+// статическая таблица из файла, её можно заменить на внешний источник курсов.
+type RateProvider interface {
+	Rate(ctx context.Context, from, to string) (money.ExchangeRate, error)
+}
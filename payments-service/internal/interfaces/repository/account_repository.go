@@ -7,11 +7,14 @@ import (
 	"payments-service/internal/domain/account"
 )
 
+// AccountRepository меняет баланс только внутри транзакции: кошелёк читается
+// с блокировкой строки (*WithTx) и сохраняется через UpdateWithTx.
 type AccountRepository interface {
 	Store(ctx context.Context, account *account.Account) error
 	StoreWithTx(ctx context.Context, tx *sql.Tx, account *account.Account) error
-	GetByUserID(ctx context.Context, userID string) (*account.Account, error)
-	GetByUserIDWithTx(ctx context.Context, tx *sql.Tx, userID string) (*account.Account, error)
-	Update(ctx context.Context, account *account.Account) error
+	GetByUserIDAndCurrency(ctx context.Context, userID, currency string) (*account.Account, error)
+	GetByUserIDAndCurrencyWithTx(ctx context.Context, tx *sql.Tx, userID, currency string) (*account.Account, error)
+	ListByUserID(ctx context.Context, userID string) ([]*account.Account, error)
+	ListByUserIDWithTx(ctx context.Context, tx *sql.Tx, userID string) ([]*account.Account, error)
 	UpdateWithTx(ctx context.Context, tx *sql.Tx, account *account.Account) error
 }
@@ -0,0 +1,5 @@
+package repository
+
+import "messaging/idempotency"
+
+type IdempotencyRepository = idempotency.Repository
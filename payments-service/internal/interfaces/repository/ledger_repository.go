@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"contracts/money"
+	"payments-service/internal/domain/ledger"
+)
+
+type LedgerRepository interface {
+	StoreWithTx(ctx context.Context, tx *sql.Tx, txn *ledger.Transaction) error
+	// BalanceWithTx считает баланс счёта по журналу: кредиты минус дебеты.
+	BalanceWithTx(ctx context.Context, tx *sql.Tx, ledgerAccount, currency string) (money.Money, error)
+	ListEntriesByUserID(ctx context.Context, userID string, after *ledger.Cursor, limit int) ([]*ledger.Entry, error)
+}
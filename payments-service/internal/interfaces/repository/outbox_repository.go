@@ -1,17 +1,8 @@
 package repository
 
-import (
-	"context"
-	"database/sql"
+import "messaging/outbox"
 
-	"payments-service/internal/domain/outbox"
-)
+type OutboxRepository = outbox.Repository
 
-type OutboxRepository interface {
-	Store(ctx context.Context, message *outbox.OutboxMessage) error
-	StoreWithTx(ctx context.Context, tx *sql.Tx, message *outbox.OutboxMessage) error
-	GetPendingMessages(ctx context.Context, limit int) ([]*outbox.OutboxMessage, error)
-	GetFailedMessages(ctx context.Context, maxRetries, limit int) ([]*outbox.OutboxMessage, error)
-	MarkAsSent(ctx context.Context, id string) error
-	MarkAsFailed(ctx context.Context, id string) error
-}
+// OutboxNotifier сигналит, что в outbox появились сообщения к отправке
+type OutboxNotifier = outbox.Notifier
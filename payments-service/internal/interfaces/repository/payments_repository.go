@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"payments-service/internal/domain/payments"
 )
@@ -12,6 +13,10 @@ type PaymentsRepository interface {
 	StoreWithTx(ctx context.Context, tx *sql.Tx, payment *payments.Payment) error
 	GetByID(ctx context.Context, id string) (*payments.Payment, error)
 	GetByOrderID(ctx context.Context, orderID string) (*payments.Payment, error)
+	GetByOrderIDWithTx(ctx context.Context, tx *sql.Tx, orderID string) (*payments.Payment, error)
+	ListExpiredHoldsWithTx(ctx context.Context, tx *sql.Tx, now time.Time, limit int) ([]*payments.Payment, error)
+	ListAwaitingFundsByUserIDWithTx(ctx context.Context, tx *sql.Tx, userID string) ([]*payments.Payment, error)
+	ListExpiredFundsWaitWithTx(ctx context.Context, tx *sql.Tx, now time.Time, limit int) ([]*payments.Payment, error)
 	Update(ctx context.Context, payment *payments.Payment) error
 	UpdateWithTx(ctx context.Context, tx *sql.Tx, payment *payments.Payment) error
 }
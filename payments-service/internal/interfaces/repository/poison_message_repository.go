@@ -0,0 +1,5 @@
+package repository
+
+import "messaging/poison"
+
+type PoisonMessageRepository = poison.Repository